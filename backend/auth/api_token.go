@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// apiTokenPrefix tells API tokens apart from the JWTs issued at login.
+const apiTokenPrefix = "wct_"
+
+// apiTokenScopeRoutes lists the paths each scope allows to write to.
+var apiTokenScopeRoutes = map[string][]string{
+	configmodels.ApiTokenScopeSubscribersWrite:  {"/api/subscriber"},
+	configmodels.ApiTokenScopeSlicesWrite:       {"/config/v1/network-slice"},
+	configmodels.ApiTokenScopeDeviceGroupsWrite: {"/config/v1/device-group"},
+	configmodels.ApiTokenScopeInventoryWrite:    {"/config/v1/inventory"},
+}
+
+// GenerateAPIToken returns a new API token and the hash it is stored under.
+func GenerateAPIToken() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, hashAPIToken(token), nil
+}
+
+// hashAPIToken returns the SHA-256 hash of a token. API tokens are random, so
+// unlike passwords they need no salt or slow hash.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func isAPIToken(token string) bool {
+	return strings.HasPrefix(token, apiTokenPrefix)
+}
+
+// getClaimsFromAPIToken looks up an API token and returns the claims of its
+// account. Revoked tokens are deleted, so they are not found. The role is read
+// from the account on every request.
+func getClaimsFromAPIToken(token string, now time.Time) (*jwtWebconsoleClaims, error) {
+	rawToken, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.ApiTokenDataColl, bson.M{"token-hash": hashAPIToken(token)})
+	if err != nil {
+		return nil, err
+	}
+	if len(rawToken) == 0 {
+		return nil, fmt.Errorf("API token not found")
+	}
+	var apiToken configmodels.DBApiToken
+	if err = json.Unmarshal(configmodels.MapToByte(rawToken), &apiToken); err != nil {
+		return nil, err
+	}
+	if apiToken.ExpiresAt != nil && !now.Before(*apiToken.ExpiresAt) {
+		return nil, fmt.Errorf("API token %s has expired", apiToken.Id)
+	}
+	rawUserAccount, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.UserAccountDataColl, bson.M{"username": apiToken.Username})
+	if err != nil {
+		return nil, err
+	}
+	if len(rawUserAccount) == 0 {
+		return nil, fmt.Errorf("account %s of API token %s not found", apiToken.Username, apiToken.Id)
+	}
+	var dbUser configmodels.DBUserAccount
+	if err = json.Unmarshal(configmodels.MapToByte(rawUserAccount), &dbUser); err != nil {
+		return nil, err
+	}
+	return &jwtWebconsoleClaims{
+		Username: dbUser.Username,
+		Role:     dbUser.Role,
+		Scopes:   apiToken.Scopes,
+	}, nil
+}
+
+// scopesAllow reports whether a token with the given scopes may send a
+// request. Tokens without scopes have the full access of their account, scoped
+// tokens can read everything and only write the paths their scopes cover.
+func scopesAllow(scopes []string, method, path string) bool {
+	if len(scopes) == 0 || method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return true
+	}
+	for _, scope := range scopes {
+		for _, prefix := range apiTokenScopeRoutes[scope] {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
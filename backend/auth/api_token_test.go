@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockMongoClientApiTokens struct {
+	dbadapter.DBInterface
+	tokens   []map[string]interface{}
+	accounts []map[string]interface{}
+}
+
+func (db *MockMongoClientApiTokens) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	documents, field := db.accounts, "username"
+	if collName == configmodels.ApiTokenDataColl {
+		documents, field = db.tokens, "token-hash"
+	}
+	for _, document := range documents {
+		if document[field] == filter[field] {
+			return document, nil
+		}
+	}
+	return map[string]interface{}{}, nil
+}
+
+func TestApiTokenAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockJWTKeys := NewHMACJWTKeys([]byte("mockSecret"))
+	token, tokenHash, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("failed to generate API token: %v", err)
+	}
+	if !strings.HasPrefix(token, apiTokenPrefix) || tokenHash != hashAPIToken(token) {
+		t.Fatalf("unexpected API token %s with hash %s", token, tokenHash)
+	}
+	serviceAccount := map[string]interface{}{"username": "ci-bot", "role": configmodels.UserRole, "service-account": true}
+	apiToken := func(scopes []string, expiresAt time.Time) map[string]interface{} {
+		document := map[string]interface{}{"id": "token1", "username": "ci-bot", "name": "ci", "token-hash": tokenHash}
+		if scopes != nil {
+			document["scopes"] = scopes
+		}
+		if !expiresAt.IsZero() {
+			document["expires-at"] = primitive.NewDateTimeFromTime(expiresAt)
+		}
+		return document
+	}
+
+	testCases := []struct {
+		name         string
+		token        string
+		tokens       []map[string]interface{}
+		accounts     []map[string]interface{}
+		method       string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "unscoped token",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken(nil, time.Time{})},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodPost,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "token not expired yet",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken(nil, time.Now().Add(time.Hour))},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodGet,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "expired token",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken(nil, time.Now().Add(-time.Hour))},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodGet,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "revoked token",
+			token:        token,
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodGet,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "token of a deleted account",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken(nil, time.Time{})},
+			method:       http.MethodGet,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "read-only token reads",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken([]string{configmodels.ApiTokenScopeReadOnly}, time.Time{})},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodGet,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "read-only token writes",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken([]string{configmodels.ApiTokenScopeReadOnly}, time.Time{})},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodPost,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "token writes in scope",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken([]string{configmodels.ApiTokenScopeSubscribersWrite}, time.Time{})},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodPost,
+			path:         "/api/subscriber/imsi-208930100007487",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "token writes out of scope",
+			token:        token,
+			tokens:       []map[string]interface{}{apiToken([]string{configmodels.ApiTokenScopeSubscribersWrite}, time.Time{})},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodDelete,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "unknown token",
+			token:        apiTokenPrefix + "unknown",
+			tokens:       []map[string]interface{}{apiToken(nil, time.Time{})},
+			accounts:     []map[string]interface{}{serviceAccount},
+			method:       http.MethodGet,
+			path:         "/config/v1/network-slice/slice1",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.WebuiDBClient
+			defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+			dbadapter.WebuiDBClient = &MockMongoClientApiTokens{tokens: tc.tokens, accounts: tc.accounts}
+			router := gin.New()
+			router.Use(AdminOrUserAuthMiddleware(mockJWTKeys))
+			router.Handle(tc.method, tc.path, func(c *gin.Context) {
+				if username := UsernameFromContext(c.Request.Context()); username != "ci-bot" {
+					t.Errorf("Expected username `ci-bot`, got `%v`", username)
+				}
+				c.JSON(http.StatusOK, gin.H{})
+			})
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestAdminOnly_ApiToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockJWTKeys := NewHMACJWTKeys([]byte("mockSecret"))
+	token, tokenHash, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("failed to generate API token: %v", err)
+	}
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbadapter.WebuiDBClient = &MockMongoClientApiTokens{
+		tokens:   []map[string]interface{}{{"id": "token1", "username": "ci-bot", "name": "ci", "token-hash": tokenHash}},
+		accounts: []map[string]interface{}{{"username": "ci-bot", "role": configmodels.UserRole, "service-account": true}},
+	}
+	router := gin.New()
+	router.GET("/config/v1/account", AdminOnly(mockJWTKeys, func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) }))
+	router.GET("/config/v1/account/:username", AdminOrMe(mockJWTKeys, func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) }))
+
+	for path, expectedCode := range map[string]int{
+		"/config/v1/account":        http.StatusForbidden,
+		"/config/v1/account/ci-bot": http.StatusOK,
+		"/config/v1/account/admin":  http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if expectedCode != w.Code {
+			t.Errorf("%s: expected `%v`, got `%v`", path, expectedCode, w.Code)
+		}
+	}
+}
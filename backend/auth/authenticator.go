@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrIncorrectCredentials is returned by an Authenticator refusing the
+	// username or the password.
+	ErrIncorrectCredentials = errors.New("incorrect username or password")
+	// ErrAuthenticatorUnavailable is returned by an Authenticator which cannot
+	// reach the service checking the credentials.
+	ErrAuthenticatorUnavailable = errors.New("authentication service is unavailable")
+
+	errAccountConflict = errors.New("a local account with the same username exists")
+	errNotInitialized  = errors.New("the admin account must be created before logging in with an identity provider")
+	errNoMappedRole    = errors.New("no role is mapped to the account")
+)
+
+// Authenticator checks the password of a user and returns their account.
+type Authenticator interface {
+	Authenticate(ctx context.Context, username string, password string) (*configmodels.DBUserAccount, error)
+}
+
+// NewAuthenticator returns the authenticator of the login: the LDAP directory
+// if ldapConfig is set, the accounts of the webui database otherwise. With a
+// directory, the local admin account keeps its password, so that it can log
+// in even if the directory is unreachable.
+func NewAuthenticator(ldapConfig *factory.LDAP) (Authenticator, error) {
+	if ldapConfig == nil {
+		return localAuthenticator{}, nil
+	}
+	directory, err := newLDAPAuthenticator(ldapConfig)
+	if err != nil {
+		return nil, err
+	}
+	return breakGlassAuthenticator{directory: directory}, nil
+}
+
+// localAuthenticator checks the bcrypt hash of the password of the account.
+type localAuthenticator struct{}
+
+func NewLocalAuthenticator() Authenticator {
+	return localAuthenticator{}
+}
+
+func (localAuthenticator) Authenticate(ctx context.Context, username string, password string) (*configmodels.DBUserAccount, error) {
+	dbUser, err := fetchDBUserAccount(username)
+	if err != nil {
+		return nil, err
+	}
+	if dbUser == nil {
+		return nil, ErrIncorrectCredentials
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(dbUser.HashedPassword), []byte(password)); err != nil {
+		logger.AuthLog.Errorln(err.Error())
+		return nil, ErrIncorrectCredentials
+	}
+	return dbUser, nil
+}
+
+// breakGlassAuthenticator authenticates the local admin account with its
+// password, and the other users with the directory.
+type breakGlassAuthenticator struct {
+	directory Authenticator
+}
+
+func (a breakGlassAuthenticator) Authenticate(ctx context.Context, username string, password string) (*configmodels.DBUserAccount, error) {
+	dbUser, err := fetchDBUserAccount(username)
+	if err != nil {
+		return nil, err
+	}
+	if dbUser != nil && dbUser.Role == configmodels.AdminRole && dbUser.HashedPassword != "" && dbUser.LDAPDN == "" {
+		return localAuthenticator{}.Authenticate(ctx, username, password)
+	}
+	return a.directory.Authenticate(ctx, username, password)
+}
+
+func fetchDBUserAccount(username string) (*configmodels.DBUserAccount, error) {
+	rawUserAccount, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.UserAccountDataColl, bson.M{"username": username})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user account: %w", err)
+	}
+	if len(rawUserAccount) == 0 {
+		return nil, nil
+	}
+	var dbUser configmodels.DBUserAccount
+	if err = json.Unmarshal(configmodels.MapToByte(rawUserAccount), &dbUser); err != nil {
+		return nil, fmt.Errorf("failed to decode user account: %w", err)
+	}
+	return &dbUser, nil
+}
+
+// syncExternalAccount returns the account of a user authenticated by an
+// identity provider, creating it on the first login. The role and roles of
+// the account follow the provider at every login, so they are managed there.
+// owned tells whether an existing account is the one of the user, so that
+// local accounts cannot be taken over.
+func syncExternalAccount(account *configmodels.DBUserAccount, owned func(*configmodels.DBUserAccount) bool) (*configmodels.DBUserAccount, error) {
+	if account.Roles == nil {
+		account.Roles = []string{}
+	}
+	dbUser, err := fetchDBUserAccount(account.Username)
+	if err != nil {
+		return nil, err
+	}
+	if dbUser == nil {
+		return createExternalAccount(account)
+	}
+	if !owned(dbUser) {
+		return nil, errAccountConflict
+	}
+	if dbUser.Role != account.Role || !slices.Equal(dbUser.Roles, account.Roles) {
+		update := bson.M{"role": account.Role, "roles": account.Roles}
+		if _, err = dbadapter.WebuiDBClient.RestfulAPIPost(configmodels.UserAccountDataColl, bson.M{"username": account.Username}, update); err != nil {
+			return nil, fmt.Errorf("failed to update the roles of %s: %w", account.Username, err)
+		}
+		dbUser.Role = account.Role
+		dbUser.Roles = account.Roles
+	}
+	return dbUser, nil
+}
+
+func createExternalAccount(account *configmodels.DBUserAccount) (*configmodels.DBUserAccount, error) {
+	// the first account is the admin one, with a password to log in even if
+	// the provider is down
+	numOfUserAccounts, err := dbadapter.WebuiDBClient.RestfulAPICount(configmodels.UserAccountDataColl, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count user accounts: %w", err)
+	}
+	if numOfUserAccounts == 0 {
+		return nil, errNotInitialized
+	}
+	document := bson.M{
+		"username": account.Username,
+		"role":     account.Role,
+		"roles":    account.Roles,
+	}
+	if account.OIDCSubject != "" {
+		document["oidc-subject"] = account.OIDCSubject
+	}
+	if account.LDAPDN != "" {
+		document["ldap-dn"] = account.LDAPDN
+	}
+	err = dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.UserAccountDataColl, bson.M{"username": account.Username}, []interface{}{document})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user account %s: %w", account.Username, err)
+	}
+	logger.AuthLog.Infof("created the account %s of an identity provider", account.Username)
+	return account, nil
+}
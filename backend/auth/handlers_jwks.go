@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWK is a public key verifying tokens, as defined by RFC 7517: the ones of
+// webconsole, or of an OpenID Connect provider.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GetJWKS godoc
+//
+// @Description  Get the public keys verifying the authentication tokens, by key ID. Only RS256 and EdDSA keys are published. Only available if enableAuthentication is enabled.
+// @Tags         Auth
+// @Success      200  {object}  JWKSResponse  "JSON Web Key Set"
+// @Failure      404  {object}  nil           "Page not found if enableAuthentication is disabled"
+// @Router       /.well-known/jwks.json  [get]
+func GetJWKS(jwtKeys *JWTKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, jwtKeys.jwks(time.Now()))
+	}
+}
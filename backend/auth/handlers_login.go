@@ -4,26 +4,27 @@
 package auth
 
 import (
-	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/omec-project/webconsole/backend/factory"
 	"github.com/omec-project/webconsole/backend/logger"
-	"github.com/omec-project/webconsole/configmodels"
-	"github.com/omec-project/webconsole/dbadapter"
-	"go.mongodb.org/mongo-driver/bson"
-	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	errorIncorrectCredentials = "incorrect username or password. Try again"
-	errorInvalidDataProvided  = "invalid data provided"
-	errorLogin                = "failed to log in"
-	errorMissingPassword      = "password is required"
-	errorMissingUsername      = "username is required"
-	errorRetrieveUserAccount  = "failed to retrieve user account"
+	errorAuthenticatorUnavailable = "the directory is unreachable, try again later"
+	errorIncorrectCredentials     = "incorrect username or password. Try again"
+	errorInvalidDataProvided      = "invalid data provided"
+	errorLogin                    = "failed to log in"
+	errorLoginLocked              = "too many failed logins. Try again later"
+	errorMissingPassword          = "password is required"
+	errorMissingUsername          = "username is required"
+	errorRetrieveUserAccount      = "failed to retrieve user account"
 )
 
 type LoginParams struct {
@@ -32,21 +33,26 @@ type LoginParams struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh-token"`
 }
 
 // LoginPost godoc
 //
-// @Description  Log in. Only available if enableAuthentication is enabled.
+// @Description  Log in. The password is checked by the LDAP directory if ldap is configured, except for the admin account. After too many failed logins of the account or from the client IP address, the logins are refused for a while. Only available if enableAuthentication is enabled.
 // @Tags         Auth
 // @Param        loginParams    body    LoginParams    true    " "
-// @Success      200  {object}  LoginResponse  "Authorization token"
+// @Success      200  {object}  LoginResponse  "Access and refresh tokens"
 // @Failure      400  {object}  nil            "Bad request"
 // @Failure      401  {object}  nil            "Authentication failed"
+// @Failure      403  {object}  nil            "No role is mapped to the directory user, or webui is not initialized"
 // @Failure      404  {object}  nil            "Page not found if enableAuthentication is disabled"
+// @Failure      409  {object}  nil            "A local account has the username of the directory user"
+// @Failure      429  {object}  nil            "Too many failed logins, retry after the Retry-After header"
 // @Failure      500  {object}  nil            "Internal server error"
+// @Failure      503  {object}  nil            "LDAP directory unreachable"
 // @Router       /login  [post]
-func Login(jwtSecret []byte) gin.HandlerFunc {
+func Login(authenticator Authenticator, jwtKeys *JWTKeys) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var loginParams LoginParams
 		err := c.ShouldBindJSON(&loginParams)
@@ -64,54 +70,73 @@ func Login(jwtSecret []byte) gin.HandlerFunc {
 			return
 		}
 
-		filter := bson.M{"username": loginParams.Username}
-		rawUserAccount, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.UserAccountDataColl, filter)
+		limits := loginLimits(loginParams.Username, c.ClientIP())
+		lockout, err := loginLockout(limits, time.Now())
 		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
 			c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
 			return
 		}
-		if len(rawUserAccount) == 0 {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": errorIncorrectCredentials})
+		if lockout > 0 {
+			logger.AuthLog.Warnf("login of %s from %s refused, locked out for %s", loginParams.Username, c.ClientIP(), lockout)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockout.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": errorLoginLocked})
 			return
 		}
-		var dbUser configmodels.DBUserAccount
-		err = json.Unmarshal(configmodels.MapToByte(rawUserAccount), &dbUser)
+
+		dbUser, err := authenticator.Authenticate(c.Request.Context(), loginParams.Username, loginParams.Password)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
+			logger.AuthLog.Errorln(err.Error())
+			switch {
+			case errors.Is(err, ErrIncorrectCredentials):
+				if err = recordLoginFailure(limits, time.Now()); err != nil {
+					logger.AuthLog.Errorln(err.Error())
+				}
+				c.JSON(http.StatusUnauthorized, gin.H{"error": errorIncorrectCredentials})
+			case errors.Is(err, ErrAuthenticatorUnavailable):
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": errorAuthenticatorUnavailable})
+			case errors.Is(err, errNoMappedRole), errors.Is(err, errNotInitialized):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			case errors.Is(err, errAccountConflict):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
+			}
 			return
 		}
-		if err = bcrypt.CompareHashAndPassword([]byte(dbUser.HashedPassword), []byte(loginParams.Password)); err != nil {
+		if err = resetLoginFailures(loginParams.Username); err != nil {
 			logger.AuthLog.Errorln(err.Error())
-			c.JSON(http.StatusUnauthorized, gin.H{"error": errorIncorrectCredentials})
+		}
+		session, refreshToken, err := createSession(dbUser.Username, time.Now())
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorLogin})
 			return
 		}
-		token, err := GenerateJWT(dbUser.Username, dbUser.Role, jwtSecret)
+		token, err := GenerateJWT(dbUser.Username, dbUser.Role, session.Id, jwtKeys)
 		if err != nil {
 			logger.AuthLog.Errorln(err.Error())
 			c.JSON(http.StatusInternalServerError, gin.H{"error": errorLogin})
 			return
 		}
 		loginResponse := LoginResponse{
-			Token: token,
+			Token:        token,
+			RefreshToken: refreshToken,
 		}
 		c.JSON(http.StatusOK, loginResponse)
 	}
 }
 
-func GenerateJWT(username string, role int, jwtSecret []byte) (string, error) {
-	tokenExpirationDuration := time.Hour * 1
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtWebconsoleClaims{
-		Username: username,
-		Role:     role,
+// GenerateJWT returns an access token of the login session sessionID.
+func GenerateJWT(username string, role int, sessionID string, jwtKeys *JWTKeys) (string, error) {
+	now := time.Now()
+	return jwtKeys.sign(jwtWebconsoleClaims{
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: &jwt.NumericDate{
-				Time: time.Now().Add(tokenExpirationDuration),
-			},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(factory.WebUIConfig.Configuration.AccessTokenLifetime)),
 		},
 	})
-	tokenString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		return "", err
-	}
-	return tokenString, nil
 }
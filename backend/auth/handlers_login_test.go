@@ -4,6 +4,7 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -132,15 +133,31 @@ func (db *MockMongoClientRegularUser) RestfulAPIGetOne(coll string, filter bson.
 	return rawUser, nil
 }
 
+func (db *MockMongoClientSuccess) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
+func (db *MockMongoClientRegularUser) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 func (db *MockMongoClientRegularUser) RestfulAPIDeleteOne(collName string, filter bson.M) error {
 	return nil
 }
 
+func (db *MockMongoClientSuccess) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	return true, nil
+}
+
+func (db *MockMongoClientSuccess) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	return nil
+}
+
 func TestLogin_FailureCases(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
 	mockJWTSecret := []byte("mockSecret")
-	AddAuthenticationService(router, mockJWTSecret)
+	AddAuthenticationService(router, NewLocalAuthenticator(), NewHMACJWTKeys(mockJWTSecret))
 
 	testCases := []struct {
 		dbAdapter    dbadapter.DBInterface
@@ -224,7 +241,7 @@ func TestLogin_SuccessCases(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
 	mockJWTSecret := []byte("mockSecret")
-	AddAuthenticationService(router, mockJWTSecret)
+	AddAuthenticationService(router, NewLocalAuthenticator(), NewHMACJWTKeys(mockJWTSecret))
 
 	testCases := []struct {
 		dbAdapter        dbadapter.DBInterface
@@ -275,6 +292,9 @@ func TestLogin_SuccessCases(t *testing.T) {
 			if !exists {
 				t.Errorf("Unable to unmarshal response`%v`", w.Body.String())
 			}
+			if !strings.HasPrefix(respondeData["refresh-token"], refreshTokenPrefix) {
+				t.Errorf("Expected a refresh token in `%v`", w.Body.String())
+			}
 
 			token, parseErr := jwt.Parse(responseToken, func(token *jwt.Token) (interface{}, error) {
 				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -287,7 +307,9 @@ func TestLogin_SuccessCases(t *testing.T) {
 				return
 			}
 			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-				if claims["username"] != tc.expectedUsername {
+				if claims["sid"] == "" || claims["sid"] == nil {
+					t.Errorf("Expected the token to have a session, got `%v`", claims)
+				} else if claims["username"] != tc.expectedUsername {
 					t.Errorf("Expected `%v` username, got `%v`", tc.expectedUsername, claims["username"])
 				} else if int(claims["role"].(float64)) != tc.expectedRole {
 					t.Errorf("Expected `%v` role, got `%v`", tc.expectedRole, claims["role"])
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+)
+
+const (
+	errorMissingOIDCCallbackParams = "code and state are required"
+	errorOIDCLogin                 = "failed to log in with the identity provider"
+)
+
+// OIDCLogin godoc
+//
+// @Description  Log in with the OpenID Connect provider: redirect to the provider, which redirects back to /oidc/callback. Only available if enableAuthentication is enabled and oidc is configured.
+// @Tags         Auth
+// @Success      302  {object}  nil  "Redirection to the provider"
+// @Failure      404  {object}  nil  "Page not found if enableAuthentication is disabled or oidc is not configured"
+// @Failure      500  {object}  nil  "Internal server error"
+// @Router       /oidc/login  [get]
+func OIDCLogin(provider *OIDCProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorizationURL, err := provider.startLogin(c.Request.Context(), time.Now())
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorOIDCLogin})
+			return
+		}
+		c.Redirect(http.StatusFound, authorizationURL)
+	}
+}
+
+// OIDCCallback godoc
+//
+// @Description  Finish the log in with the OpenID Connect provider. The account is created on its first log in. Only available if enableAuthentication is enabled and oidc is configured.
+// @Tags         Auth
+// @Param        code   query  string  true  "Authorization code"
+// @Param        state  query  string  true  "State of the log in"
+// @Success      200  {object}  LoginResponse  "Access and refresh tokens"
+// @Failure      400  {object}  nil            "Bad request"
+// @Failure      401  {object}  nil            "Authentication failed"
+// @Failure      403  {object}  nil            "No role is mapped to the account, or webui is not initialized"
+// @Failure      404  {object}  nil            "Page not found if enableAuthentication is disabled or oidc is not configured"
+// @Failure      409  {object}  nil            "A local account has the same username"
+// @Failure      500  {object}  nil            "Internal server error"
+// @Router       /oidc/callback  [get]
+func OIDCCallback(provider *OIDCProvider, jwtKeys *JWTKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if providerError := c.Query("error"); providerError != "" {
+			logger.AuthLog.Errorf("identity provider refused the log in: %s %s", providerError, c.Query("error_description"))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errorOIDCLogin})
+			return
+		}
+		code, state := c.Query("code"), c.Query("state")
+		if code == "" || state == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMissingOIDCCallbackParams})
+			return
+		}
+		claims, err := provider.finishLogin(c.Request.Context(), state, code, time.Now())
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			switch {
+			case errors.Is(err, errInvalidOIDCLogin):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, errOIDCAuthentication):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": errorOIDCLogin})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": errorOIDCLogin})
+			}
+			return
+		}
+		dbUser, err := provider.account(claims)
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			switch {
+			case errors.Is(err, errOIDCMissingClaim):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			case errors.Is(err, errNoMappedRole), errors.Is(err, errNotInitialized):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			case errors.Is(err, errAccountConflict):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": errorOIDCLogin})
+			}
+			return
+		}
+		session, refreshToken, err := createSession(dbUser.Username, time.Now())
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorLogin})
+			return
+		}
+		token, err := GenerateJWT(dbUser.Username, dbUser.Role, session.Id, jwtKeys)
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorLogin})
+			return
+		}
+		c.JSON(http.StatusOK, LoginResponse{
+			Token:        token,
+			RefreshToken: refreshToken,
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	errorMissingRefreshToken = "refresh-token is required"
+	errorRefresh             = "failed to refresh the session"
+	errorLogout              = "failed to log out"
+	errorNoSession           = "API tokens have no session, they are revoked with DELETE /config/v1/account/{username}/tokens/{tokenId}"
+)
+
+type RefreshParams struct {
+	RefreshToken string `json:"refresh-token"`
+}
+
+// Refresh godoc
+//
+// @Description  Get a new access token with a refresh token. The refresh token can only be used once, the response has the next one. Only available if enableAuthentication is enabled.
+// @Tags         Auth
+// @Param        refreshParams    body    RefreshParams    true    " "
+// @Success      200  {object}  LoginResponse  "Access and refresh tokens"
+// @Failure      400  {object}  nil            "Bad request"
+// @Failure      401  {object}  nil            "Refresh token not valid, expired or revoked"
+// @Failure      404  {object}  nil            "Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil            "Internal server error"
+// @Router       /refresh  [post]
+func Refresh(jwtKeys *JWTKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var refreshParams RefreshParams
+		if err := c.ShouldBindJSON(&refreshParams); err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorInvalidDataProvided})
+			return
+		}
+		if refreshParams.RefreshToken == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMissingRefreshToken})
+			return
+		}
+		session, refreshToken, err := refreshSession(refreshParams.RefreshToken, time.Now())
+		if errors.Is(err, errInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorRefresh})
+			return
+		}
+		// the role is read again, the account may have changed since the login
+		rawUserAccount, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.UserAccountDataColl, bson.M{"username": session.Username})
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
+			return
+		}
+		if len(rawUserAccount) == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken.Error()})
+			return
+		}
+		var dbUser configmodels.DBUserAccount
+		if err = json.Unmarshal(configmodels.MapToByte(rawUserAccount), &dbUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
+			return
+		}
+		token, err := GenerateJWT(dbUser.Username, dbUser.Role, session.Id, jwtKeys)
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorRefresh})
+			return
+		}
+		c.JSON(http.StatusOK, LoginResponse{
+			Token:        token,
+			RefreshToken: refreshToken,
+		})
+	}
+}
+
+// Logout godoc
+//
+// @Description  Log out. The access and refresh tokens of the session stop being accepted. Only available if enableAuthentication is enabled.
+// @Tags         Auth
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Logged out"
+// @Failure      400  {object}  nil  "API tokens cannot log out"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      404  {object}  nil  "Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil  "Internal server error"
+// @Router       /logout  [post]
+func Logout(jwtKeys *JWTKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtKeys)
+		if err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "auth failed: " + err.Error()})
+			return
+		}
+		if claims.SessionID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorNoSession})
+			return
+		}
+		if err = revokeSession(claims.SessionID); err != nil {
+			logger.AuthLog.Errorln(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorLogout})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	}
+}
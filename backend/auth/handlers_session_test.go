@@ -0,0 +1,254 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientSessions struct {
+	dbadapter.DBInterface
+	sessions []map[string]interface{}
+}
+
+func (db *MockMongoClientSessions) matches(session map[string]interface{}, filter bson.M) bool {
+	for field, value := range filter {
+		if used, ok := session[field].([]string); ok {
+			if !slices.Contains(used, value.(string)) {
+				return false
+			}
+		} else if session[field] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (db *MockMongoClientSessions) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	if collName == configmodels.UserAccountDataColl {
+		return map[string]interface{}{"username": filter["username"], "role": configmodels.UserRole}, nil
+	}
+	for _, session := range db.sessions {
+		if db.matches(session, filter) {
+			return session, nil
+		}
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (db *MockMongoClientSessions) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, postData := range postDataArray {
+		db.sessions = append(db.sessions, postData.(bson.M))
+	}
+	return nil
+}
+
+func (db *MockMongoClientSessions) RestfulAPICompareAndSwapWithContext(ctx context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	for _, session := range db.sessions {
+		if db.matches(session, filter) {
+			for field, value := range putData {
+				session[field] = value
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (db *MockMongoClientSessions) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	return db.RestfulAPIDeleteMany(collName, filter)
+}
+
+func (db *MockMongoClientSessions) RestfulAPIDeleteMany(collName string, filter bson.M) error {
+	var sessions []map[string]interface{}
+	for _, session := range db.sessions {
+		if !db.matches(session, filter) {
+			sessions = append(sessions, session)
+		}
+	}
+	db.sessions = sessions
+	return nil
+}
+
+func postJSON(router *gin.Engine, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newSessionRouter(jwtKeys *JWTKeys) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	AddAuthenticationService(router, NewLocalAuthenticator(), jwtKeys)
+	router.GET("/config/v1/network-slice", AdminOrUserAuthMiddleware(jwtKeys), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	return router
+}
+
+func getWithToken(router *gin.Engine, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/config/v1/network-slice", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRefresh(t *testing.T) {
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbClient := &MockMongoClientSessions{}
+	dbadapter.WebuiDBClient = dbClient
+	router := newSessionRouter(NewHMACJWTKeys([]byte("mockSecret")))
+	session, refreshToken, err := createSession("johndoe", time.Now())
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	w := postJSON(router, "/refresh", `{"refresh-token": "`+refreshToken+`"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response LoginResponse
+	if err = json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if response.RefreshToken == refreshToken || dbClient.sessions[0]["refresh-token-hash"] != hashAPIToken(response.RefreshToken) {
+		t.Errorf("Expected the refresh token to be replaced, got %v", dbClient.sessions)
+	}
+	if code := getWithToken(router, response.Token); code != http.StatusOK {
+		t.Errorf("Expected the refreshed access token to be accepted, got `%v`", code)
+	}
+
+	// the replaced refresh token leaked: the whole session is revoked
+	if w = postJSON(router, "/refresh", `{"refresh-token": "`+refreshToken+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a reused refresh token to be refused, got `%v`", w.Code)
+	}
+	if active, _ := isSessionActive(session.Id, time.Now()); active {
+		t.Errorf("Expected the session to be revoked")
+	}
+	if code := getWithToken(router, response.Token); code != http.StatusUnauthorized {
+		t.Errorf("Expected the access tokens of the revoked session to be refused, got `%v`", code)
+	}
+
+	for body, expectedCode := range map[string]int{
+		`{}`:                                 http.StatusBadRequest,
+		`{"refresh-token": "wcr_unknown"}`:   http.StatusUnauthorized,
+		`{"refresh-token": 1}`:               http.StatusBadRequest,
+		`{"refresh-token": "not-a-session"}`: http.StatusUnauthorized,
+	} {
+		if w = postJSON(router, "/refresh", body, ""); w.Code != expectedCode {
+			t.Errorf("%s: expected `%v`, got `%v`", body, expectedCode, w.Code)
+		}
+	}
+}
+
+func TestRefresh_ExpiredSession(t *testing.T) {
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbadapter.WebuiDBClient = &MockMongoClientSessions{}
+	router := newSessionRouter(NewHMACJWTKeys([]byte("mockSecret")))
+	_, refreshToken, err := createSession("johndoe", time.Now().Add(-8*24*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	if w := postJSON(router, "/refresh", `{"refresh-token": "`+refreshToken+`"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected `%v`, got `%v`", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestRefresh_UsedRefreshTokensAreCapped(t *testing.T) {
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbClient := &MockMongoClientSessions{}
+	dbadapter.WebuiDBClient = dbClient
+	_, refreshToken, err := createSession("johndoe", time.Now())
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	firstRefreshToken := refreshToken
+
+	for range maxUsedRefreshTokenHashes + 1 {
+		if _, refreshToken, err = refreshSession(refreshToken, time.Now()); err != nil {
+			t.Fatalf("failed to refresh session: %v", err)
+		}
+	}
+
+	used := dbClient.sessions[0]["used-refresh-token-hashes"].([]string)
+	if len(used) != maxUsedRefreshTokenHashes {
+		t.Errorf("Expected %d used refresh tokens to be kept, got %d", maxUsedRefreshTokenHashes, len(used))
+	}
+	if slices.Contains(used, hashAPIToken(firstRefreshToken)) {
+		t.Errorf("Expected the oldest used refresh token to be dropped")
+	}
+	// the dropped refresh token is still refused, without revoking the session
+	if _, _, err = refreshSession(firstRefreshToken, time.Now()); err != errInvalidRefreshToken {
+		t.Errorf("Expected the dropped refresh token to be refused, got %v", err)
+	}
+	if _, _, err = refreshSession(refreshToken, time.Now()); err != nil {
+		t.Errorf("Expected the session to be kept, got %v", err)
+	}
+}
+
+func TestLogoutAndRevokeSessions(t *testing.T) {
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbClient := &MockMongoClientSessions{}
+	dbadapter.WebuiDBClient = dbClient
+	jwtKeys := NewHMACJWTKeys([]byte("mockSecret"))
+	router := newSessionRouter(jwtKeys)
+	var tokens []string
+	for _, username := range []string{"johndoe", "johndoe", "janedoe"} {
+		session, _, err := createSession(username, time.Now())
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		token, err := GenerateJWT(username, configmodels.UserRole, session.Id, jwtKeys)
+		if err != nil {
+			t.Fatalf("failed to generate JWT: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if w := postJSON(router, "/logout", "", tokens[0]); w.Code != http.StatusOK {
+		t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if code := getWithToken(router, tokens[0]); code != http.StatusUnauthorized {
+		t.Errorf("Expected the token of the logged out session to be refused, got `%v`", code)
+	}
+	if code := getWithToken(router, tokens[1]); code != http.StatusOK {
+		t.Errorf("Expected the other sessions to stay active, got `%v`", code)
+	}
+	if w := postJSON(router, "/logout", "", tokens[0]); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a second logout to be refused, got `%v`", w.Code)
+	}
+
+	if err := RevokeSessions("johndoe"); err != nil {
+		t.Fatalf("failed to revoke sessions: %v", err)
+	}
+	if code := getWithToken(router, tokens[1]); code != http.StatusUnauthorized {
+		t.Errorf("Expected all the sessions of the account to be revoked, got `%v`", code)
+	}
+	if code := getWithToken(router, tokens[2]); code != http.StatusOK {
+		t.Errorf("Expected the sessions of other accounts to stay active, got `%v`", code)
+	}
+}
@@ -16,7 +16,7 @@ func TestStatus(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
 	mockJWTSecret := []byte("mockSecret")
-	AddAuthenticationService(router, mockJWTSecret)
+	AddAuthenticationService(router, NewLocalAuthenticator(), NewHMACJWTKeys(mockJWTSecret))
 
 	testCases := []struct {
 		dbAdapter    dbadapter.DBInterface
@@ -0,0 +1,424 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	jwtAlgorithmHS256   = "HS256"
+	jwtAlgorithmRS256   = "RS256"
+	jwtAlgorithmEdDSA   = "EdDSA"
+	jwtSecretLength     = 32
+	jwtRSAKeyBits       = 2048
+	jwtKeyCheckInterval = time.Minute
+)
+
+type jwtKey struct {
+	id        string
+	algorithm string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	createdAt time.Time
+	expiresAt *time.Time
+}
+
+// JWTKeys holds the keys signing and verifying the authentication tokens. Every
+// token names its key in the kid header, so the keys can be rotated: the newest
+// key signs, the older ones keep verifying the tokens signed before.
+type JWTKeys struct {
+	mu         sync.RWMutex
+	algorithm  string
+	signingKey *jwtKey
+	keys       map[string]*jwtKey
+	// persisted keys are kept in the webui database and shared by the replicas
+	persisted      bool
+	rotationPeriod time.Duration
+}
+
+// NewHMACJWTKeys returns keys signing with an HS256 secret only.
+func NewHMACJWTKeys(secret []byte) *JWTKeys {
+	key := newHMACKey(secret, time.Now())
+	return &JWTKeys{
+		algorithm:  jwtAlgorithmHS256,
+		signingKey: key,
+		keys:       map[string]*jwtKey{key.id: key},
+	}
+}
+
+// LoadJWTKeys returns the keys of the configured key files or, without any,
+// the keys of the webui database, generating one if none can sign.
+func LoadJWTKeys(config *factory.JWT) (*JWTKeys, error) {
+	if config == nil {
+		config = &factory.JWT{}
+	}
+	algorithm := config.Algorithm
+	if algorithm == "" {
+		algorithm = jwtAlgorithmHS256
+	}
+	jwtKeys := &JWTKeys{
+		algorithm:      algorithm,
+		keys:           map[string]*jwtKey{},
+		rotationPeriod: config.RotationPeriod,
+	}
+	if len(config.KeyFiles) == 0 {
+		jwtKeys.persisted = true
+		if err := jwtKeys.refresh(time.Now()); err != nil {
+			return nil, err
+		}
+		return jwtKeys, nil
+	}
+	for _, keyFile := range config.KeyFiles {
+		material, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key file: %w", err)
+		}
+		key, err := parseJWTKey(algorithm, material, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT key file %s: %w", keyFile, err)
+		}
+		if jwtKeys.signingKey == nil {
+			jwtKeys.signingKey = key
+		}
+		jwtKeys.keys[key.id] = key
+	}
+	return jwtKeys, nil
+}
+
+// StartRefresh keeps the keys of the webui database up to date: keys rotated
+// by other replicas are adopted and the signing key is rotated once it is older
+// than the rotation period.
+func (k *JWTKeys) StartRefresh(ctx context.Context) {
+	if !k.persisted {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(jwtKeyCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := k.refresh(time.Now()); err != nil {
+					logger.AuthLog.Errorln(err)
+				}
+			}
+		}
+	}()
+}
+
+// refresh reloads the keys of the webui database and generates a new signing
+// key if none can sign or it is due for rotation. The keys it replaces expire
+// once the tokens they signed have.
+func (k *JWTKeys) refresh(now time.Time) error {
+	keys, err := loadPersistedJWTKeys(now)
+	if err != nil {
+		return err
+	}
+	var signingKey *jwtKey
+	for _, key := range keys {
+		if key.algorithm == k.algorithm && key.expiresAt == nil &&
+			(signingKey == nil || key.createdAt.After(signingKey.createdAt)) {
+			signingKey = key
+		}
+	}
+	if signingKey == nil || (k.rotationPeriod > 0 && now.Sub(signingKey.createdAt) >= k.rotationPeriod) {
+		signingKey, err = generateJWTKey(k.algorithm, now)
+		if err != nil {
+			return err
+		}
+		if err = storeJWTKey(signingKey); err != nil {
+			return err
+		}
+		logger.AuthLog.Infof("generated JWT signing key %s", signingKey.id)
+		// a replaced key keeps verifying the access tokens it signed until they expire
+		expiresAt := now.Add(factory.WebUIConfig.Configuration.AccessTokenLifetime)
+		for _, key := range keys {
+			if key.expiresAt != nil {
+				continue
+			}
+			if _, err = dbadapter.WebuiDBClient.RestfulAPIPost(configmodels.JwtKeyDataColl, bson.M{"kid": key.id}, bson.M{"expires-at": expiresAt}); err != nil {
+				return fmt.Errorf("failed to expire JWT key %s: %w", key.id, err)
+			}
+			key.expiresAt = &expiresAt
+		}
+		keys[signingKey.id] = signingKey
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.signingKey = signingKey
+	k.keys = keys
+	return nil
+}
+
+func (k *JWTKeys) sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	key := k.signingKey
+	k.mu.RUnlock()
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.signKey)
+}
+
+// keyFunc returns the key verifying a token. A key unknown to this replica may
+// have just been generated by another one, so it is looked up in the webui
+// database.
+func (k *JWTKeys) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("token has no key ID")
+	}
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+	if !ok && k.persisted {
+		var err error
+		if key, err = loadPersistedJWTKey(kid, time.Now()); err != nil {
+			return nil, err
+		}
+		if ok = key != nil; ok {
+			k.mu.Lock()
+			k.keys[kid] = key
+			k.mu.Unlock()
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %s", kid)
+	}
+	if key.expiresAt != nil && time.Now().After(*key.expiresAt) {
+		return nil, fmt.Errorf("key %s has expired", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// jwks returns the public keys verifying the tokens. HS256 secrets are never
+// published, so other services can only verify RS256 and EdDSA tokens.
+func (k *JWTKeys) jwks(now time.Time) JWKSResponse {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	response := JWKSResponse{Keys: []JWK{}}
+	for _, key := range k.keys {
+		if key.expiresAt != nil && now.After(*key.expiresAt) {
+			continue
+		}
+		jwk := JWK{Kid: key.id, Use: "sig", Alg: key.algorithm}
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+		response.Keys = append(response.Keys, jwk)
+	}
+	sort.Slice(response.Keys, func(i, j int) bool { return response.Keys[i].Kid < response.Keys[j].Kid })
+	return response
+}
+
+func loadPersistedJWTKeys(now time.Time) (map[string]*jwtKey, error) {
+	rawKeys, err := dbadapter.WebuiDBClient.RestfulAPIGetMany(configmodels.JwtKeyDataColl, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve JWT keys: %w", err)
+	}
+	keys := map[string]*jwtKey{}
+	for _, rawKey := range rawKeys {
+		key, err := decodePersistedJWTKey(rawKey, now)
+		if err != nil {
+			logger.AuthLog.Warnln(err)
+			continue
+		}
+		if key != nil {
+			keys[key.id] = key
+		}
+	}
+	return keys, nil
+}
+
+func loadPersistedJWTKey(kid string, now time.Time) (*jwtKey, error) {
+	rawKey, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.JwtKeyDataColl, bson.M{"kid": kid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve JWT key %s: %w", kid, err)
+	}
+	if len(rawKey) == 0 {
+		return nil, nil
+	}
+	return decodePersistedJWTKey(rawKey, now)
+}
+
+// decodePersistedJWTKey returns the key of a document of the webui database, or
+// nil if it has expired and is waiting for its removal.
+func decodePersistedJWTKey(rawKey map[string]interface{}, now time.Time) (*jwtKey, error) {
+	var dbKey configmodels.DBJwtKey
+	if err := json.Unmarshal(configmodels.MapToByte(rawKey), &dbKey); err != nil {
+		return nil, fmt.Errorf("failed to decode JWT key: %w", err)
+	}
+	if dbKey.ExpiresAt != nil && now.After(*dbKey.ExpiresAt) {
+		return nil, nil
+	}
+	material := []byte(dbKey.Key)
+	if dbKey.Algorithm == jwtAlgorithmHS256 {
+		secret, err := base64.StdEncoding.DecodeString(dbKey.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode JWT key %s: %w", dbKey.Kid, err)
+		}
+		material = secret
+	}
+	key, err := parseJWTKey(dbKey.Algorithm, material, dbKey.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT key %s: %w", dbKey.Kid, err)
+	}
+	key.expiresAt = dbKey.ExpiresAt
+	return key, nil
+}
+
+func storeJWTKey(key *jwtKey) error {
+	var encodedKey string
+	switch signKey := key.signKey.(type) {
+	case []byte:
+		encodedKey = base64.StdEncoding.EncodeToString(signKey)
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(signKey)
+		if err != nil {
+			return fmt.Errorf("failed to encode JWT key: %w", err)
+		}
+		encodedKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	}
+	document := bson.M{
+		"kid":        key.id,
+		"algorithm":  key.algorithm,
+		"key":        encodedKey,
+		"created-at": key.createdAt,
+	}
+	err := dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.JwtKeyDataColl, bson.M{"kid": key.id}, []interface{}{document})
+	if err != nil {
+		return fmt.Errorf("failed to store JWT key: %w", err)
+	}
+	return nil
+}
+
+func generateJWTKey(algorithm string, now time.Time) (*jwtKey, error) {
+	switch algorithm {
+	case jwtAlgorithmHS256:
+		secret := make([]byte, jwtSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+		}
+		return newHMACKey(secret, now), nil
+	case jwtAlgorithmRS256:
+		privateKey, err := rsa.GenerateKey(rand.Reader, jwtRSAKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate JWT key: %w", err)
+		}
+		return newAsymmetricKey(algorithm, jwt.SigningMethodRS256, privateKey, now)
+	case jwtAlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate JWT key: %w", err)
+		}
+		return newAsymmetricKey(algorithm, jwt.SigningMethodEdDSA, privateKey, now)
+	}
+	return nil, fmt.Errorf("unsupported JWT algorithm %s", algorithm)
+}
+
+// parseJWTKey parses an HS256 secret or a PEM private key.
+func parseJWTKey(algorithm string, material []byte, createdAt time.Time) (*jwtKey, error) {
+	if algorithm == jwtAlgorithmHS256 {
+		if len(material) < jwtSecretLength {
+			return nil, fmt.Errorf("HS256 secret must have at least %d bytes", jwtSecretLength)
+		}
+		return newHMACKey(material, createdAt), nil
+	}
+	block, _ := pem.Decode(material)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM private key found")
+	}
+	var privateKey interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch privateKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if algorithm == jwtAlgorithmRS256 {
+			return newAsymmetricKey(algorithm, jwt.SigningMethodRS256, privateKey, createdAt)
+		}
+	case ed25519.PrivateKey:
+		if algorithm == jwtAlgorithmEdDSA {
+			return newAsymmetricKey(algorithm, jwt.SigningMethodEdDSA, privateKey, createdAt)
+		}
+	}
+	return nil, fmt.Errorf("private key is not a %s key", algorithm)
+}
+
+func newHMACKey(secret []byte, createdAt time.Time) *jwtKey {
+	return &jwtKey{
+		id:        jwtKeyID(secret),
+		algorithm: jwtAlgorithmHS256,
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+		createdAt: createdAt,
+	}
+}
+
+func newAsymmetricKey(algorithm string, method jwt.SigningMethod, privateKey crypto.Signer, createdAt time.Time) (*jwtKey, error) {
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &jwtKey{
+		id:        jwtKeyID(publicKeyDER),
+		algorithm: algorithm,
+		method:    method,
+		signKey:   privateKey,
+		verifyKey: privateKey.Public(),
+		createdAt: createdAt,
+	}, nil
+}
+
+// jwtKeyID derives the key ID from the key, so that replicas loading the same
+// key file agree on it.
+func jwtKeyID(material []byte) string {
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientJwtKeys struct {
+	dbadapter.DBInterface
+	keys []map[string]interface{}
+}
+
+func (db *MockMongoClientJwtKeys) RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error) {
+	return db.keys, nil
+}
+
+func (db *MockMongoClientJwtKeys) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	for _, key := range db.keys {
+		if key["kid"] == filter["kid"] {
+			return key, nil
+		}
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (db *MockMongoClientJwtKeys) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, postData := range postDataArray {
+		db.keys = append(db.keys, postData.(bson.M))
+	}
+	return nil
+}
+
+func (db *MockMongoClientJwtKeys) RestfulAPIPost(collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
+	for _, key := range db.keys {
+		if key["kid"] == filter["kid"] {
+			for field, value := range postData {
+				key[field] = value
+			}
+		}
+	}
+	return true, nil
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwtWebconsoleClaims{})
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTKeys_PersistedAcrossRestartsAndReplicas(t *testing.T) {
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbClient := &MockMongoClientJwtKeys{}
+	dbadapter.WebuiDBClient = dbClient
+
+	for _, algorithm := range []string{jwtAlgorithmHS256, jwtAlgorithmRS256, jwtAlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			dbClient.keys = nil
+			replica1, err := LoadJWTKeys(&factory.JWT{Algorithm: algorithm})
+			if err != nil {
+				t.Fatalf("failed to load JWT keys: %v", err)
+			}
+			if len(dbClient.keys) != 1 || dbClient.keys[0]["algorithm"] != algorithm {
+				t.Fatalf("Expected one stored %s key, got %v", algorithm, dbClient.keys)
+			}
+			replica2, err := LoadJWTKeys(&factory.JWT{Algorithm: algorithm})
+			if err != nil {
+				t.Fatalf("failed to load JWT keys: %v", err)
+			}
+			if len(dbClient.keys) != 1 {
+				t.Fatalf("Expected the stored key to be reused, got %d keys", len(dbClient.keys))
+			}
+			token, err := GenerateJWT("janedoe", configmodels.AdminRole, "session1", replica1)
+			if err != nil {
+				t.Fatalf("failed to generate JWT: %v", err)
+			}
+			claims, err := getClaimsFromJWT(token, replica2)
+			if err != nil {
+				t.Fatalf("Expected the token to be valid on the other replica: %v", err)
+			}
+			if claims.Username != "janedoe" || tokenKeyID(t, token) != dbClient.keys[0]["kid"] {
+				t.Errorf("Unexpected claims %+v of token %s", claims, token)
+			}
+		})
+	}
+}
+
+func TestJWTKeys_Rotation(t *testing.T) {
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbClient := &MockMongoClientJwtKeys{}
+	dbadapter.WebuiDBClient = dbClient
+	rotationPeriod := 24 * time.Hour
+	replica1, err := LoadJWTKeys(&factory.JWT{RotationPeriod: rotationPeriod})
+	if err != nil {
+		t.Fatalf("failed to load JWT keys: %v", err)
+	}
+	replica2, err := LoadJWTKeys(&factory.JWT{RotationPeriod: rotationPeriod})
+	if err != nil {
+		t.Fatalf("failed to load JWT keys: %v", err)
+	}
+	oldToken, err := GenerateJWT("janedoe", configmodels.AdminRole, "session1", replica1)
+	if err != nil {
+		t.Fatalf("failed to generate JWT: %v", err)
+	}
+
+	if err = replica1.refresh(time.Now().Add(rotationPeriod)); err != nil {
+		t.Fatalf("failed to rotate JWT keys: %v", err)
+	}
+	if len(dbClient.keys) != 2 || dbClient.keys[0]["expires-at"] == nil || dbClient.keys[1]["expires-at"] != nil {
+		t.Fatalf("Expected the old key to expire and a new one to be stored, got %v", dbClient.keys)
+	}
+	newToken, err := GenerateJWT("janedoe", configmodels.AdminRole, "session1", replica1)
+	if err != nil {
+		t.Fatalf("failed to generate JWT: %v", err)
+	}
+	if tokenKeyID(t, newToken) == tokenKeyID(t, oldToken) {
+		t.Errorf("Expected the new token to be signed by the new key")
+	}
+	if _, err = getClaimsFromJWT(oldToken, replica1); err != nil {
+		t.Errorf("Expected the old key to keep verifying its tokens: %v", err)
+	}
+	if _, err = getClaimsFromJWT(newToken, replica2); err != nil {
+		t.Errorf("Expected the other replica to verify tokens of the new key: %v", err)
+	}
+
+	if err = replica2.refresh(time.Now()); err != nil {
+		t.Fatalf("failed to refresh JWT keys: %v", err)
+	}
+	adoptedToken, err := GenerateJWT("janedoe", configmodels.AdminRole, "session1", replica2)
+	if err != nil {
+		t.Fatalf("failed to generate JWT: %v", err)
+	}
+	if tokenKeyID(t, adoptedToken) != tokenKeyID(t, newToken) || len(dbClient.keys) != 2 {
+		t.Errorf("Expected the other replica to adopt the new key, got %d keys", len(dbClient.keys))
+	}
+
+	if err = replica1.refresh(time.Now().Add(rotationPeriod + 2*factory.WebUIConfig.Configuration.AccessTokenLifetime)); err != nil {
+		t.Fatalf("failed to refresh JWT keys: %v", err)
+	}
+	if _, ok := replica1.keys[tokenKeyID(t, oldToken)]; ok {
+		t.Errorf("Expected the expired key to be dropped")
+	}
+}
+
+func writeKeyFile(t *testing.T, name string, privateKey interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	return path
+}
+
+func TestJWTKeys_KeyFiles(t *testing.T) {
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	newKeyFile := writeKeyFile(t, "new.pem", newKey)
+	oldKeyFile := writeKeyFile(t, "old.pem", oldKey)
+
+	oldKeys, err := LoadJWTKeys(&factory.JWT{Algorithm: jwtAlgorithmEdDSA, KeyFiles: []string{oldKeyFile}})
+	if err != nil {
+		t.Fatalf("failed to load JWT keys: %v", err)
+	}
+	rotatedKeys, err := LoadJWTKeys(&factory.JWT{Algorithm: jwtAlgorithmEdDSA, KeyFiles: []string{newKeyFile, oldKeyFile}})
+	if err != nil {
+		t.Fatalf("failed to load JWT keys: %v", err)
+	}
+	oldToken, err := GenerateJWT("janedoe", configmodels.AdminRole, "session1", oldKeys)
+	if err != nil {
+		t.Fatalf("failed to generate JWT: %v", err)
+	}
+	if _, err = getClaimsFromJWT(oldToken, rotatedKeys); err != nil {
+		t.Errorf("Expected the old key to keep verifying its tokens: %v", err)
+	}
+	newToken, err := GenerateJWT("janedoe", configmodels.AdminRole, "session1", rotatedKeys)
+	if err != nil {
+		t.Fatalf("failed to generate JWT: %v", err)
+	}
+	if _, err = getClaimsFromJWT(newToken, oldKeys); err == nil {
+		t.Errorf("Expected a token of an unknown key to be rejected")
+	}
+
+	jwks := rotatedKeys.jwks(time.Now())
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("Expected two published keys, got %+v", jwks)
+	}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != jwtAlgorithmEdDSA || jwk.X == "" {
+			t.Errorf("Unexpected JWK %+v", jwk)
+		}
+	}
+
+	if _, err = LoadJWTKeys(&factory.JWT{Algorithm: jwtAlgorithmRS256, KeyFiles: []string{newKeyFile}}); err == nil {
+		t.Errorf("Expected an EdDSA key file to be refused for RS256")
+	}
+}
+
+func TestJWTKeys_RejectedTokens(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, jwtRSAKeyBits)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rsaKeys, err := LoadJWTKeys(&factory.JWT{Algorithm: jwtAlgorithmRS256, KeyFiles: []string{writeKeyFile(t, "rsa.pem", rsaKey)}})
+	if err != nil {
+		t.Fatalf("failed to load JWT keys: %v", err)
+	}
+	if jwks := rsaKeys.jwks(time.Now()); len(jwks.Keys) != 1 || jwks.Keys[0].Kty != "RSA" || jwks.Keys[0].E != "AQAB" {
+		t.Errorf("Unexpected JWKS %+v", jwks)
+	}
+	claims := jwtWebconsoleClaims{
+		Username:         "janedoe",
+		Role:             configmodels.AdminRole,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}
+
+	withoutKeyID, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(rsaKey)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	if _, err = getClaimsFromJWT(withoutKeyID, rsaKeys); err == nil {
+		t.Errorf("Expected a token without key ID to be rejected")
+	}
+
+	// an HS256 token signed with the public key must not pass as an RS256 one
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	confused.Header["kid"] = jwtKeyID(publicKeyDER)
+	confusedToken, err := confused.SignedString(publicKeyDER)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	if _, err = getClaimsFromJWT(confusedToken, rsaKeys); err == nil {
+		t.Errorf("Expected a token with another algorithm than its key to be rejected")
+	}
+
+	if jwks := NewHMACJWTKeys([]byte("mockSecret")).jwks(time.Now()); len(jwks.Keys) != 0 {
+		t.Errorf("Expected HS256 secrets not to be published, got %+v", jwks)
+	}
+}
@@ -0,0 +1,554 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+// The LDAP messages used by webconsole (RFC 4511), encoded with the BER tags of
+// their ASN.1 types.
+const (
+	berBoolean     = 0x01
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berEnumerated  = 0x0a
+	berSequence    = 0x30
+
+	ldapBindRequest           = 0x60
+	ldapBindResponse          = 0x61
+	ldapUnbindRequest         = 0x42
+	ldapSearchRequest         = 0x63
+	ldapSearchResultEntry     = 0x64
+	ldapSearchResultDone      = 0x65
+	ldapSearchResultReference = 0x73
+	ldapExtendedRequest       = 0x77
+	ldapExtendedResponse      = 0x78
+
+	ldapSimpleAuthentication = 0x80
+	ldapExtendedRequestName  = 0x80
+	ldapFilterAnd            = 0xa0
+	ldapFilterEqualityMatch  = 0xa3
+	ldapFilterPresent        = 0x87
+
+	ldapResultSuccess            = 0
+	ldapResultSizeLimitExceeded  = 4
+	ldapResultInvalidCredentials = 49
+
+	ldapScopeBaseObject   = 0
+	ldapScopeWholeSubtree = 2
+
+	ldapStartTLSOID    = "1.3.6.1.4.1.1466.20037"
+	ldapMaxMessageSize = 4 << 20
+)
+
+var errMalformedLDAPMessage = errors.New("malformed LDAP message")
+
+type ldapResultError struct {
+	code    int
+	message string
+}
+
+func (e *ldapResultError) Error() string {
+	return fmt.Sprintf("LDAP result code %d: %s", e.code, e.message)
+}
+
+// berElement is a decoded BER element: its tag and contents.
+type berElement struct {
+	tag   byte
+	value []byte
+}
+
+func berEncode(tag byte, contents ...[]byte) []byte {
+	value := bytes.Join(contents, nil)
+	header := []byte{tag}
+	if len(value) < 0x80 {
+		header = append(header, byte(len(value)))
+	} else {
+		var length []byte
+		for l := len(value); l > 0; l >>= 8 {
+			length = append([]byte{byte(l)}, length...)
+		}
+		header = append(append(header, 0x80|byte(len(length))), length...)
+	}
+	return append(header, value...)
+}
+
+func berEncodeInteger(tag byte, v int) []byte {
+	value := []byte{byte(v)}
+	for v >>= 8; v > 0; v >>= 8 {
+		value = append([]byte{byte(v)}, value...)
+	}
+	if value[0]&0x80 != 0 {
+		value = append([]byte{0}, value...)
+	}
+	return berEncode(tag, value)
+}
+
+func berEncodeString(tag byte, s string) []byte {
+	return berEncode(tag, []byte(s))
+}
+
+func berDecodeInteger(value []byte) int {
+	v := 0
+	for _, b := range value {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// berDecode returns the first element of data, and the data after it.
+func berDecode(data []byte) (berElement, []byte, error) {
+	if len(data) < 2 {
+		return berElement{}, nil, errMalformedLDAPMessage
+	}
+	length, offset := int(data[1]), 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || len(data) < offset+n {
+			return berElement{}, nil, errMalformedLDAPMessage
+		}
+		length = berDecodeInteger(data[offset : offset+n])
+		offset += n
+	}
+	if length < 0 || len(data)-offset < length {
+		return berElement{}, nil, errMalformedLDAPMessage
+	}
+	return berElement{tag: data[0], value: data[offset : offset+length]}, data[offset+length:], nil
+}
+
+func berDecodeAll(data []byte) ([]berElement, error) {
+	var elements []berElement
+	for len(data) > 0 {
+		element, rest, err := berDecode(data)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		data = rest
+	}
+	return elements, nil
+}
+
+func ldapEqualityFilter(attribute string, value string) []byte {
+	return berEncode(ldapFilterEqualityMatch, berEncodeString(berOctetString, attribute), berEncodeString(berOctetString, value))
+}
+
+func ldapAndFilter(filters ...[]byte) []byte {
+	return berEncode(ldapFilterAnd, filters...)
+}
+
+func ldapPresentFilter(attribute string) []byte {
+	return berEncodeString(ldapFilterPresent, attribute)
+}
+
+// escapeDN escapes value for a distinguished name, following RFC 4514.
+func escapeDN(value string) string {
+	var escaped strings.Builder
+	for i, r := range value {
+		switch {
+		case r == 0:
+			escaped.WriteString(`\00`)
+		case strings.ContainsRune(`,+"\<>;=`, r), r == '#' && i == 0, r == ' ' && (i == 0 || i == len(value)-1):
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
+
+type ldapEntry struct {
+	dn string
+	// attributes are keyed by their lower case name, as they are case insensitive
+	attributes map[string][]string
+}
+
+// ldapConn is a connection to an LDAP server, for one login.
+type ldapConn struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	messageID int
+	timeout   time.Duration
+}
+
+func dialLDAP(ctx context.Context, config *factory.LDAP, tlsConfig *tls.Config) (*ldapConn, error) {
+	ldapURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	address := ldapURL.Host
+	if ldapURL.Port() == "" {
+		port := "389"
+		if ldapURL.Scheme == "ldaps" {
+			port = "636"
+		}
+		address = net.JoinHostPort(ldapURL.Hostname(), port)
+	}
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	var conn net.Conn
+	if ldapURL.Scheme == "ldaps" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthenticatorUnavailable, err)
+	}
+	if err = conn.SetDeadline(time.Now().Add(config.Timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &ldapConn{conn: conn, reader: bufio.NewReader(conn), timeout: config.Timeout}
+	if config.StartTLS {
+		if err = c.startTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *ldapConn) send(op []byte) (int, error) {
+	c.messageID++
+	if _, err := c.conn.Write(berEncode(berSequence, berEncodeInteger(berInteger, c.messageID), op)); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrAuthenticatorUnavailable, err)
+	}
+	return c.messageID, nil
+}
+
+// receive returns the operation of the next message, which must answer the
+// request messageID.
+func (c *ldapConn) receive(messageID int) (berElement, error) {
+	receivedID, op, err := readLDAPMessage(c.reader)
+	if err != nil {
+		return berElement{}, err
+	}
+	if receivedID != messageID {
+		return berElement{}, fmt.Errorf("unexpected LDAP message %d", receivedID)
+	}
+	return op, nil
+}
+
+// readLDAPMessage returns the message ID and the operation of the next
+// message.
+func readLDAPMessage(reader *bufio.Reader) (int, berElement, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, berElement{}, fmt.Errorf("%w: %w", ErrAuthenticatorUnavailable, err)
+	}
+	if header[0] != berSequence {
+		return 0, berElement{}, errMalformedLDAPMessage
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		lengthBytes := make([]byte, length&0x7f)
+		if len(lengthBytes) == 0 || len(lengthBytes) > 4 {
+			return 0, berElement{}, errMalformedLDAPMessage
+		}
+		if _, err := io.ReadFull(reader, lengthBytes); err != nil {
+			return 0, berElement{}, fmt.Errorf("%w: %w", ErrAuthenticatorUnavailable, err)
+		}
+		length = berDecodeInteger(lengthBytes)
+	}
+	if length > ldapMaxMessageSize {
+		return 0, berElement{}, fmt.Errorf("LDAP message of %d bytes is too large", length)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, berElement{}, fmt.Errorf("%w: %w", ErrAuthenticatorUnavailable, err)
+	}
+	elements, err := berDecodeAll(body)
+	if err != nil {
+		return 0, berElement{}, err
+	}
+	if len(elements) < 2 || elements[0].tag != berInteger {
+		return 0, berElement{}, errMalformedLDAPMessage
+	}
+	return berDecodeInteger(elements[0].value), elements[1], nil
+}
+
+// ldapResult returns the error of the LDAPResult of a response, nil if it succeeded.
+func ldapResult(op berElement, tag byte) error {
+	if op.tag != tag {
+		return fmt.Errorf("unexpected LDAP operation %#x", op.tag)
+	}
+	elements, err := berDecodeAll(op.value)
+	if err != nil {
+		return err
+	}
+	if len(elements) < 3 || elements[0].tag != berEnumerated {
+		return errMalformedLDAPMessage
+	}
+	if code := berDecodeInteger(elements[0].value); code != ldapResultSuccess {
+		return &ldapResultError{code: code, message: string(elements[2].value)}
+	}
+	return nil
+}
+
+func (c *ldapConn) startTLS(tlsConfig *tls.Config) error {
+	messageID, err := c.send(berEncode(ldapExtendedRequest, berEncodeString(ldapExtendedRequestName, ldapStartTLSOID)))
+	if err != nil {
+		return err
+	}
+	op, err := c.receive(messageID)
+	if err != nil {
+		return err
+	}
+	if err = ldapResult(op, ldapExtendedResponse); err != nil {
+		return fmt.Errorf("failed to start TLS: %w", err)
+	}
+	tlsConn := tls.Client(c.conn, tlsConfig)
+	if err = tlsConn.Handshake(); err != nil {
+		return fmt.Errorf("failed to start TLS: %w", err)
+	}
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// bind authenticates the connection as dn. A refused password is returned as
+// ErrIncorrectCredentials.
+func (c *ldapConn) bind(dn string, password string) error {
+	messageID, err := c.send(berEncode(ldapBindRequest,
+		berEncodeInteger(berInteger, 3),
+		berEncodeString(berOctetString, dn),
+		berEncodeString(ldapSimpleAuthentication, password)))
+	if err != nil {
+		return err
+	}
+	op, err := c.receive(messageID)
+	if err != nil {
+		return err
+	}
+	err = ldapResult(op, ldapBindResponse)
+	var resultErr *ldapResultError
+	if errors.As(err, &resultErr) && resultErr.code == ldapResultInvalidCredentials {
+		return ErrIncorrectCredentials
+	}
+	return err
+}
+
+// search returns the entries under baseDN matching filter, at most two: enough
+// to know whether a user is ambiguous.
+func (c *ldapConn) search(baseDN string, scope int, filter []byte, attributes []string) ([]ldapEntry, error) {
+	var attributeList [][]byte
+	for _, attribute := range attributes {
+		attributeList = append(attributeList, berEncodeString(berOctetString, attribute))
+	}
+	messageID, err := c.send(berEncode(ldapSearchRequest,
+		berEncodeString(berOctetString, baseDN),
+		berEncodeInteger(berEnumerated, scope),
+		berEncodeInteger(berEnumerated, 0), // never dereference aliases
+		berEncodeInteger(berInteger, 2),
+		berEncodeInteger(berInteger, int(c.timeout.Seconds())),
+		berEncode(berBoolean, []byte{0}),
+		filter,
+		berEncode(berSequence, attributeList...)))
+	if err != nil {
+		return nil, err
+	}
+	var entries []ldapEntry
+	for {
+		op, err := c.receive(messageID)
+		if err != nil {
+			return nil, err
+		}
+		switch op.tag {
+		case ldapSearchResultEntry:
+			entry, err := decodeLDAPEntry(op.value)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case ldapSearchResultReference:
+			// referrals to other servers are not followed
+		default:
+			err = ldapResult(op, ldapSearchResultDone)
+			var resultErr *ldapResultError
+			if errors.As(err, &resultErr) && resultErr.code == ldapResultSizeLimitExceeded {
+				return entries, nil
+			}
+			return entries, err
+		}
+	}
+}
+
+func decodeLDAPEntry(data []byte) (ldapEntry, error) {
+	elements, err := berDecodeAll(data)
+	if err != nil {
+		return ldapEntry{}, err
+	}
+	if len(elements) != 2 {
+		return ldapEntry{}, errMalformedLDAPMessage
+	}
+	entry := ldapEntry{dn: string(elements[0].value), attributes: map[string][]string{}}
+	attributes, err := berDecodeAll(elements[1].value)
+	if err != nil {
+		return ldapEntry{}, err
+	}
+	for _, attribute := range attributes {
+		typeAndValues, err := berDecodeAll(attribute.value)
+		if err != nil {
+			return ldapEntry{}, err
+		}
+		if len(typeAndValues) != 2 {
+			return ldapEntry{}, errMalformedLDAPMessage
+		}
+		values, err := berDecodeAll(typeAndValues[1].value)
+		if err != nil {
+			return ldapEntry{}, err
+		}
+		name := strings.ToLower(string(typeAndValues[0].value))
+		for _, value := range values {
+			entry.attributes[name] = append(entry.attributes[name], string(value.value))
+		}
+	}
+	return entry, nil
+}
+
+func (c *ldapConn) close() {
+	if _, err := c.send(berEncode(ldapUnbindRequest)); err != nil {
+		logger.AuthLog.Debugln(err)
+	}
+	c.conn.Close()
+}
+
+// ldapAuthenticator authenticates the users of an LDAP directory, and creates
+// their account on their first login.
+type ldapAuthenticator struct {
+	config    *factory.LDAP
+	tlsConfig *tls.Config
+}
+
+func newLDAPAuthenticator(config *factory.LDAP) (*ldapAuthenticator, error) {
+	ldapURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	tlsConfig := &tls.Config{ServerName: ldapURL.Hostname(), MinVersion: tls.VersionTLS12}
+	if config.CAFile != "" {
+		caPEM, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LDAP CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificate found in LDAP CA file %s", config.CAFile)
+		}
+	}
+	return &ldapAuthenticator{config: config, tlsConfig: tlsConfig}, nil
+}
+
+func (a *ldapAuthenticator) Authenticate(ctx context.Context, username string, password string) (*configmodels.DBUserAccount, error) {
+	// a bind without password is an unauthenticated bind, which succeeds
+	if username == "" || password == "" {
+		return nil, ErrIncorrectCredentials
+	}
+	conn, err := dialLDAP(ctx, a.config, a.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+	entry, err := a.findUser(conn, username, password)
+	if err != nil {
+		return nil, err
+	}
+	// the directory matches the username case insensitively, the account
+	// is named as in the directory
+	if values := entry.attributes[strings.ToLower(a.config.UserAttribute)]; len(values) == 1 && strings.EqualFold(values[0], username) {
+		username = values[0]
+	}
+	roles := a.mapRoles(entry.attributes[strings.ToLower(a.config.GroupAttribute)])
+	if len(a.config.RoleMapping) > 0 && len(roles) == 0 {
+		return nil, errNoMappedRole
+	}
+	account := &configmodels.DBUserAccount{
+		Username: username,
+		Role:     configmodels.UserRole,
+		LDAPDN:   entry.dn,
+		Roles:    roles,
+	}
+	if slices.Contains(roles, configmodels.RoleAdmin) {
+		account.Role = configmodels.AdminRole
+		account.Roles = nil
+	}
+	return syncExternalAccount(account, func(dbUser *configmodels.DBUserAccount) bool {
+		return dbUser.LDAPDN != ""
+	})
+}
+
+// findUser returns the entry of the user, once bound with their password.
+func (a *ldapAuthenticator) findUser(conn *ldapConn, username string, password string) (*ldapEntry, error) {
+	attributes := []string{a.config.UserAttribute, a.config.GroupAttribute}
+	if a.config.UserDNTemplate != "" {
+		userDN := strings.ReplaceAll(a.config.UserDNTemplate, "{username}", escapeDN(username))
+		if err := conn.bind(userDN, password); err != nil {
+			return nil, err
+		}
+		entries, err := conn.search(userDN, ldapScopeBaseObject, ldapPresentFilter("objectClass"), attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LDAP entry %s: %w", userDN, err)
+		}
+		if len(entries) != 1 {
+			return nil, fmt.Errorf("failed to read LDAP entry %s", userDN)
+		}
+		return &entries[0], nil
+	}
+	if a.config.BindDN != "" {
+		if err := conn.bind(a.config.BindDN, a.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("failed to bind as %s: %w", a.config.BindDN, err)
+		}
+	}
+	filter := ldapEqualityFilter(a.config.UserAttribute, username)
+	if a.config.UserObjectClass != "" {
+		filter = ldapAndFilter(ldapEqualityFilter("objectClass", a.config.UserObjectClass), filter)
+	}
+	entries, err := conn.search(a.config.BaseDN, ldapScopeWholeSubtree, filter, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search LDAP user %s: %w", username, err)
+	}
+	if len(entries) != 1 {
+		if len(entries) > 1 {
+			logger.AuthLog.Warnf("LDAP user %s matches several entries under %s", username, a.config.BaseDN)
+		}
+		return nil, ErrIncorrectCredentials
+	}
+	if err = conn.bind(entries[0].dn, password); err != nil {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// mapRoles returns the roles given to the groups, in order.
+func (a *ldapAuthenticator) mapRoles(groups []string) []string {
+	roles := []string{}
+	for groupDN, role := range a.config.RoleMapping {
+		for _, group := range groups {
+			if strings.EqualFold(group, groupDN) && !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	slices.Sort(roles)
+	return roles
+}
@@ -0,0 +1,468 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockLDAPEntry struct {
+	password   string
+	attributes map[string][]string
+}
+
+// mockLDAPServer is an LDAP directory answering the requests of webconsole.
+type mockLDAPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	entries   map[string]mockLDAPEntry
+
+	mu sync.Mutex
+	// plainBinds counts the binds received without TLS
+	plainBinds int
+}
+
+func newMockLDAPServer(t *testing.T, ldaps bool) (*mockLDAPServer, string) {
+	certificate, caFile := newTestCertificate(t)
+	server := &mockLDAPServer{
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{certificate}},
+		entries: map[string]mockLDAPEntry{
+			"cn=webui,ou=services,dc=example,dc=com": {password: "service-secret"},
+			"uid=janedoe,ou=people,dc=example,dc=com": {password: "jane-secret", attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"janedoe"},
+				"memberOf":    {"CN=Operators,OU=Groups,DC=example,DC=com", "cn=other,ou=groups,dc=example,dc=com"},
+			}},
+			"uid=root,ou=people,dc=example,dc=com": {password: "root-secret", attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"root"},
+				"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com"},
+			}},
+			"uid=guest,ou=people,dc=example,dc=com": {password: "guest-secret", attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"guest"},
+			}},
+			`uid=doe\, john,ou=people,dc=example,dc=com`: {password: "john-secret", attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"doe, john"},
+				"memberOf":    {"cn=operators,ou=groups,dc=example,dc=com"},
+			}},
+			"uid=localuser,ou=people,dc=example,dc=com": {password: "local-secret", attributes: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"localuser"},
+				"memberOf":    {"cn=operators,ou=groups,dc=example,dc=com"},
+			}},
+		},
+	}
+	var err error
+	if ldaps {
+		server.listener, err = tls.Listen("tcp", "127.0.0.1:0", server.tlsConfig)
+	} else {
+		server.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { server.listener.Close() })
+	go server.serve()
+	return server, caFile
+}
+
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func (s *mockLDAPServer) url(scheme string) string {
+	return scheme + "://" + s.listener.Addr().String()
+}
+
+func (s *mockLDAPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *mockLDAPServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	_, secure := conn.(*tls.Conn)
+	reader := bufio.NewReader(conn)
+	reply := func(messageID int, op []byte) {
+		_, _ = conn.Write(berEncode(berSequence, berEncodeInteger(berInteger, messageID), op))
+	}
+	result := func(tag byte, code int) []byte {
+		return berEncode(tag, berEncodeInteger(berEnumerated, code), berEncodeString(berOctetString, ""), berEncodeString(berOctetString, ""))
+	}
+	for {
+		messageID, op, err := readLDAPMessage(reader)
+		if err != nil {
+			return
+		}
+		elements, _ := berDecodeAll(op.value)
+		switch op.tag {
+		case ldapExtendedRequest:
+			reply(messageID, result(ldapExtendedResponse, ldapResultSuccess))
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn, reader, secure = tlsConn, bufio.NewReader(tlsConn), true
+		case ldapBindRequest:
+			s.mu.Lock()
+			if !secure {
+				s.plainBinds++
+			}
+			s.mu.Unlock()
+			entry, ok := s.entries[string(elements[1].value)]
+			if !ok || entry.password != string(elements[2].value) {
+				reply(messageID, result(ldapBindResponse, ldapResultInvalidCredentials))
+				continue
+			}
+			reply(messageID, result(ldapBindResponse, ldapResultSuccess))
+		case ldapSearchRequest:
+			baseDN, scope, filter := string(elements[0].value), berDecodeInteger(elements[1].value), elements[6]
+			for dn, entry := range s.entries {
+				inScope := dn == baseDN || (scope == ldapScopeWholeSubtree && strings.HasSuffix(dn, ","+baseDN))
+				if inScope && matchesMockFilter(entry, filter) {
+					reply(messageID, encodeMockEntry(dn, entry))
+				}
+			}
+			reply(messageID, result(ldapSearchResultDone, ldapResultSuccess))
+		case ldapUnbindRequest:
+			return
+		}
+	}
+}
+
+func matchesMockFilter(entry mockLDAPEntry, filter berElement) bool {
+	elements, _ := berDecodeAll(filter.value)
+	switch filter.tag {
+	case ldapFilterAnd:
+		for _, element := range elements {
+			if !matchesMockFilter(entry, element) {
+				return false
+			}
+		}
+		return true
+	case ldapFilterEqualityMatch:
+		for name, values := range entry.attributes {
+			if strings.EqualFold(name, string(elements[0].value)) {
+				for _, value := range values {
+					if strings.EqualFold(value, string(elements[1].value)) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	case ldapFilterPresent:
+		return strings.EqualFold(string(filter.value), "objectClass")
+	}
+	return false
+}
+
+func encodeMockEntry(dn string, entry mockLDAPEntry) []byte {
+	var attributes [][]byte
+	for name, values := range entry.attributes {
+		var encodedValues [][]byte
+		for _, value := range values {
+			encodedValues = append(encodedValues, berEncodeString(berOctetString, value))
+		}
+		attributes = append(attributes, berEncode(berSequence, berEncodeString(berOctetString, name), berEncode(0x31, encodedValues...)))
+	}
+	return berEncode(ldapSearchResultEntry, berEncodeString(berOctetString, dn), berEncode(berSequence, attributes...))
+}
+
+func newTestLDAPConfig(url string, caFile string) *factory.LDAP {
+	return &factory.LDAP{
+		URL:             url,
+		CAFile:          caFile,
+		BindDN:          "cn=webui,ou=services,dc=example,dc=com",
+		BindPassword:    "service-secret",
+		BaseDN:          "ou=people,dc=example,dc=com",
+		UserAttribute:   "uid",
+		UserObjectClass: "person",
+		GroupAttribute:  "memberOf",
+		RoleMapping: map[string]string{
+			"cn=operators,ou=groups,dc=example,dc=com": "network-engineer",
+			"cn=admins,ou=groups,dc=example,dc=com":    configmodels.RoleAdmin,
+		},
+		Timeout: 5 * time.Second,
+	}
+}
+
+func setUpLDAPAccounts(t *testing.T) *MockMongoClientCollections {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("Admin1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	return setUpMockCollections(t, map[string][]map[string]interface{}{
+		configmodels.UserAccountDataColl: {
+			{"username": "admin", "password": string(hashedPassword), "role": configmodels.AdminRole},
+			{"username": "localuser", "password": string(hashedPassword), "role": configmodels.UserRole},
+		},
+	})
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	server, caFile := newMockLDAPServer(t, false)
+	testCases := []struct {
+		name            string
+		username        string
+		password        string
+		expectedErr     error
+		expectedAccount *configmodels.DBUserAccount
+	}{
+		{
+			name:     "User",
+			username: "janedoe",
+			password: "jane-secret",
+			expectedAccount: &configmodels.DBUserAccount{
+				Username: "janedoe",
+				Role:     configmodels.UserRole,
+				LDAPDN:   "uid=janedoe,ou=people,dc=example,dc=com",
+				Roles:    []string{"network-engineer"},
+			},
+		},
+		{
+			name:     "UsernameOfTheDirectory",
+			username: "JaneDoe",
+			password: "jane-secret",
+			expectedAccount: &configmodels.DBUserAccount{
+				Username: "janedoe",
+				Role:     configmodels.UserRole,
+				LDAPDN:   "uid=janedoe,ou=people,dc=example,dc=com",
+				Roles:    []string{"network-engineer"},
+			},
+		},
+		{
+			name:     "Admin",
+			username: "root",
+			password: "root-secret",
+			expectedAccount: &configmodels.DBUserAccount{
+				Username: "root",
+				Role:     configmodels.AdminRole,
+				LDAPDN:   "uid=root,ou=people,dc=example,dc=com",
+				Roles:    []string{},
+			},
+		},
+		{
+			name:        "WrongPassword",
+			username:    "janedoe",
+			password:    "wrong",
+			expectedErr: ErrIncorrectCredentials,
+		},
+		{
+			name:        "EmptyPassword",
+			username:    "janedoe",
+			password:    "",
+			expectedErr: ErrIncorrectCredentials,
+		},
+		{
+			name:        "UnknownUser",
+			username:    "johndoe",
+			password:    "jane-secret",
+			expectedErr: ErrIncorrectCredentials,
+		},
+		{
+			name:        "NoMappedRole",
+			username:    "guest",
+			password:    "guest-secret",
+			expectedErr: errNoMappedRole,
+		},
+		{
+			name:        "LocalAccount",
+			username:    "localuser",
+			password:    "local-secret",
+			expectedErr: errAccountConflict,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbClient := setUpLDAPAccounts(t)
+			authenticator, err := newLDAPAuthenticator(newTestLDAPConfig(server.url("ldap"), caFile))
+			if err != nil {
+				t.Fatalf("failed to create LDAP authenticator: %v", err)
+			}
+			account, err := authenticator.Authenticate(context.Background(), tc.username, tc.password)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error `%v`, got `%v`", tc.expectedErr, err)
+			}
+			if tc.expectedAccount == nil {
+				if len(dbClient.collections[configmodels.UserAccountDataColl]) != 2 {
+					t.Errorf("Expected no account to be created, got %v", dbClient.collections[configmodels.UserAccountDataColl])
+				}
+				return
+			}
+			if string(configmodels.MapToByte(configmodels.ToBsonM(account))) != string(configmodels.MapToByte(configmodels.ToBsonM(tc.expectedAccount))) {
+				t.Errorf("Expected account %+v, got %+v", tc.expectedAccount, account)
+			}
+			stored, _ := dbClient.RestfulAPIGetOne(configmodels.UserAccountDataColl, map[string]interface{}{"username": tc.expectedAccount.Username})
+			if stored["ldap-dn"] != tc.expectedAccount.LDAPDN || stored["role"] != tc.expectedAccount.Role {
+				t.Errorf("Expected the account to be stored, got %v", stored)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticator_Connections(t *testing.T) {
+	plainServer, caFile := newMockLDAPServer(t, false)
+	ldapsServer, ldapsCAFile := newMockLDAPServer(t, true)
+	testCases := []struct {
+		name   string
+		server *mockLDAPServer
+		config func() *factory.LDAP
+	}{
+		{
+			name:   "StartTLS",
+			server: plainServer,
+			config: func() *factory.LDAP {
+				config := newTestLDAPConfig(plainServer.url("ldap"), caFile)
+				config.StartTLS = true
+				return config
+			},
+		},
+		{
+			name:   "LDAPS",
+			server: ldapsServer,
+			config: func() *factory.LDAP { return newTestLDAPConfig(ldapsServer.url("ldaps"), ldapsCAFile) },
+		},
+		{
+			name:   "UserDNTemplate",
+			server: plainServer,
+			config: func() *factory.LDAP {
+				config := newTestLDAPConfig(plainServer.url("ldap"), caFile)
+				config.StartTLS = true
+				config.UserDNTemplate = "uid={username},ou=people,dc=example,dc=com"
+				config.BindDN, config.BindPassword, config.BaseDN = "", "", ""
+				return config
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setUpLDAPAccounts(t)
+			tc.server.mu.Lock()
+			tc.server.plainBinds = 0
+			tc.server.mu.Unlock()
+			authenticator, err := newLDAPAuthenticator(tc.config())
+			if err != nil {
+				t.Fatalf("failed to create LDAP authenticator: %v", err)
+			}
+			for username, password := range map[string]string{"janedoe": "jane-secret", "doe, john": "john-secret"} {
+				account, err := authenticator.Authenticate(context.Background(), username, password)
+				if err != nil {
+					t.Fatalf("Expected %s to log in: %v", username, err)
+				}
+				if account.Username != username || account.Role != configmodels.UserRole {
+					t.Errorf("Unexpected account %+v", account)
+				}
+			}
+			tc.server.mu.Lock()
+			defer tc.server.mu.Unlock()
+			if tc.server.plainBinds != 0 {
+				t.Errorf("Expected the passwords to be sent over TLS only, got %d plain binds", tc.server.plainBinds)
+			}
+		})
+	}
+
+	// the certificate of the server is verified
+	authenticator, err := newLDAPAuthenticator(newTestLDAPConfig(ldapsServer.url("ldaps"), caFile))
+	if err != nil {
+		t.Fatalf("failed to create LDAP authenticator: %v", err)
+	}
+	if _, err = authenticator.Authenticate(context.Background(), "janedoe", "jane-secret"); !errors.Is(err, ErrAuthenticatorUnavailable) {
+		t.Errorf("Expected a server with an unknown certificate to be refused, got `%v`", err)
+	}
+}
+
+func TestLogin_LDAPBreakGlassAdmin(t *testing.T) {
+	// a closed port: the directory is unreachable
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	unreachableURL := "ldap://" + listener.Addr().String()
+	listener.Close()
+	setUpLDAPAccounts(t)
+	authenticator, err := NewAuthenticator(newTestLDAPConfig(unreachableURL, ""))
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	AddAuthenticationService(router, authenticator, NewHMACJWTKeys([]byte("mockSecret")))
+
+	for body, expectedCode := range map[string]int{
+		`{"username": "admin", "password": "Admin1234"}`:     http.StatusOK,
+		`{"username": "admin", "password": "wrong"}`:         http.StatusUnauthorized,
+		`{"username": "janedoe", "password": "jane-secret"}`: http.StatusServiceUnavailable,
+	} {
+		if w := postJSON(router, "/login", body, ""); w.Code != expectedCode {
+			t.Errorf("%s: expected `%v`, got `%v`: %s", body, expectedCode, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestEscapeDN(t *testing.T) {
+	for value, expected := range map[string]string{
+		"janedoe":           "janedoe",
+		"doe, john":         `doe\, john`,
+		"#admin":            `\#admin`,
+		" padded ":          `\ padded\ `,
+		`a+b"c\d<e>f;g=h`:   `a\+b\"c\\d\<e\>f\;g\=h`,
+		"x,ou=admins,dc=ex": `x\,ou\=admins\,dc\=ex`,
+	} {
+		if escaped := escapeDN(value); escaped != expected {
+			t.Errorf("escapeDN(%q): expected %q, got %q", value, expected, escaped)
+		}
+	}
+}
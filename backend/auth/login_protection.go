@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/backend/metrics"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxLoginFailureUpdates bounds the attempts to count a failure while other
+// replicas count failures of the same account or IP address.
+const maxLoginFailureUpdates = 5
+
+// loginLimit is a limit of failed logins of an account or a client IP address.
+// The failures are counted in the webui database, so that the limit holds
+// across the replicas of webconsole.
+type loginLimit struct {
+	label       string
+	key         string
+	maxFailures int
+}
+
+// loginLimits returns the enabled limits of the logins as username from the
+// client IP address. Usernames are compared case-insensitively, as the
+// directories do, so that changing the case does not reset the count.
+func loginLimits(username string, clientIP string) []loginLimit {
+	config := factory.WebUIConfig.Configuration.LoginProtection
+	limits := []loginLimit{}
+	if config.MaxFailures > 0 {
+		limits = append(limits, loginLimit{label: metrics.LoginLimitAccount, key: accountLoginKey(username), maxFailures: config.MaxFailures})
+	}
+	if config.MaxFailuresPerIP > 0 {
+		limits = append(limits, loginLimit{label: metrics.LoginLimitIP, key: "ip:" + clientIP, maxFailures: config.MaxFailuresPerIP})
+	}
+	return limits
+}
+
+func accountLoginKey(username string) string {
+	return "account:" + strings.ToLower(username)
+}
+
+// loginLockout returns how long the logins are still refused by the limits, 0
+// if none of them is locked out.
+func loginLockout(limits []loginLimit, now time.Time) (time.Duration, error) {
+	var lockout time.Duration
+	for _, limit := range limits {
+		failures, err := fetchLoginFailures(limit.key)
+		if err != nil {
+			return 0, err
+		}
+		if failures != nil && failures.Failures >= limit.maxFailures && now.Before(failures.ExpiresAt) {
+			metrics.LoginsRefusedLocked.WithLabelValues(limit.label).Inc()
+			lockout = max(lockout, failures.ExpiresAt.Sub(now))
+		}
+	}
+	return lockout, nil
+}
+
+// recordLoginFailure counts a failed login against every limit.
+func recordLoginFailure(limits []loginLimit, now time.Time) error {
+	metrics.LoginFailures.Inc()
+	for _, limit := range limits {
+		if err := countLoginFailure(limit, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func countLoginFailure(limit loginLimit, now time.Time) error {
+	config := factory.WebUIConfig.Configuration.LoginProtection
+	for range maxLoginFailureUpdates {
+		failures, err := fetchLoginFailures(limit.key)
+		if err != nil {
+			return err
+		}
+		count, expiresAt := 1, now.Add(config.FailureWindow)
+		if failures != nil && now.Before(failures.ExpiresAt) {
+			count, expiresAt = failures.Failures+1, failures.ExpiresAt
+		}
+		if count >= limit.maxFailures {
+			expiresAt = now.Add(config.LockoutDuration)
+		}
+		if failures == nil {
+			document := bson.M{"key": limit.key, "failures": count, "expires-at": expiresAt}
+			err = dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.LoginFailureDataColl, bson.M{"key": limit.key}, []interface{}{document})
+			if err != nil {
+				// another replica counted the first failure in the meantime
+				logger.AuthLog.Debugf("failed to store the first failed login of %s: %v", limit.key, err)
+				continue
+			}
+		} else {
+			swapped, err := dbadapter.WebuiDBClient.RestfulAPICompareAndSwapWithContext(context.Background(), configmodels.LoginFailureDataColl,
+				bson.M{"key": limit.key, "failures": failures.Failures},
+				bson.M{"failures": count, "expires-at": expiresAt})
+			if err != nil {
+				return fmt.Errorf("failed to count the failed login of %s: %w", limit.key, err)
+			}
+			if !swapped {
+				continue
+			}
+		}
+		if count == limit.maxFailures {
+			logger.AuthLog.Warnf("%d failed logins of %s, locked out until %s", count, limit.key, expiresAt.Format(time.RFC3339))
+			metrics.LoginLockouts.WithLabelValues(limit.label).Inc()
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to count the failed login of %s: too many concurrent updates", limit.key)
+}
+
+// resetLoginFailures forgets the failed logins of the account, after it logged
+// in. The failures of the client IP address keep counting.
+func resetLoginFailures(username string) error {
+	if err := dbadapter.WebuiDBClient.RestfulAPIDeleteOne(configmodels.LoginFailureDataColl, bson.M{"key": accountLoginKey(username)}); err != nil {
+		return fmt.Errorf("failed to reset the failed logins of %s: %w", username, err)
+	}
+	return nil
+}
+
+// UnlockAccount ends the lockout of the account, if any, and resets its count
+// of failed logins.
+func UnlockAccount(username string) error {
+	return resetLoginFailures(username)
+}
+
+func fetchLoginFailures(key string) (*configmodels.DBLoginFailures, error) {
+	rawFailures, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.LoginFailureDataColl, bson.M{"key": key})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the failed logins of %s: %w", key, err)
+	}
+	if len(rawFailures) == 0 {
+		return nil, nil
+	}
+	var failures configmodels.DBLoginFailures
+	if err = json.Unmarshal(configmodels.MapToByte(rawFailures), &failures); err != nil {
+		return nil, fmt.Errorf("failed to decode the failed logins of %s: %w", key, err)
+	}
+	return &failures, nil
+}
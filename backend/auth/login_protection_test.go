@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/metrics"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setUpLoginProtection(t *testing.T) (*MockMongoClientCollections, *gin.Engine) {
+	originalLoginProtection := factory.WebUIConfig.Configuration.LoginProtection
+	t.Cleanup(func() { factory.WebUIConfig.Configuration.LoginProtection = originalLoginProtection })
+	factory.WebUIConfig.Configuration.LoginProtection = factory.LoginProtection{
+		MaxFailures:      3,
+		MaxFailuresPerIP: 5,
+		FailureWindow:    time.Minute,
+		LockoutDuration:  10 * time.Minute,
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("Admin1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	dbClient := setUpMockCollections(t, map[string][]map[string]interface{}{
+		configmodels.UserAccountDataColl: {
+			{"username": "admin", "password": string(hashedPassword), "role": configmodels.AdminRole},
+			{"username": "janedoe", "password": string(hashedPassword), "role": configmodels.UserRole},
+		},
+	})
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// as webconsole does, so that X-Forwarded-For cannot change the client IP address
+	if err = router.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("failed to set trusted proxies: %v", err)
+	}
+	AddAuthenticationService(router, NewLocalAuthenticator(), NewHMACJWTKeys([]byte("mockSecret")))
+	return dbClient, router
+}
+
+func loginFrom(router *gin.Engine, clientIP string, username string, password string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username": "`+username+`", "password": "`+password+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", "203.0.113.1")
+	req.RemoteAddr = clientIP + ":41000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLogin_AccountLockout(t *testing.T) {
+	_, router := setUpLoginProtection(t)
+	lockouts := testutil.ToFloat64(metrics.LoginLockouts.WithLabelValues(metrics.LoginLimitAccount))
+
+	// a success resets the count of failures
+	loginFrom(router, "192.0.2.1", "admin", "wrong")
+	loginFrom(router, "192.0.2.1", "admin", "wrong")
+	if w := loginFrom(router, "192.0.2.1", "admin", "Admin1234"); w.Code != http.StatusOK {
+		t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	// the failures are counted whatever the case of the username, and the IP address
+	for i, clientIP := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		if w := loginFrom(router, clientIP, []string{"admin", "Admin", "ADMIN"}[i], "wrong"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusUnauthorized, w.Code, w.Body.String())
+		}
+	}
+	w := loginFrom(router, "192.0.2.4", "admin", "Admin1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected the account to be locked out, got `%v`: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") != "600" {
+		t.Errorf("Expected to retry after 600 seconds, got `%v`", w.Header().Get("Retry-After"))
+	}
+	if locked := testutil.ToFloat64(metrics.LoginLockouts.WithLabelValues(metrics.LoginLimitAccount)) - lockouts; locked != 1 {
+		t.Errorf("Expected 1 account lockout to be counted, got %v", locked)
+	}
+	// the other accounts are not locked out
+	if w = loginFrom(router, "192.0.2.4", "janedoe", "Admin1234"); w.Code != http.StatusOK {
+		t.Errorf("Expected `%v`, got `%v`: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	if err := UnlockAccount("Admin"); err != nil {
+		t.Fatalf("failed to unlock account: %v", err)
+	}
+	if w = loginFrom(router, "192.0.2.4", "admin", "Admin1234"); w.Code != http.StatusOK {
+		t.Errorf("Expected the account to be unlocked, got `%v`: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLogin_IPLockout(t *testing.T) {
+	_, router := setUpLoginProtection(t)
+
+	for _, username := range []string{"a", "b", "c", "d", "e"} {
+		if w := loginFrom(router, "192.0.2.1", username, "wrong"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusUnauthorized, w.Code, w.Body.String())
+		}
+	}
+	// the X-Forwarded-For header of an untrusted client is ignored
+	if w := loginFrom(router, "192.0.2.1", "admin", "Admin1234"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the IP address to be locked out, got `%v`: %s", w.Code, w.Body.String())
+	}
+	if w := loginFrom(router, "192.0.2.2", "admin", "Admin1234"); w.Code != http.StatusOK {
+		t.Errorf("Expected another IP address to log in, got `%v`: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLoginFailures_Expiry(t *testing.T) {
+	dbClient, _ := setUpLoginProtection(t)
+	now := time.Now()
+	limits := loginLimits("janedoe", "192.0.2.1")
+
+	// failures older than the window are not counted
+	for _, failedAt := range []time.Time{now, now.Add(30 * time.Second), now.Add(2 * time.Minute), now.Add(150 * time.Second)} {
+		if err := recordLoginFailure(limits, failedAt); err != nil {
+			t.Fatalf("failed to record failure: %v", err)
+		}
+	}
+	failures, err := fetchLoginFailures(accountLoginKey("janedoe"))
+	if err != nil || failures == nil || failures.Failures != 2 {
+		t.Fatalf("Expected 2 failures, got %+v %v", failures, err)
+	}
+	if lockout, err := loginLockout(limits, now.Add(150*time.Second)); err != nil || lockout != 0 {
+		t.Errorf("Expected no lockout, got `%v` %v", lockout, err)
+	}
+
+	// the third failure locks the account out until the end of the lockout
+	lockedAt := now.Add(170 * time.Second)
+	if err = recordLoginFailure(limits, lockedAt); err != nil {
+		t.Fatalf("failed to record failure: %v", err)
+	}
+	if lockout, err := loginLockout(limits, lockedAt.Add(5*time.Minute)); err != nil || lockout != 5*time.Minute {
+		t.Errorf("Expected a lockout of 5 minutes, got `%v` %v", lockout, err)
+	}
+	if lockout, err := loginLockout(limits, now.Add(13*time.Minute)); err != nil || lockout != 0 {
+		t.Errorf("Expected the lockout to be over, got `%v` %v", lockout, err)
+	}
+	if err = recordLoginFailure(limits, now.Add(13*time.Minute)); err != nil {
+		t.Fatalf("failed to record failure: %v", err)
+	}
+	if failures, err = fetchLoginFailures(accountLoginKey("janedoe")); err != nil || failures.Failures != 1 {
+		t.Errorf("Expected the count to restart after the lockout, got %+v %v", failures, err)
+	}
+	if len(dbClient.collections[configmodels.LoginFailureDataColl]) != 2 {
+		t.Errorf("Expected the failures of the account and the IP address, got %v", dbClient.collections[configmodels.LoginFailureDataColl])
+	}
+}
+
+func TestLoginLimits_Disabled(t *testing.T) {
+	setUpLoginProtection(t)
+	factory.WebUIConfig.Configuration.LoginProtection.MaxFailures = 0
+	limits := loginLimits("janedoe", "192.0.2.1")
+	if len(limits) != 1 || limits[0].key != "ip:192.0.2.1" {
+		t.Errorf("Expected only the limit of the IP address, got %+v", limits)
+	}
+	factory.WebUIConfig.Configuration.LoginProtection.MaxFailuresPerIP = 0
+	if limits = loginLimits("janedoe", "192.0.2.1"); len(limits) != 0 {
+		t.Errorf("Expected no limit, got %+v", limits)
+	}
+}
@@ -4,10 +4,11 @@
 package auth
 
 import (
-	"crypto/rand"
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -19,23 +20,49 @@ import (
 
 type jwtWebconsoleClaims struct {
 	jwt.RegisteredClaims
-	Username string `json:"username"`
-	Role     int    `json:"role"`
+	Username string   `json:"username"`
+	Role     int      `json:"role"`
+	Scopes   []string `json:"scopes,omitempty"`
+	// SessionID is the login session of the token, API tokens have none
+	SessionID string `json:"sid,omitempty"`
 }
 
-func GenerateJWTSecret() ([]byte, error) {
-	bytes := make([]byte, 32)
-	if _, err := rand.Read(bytes); err != nil {
-		return bytes, fmt.Errorf("failed to generate JWT secret: %w", err)
-	}
-	return bytes, nil
+type contextKey string
+
+const (
+	usernameContextKey contextKey = "username"
+	roleContextKey     contextKey = "role"
+)
+
+// UsernameFromContext returns the username of the authenticated user that issued the request.
+// It returns an empty string when the request was not authenticated.
+func UsernameFromContext(ctx context.Context) string {
+	username, _ := ctx.Value(usernameContextKey).(string)
+	return username
+}
+
+// RoleFromContext returns the role of the authenticated user that issued the request.
+// The second value is false when the request was not authenticated.
+func RoleFromContext(ctx context.Context) (int, bool) {
+	role, ok := ctx.Value(roleContextKey).(int)
+	return role, ok
+}
+
+// ContextWithUser returns a copy of ctx carrying the username and role of the authenticated user.
+func ContextWithUser(ctx context.Context, username string, role int) context.Context {
+	ctx = context.WithValue(ctx, usernameContextKey, username)
+	return context.WithValue(ctx, roleContextKey, role)
+}
+
+func setAuthenticatedUser(c *gin.Context, claims *jwtWebconsoleClaims) {
+	c.Request = c.Request.WithContext(ContextWithUser(c.Request.Context(), claims.Username, claims.Role))
 }
 
 // AdminOrUserAuthMiddleware intercepts requests that need authorization to check if the user's token exists and is
 // permitted to use the endpoint
-func AdminOrUserAuthMiddleware(jwtSecret []byte) gin.HandlerFunc {
+func AdminOrUserAuthMiddleware(jwtKeys *JWTKeys) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtSecret)
+		claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtKeys)
 		if err != nil {
 			logger.AuthLog.Errorln(err.Error())
 			c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("auth failed: %s", err.Error())})
@@ -45,16 +72,21 @@ func AdminOrUserAuthMiddleware(jwtSecret []byte) gin.HandlerFunc {
 		if claims.Role != configmodels.AdminRole && claims.Role != configmodels.UserRole {
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: admin or user access required"})
 			c.Abort()
+			return
 		}
+		if !checkScopes(c, claims) {
+			return
+		}
+		setAuthenticatedUser(c, claims)
 		c.Next()
 	}
 }
 
 // AdminOnly checks if the authorization token is valid for this endpoint.
 // Only tokens with AdminRole will be allowed.
-func AdminOnly(jwtSecret []byte, handler func(c *gin.Context)) func(c *gin.Context) {
+func AdminOnly(jwtKeys *JWTKeys, handler func(c *gin.Context)) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtSecret)
+		claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtKeys)
 		if err != nil {
 			logger.AuthLog.Errorln(err.Error())
 			c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("auth failed: %s", err.Error())})
@@ -66,6 +98,10 @@ func AdminOnly(jwtSecret []byte, handler func(c *gin.Context)) func(c *gin.Conte
 			c.Abort()
 			return
 		}
+		if !checkScopes(c, claims) {
+			return
+		}
+		setAuthenticatedUser(c, claims)
 		handler(c)
 	}
 }
@@ -73,9 +109,9 @@ func AdminOnly(jwtSecret []byte, handler func(c *gin.Context)) func(c *gin.Conte
 // AdminOrMe checks if the authorization token is valid for this endpoint.
 // Admin role is allowed. UserRole is allowed with the condition of performing the action
 // over their own account
-func AdminOrMe(jwtSecret []byte, handler func(c *gin.Context)) func(c *gin.Context) {
+func AdminOrMe(jwtKeys *JWTKeys, handler func(c *gin.Context)) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtSecret)
+		claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtKeys)
 		if err != nil {
 			logger.AuthLog.Errorln(err.Error())
 			c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("auth failed: %s", err.Error())})
@@ -83,6 +119,10 @@ func AdminOrMe(jwtSecret []byte, handler func(c *gin.Context)) func(c *gin.Conte
 			return
 		}
 		if claims.Role == configmodels.AdminRole || (claims.Role == configmodels.UserRole && claims.Username == c.Param("username")) {
+			if !checkScopes(c, claims) {
+				return
+			}
+			setAuthenticatedUser(c, claims)
 			handler(c)
 			return
 		}
@@ -93,7 +133,7 @@ func AdminOrMe(jwtSecret []byte, handler func(c *gin.Context)) func(c *gin.Conte
 
 // AdminOrFirstUser checks if the authorization token is valid for this endpoint.
 // check if the user has admin role or if the user is the first user before allowing access to the handler.
-func AdminOrFirstUser(jwtSecret []byte, handler func(c *gin.Context)) func(c *gin.Context) {
+func AdminOrFirstUser(jwtKeys *JWTKeys, handler func(c *gin.Context)) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		numOfUserAccounts, err := dbadapter.WebuiDBClient.RestfulAPICount(configmodels.UserAccountDataColl, bson.M{})
 		if err != nil {
@@ -103,7 +143,7 @@ func AdminOrFirstUser(jwtSecret []byte, handler func(c *gin.Context)) func(c *gi
 			return
 		}
 		if numOfUserAccounts > 0 {
-			claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtSecret)
+			claims, err := getClaimsFromAuthorizationHeader(c.Request.Header.Get("Authorization"), jwtKeys)
 			if err != nil {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("auth failed: %s", err.Error())})
 				c.Abort()
@@ -114,12 +154,15 @@ func AdminOrFirstUser(jwtSecret []byte, handler func(c *gin.Context)) func(c *gi
 				c.Abort()
 				return
 			}
+			if !checkScopes(c, claims) {
+				return
+			}
 		}
 		handler(c)
 	}
 }
 
-func getClaimsFromAuthorizationHeader(header string, JwtSecret []byte) (*jwtWebconsoleClaims, error) {
+func getClaimsFromAuthorizationHeader(header string, jwtKeys *JWTKeys) (*jwtWebconsoleClaims, error) {
 	if header == "" {
 		return nil, fmt.Errorf("authorization header not found")
 	}
@@ -127,21 +170,45 @@ func getClaimsFromAuthorizationHeader(header string, JwtSecret []byte) (*jwtWebc
 	if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
 		return nil, fmt.Errorf("authorization header couldn't be processed. The expected format is 'Bearer token'")
 	}
-	claims, err := getClaimsFromJWT(bearerToken[1], JwtSecret)
+	if isAPIToken(bearerToken[1]) {
+		claims, err := getClaimsFromAPIToken(bearerToken[1], time.Now())
+		if err != nil {
+			logger.AuthLog.Warnln(err.Error())
+			return nil, fmt.Errorf("token is not valid")
+		}
+		return claims, nil
+	}
+	claims, err := getClaimsFromJWT(bearerToken[1], jwtKeys)
+	if err != nil || claims.SessionID == "" {
+		return nil, fmt.Errorf("token is not valid")
+	}
+	// the token is revoked with its session, on logout, password change or
+	// account deletion
+	active, err := isSessionActive(claims.SessionID, time.Now())
 	if err != nil {
+		logger.AuthLog.Errorln(err.Error())
 		return nil, fmt.Errorf("token is not valid")
 	}
+	if !active {
+		return nil, fmt.Errorf("token has been revoked")
+	}
 	return claims, nil
 }
 
-func getClaimsFromJWT(bearerToken string, JwtSecret []byte) (*jwtWebconsoleClaims, error) {
+// checkScopes refuses the request if the scopes of the token do not allow it.
+// It reports whether the request may go on.
+func checkScopes(c *gin.Context, claims *jwtWebconsoleClaims) bool {
+	if scopesAllow(claims.Scopes, c.Request.Method, c.Request.URL.Path) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: the token scopes do not allow this request"})
+	c.Abort()
+	return false
+}
+
+func getClaimsFromJWT(bearerToken string, jwtKeys *JWTKeys) (*jwtWebconsoleClaims, error) {
 	claims := jwtWebconsoleClaims{}
-	token, err := jwt.ParseWithClaims(bearerToken, &claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return JwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(bearerToken, &claims, jwtKeys.keyFunc)
 	if err != nil || !token.Valid {
 		return nil, err
 	}
@@ -0,0 +1,432 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	oidcHTTPTimeout = 10 * time.Second
+	// oidcLoginLifetime is how long the user has to log in with the provider
+	oidcLoginLifetime = 10 * time.Minute
+	// oidcKeyFetchInterval limits how often tokens signed by unknown keys make
+	// the provider keys be fetched again
+	oidcKeyFetchInterval = time.Minute
+)
+
+var (
+	errInvalidOIDCLogin   = errors.New("login request is not valid or has expired")
+	errOIDCAuthentication = errors.New("failed to authenticate with the identity provider")
+	errOIDCMissingClaim   = errors.New("ID token does not identify the account")
+)
+
+// oidcMetadata is the part of the OpenID Connect discovery document used by
+// webconsole.
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// OIDCProvider logs users in with an OpenID Connect provider, following the
+// authorization code flow with PKCE. The discovery document and the keys of
+// the provider are fetched on the first login, so that webconsole starts even
+// if the provider is unreachable.
+type OIDCProvider struct {
+	config     *factory.OIDC
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	metadata      *oidcMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(config *factory.OIDC) *OIDCProvider {
+	return &OIDCProvider{
+		config:     config,
+		httpClient: &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var metadata oidcMetadata
+	discoveryURL := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	if metadata.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("OIDC provider issuer %s does not match the configured issuer %s", metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksURI == "" {
+		return nil, fmt.Errorf("OIDC provider %s does not publish its endpoints", p.config.Issuer)
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// startLogin stores a new login request and returns the URL of the provider
+// the user is redirected to.
+func (p *OIDCProvider) startLogin(ctx context.Context, now time.Time) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	login := configmodels.DBOIDCLogin{ExpiresAt: now.Add(oidcLoginLifetime)}
+	for _, value := range []*string{&login.State, &login.Nonce, &login.CodeVerifier} {
+		if *value, err = randomURLSafeString(); err != nil {
+			return "", err
+		}
+	}
+	document := bson.M{
+		"state":         login.State,
+		"nonce":         login.Nonce,
+		"code-verifier": login.CodeVerifier,
+		"expires-at":    login.ExpiresAt,
+	}
+	if err = dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.OIDCLoginDataColl, bson.M{"state": login.State}, []interface{}{document}); err != nil {
+		return "", fmt.Errorf("failed to store OIDC login: %w", err)
+	}
+	codeChallenge := sha256.Sum256([]byte(login.CodeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {login.State},
+		"nonce":                 {login.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(codeChallenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// finishLogin redeems the authorization code of the login request state and
+// returns the claims of the verified ID token. A login request can only be
+// finished once.
+func (p *OIDCProvider) finishLogin(ctx context.Context, state string, code string, now time.Time) (jwt.MapClaims, error) {
+	rawLogin, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.OIDCLoginDataColl, bson.M{"state": state})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve OIDC login: %w", err)
+	}
+	if len(rawLogin) == 0 {
+		return nil, errInvalidOIDCLogin
+	}
+	if err = dbadapter.WebuiDBClient.RestfulAPIDeleteOne(configmodels.OIDCLoginDataColl, bson.M{"state": state}); err != nil {
+		return nil, fmt.Errorf("failed to delete OIDC login: %w", err)
+	}
+	var login configmodels.DBOIDCLogin
+	if err = json.Unmarshal(configmodels.MapToByte(rawLogin), &login); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC login: %w", err)
+	}
+	if !now.Before(login.ExpiresAt) {
+		return nil, errInvalidOIDCLogin
+	}
+	idToken, err := p.exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errOIDCAuthentication, err)
+	}
+	claims, err := p.verifyIDToken(ctx, idToken, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errOIDCAuthentication, err)
+	}
+	return claims, nil
+}
+
+// account returns the account of the user of claims, creating it on the first
+// login. The roles of the account follow the role claim at every login.
+func (p *OIDCProvider) account(claims jwt.MapClaims) (*configmodels.DBUserAccount, error) {
+	subject, _ := claims["sub"].(string)
+	username, _ := claims[p.config.UsernameClaim].(string)
+	if subject == "" || username == "" {
+		return nil, errOIDCMissingClaim
+	}
+	roles := p.mapRoles(claims[p.config.RoleClaim])
+	if len(p.config.RoleMapping) > 0 && len(roles) == 0 {
+		return nil, errNoMappedRole
+	}
+	account := &configmodels.DBUserAccount{
+		Username:    username,
+		Role:        configmodels.UserRole,
+		OIDCSubject: subject,
+		Roles:       roles,
+	}
+	// the account of another user of the provider is not theirs either
+	return syncExternalAccount(account, func(dbUser *configmodels.DBUserAccount) bool {
+		return dbUser.OIDCSubject == subject
+	})
+}
+
+// mapRoles returns the roles given to the values of the role claim, which is
+// a string or a list of strings.
+func (p *OIDCProvider) mapRoles(roleClaim interface{}) []string {
+	roles := []string{}
+	if len(p.config.RoleMapping) == 0 {
+		return roles
+	}
+	var values []interface{}
+	switch roleClaim := roleClaim.(type) {
+	case string:
+		values = []interface{}{roleClaim}
+	case []interface{}:
+		values = roleClaim
+	}
+	for _, value := range values {
+		value, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if role, ok := p.config.RoleMapping[value]; ok && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func (p *OIDCProvider) exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	var tokenResponse oidcTokenResponse
+	if err = json.Unmarshal(body, &tokenResponse); err != nil {
+		return "", fmt.Errorf("failed to redeem authorization code: %s returned %s", metadata.TokenEndpoint, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to redeem authorization code: %s %s", tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+	if tokenResponse.IDToken == "" {
+		return "", fmt.Errorf("failed to redeem authorization code: no ID token returned")
+	}
+	return tokenResponse.IDToken, nil
+}
+
+// verifyIDToken checks the ID token was signed by the provider for webconsole,
+// in answer to the login request of nonce.
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, idToken string, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ID token is not valid: %w", err)
+	}
+	if claims["nonce"] != nonce {
+		return nil, fmt.Errorf("ID token is not valid: nonce does not match the login request")
+	}
+	if azp, ok := claims["azp"]; ok && azp != p.config.ClientID {
+		return nil, fmt.Errorf("ID token is not valid: it was issued to %v", azp)
+	}
+	return claims, nil
+}
+
+// publicKey returns the key of the provider with the key ID kid. The keys are
+// fetched again for an unknown key ID, as the provider may have rotated them.
+func (p *OIDCProvider) publicKey(ctx context.Context, kid string) (interface{}, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetchedAt) < oidcKeyFetchInterval {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	var jwks JWKSResponse
+	if err = p.getJSON(ctx, metadata.JwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC provider keys: %w", err)
+	}
+	p.keys = map[string]interface{}{}
+	p.keysFetchedAt = time.Now()
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		p.keys[jwk.Kid] = key
+	}
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// findKey returns the key of kid or, if the token names no key, the only key
+// of the provider.
+func (p *OIDCProvider) findKey(kid string) interface{} {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+func parseJWK(jwk JWK) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		byteLen := (curve.Params().BitSize + 7) / 8
+		if len(x) > byteLen || len(y) > byteLen {
+			return nil, fmt.Errorf("invalid EC point")
+		}
+		// parsing the uncompressed point checks it is on the curve
+		point := make([]byte, 1+2*byteLen)
+		point[0] = 4
+		copy(point[1+byteLen-len(x):], x)
+		copy(point[1+2*byteLen-len(y):], y)
+		if _, err = ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
+
+func randomURLSafeString() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
@@ -0,0 +1,387 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// mockOIDCProvider is an OpenID Connect provider issuing ID tokens for the
+// authorization requests the test approved.
+type mockOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	codes  map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	codeChallenge string
+	redirectURI   string
+	claims        jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, jwtRSAKeyBits)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	provider := &mockOIDCProvider{t: t, key: key, codes: map[string]mockAuthorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 provider.server.URL,
+			"authorization_endpoint": provider.server.URL + "/authorize",
+			"token_endpoint":         provider.server.URL + "/token",
+			"jwks_uri":               provider.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, JWKSResponse{Keys: []JWK{{
+			Kty: "RSA",
+			Kid: "provider-key",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", provider.token)
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != "webui" || clientSecret != "webui-secret" {
+		writeTestJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	authorization, ok := p.codes[r.PostFormValue("code")]
+	delete(p.codes, r.PostFormValue("code"))
+	codeChallenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != authorization.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(codeChallenge[:]) != authorization.codeChallenge {
+		writeTestJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, authorization.claims)
+	idToken.Header["kid"] = "provider-key"
+	signedIDToken, err := idToken.SignedString(p.key)
+	if err != nil {
+		p.t.Fatalf("failed to sign ID token: %v", err)
+	}
+	writeTestJSON(w, http.StatusOK, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": signedIDToken})
+}
+
+// authorize plays the user logging in at the authorization URL: it returns
+// the callback query of the approved login, with an ID token of claims, which
+// are completed with the standard claims.
+func (p *mockOIDCProvider) authorize(authorizationURL string, claims jwt.MapClaims) url.Values {
+	parsedURL, err := url.Parse(authorizationURL)
+	if err != nil {
+		p.t.Fatalf("failed to parse authorization URL: %v", err)
+	}
+	query := parsedURL.Query()
+	if parsedURL.Path != "/authorize" || query.Get("response_type") != "code" || query.Get("client_id") != "webui" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("scope") != "openid profile groups" {
+		p.t.Fatalf("unexpected authorization URL %s", authorizationURL)
+	}
+	defaultClaims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   "webui",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for claim, value := range claims {
+		defaultClaims[claim] = value
+	}
+	code := rand.Text()
+	p.codes[code] = mockAuthorization{
+		codeChallenge: query.Get("code_challenge"),
+		redirectURI:   query.Get("redirect_uri"),
+		claims:        defaultClaims,
+	}
+	return url.Values{"code": {code}, "state": {query.Get("state")}}
+}
+
+type MockMongoClientCollections struct {
+	dbadapter.DBInterface
+	collections map[string][]map[string]interface{}
+}
+
+func (db *MockMongoClientCollections) find(collName string, filter bson.M) int {
+	for i, document := range db.collections[collName] {
+		matches := true
+		for field, value := range filter {
+			if document[field] != value {
+				matches = false
+			}
+		}
+		if matches {
+			return i
+		}
+	}
+	return -1
+}
+
+func (db *MockMongoClientCollections) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	if i := db.find(collName, filter); i >= 0 {
+		return db.collections[collName][i], nil
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (db *MockMongoClientCollections) RestfulAPICount(collName string, filter bson.M) (int64, error) {
+	return int64(len(db.collections[collName])), nil
+}
+
+func (db *MockMongoClientCollections) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, postData := range postDataArray {
+		db.collections[collName] = append(db.collections[collName], postData.(bson.M))
+	}
+	return nil
+}
+
+func (db *MockMongoClientCollections) RestfulAPIPost(collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
+	if i := db.find(collName, filter); i >= 0 {
+		for field, value := range postData {
+			db.collections[collName][i][field] = value
+		}
+	}
+	return true, nil
+}
+
+func (db *MockMongoClientCollections) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	i := db.find(collName, filter)
+	if i < 0 {
+		return false, nil
+	}
+	for field, value := range putData {
+		db.collections[collName][i][field] = value
+	}
+	return true, nil
+}
+
+func (db *MockMongoClientCollections) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	if i := db.find(collName, filter); i >= 0 {
+		db.collections[collName] = append(db.collections[collName][:i], db.collections[collName][i+1:]...)
+	}
+	return nil
+}
+
+func setUpMockCollections(t *testing.T, collections map[string][]map[string]interface{}) *MockMongoClientCollections {
+	originalDBClient := dbadapter.WebuiDBClient
+	t.Cleanup(func() { dbadapter.WebuiDBClient = originalDBClient })
+	dbClient := &MockMongoClientCollections{collections: collections}
+	dbadapter.WebuiDBClient = dbClient
+	return dbClient
+}
+
+func setUpOIDC(t *testing.T) (*mockOIDCProvider, *MockMongoClientCollections, *JWTKeys, *gin.Engine) {
+	dbClient := setUpMockCollections(t, map[string][]map[string]interface{}{
+		configmodels.UserAccountDataColl: {{"username": "admin", "password": "hash", "role": configmodels.AdminRole}},
+	})
+	mockProvider := newMockOIDCProvider(t)
+	provider := NewOIDCProvider(&factory.OIDC{
+		Issuer:        mockProvider.server.URL,
+		ClientID:      "webui",
+		ClientSecret:  "webui-secret",
+		RedirectURL:   "https://webui.example.com/oidc/callback",
+		Scopes:        []string{"openid", "profile", "groups"},
+		UsernameClaim: "preferred_username",
+		RoleClaim:     "groups",
+		RoleMapping:   map[string]string{"core-operators": "network-engineer", "noc": "viewer"},
+	})
+	jwtKeys := NewHMACJWTKeys([]byte("mockSecret"))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	AddOIDCService(router, provider, jwtKeys)
+	return mockProvider, dbClient, jwtKeys, router
+}
+
+func startOIDCLogin(t *testing.T, router *gin.Engine) string {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusFound, w.Code, w.Body.String())
+	}
+	return w.Header().Get("Location")
+}
+
+func oidcCallback(router *gin.Engine, query url.Values) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/callback?"+query.Encode(), nil))
+	return w
+}
+
+func TestOIDCLogin(t *testing.T) {
+	mockProvider, dbClient, jwtKeys, router := setUpOIDC(t)
+
+	query := mockProvider.authorize(startOIDCLogin(t, router), jwt.MapClaims{
+		"sub":                "subject-1",
+		"preferred_username": "janedoe",
+		"groups":             []string{"noc", "core-operators", "unrelated"},
+	})
+	w := oidcCallback(router, query)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response LoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	claims, err := getClaimsFromJWT(response.Token, jwtKeys)
+	if err != nil {
+		t.Fatalf("Expected a valid webconsole token: %v", err)
+	}
+	if claims.Username != "janedoe" || claims.Role != configmodels.UserRole || claims.SessionID == "" || response.RefreshToken == "" {
+		t.Errorf("Unexpected login response %+v with claims %+v", response, claims)
+	}
+	account, _ := dbClient.RestfulAPIGetOne(configmodels.UserAccountDataColl, bson.M{"username": "janedoe"})
+	expectedAccount := bson.M{"username": "janedoe", "role": configmodels.UserRole, "oidc-subject": "subject-1", "roles": []string{"viewer", "network-engineer"}}
+	if string(configmodels.MapToByte(account)) != string(configmodels.MapToByte(expectedAccount)) {
+		t.Errorf("Expected account %v, got %v", expectedAccount, account)
+	}
+	if len(dbClient.collections[configmodels.OIDCLoginDataColl]) != 0 {
+		t.Errorf("Expected the login request to be consumed")
+	}
+
+	// the state can only be used once
+	if w = oidcCallback(router, query); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a replayed callback to be refused, got `%v`", w.Code)
+	}
+
+	// the roles follow the provider at the next login
+	query = mockProvider.authorize(startOIDCLogin(t, router), jwt.MapClaims{
+		"sub":                "subject-1",
+		"preferred_username": "janedoe",
+		"groups":             "noc",
+	})
+	if w = oidcCallback(router, query); w.Code != http.StatusOK {
+		t.Fatalf("Expected `%v`, got `%v`: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	account, _ = dbClient.RestfulAPIGetOne(configmodels.UserAccountDataColl, bson.M{"username": "janedoe"})
+	if roles, _ := account["roles"].([]string); len(roles) != 1 || roles[0] != "viewer" || len(dbClient.collections[configmodels.UserAccountDataColl]) != 2 {
+		t.Errorf("Expected the roles of the account to be updated, got %v", dbClient.collections[configmodels.UserAccountDataColl])
+	}
+}
+
+func TestOIDCLogin_Refused(t *testing.T) {
+	testCases := []struct {
+		name         string
+		claims       jwt.MapClaims
+		callback     func(query url.Values)
+		expectedCode int
+	}{
+		{
+			name:         "UnknownState",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc"},
+			callback:     func(query url.Values) { query.Set("state", "unknown") },
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "MissingCode",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc"},
+			callback:     func(query url.Values) { query.Del("code") },
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "ProviderError",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc"},
+			callback:     func(query url.Values) { query.Set("error", "access_denied") },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "InvalidCode",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc"},
+			callback:     func(query url.Values) { query.Set("code", "forged") },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "WrongNonce",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc", "nonce": "replayed"},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "WrongAudience",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc", "aud": "other-client"},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Expired",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc", "exp": time.Now().Add(-time.Minute).Unix()},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "MissingUsername",
+			claims:       jwt.MapClaims{"sub": "subject-1", "groups": "noc"},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "NoMappedRole",
+			claims:       jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": []string{"unrelated"}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "LocalAccount",
+			claims:       jwt.MapClaims{"sub": "subject-2", "preferred_username": "admin", "groups": "noc"},
+			expectedCode: http.StatusConflict,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockProvider, dbClient, _, router := setUpOIDC(t)
+			query := mockProvider.authorize(startOIDCLogin(t, router), tc.claims)
+			if tc.callback != nil {
+				tc.callback(query)
+			}
+			if w := oidcCallback(router, query); w.Code != tc.expectedCode {
+				t.Errorf("Expected `%v`, got `%v`: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if len(dbClient.collections[configmodels.UserAccountDataColl]) != 1 || len(dbClient.collections[configmodels.SessionDataColl]) != 0 {
+				t.Errorf("Expected no account nor session to be created, got %v", dbClient.collections)
+			}
+		})
+	}
+}
+
+func TestOIDCLogin_NotInitialized(t *testing.T) {
+	mockProvider, dbClient, _, router := setUpOIDC(t)
+	dbClient.collections[configmodels.UserAccountDataColl] = nil
+
+	query := mockProvider.authorize(startOIDCLogin(t, router), jwt.MapClaims{"sub": "subject-1", "preferred_username": "janedoe", "groups": "noc"})
+	if w := oidcCallback(router, query); w.Code != http.StatusForbidden {
+		t.Errorf("Expected `%v`, got `%v`: %s", http.StatusForbidden, w.Code, w.Body.String())
+	}
+	if len(dbClient.collections[configmodels.UserAccountDataColl]) != 0 {
+		t.Errorf("Expected the first account not to be created by the single sign-on")
+	}
+}
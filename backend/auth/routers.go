@@ -18,9 +18,15 @@ type Route struct {
 
 type Routes []Route
 
-func AddAuthenticationService(engine *gin.Engine, jwtSecret []byte) {
+func AddAuthenticationService(engine *gin.Engine, authenticator Authenticator, jwtKeys *JWTKeys) {
 	group := engine.Group("/")
-	addRoutes(group, getAuthenticationRoutes(jwtSecret))
+	addRoutes(group, getAuthenticationRoutes(authenticator, jwtKeys))
+}
+
+// AddOIDCService adds the single sign-on with the OpenID Connect provider.
+func AddOIDCService(engine *gin.Engine, provider *OIDCProvider, jwtKeys *JWTKeys) {
+	group := engine.Group("/oidc")
+	addRoutes(group, getOIDCRoutes(provider, jwtKeys))
 }
 
 func addRoutes(group *gin.RouterGroup, routes Routes) {
@@ -38,13 +44,25 @@ func addRoutes(group *gin.RouterGroup, routes Routes) {
 	}
 }
 
-func getAuthenticationRoutes(jwtSecret []byte) Routes {
+func getAuthenticationRoutes(authenticator Authenticator, jwtKeys *JWTKeys) Routes {
 	return Routes{
 		{
 			"Login",
 			http.MethodPost,
 			"/login",
-			Login(jwtSecret),
+			Login(authenticator, jwtKeys),
+		},
+		{
+			"Refresh",
+			http.MethodPost,
+			"/refresh",
+			Refresh(jwtKeys),
+		},
+		{
+			"Logout",
+			http.MethodPost,
+			"/logout",
+			Logout(jwtKeys),
 		},
 		{
 			"Status",
@@ -52,5 +70,28 @@ func getAuthenticationRoutes(jwtSecret []byte) Routes {
 			"/status",
 			GetStatus(),
 		},
+		{
+			"GetJWKS",
+			http.MethodGet,
+			"/.well-known/jwks.json",
+			GetJWKS(jwtKeys),
+		},
+	}
+}
+
+func getOIDCRoutes(provider *OIDCProvider, jwtKeys *JWTKeys) Routes {
+	return Routes{
+		{
+			"OIDCLogin",
+			http.MethodGet,
+			"/login",
+			OIDCLogin(provider),
+		},
+		{
+			"OIDCCallback",
+			http.MethodGet,
+			"/callback",
+			OIDCCallback(provider, jwtKeys),
+		},
 	}
 }
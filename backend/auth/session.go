@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// refreshTokenPrefix tells refresh tokens apart from the other tokens.
+const refreshTokenPrefix = "wcr_"
+
+// maxUsedRefreshTokenHashes bounds the replaced refresh tokens a session
+// remembers, well above the refreshes of a client renewing its access token as
+// it expires. The older ones are still refused, without revoking the session.
+const maxUsedRefreshTokenHashes = 256
+
+var errInvalidRefreshToken = errors.New("refresh token is not valid")
+
+func generateRefreshToken() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := refreshTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, hashAPIToken(token), nil
+}
+
+// createSession starts a login session of the account and returns it with its
+// refresh token. The session can be refreshed until the refresh token lifetime
+// has passed, then the user has to log in again.
+func createSession(username string, now time.Time) (*configmodels.DBSession, string, error) {
+	refreshToken, refreshTokenHash, err := generateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	session := &configmodels.DBSession{
+		Id:               uuid.New().String(),
+		Username:         username,
+		RefreshTokenHash: refreshTokenHash,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(factory.WebUIConfig.Configuration.RefreshTokenLifetime),
+	}
+	document := bson.M{
+		"id":                 session.Id,
+		"username":           session.Username,
+		"refresh-token-hash": session.RefreshTokenHash,
+		"created-at":         session.CreatedAt,
+		"expires-at":         session.ExpiresAt,
+	}
+	err = dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.SessionDataColl, bson.M{"id": session.Id}, []interface{}{document})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to store session: %w", err)
+	}
+	return session, refreshToken, nil
+}
+
+// refreshSession replaces the refresh token of its session. A refresh token
+// can only be used once: presenting a replaced one means it leaked, so the
+// session is revoked.
+func refreshSession(refreshToken string, now time.Time) (*configmodels.DBSession, string, error) {
+	refreshTokenHash := hashAPIToken(refreshToken)
+	rawSession, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.SessionDataColl, bson.M{"refresh-token-hash": refreshTokenHash})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to retrieve session: %w", err)
+	}
+	if len(rawSession) == 0 {
+		revokeReusedSession(refreshTokenHash)
+		return nil, "", errInvalidRefreshToken
+	}
+	session, err := decodeSession(rawSession)
+	if err != nil {
+		return nil, "", err
+	}
+	if !now.Before(session.ExpiresAt) {
+		return nil, "", errInvalidRefreshToken
+	}
+	newRefreshToken, newRefreshTokenHash, err := generateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	usedRefreshTokenHashes := append(session.UsedRefreshTokenHashes, refreshTokenHash)
+	if len(usedRefreshTokenHashes) > maxUsedRefreshTokenHashes {
+		usedRefreshTokenHashes = usedRefreshTokenHashes[len(usedRefreshTokenHashes)-maxUsedRefreshTokenHashes:]
+	}
+	swapped, err := dbadapter.WebuiDBClient.RestfulAPICompareAndSwapWithContext(context.Background(), configmodels.SessionDataColl,
+		bson.M{"id": session.Id, "refresh-token-hash": refreshTokenHash},
+		bson.M{"refresh-token-hash": newRefreshTokenHash, "used-refresh-token-hashes": usedRefreshTokenHashes})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to refresh session: %w", err)
+	}
+	if !swapped {
+		// another request used the same refresh token in the meantime
+		logger.AuthLog.Warnf("refresh token of session %s of %s used twice, revoking the session", session.Id, session.Username)
+		if err = revokeSession(session.Id); err != nil {
+			logger.AuthLog.Errorln(err)
+		}
+		return nil, "", errInvalidRefreshToken
+	}
+	session.RefreshTokenHash = newRefreshTokenHash
+	session.UsedRefreshTokenHashes = usedRefreshTokenHashes
+	return session, newRefreshToken, nil
+}
+
+// revokeReusedSession revokes the session a replaced refresh token belonged to,
+// if any: either the user or whoever stole the token already refreshed with it.
+func revokeReusedSession(refreshTokenHash string) {
+	rawSession, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.SessionDataColl, bson.M{"used-refresh-token-hashes": refreshTokenHash})
+	if err != nil {
+		logger.AuthLog.Errorf("failed to retrieve session: %v", err)
+		return
+	}
+	if len(rawSession) == 0 {
+		return
+	}
+	session, err := decodeSession(rawSession)
+	if err != nil {
+		logger.AuthLog.Errorln(err)
+		return
+	}
+	logger.AuthLog.Warnf("replaced refresh token of session %s of %s used, revoking the session", session.Id, session.Username)
+	if err = revokeSession(session.Id); err != nil {
+		logger.AuthLog.Errorln(err)
+	}
+}
+
+// isSessionActive reports whether the session of an access token still exists,
+// that is it was neither revoked nor has it expired.
+func isSessionActive(sessionID string, now time.Time) (bool, error) {
+	rawSession, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.SessionDataColl, bson.M{"id": sessionID})
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve session: %w", err)
+	}
+	if len(rawSession) == 0 {
+		return false, nil
+	}
+	session, err := decodeSession(rawSession)
+	if err != nil {
+		return false, err
+	}
+	return now.Before(session.ExpiresAt), nil
+}
+
+func revokeSession(sessionID string) error {
+	if err := dbadapter.WebuiDBClient.RestfulAPIDeleteOne(configmodels.SessionDataColl, bson.M{"id": sessionID}); err != nil {
+		return fmt.Errorf("failed to revoke session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// RevokeSessions logs the account out everywhere: its access and refresh tokens
+// stop being accepted.
+func RevokeSessions(username string) error {
+	if err := dbadapter.WebuiDBClient.RestfulAPIDeleteMany(configmodels.SessionDataColl, bson.M{"username": username}); err != nil {
+		return fmt.Errorf("failed to revoke the sessions of %s: %w", username, err)
+	}
+	return nil
+}
+
+func decodeSession(rawSession map[string]interface{}) (*configmodels.DBSession, error) {
+	var session configmodels.DBSession
+	if err := json.Unmarshal(configmodels.MapToByte(rawSession), &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return &session, nil
+}
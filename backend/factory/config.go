@@ -15,6 +15,8 @@
 package factory
 
 import (
+	"time"
+
 	"github.com/omec-project/util/logger"
 )
 
@@ -31,16 +33,81 @@ type Info struct {
 }
 
 type Configuration struct {
-	Mongodb                 *Mongodb    `yaml:"mongodb"`
-	WebuiTLS                *TLS        `yaml:"webui-tls"`
-	NfConfigTLS             *TLS        `yaml:"nfconfig-tls"`
-	RocEnd                  *RocEndpt   `yaml:"managedByConfigPod,omitempty"` // fetch config during bootup
-	LteEnd                  []*LteEndpt `yaml:"endpoints,omitempty"`          // LTE endpoints are configured and not auto-detected
-	Mode5G                  bool        `yaml:"mode5G,omitempty"`
-	SdfComp                 bool        `yaml:"spec-compliant-sdf"`
-	EnableAuthentication    bool        `yaml:"enableAuthentication,omitempty"`
-	SendPebbleNotifications bool        `yaml:"send-pebble-notifications,omitempty"`
-	CfgPort                 int         `yaml:"cfgport,omitempty"`
+	Mongodb                 *Mongodb        `yaml:"mongodb"`
+	WebuiTLS                *TLS            `yaml:"webui-tls"`
+	NfConfigTLS             *TLS            `yaml:"nfconfig-tls"`
+	RocEnd                  *RocEndpt       `yaml:"managedByConfigPod,omitempty"` // fetch config during bootup
+	LteEnd                  []*LteEndpt     `yaml:"endpoints,omitempty"`          // LTE endpoints are configured and not auto-detected
+	Mode5G                  bool            `yaml:"mode5G,omitempty"`
+	SdfComp                 bool            `yaml:"spec-compliant-sdf"`
+	EnableAuthentication    bool            `yaml:"enableAuthentication,omitempty"`
+	SendPebbleNotifications bool            `yaml:"send-pebble-notifications,omitempty"`
+	Webhooks                []*Webhook      `yaml:"webhooks,omitempty"`
+	CfgPort                 int             `yaml:"cfgport,omitempty"`
+	IdempotencyKeyTTL       time.Duration   `yaml:"idempotency-key-ttl,omitempty"` // how long the result of a request with an Idempotency-Key is replayed
+	MaxImportSize           int64           `yaml:"max-import-size,omitempty"`     // the largest backup the import accepts, in bytes
+	AuditLogRetention       time.Duration   `yaml:"audit-log-retention,omitempty"` // how long the audit log entries are kept
+	JWT                     *JWT            `yaml:"jwt,omitempty"`
+	AccessTokenLifetime     time.Duration   `yaml:"access-token-lifetime,omitempty"`  // how long an access token is valid
+	RefreshTokenLifetime    time.Duration   `yaml:"refresh-token-lifetime,omitempty"` // how long a login session can be refreshed
+	OIDC                    *OIDC           `yaml:"oidc,omitempty"`
+	LDAP                    *LDAP           `yaml:"ldap,omitempty"`
+	LoginProtection         LoginProtection `yaml:"login-protection,omitempty"`
+}
+
+// LoginProtection limits the failed logins, against password guessing. An
+// account or a client IP address reaching its limit of failures within
+// FailureWindow cannot log in for LockoutDuration. A limit of 0 disables it.
+// The client IP address is the one of the connection, unless it comes from
+// one of the TrustedProxies.
+type LoginProtection struct {
+	MaxFailures      int           `yaml:"max-failures"`              // per account
+	MaxFailuresPerIP int           `yaml:"max-failures-per-ip"`       // per client IP address
+	FailureWindow    time.Duration `yaml:"failure-window"`            // how long a failure is counted
+	LockoutDuration  time.Duration `yaml:"lockout-duration"`          // how long the logins are refused
+	TrustedProxies   []string      `yaml:"trusted-proxies,omitempty"` // IPs or CIDRs whose X-Forwarded-For header gives the client IP address
+}
+
+// JWT configures the keys signing the authentication tokens. Without key files
+// the keys are generated and kept in the webui database, so that they survive
+// restarts and are shared by the replicas.
+type JWT struct {
+	Algorithm      string        `yaml:"algorithm,omitempty"`       // HS256 (default), RS256 or EdDSA
+	KeyFiles       []string      `yaml:"key-files,omitempty"`       // the first key signs, the others only verify tokens
+	RotationPeriod time.Duration `yaml:"rotation-period,omitempty"` // how often the keys of the webui database are replaced, never if unset
+}
+
+// OIDC configures the single sign-on with an OpenID Connect provider. The
+// accounts logging in with it are created on their first login, with the roles
+// RoleMapping gives to the values of their RoleClaim.
+type OIDC struct {
+	Issuer        string            `yaml:"issuer"`
+	ClientID      string            `yaml:"client-id"`
+	ClientSecret  string            `yaml:"client-secret,omitempty"`
+	RedirectURL   string            `yaml:"redirect-url"`             // the /oidc/callback URL of the webui, as registered with the provider
+	Scopes        []string          `yaml:"scopes,omitempty"`         // openid, profile and email if unset
+	UsernameClaim string            `yaml:"username-claim,omitempty"` // preferred_username if unset
+	RoleClaim     string            `yaml:"role-claim,omitempty"`     // groups if unset
+	RoleMapping   map[string]string `yaml:"role-mapping,omitempty"`   // role claim value to webui role, every account gets the user role if unset
+}
+
+// LDAP configures the login with an LDAP directory, such as Active Directory.
+// The entry of a user is either UserDNTemplate, or searched under BaseDN,
+// bound as BindDN if set. The user is then bound with their password, and gets
+// the roles RoleMapping gives to the groups of the entry.
+type LDAP struct {
+	URL             string            `yaml:"url"`                        // ldap://host:389 or ldaps://host:636
+	StartTLS        bool              `yaml:"start-tls,omitempty"`        // upgrade an ldap:// connection to TLS
+	CAFile          string            `yaml:"ca-file,omitempty"`          // the system CAs if unset
+	UserDNTemplate  string            `yaml:"user-dn-template,omitempty"` // uid={username},ou=people,dc=example,dc=com
+	BindDN          string            `yaml:"bind-dn,omitempty"`          // anonymous search if unset
+	BindPassword    string            `yaml:"bind-password,omitempty"`
+	BaseDN          string            `yaml:"base-dn,omitempty"`           // where users are searched without user-dn-template
+	UserAttribute   string            `yaml:"user-attribute,omitempty"`    // uid if unset, sAMAccountName for Active Directory
+	UserObjectClass string            `yaml:"user-object-class,omitempty"` // any if unset
+	GroupAttribute  string            `yaml:"group-attribute,omitempty"`   // memberOf if unset
+	RoleMapping     map[string]string `yaml:"role-mapping,omitempty"`      // group DN to webui role, every user gets the user role if unset
+	Timeout         time.Duration     `yaml:"timeout,omitempty"`           // 10s if unset
 }
 
 type TLS struct {
@@ -57,6 +124,15 @@ type Mongodb struct {
 	WebuiDBUrl     string `yaml:"webuiDbUrl,omitempty"`
 }
 
+// Webhook receives the configuration changes of the given kinds (all kinds if
+// none is given), signed with Secret.
+type Webhook struct {
+	Name   string   `yaml:"name"`
+	Url    string   `yaml:"url"`
+	Secret string   `yaml:"secret,omitempty"`
+	Kinds  []string `yaml:"kinds,omitempty"`
+}
+
 type RocEndpt struct {
 	SyncUrl string `yaml:"syncUrl,omitempty"`
 	Enabled bool   `yaml:"enabled,omitempty"`
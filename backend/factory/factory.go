@@ -14,10 +14,15 @@ package factory
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	utilLogger "github.com/omec-project/util/logger"
 	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
 	"github.com/urfave/cli/v3"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -27,7 +32,20 @@ import (
 var WebUIConfig *Config
 
 func init() {
-	WebUIConfig = &Config{Configuration: &Configuration{CfgPort: 5000}}
+	WebUIConfig = &Config{Configuration: &Configuration{
+		CfgPort:              5000,
+		IdempotencyKeyTTL:    24 * time.Hour,
+		MaxImportSize:        64 * 1024 * 1024,
+		AuditLogRetention:    90 * 24 * time.Hour,
+		AccessTokenLifetime:  time.Hour,
+		RefreshTokenLifetime: 7 * 24 * time.Hour,
+		LoginProtection: LoginProtection{
+			MaxFailures:      5,
+			MaxFailuresPerIP: 20,
+			FailureWindow:    15 * time.Minute,
+			LockoutDuration:  15 * time.Minute,
+		},
+	}}
 }
 
 func GetConfig() *Config {
@@ -54,6 +72,86 @@ func InitConfigFactory(f string) error {
 				return fmt.Errorf("[NFConfig Configuration] TLS Key and PEM must be set")
 			}
 		}
+		for _, webhook := range WebUIConfig.Configuration.Webhooks {
+			if webhook == nil || webhook.Name == "" || webhook.Url == "" {
+				return fmt.Errorf("[Configuration] webhook name and url must be set")
+			}
+		}
+		if WebUIConfig.Configuration.IdempotencyKeyTTL <= 0 {
+			return fmt.Errorf("[Configuration] idempotency-key-ttl must be positive")
+		}
+		if WebUIConfig.Configuration.MaxImportSize <= 0 {
+			return fmt.Errorf("[Configuration] max-import-size must be positive")
+		}
+		if WebUIConfig.Configuration.AuditLogRetention <= 0 {
+			return fmt.Errorf("[Configuration] audit-log-retention must be positive")
+		}
+		if WebUIConfig.Configuration.AccessTokenLifetime <= 0 || WebUIConfig.Configuration.RefreshTokenLifetime <= 0 {
+			return fmt.Errorf("[Configuration] access-token-lifetime and refresh-token-lifetime must be positive")
+		}
+		if loginProtection := WebUIConfig.Configuration.LoginProtection; loginProtection.MaxFailures < 0 || loginProtection.MaxFailuresPerIP < 0 ||
+			loginProtection.FailureWindow <= 0 || loginProtection.LockoutDuration <= 0 {
+			return fmt.Errorf("[Configuration] login-protection limits cannot be negative, failure-window and lockout-duration must be positive")
+		}
+		if jwtConfig := WebUIConfig.Configuration.JWT; jwtConfig != nil {
+			switch jwtConfig.Algorithm {
+			case "", "HS256", "RS256", "EdDSA":
+			default:
+				return fmt.Errorf("[Configuration] jwt algorithm must be HS256, RS256 or EdDSA")
+			}
+			if len(jwtConfig.KeyFiles) > 0 && jwtConfig.RotationPeriod != 0 {
+				return fmt.Errorf("[Configuration] jwt key-files are rotated by replacing them, rotation-period cannot be set")
+			}
+			if jwtConfig.RotationPeriod < 0 {
+				return fmt.Errorf("[Configuration] jwt rotation-period must be positive")
+			}
+		}
+		if oidcConfig := WebUIConfig.Configuration.OIDC; oidcConfig != nil {
+			if oidcConfig.Issuer == "" || oidcConfig.ClientID == "" || oidcConfig.RedirectURL == "" {
+				return fmt.Errorf("[Configuration] oidc issuer, client-id and redirect-url must be set")
+			}
+			if len(oidcConfig.Scopes) == 0 {
+				oidcConfig.Scopes = []string{"openid", "profile", "email"}
+			}
+			if !slices.Contains(oidcConfig.Scopes, "openid") {
+				return fmt.Errorf("[Configuration] oidc scopes must include openid")
+			}
+			if oidcConfig.UsernameClaim == "" {
+				oidcConfig.UsernameClaim = "preferred_username"
+			}
+			if oidcConfig.RoleClaim == "" {
+				oidcConfig.RoleClaim = "groups"
+			}
+			for _, role := range oidcConfig.RoleMapping {
+				if role == configmodels.RoleAdmin {
+					return fmt.Errorf("[Configuration] oidc role-mapping cannot give the admin role, it belongs to the admin account")
+				}
+			}
+		}
+		if ldapConfig := WebUIConfig.Configuration.LDAP; ldapConfig != nil {
+			ldapURL, err := url.Parse(ldapConfig.URL)
+			if err != nil || (ldapURL.Scheme != "ldap" && ldapURL.Scheme != "ldaps") || ldapURL.Host == "" {
+				return fmt.Errorf("[Configuration] ldap url must be an ldap:// or ldaps:// URL")
+			}
+			if ldapConfig.StartTLS && ldapURL.Scheme == "ldaps" {
+				return fmt.Errorf("[Configuration] ldap start-tls cannot be used with ldaps")
+			}
+			if ldapConfig.UserDNTemplate != "" && !strings.Contains(ldapConfig.UserDNTemplate, "{username}") {
+				return fmt.Errorf("[Configuration] ldap user-dn-template must contain {username}")
+			}
+			if ldapConfig.UserDNTemplate == "" && ldapConfig.BaseDN == "" {
+				return fmt.Errorf("[Configuration] ldap user-dn-template or base-dn must be set")
+			}
+			if ldapConfig.UserAttribute == "" {
+				ldapConfig.UserAttribute = "uid"
+			}
+			if ldapConfig.GroupAttribute == "" {
+				ldapConfig.GroupAttribute = "memberOf"
+			}
+			if ldapConfig.Timeout <= 0 {
+				ldapConfig.Timeout = 10 * time.Second
+			}
+		}
 		if WebUIConfig.Configuration.Mongodb.AuthUrl == "" {
 			authUrl := WebUIConfig.Configuration.Mongodb.Url
 			WebUIConfig.Configuration.Mongodb.AuthUrl = authUrl
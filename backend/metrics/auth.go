@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// The limit labels of the login protection: the failures of an account, or of
+// a client IP address.
+const (
+	LoginLimitAccount = "account"
+	LoginLimitIP      = "ip"
+)
+
+var (
+	// LoginFailures counts the logins refused for an incorrect username or
+	// password.
+	LoginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webconsole_login_failures_total",
+		Help: "Number of logins refused for an incorrect username or password",
+	})
+	// LoginLockouts counts the accounts and client IP addresses locked out for
+	// reaching their limit of failed logins.
+	LoginLockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webconsole_login_lockouts_total",
+		Help: "Number of lockouts of an account or a client IP address after too many failed logins",
+	}, []string{"limit"})
+	// LoginsRefusedLocked counts the logins refused during a lockout.
+	LoginsRefusedLocked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webconsole_logins_refused_locked_total",
+		Help: "Number of logins refused because the account or the client IP address is locked out",
+	}, []string{"limit"})
+)
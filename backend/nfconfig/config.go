@@ -9,12 +9,23 @@ import (
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/omec-project/openapi/nfConfigApi"
 	"github.com/omec-project/webconsole/backend/logger"
 	"github.com/omec-project/webconsole/configmodels"
 )
 
+const (
+	protocolTcp              = 6
+	protocolUdp              = 17
+	default5Qi               = 9
+	defaultArpPriorityLevel  = 1
+	maxArpPriorityLevel      = 15
+	defaultPccRuleId         = "DefaultRule"
+	defaultPccRulePrecedence = 255
+)
+
 type accessAndMobilityKey struct {
 	plmn    configmodels.SliceSiteInfoPlmn
 	sliceId configmodels.SliceSliceId
@@ -26,6 +37,19 @@ type inMemoryConfig struct {
 	accessAndMobility []nfConfigApi.AccessAndMobility
 	sessionManagement []nfConfigApi.SessionManagement
 	policyControl     []nfConfigApi.PolicyControl
+	// the inventory records are not part of the NF configuration API yet
+	upfInventory []configmodels.Upf
+	gnbInventory []configmodels.Gnb
+	sliceUpfs    []sliceUpfs
+}
+
+// sliceUpfs are the UPFs of a network slice, by order of preference. The
+// SessionManagement schema only holds the preferred one.
+type sliceUpfs struct {
+	SliceName string                          `json:"slice-name"`
+	Plmn      configmodels.SliceSiteInfoPlmn  `json:"plmn"`
+	SliceId   configmodels.SliceSliceId       `json:"slice-id"`
+	Upfs      []configmodels.SliceSiteInfoUpf `json:"upfs"`
 }
 
 func (c *inMemoryConfig) syncPlmn(slices []configmodels.Slice) {
@@ -255,33 +279,20 @@ func extractIpDomains(groupNames []string, deviceGroupMap map[string]configmodel
 	return ipDomains
 }
 
+// extractUpf returns the preferred UPF of the slice, as SessionManagement
+// holds a single UPF.
 func extractUpf(slice configmodels.Slice) *nfConfigApi.Upf {
-	upfMap := slice.SiteInfo.Upf
-	if upfMap == nil {
-		logger.NfConfigLog.Warnf("no UPF defined for slice %s", slice.SliceName)
-		return nil
-	}
-	hostnameRaw, ok := upfMap["upf-name"]
+	primary, ok := slice.SiteInfo.PrimaryUpf()
 	if !ok {
-		logger.NfConfigLog.Warnf("missing UPF hostname for slice %s", slice.SliceName)
-		return nil
-	}
-	hostname, ok := hostnameRaw.(string)
-	if !ok || hostname == "" {
-		logger.NfConfigLog.Warnf("invalid UPF hostname for slice %s: %v", slice.SliceName, hostnameRaw)
+		logger.NfConfigLog.Warnf("no UPF defined for slice %s", slice.SliceName)
 		return nil
 	}
-	upf := nfConfigApi.NewUpf(hostname)
-
-	if portRaw, ok := upfMap["upf-port"]; ok {
-		if portStr, ok := portRaw.(string); ok {
-			if port, err := strconv.ParseUint(portStr, 10, 16); err == nil {
-				upf.SetPort(int32(port))
-			} else {
-				logger.NfConfigLog.Warnf("invalid UPF port for slice %s: %+v", slice.SliceName, err)
-			}
+	upf := nfConfigApi.NewUpf(primary.UpfName)
+	if primary.UpfPort != "" {
+		if port, err := strconv.ParseUint(primary.UpfPort, 10, 16); err == nil {
+			upf.SetPort(int32(port))
 		} else {
-			logger.NfConfigLog.Warnf("UPF port should be a string for slice %s, got: %T", slice.SliceName, portRaw)
+			logger.NfConfigLog.Warnf("invalid UPF port for slice %s: %+v", slice.SliceName, err)
 		}
 	}
 	return upf
@@ -296,7 +307,253 @@ func extractGnbNames(slice configmodels.Slice) []string {
 	return names
 }
 
-func (c *inMemoryConfig) syncPolicyControl() {
-	c.policyControl = []nfConfigApi.PolicyControl{}
+func (c *inMemoryConfig) syncPolicyControl(networkSlices []configmodels.Slice, deviceGroupMap map[string]configmodels.DeviceGroups, sdfComp bool) {
+	policyControlMap := map[accessAndMobilityKey]*nfConfigApi.PolicyControl{}
+	for _, slice := range networkSlices {
+		key := accessAndMobilityKey{
+			plmn:    slice.SiteInfo.Plmn,
+			sliceId: slice.SliceId,
+		}
+		policyControl, ok := buildPolicyControlConfig(slice, deviceGroupMap, sdfComp)
+		if !ok {
+			continue
+		}
+		existing, found := policyControlMap[key]
+		if !found {
+			policyControlMap[key] = policyControl
+			continue
+		}
+		logger.NfConfigLog.Warnf("Found duplicate Network slice `%+v` for PLMN `%+v`, merging DNN QoS and PCC rules for Policy Control", slice.SliceId, slice.SiteInfo.Plmn)
+		existing.DnnQos = mergeDnnQos(existing.DnnQos, policyControl.DnnQos)
+		for _, rule := range policyControl.PccRules {
+			if !slices.ContainsFunc(existing.PccRules, func(r nfConfigApi.PccRule) bool { return r.RuleId == rule.RuleId }) {
+				existing.PccRules = append(existing.PccRules, rule)
+			}
+		}
+	}
+
+	policyControl := make([]nfConfigApi.PolicyControl, 0, len(policyControlMap))
+	for _, p := range policyControlMap {
+		policyControl = append(policyControl, *p)
+	}
+	sortPolicyControlConfig(policyControl)
+	c.policyControl = policyControl
 	logger.NfConfigLog.Debugf("Updated Policy Control in-memory configuration. New configuration: %+v", c.policyControl)
 }
+
+// buildPolicyControlConfig returns the policy control configuration of a
+// network slice, as sent to the PCF over gRPC by NetworkSliceSubscribe. As
+// there, a slice is left out while one of its device groups is missing.
+func buildPolicyControlConfig(slice configmodels.Slice, deviceGroupMap map[string]configmodels.DeviceGroups, sdfComp bool) (*nfConfigApi.PolicyControl, bool) {
+	plmn := nfConfigApi.NewPlmnId(slice.SiteInfo.Plmn.Mcc, slice.SiteInfo.Plmn.Mnc)
+	snssai, err := parseSnssaiFromSlice(slice.SliceId)
+	if err != nil {
+		logger.NfConfigLog.Errorf("Invalid SNSSAI for slice %s: %+v", slice.SliceName, err)
+		return nil, false
+	}
+
+	var dnnQos []nfConfigApi.DnnQos
+	// the traffic class of the first device group is the default QoS of the rules
+	var defaultTrafficClass *configmodels.TrafficClassInfo
+	for _, name := range slice.SiteDeviceGroup {
+		dg, exists := deviceGroupMap[name]
+		if !exists {
+			logger.NfConfigLog.Warnf("Device group %s of slice %s not found, skipping the slice", name, slice.SliceName)
+			return nil, false
+		}
+		ueDnnQos := dg.IpDomainExpanded.UeDnnQos
+		if ueDnnQos == nil {
+			continue
+		}
+		if defaultTrafficClass == nil && ueDnnQos.TrafficClass != nil {
+			defaultTrafficClass = ueDnnQos.TrafficClass
+		}
+		dnnQos = mergeDnnQos(dnnQos, []nfConfigApi.DnnQos{buildDnnQos(dg.IpDomainExpanded.Dnn, ueDnnQos)})
+	}
+
+	pccRules := make([]nfConfigApi.PccRule, 0, len(slice.ApplicationFilteringRules))
+	for _, rule := range slice.ApplicationFilteringRules {
+		pccRules = append(pccRules, buildPccRule(rule, defaultTrafficClass, sdfComp))
+	}
+	if len(pccRules) == 0 {
+		pccRules = append(pccRules, defaultPccRule())
+	}
+
+	policyControl := nfConfigApi.NewPolicyControl(*plmn, snssai, pccRules)
+	if len(dnnQos) > 0 {
+		policyControl.SetDnnQos(dnnQos)
+	}
+	return policyControl, true
+}
+
+func buildDnnQos(dnn string, ueDnnQos *configmodels.DeviceGroupsIpDomainExpandedUeDnnQos) nfConfigApi.DnnQos {
+	dnnQos := nfConfigApi.NewDnnQos(dnn, formatBitrate(ueDnnQos.DnnMbrUplink), formatBitrate(ueDnnQos.DnnMbrDownlink))
+	if ueDnnQos.TrafficClass != nil {
+		dnnQos.SetFiveQi(ueDnnQos.TrafficClass.Qci)
+		dnnQos.SetArpPriorityLevel(ueDnnQos.TrafficClass.Arp)
+	}
+	return *dnnQos
+}
+
+// mergeDnnQos adds the QoS of the DNNs not in dnnQos yet, keeping the list
+// sorted by DNN name.
+func mergeDnnQos(dnnQos []nfConfigApi.DnnQos, others []nfConfigApi.DnnQos) []nfConfigApi.DnnQos {
+	for _, other := range others {
+		if slices.ContainsFunc(dnnQos, func(q nfConfigApi.DnnQos) bool { return q.DnnName == other.DnnName }) {
+			logger.NfConfigLog.Warnf("Found duplicate DNN %s, keeping the first QoS", other.DnnName)
+			continue
+		}
+		dnnQos = append(dnnQos, other)
+	}
+	sort.Slice(dnnQos, func(i, j int) bool {
+		return dnnQos[i].DnnName < dnnQos[j].DnnName
+	})
+	return dnnQos
+}
+
+func buildPccRule(rule configmodels.SliceApplicationFilteringRules, defaultTrafficClass *configmodels.TrafficClassInfo, sdfComp bool) nfConfigApi.PccRule {
+	fiveQi, arpPriorityLevel := int32(default5Qi), int32(defaultArpPriorityLevel)
+	if rule.TrafficClass != nil {
+		fiveQi, arpPriorityLevel = rule.TrafficClass.Qci, rule.TrafficClass.Arp
+	} else if defaultTrafficClass != nil {
+		fiveQi, arpPriorityLevel = defaultTrafficClass.Qci, defaultTrafficClass.Arp
+	}
+	arpPriorityLevel = min(arpPriorityLevel, maxArpPriorityLevel)
+	qos := nfConfigApi.NewPccQos(
+		fiveQi,
+		formatBitrate(int64(rule.AppMbrUplink)),
+		formatBitrate(int64(rule.AppMbrDownlink)),
+		*nfConfigApi.NewArp(arpPriorityLevel, nfConfigApi.PREEMPTCAP_MAY_PREEMPT, nfConfigApi.PREEMPTVULN_PREEMPTABLE),
+	)
+
+	flow := nfConfigApi.NewPccFlow(buildFlowDescription(rule, sdfComp), nfConfigApi.DIRECTION_BIDIRECTIONAL)
+	if rule.Action == "deny" {
+		flow.SetStatus(nfConfigApi.STATUS_DISABLED)
+	} else {
+		flow.SetStatus(nfConfigApi.STATUS_ENABLED)
+	}
+	return *nfConfigApi.NewPccRule(rule.RuleName, []nfConfigApi.PccFlow{*flow}, *qos, rule.Priority)
+}
+
+// buildFlowDescription returns the IPFilterRule of an application filtering
+// rule. With sdfComp, the port range is given on the source as per 3GPP TS
+// 29.212.
+func buildFlowDescription(rule configmodels.SliceApplicationFilteringRules, sdfComp bool) string {
+	endpoint := rule.Endpoint
+	if strings.HasPrefix(endpoint, "0.0.0.0") {
+		endpoint = "any"
+	}
+	var protocol string
+	switch rule.Protocol {
+	case protocolTcp:
+		protocol = "tcp"
+	case protocolUdp:
+		protocol = "udp"
+	default:
+		return "permit out ip from " + endpoint + " to assigned"
+	}
+	if rule.StartPort == 0 && rule.EndPort == 0 {
+		return "permit out " + protocol + " from " + endpoint + " to assigned"
+	}
+	ports := strconv.Itoa(int(rule.StartPort)) + "-" + strconv.Itoa(int(rule.EndPort))
+	if sdfComp {
+		return "permit out " + protocol + " from " + endpoint + " " + ports + " to assigned"
+	}
+	return "permit out " + protocol + " from " + endpoint + " to assigned " + ports
+}
+
+// defaultPccRule allows all the traffic of a network slice without
+// application filtering rules.
+func defaultPccRule() nfConfigApi.PccRule {
+	qos := nfConfigApi.NewPccQos(
+		default5Qi,
+		formatBitrate(0),
+		formatBitrate(0),
+		*nfConfigApi.NewArp(defaultArpPriorityLevel, nfConfigApi.PREEMPTCAP_MAY_PREEMPT, nfConfigApi.PREEMPTVULN_PREEMPTABLE),
+	)
+	flow := nfConfigApi.NewPccFlow("permit out ip from any to assigned", nfConfigApi.DIRECTION_BIDIRECTIONAL)
+	flow.SetStatus(nfConfigApi.STATUS_ENABLED)
+	return *nfConfigApi.NewPccRule(defaultPccRuleId, []nfConfigApi.PccFlow{*flow}, *qos, defaultPccRulePrecedence)
+}
+
+// formatBitrate returns a bitrate in bps as a string in the largest unit
+// that represents it exactly, e.g. "10 Mbps".
+func formatBitrate(bps int64) string {
+	units := []struct {
+		name  string
+		value int64
+	}{
+		{"Gbps", 1000000000},
+		{"Mbps", 1000000},
+		{"Kbps", 1000},
+	}
+	for _, unit := range units {
+		if bps != 0 && bps%unit.value == 0 {
+			return strconv.FormatInt(bps/unit.value, 10) + " " + unit.name
+		}
+	}
+	return strconv.FormatInt(bps, 10) + " bps"
+}
+
+func sortPolicyControlConfig(policyControl []nfConfigApi.PolicyControl) {
+	sort.Slice(policyControl, func(i, j int) bool {
+		if policyControl[i].PlmnId.GetMcc() != policyControl[j].PlmnId.GetMcc() {
+			return policyControl[i].PlmnId.GetMcc() < policyControl[j].PlmnId.GetMcc()
+		}
+		if policyControl[i].PlmnId.GetMnc() != policyControl[j].PlmnId.GetMnc() {
+			return policyControl[i].PlmnId.GetMnc() < policyControl[j].PlmnId.GetMnc()
+		}
+		if policyControl[i].Snssai.GetSst() != policyControl[j].Snssai.GetSst() {
+			return policyControl[i].Snssai.GetSst() < policyControl[j].Snssai.GetSst()
+		}
+		if policyControl[i].Snssai.HasSd() != policyControl[j].Snssai.HasSd() {
+			return !policyControl[i].Snssai.HasSd()
+		}
+		return policyControl[i].Snssai.GetSd() < policyControl[j].Snssai.GetSd()
+	})
+}
+
+func (c *inMemoryConfig) syncUpfInventory(upfs []configmodels.Upf) {
+	upfInventory := slices.Clone(upfs)
+	if upfInventory == nil {
+		upfInventory = []configmodels.Upf{}
+	}
+	sort.Slice(upfInventory, func(i, j int) bool {
+		return upfInventory[i].Hostname < upfInventory[j].Hostname
+	})
+	c.upfInventory = upfInventory
+	logger.NfConfigLog.Debugf("Updated UPF inventory in-memory configuration. New configuration: %+v", c.upfInventory)
+}
+
+func (c *inMemoryConfig) syncGnbInventory(gnbs []configmodels.Gnb) {
+	gnbInventory := slices.Clone(gnbs)
+	if gnbInventory == nil {
+		gnbInventory = []configmodels.Gnb{}
+	}
+	sort.Slice(gnbInventory, func(i, j int) bool {
+		return gnbInventory[i].Name < gnbInventory[j].Name
+	})
+	c.gnbInventory = gnbInventory
+	logger.NfConfigLog.Debugf("Updated gNB inventory in-memory configuration. New configuration: %+v", c.gnbInventory)
+}
+
+func (c *inMemoryConfig) syncSliceUpfs(networkSlices []configmodels.Slice) {
+	newSliceUpfs := []sliceUpfs{}
+	for _, slice := range networkSlices {
+		upfs := slice.SiteInfo.UpfList()
+		if len(upfs) == 0 {
+			continue
+		}
+		newSliceUpfs = append(newSliceUpfs, sliceUpfs{
+			SliceName: slice.SliceName,
+			Plmn:      slice.SiteInfo.Plmn,
+			SliceId:   slice.SliceId,
+			Upfs:      upfs,
+		})
+	}
+	sort.Slice(newSliceUpfs, func(i, j int) bool {
+		return newSliceUpfs[i].SliceName < newSliceUpfs[j].SliceName
+	})
+	c.sliceUpfs = newSliceUpfs
+	logger.NfConfigLog.Debugf("Updated slice UPFs in-memory configuration. New configuration: %+v", c.sliceUpfs)
+}
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2025 Canonical Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package nfconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockInventoryDBClient struct {
+	dbadapter.DBInterface
+	collections map[string][]any
+}
+
+func (m *MockInventoryDBClient) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]any, error) {
+	results := []map[string]any{}
+	for _, record := range m.collections[coll] {
+		results = append(results, configmodels.ToBsonM(record))
+	}
+	return results, nil
+}
+
+func TestSyncInMemoryConfig_Inventory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tac := int32(1)
+	gnbId := int64(4096)
+	upf1 := configmodels.Upf{
+		Hostname: "upf1.my-domain.com",
+		Port:     "8805",
+		UpfInventory: configmodels.UpfInventory{
+			N3Address: "10.0.0.1",
+			N4Address: "10.0.1.1",
+			Dnns:      []string{"internet"},
+			Snssais:   []configmodels.SliceSliceId{{Sst: "1", Sd: "010203"}},
+			Capacity:  10,
+		},
+	}
+	upf0 := configmodels.Upf{Hostname: "upf0.my-domain.com", Port: "8805"}
+	gnb := configmodels.Gnb{
+		Name: "gnb1",
+		Tac:  &tac,
+		GnbInventory: configmodels.GnbInventory{
+			GnbId:     &gnbId,
+			Plmn:      &configmodels.SliceSiteInfoPlmn{Mcc: "001", Mnc: "01"},
+			N2Address: "192.168.0.10",
+			SiteName:  "site-1",
+			Location:  &configmodels.GnbLocation{Latitude: 45.5, Longitude: -73.6},
+		},
+	}
+	slice := makeNetworkSlice("001", "01", "1", "010203", []int32{1})
+	slice.SliceName = "slice1"
+	slice.SiteInfo.SetUpfs([]configmodels.SliceSiteInfoUpf{
+		{UpfName: "upf0.my-domain.com", UpfPort: "8805", Priority: 2, Weight: 10},
+		{UpfName: "upf1.my-domain.com", UpfPort: "8805", Priority: 1, Weight: 30, Dnns: []string{"internet"}},
+	})
+	sliceWithoutUpf := makeNetworkSlice("001", "01", "2", "010203", []int32{1})
+	sliceWithoutUpf.SliceName = "slice2"
+	mockDB := &MockInventoryDBClient{
+		collections: map[string][]any{
+			configmodels.UpfDataColl: {upf1, upf0, configmodels.Upf{Port: "8805"}},
+			configmodels.GnbDataColl: {gnb},
+			sliceDataColl:            {sliceWithoutUpf, slice},
+		},
+	}
+	originalDBClient := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = originalDBClient }()
+	dbadapter.CommonDBClient = mockDB
+
+	n := &NFConfigServer{Router: gin.New()}
+	n.setupRoutes()
+	if err := n.syncInMemoryConfig(); err != nil {
+		t.Fatalf("failed to sync in-memory config: %v", err)
+	}
+
+	expectedUpfs := []configmodels.Upf{upf0, upf1}
+	if !reflect.DeepEqual(expectedUpfs, n.inMemoryConfig.upfInventory) {
+		t.Errorf("expected UPF inventory %+v, got %+v", expectedUpfs, n.inMemoryConfig.upfInventory)
+	}
+	expectedGnbs := []configmodels.Gnb{gnb}
+	if !reflect.DeepEqual(expectedGnbs, n.inMemoryConfig.gnbInventory) {
+		t.Errorf("expected gNB inventory %+v, got %+v", expectedGnbs, n.inMemoryConfig.gnbInventory)
+	}
+
+	expectedSliceUpfs := []sliceUpfs{{
+		SliceName: "slice1",
+		Plmn:      configmodels.SliceSiteInfoPlmn{Mcc: "001", Mnc: "01"},
+		SliceId:   configmodels.SliceSliceId{Sst: "1", Sd: "010203"},
+		Upfs: []configmodels.SliceSiteInfoUpf{
+			{UpfName: "upf1.my-domain.com", UpfPort: "8805", Priority: 1, Weight: 30, Dnns: []string{"internet"}},
+			{UpfName: "upf0.my-domain.com", UpfPort: "8805", Priority: 2, Weight: 10},
+		},
+	}}
+	if !reflect.DeepEqual(expectedSliceUpfs, n.inMemoryConfig.sliceUpfs) {
+		t.Errorf("expected slice UPFs %+v, got %+v", expectedSliceUpfs, n.inMemoryConfig.sliceUpfs)
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected any
+	}{
+		{
+			name:     "UPF inventory",
+			path:     "/nfconfig/inventory/upf",
+			expected: expectedUpfs,
+		},
+		{
+			name:     "slice UPF inventory",
+			path:     "/nfconfig/inventory/slice-upfs",
+			expected: expectedSliceUpfs,
+		},
+		{
+			name:     "gNB inventory",
+			path:     "/nfconfig/inventory/gnb",
+			expected: expectedGnbs,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := getConfig(n, tc.path, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+			}
+			expectedBody, err := json.Marshal(tc.expected)
+			if err != nil {
+				t.Fatalf("failed to marshal expected body: %v", err)
+			}
+			if w.Body.String() != string(expectedBody) {
+				t.Errorf("expected body %s, got %s", expectedBody, w.Body.String())
+			}
+		})
+	}
+}
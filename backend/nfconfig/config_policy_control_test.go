@@ -0,0 +1,247 @@
+// SPDX-FileCopyrightText: 2025 Canonical Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package nfconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/omec-project/openapi/nfConfigApi"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+func makeDefaultPolicyControl(mcc, mnc string, sst int32, sd string) nfConfigApi.PolicyControl {
+	return nfConfigApi.PolicyControl{
+		PlmnId:   *nfConfigApi.NewPlmnId(mcc, mnc),
+		Snssai:   makeSnssaiWithSd(sst, sd),
+		PccRules: []nfConfigApi.PccRule{defaultPccRule()},
+	}
+}
+
+func makePccRule(ruleId string, precedence int32, description string, status nfConfigApi.Status, fiveQi int32, arp int32, maxBrUl string, maxBrDl string) nfConfigApi.PccRule {
+	return nfConfigApi.PccRule{
+		RuleId: ruleId,
+		Flows: []nfConfigApi.PccFlow{
+			{
+				Description: description,
+				Direction:   nfConfigApi.DIRECTION_BIDIRECTIONAL,
+				Status:      ptr(status),
+			},
+		},
+		Qos: nfConfigApi.PccQos{
+			FiveQi:  fiveQi,
+			MaxBrUl: maxBrUl,
+			MaxBrDl: maxBrDl,
+			Arp: nfConfigApi.Arp{
+				PriorityLevel: arp,
+				PreemptCap:    nfConfigApi.PREEMPTCAP_MAY_PREEMPT,
+				PreemptVuln:   nfConfigApi.PREEMPTVULN_PREEMPTABLE,
+			},
+		},
+		Precedence: precedence,
+	}
+}
+
+func makeQosDeviceGroup(name, dnn string, mbrUplink, mbrDownlink int64, trafficClass *configmodels.TrafficClassInfo) configmodels.DeviceGroups {
+	return configmodels.DeviceGroups{
+		DeviceGroupName: name,
+		IpDomainExpanded: configmodels.DeviceGroupsIpDomainExpanded{
+			Dnn: dnn,
+			UeDnnQos: &configmodels.DeviceGroupsIpDomainExpandedUeDnnQos{
+				DnnMbrUplink:   mbrUplink,
+				DnnMbrDownlink: mbrDownlink,
+				TrafficClass:   trafficClass,
+			},
+		},
+	}
+}
+
+func TestSyncPolicyControl(t *testing.T) {
+	platinum := &configmodels.TrafficClassInfo{Name: "platinum", Qci: 8, Arp: 6}
+	tests := []struct {
+		name         string
+		slices       []configmodels.Slice
+		rules        [][]configmodels.SliceApplicationFilteringRules
+		deviceGroups map[string]configmodels.DeviceGroups
+		sdfComp      bool
+		expected     []nfConfigApi.PolicyControl
+	}{
+		{
+			name: "slice without rules gets the default rule",
+			slices: []configmodels.Slice{
+				prepareNetworkSlice(networkSliceParams{sliceName: "slice-1", mcc: "001", mnc: "01", sst: "1", sd: "010203"}),
+			},
+			expected: []nfConfigApi.PolicyControl{
+				makeDefaultPolicyControl("001", "01", 1, "010203"),
+			},
+		},
+		{
+			name: "rules with their own traffic class and the device group one",
+			slices: []configmodels.Slice{
+				prepareNetworkSlice(networkSliceParams{sliceName: "slice-1", mcc: "001", mnc: "01", sst: "1", sd: "010203", deviceGroups: []string{"dg-1", "dg-2"}}),
+			},
+			rules: [][]configmodels.SliceApplicationFilteringRules{
+				{
+					{
+						RuleName:       "rule-1",
+						Priority:       10,
+						Action:         "permit",
+						Endpoint:       "1.1.1.1/32",
+						Protocol:       protocolTcp,
+						StartPort:      80,
+						EndPort:        443,
+						AppMbrUplink:   2000000,
+						AppMbrDownlink: 1500,
+						TrafficClass:   &configmodels.TrafficClassInfo{Qci: 7, Arp: 20},
+					},
+					{
+						RuleName: "rule-2",
+						Priority: 20,
+						Action:   "deny",
+						Endpoint: "0.0.0.0/0",
+						Protocol: protocolUdp,
+					},
+				},
+			},
+			deviceGroups: map[string]configmodels.DeviceGroups{
+				"dg-1": makeQosDeviceGroup("dg-1", "internet", 10000000, 20000000, platinum),
+				"dg-2": makeQosDeviceGroup("dg-2", "ims", 1000, 1000, nil),
+			},
+			expected: []nfConfigApi.PolicyControl{
+				{
+					PlmnId: *nfConfigApi.NewPlmnId("001", "01"),
+					Snssai: makeSnssaiWithSd(1, "010203"),
+					DnnQos: []nfConfigApi.DnnQos{
+						{DnnName: "ims", MbrUplink: "1 Kbps", MbrDownlink: "1 Kbps"},
+						{DnnName: "internet", MbrUplink: "10 Mbps", MbrDownlink: "20 Mbps", FiveQi: ptr(int32(8)), ArpPriorityLevel: ptr(int32(6))},
+					},
+					PccRules: []nfConfigApi.PccRule{
+						makePccRule("rule-1", 10, "permit out tcp from 1.1.1.1/32 to assigned 80-443", nfConfigApi.STATUS_ENABLED, 7, 15, "2 Mbps", "1500 bps"),
+						makePccRule("rule-2", 20, "permit out udp from any to assigned", nfConfigApi.STATUS_DISABLED, 8, 6, "0 bps", "0 bps"),
+					},
+				},
+			},
+		},
+		{
+			name: "spec compliant SDF and no traffic class",
+			slices: []configmodels.Slice{
+				prepareNetworkSlice(networkSliceParams{sliceName: "slice-1", mcc: "001", mnc: "01", sst: "1", sd: "010203"}),
+			},
+			rules: [][]configmodels.SliceApplicationFilteringRules{
+				{
+					{RuleName: "rule-1", Priority: 1, Endpoint: "8.8.8.8/32", Protocol: protocolUdp, StartPort: 53, EndPort: 53},
+					{RuleName: "rule-2", Priority: 2, Endpoint: "8.8.4.4/32", Protocol: 1},
+				},
+			},
+			sdfComp: true,
+			expected: []nfConfigApi.PolicyControl{
+				{
+					PlmnId: *nfConfigApi.NewPlmnId("001", "01"),
+					Snssai: makeSnssaiWithSd(1, "010203"),
+					PccRules: []nfConfigApi.PccRule{
+						makePccRule("rule-1", 1, "permit out udp from 8.8.8.8/32 53-53 to assigned", nfConfigApi.STATUS_ENABLED, 9, 1, "0 bps", "0 bps"),
+						makePccRule("rule-2", 2, "permit out ip from 8.8.4.4/32 to assigned", nfConfigApi.STATUS_ENABLED, 9, 1, "0 bps", "0 bps"),
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate slices are merged and sorted",
+			slices: []configmodels.Slice{
+				prepareNetworkSlice(networkSliceParams{sliceName: "slice-2", mcc: "002", mnc: "01", sst: "1", sd: "010203"}),
+				prepareNetworkSlice(networkSliceParams{sliceName: "slice-1", mcc: "001", mnc: "01", sst: "1", sd: "010203", deviceGroups: []string{"dg-1"}}),
+				prepareNetworkSlice(networkSliceParams{sliceName: "slice-1-bis", mcc: "001", mnc: "01", sst: "1", sd: "010203", deviceGroups: []string{"dg-2"}}),
+			},
+			deviceGroups: map[string]configmodels.DeviceGroups{
+				"dg-1": makeQosDeviceGroup("dg-1", "internet", 1000000, 1000000, nil),
+				"dg-2": makeQosDeviceGroup("dg-2", "internet", 5000000, 5000000, nil),
+			},
+			expected: []nfConfigApi.PolicyControl{
+				{
+					PlmnId:   *nfConfigApi.NewPlmnId("001", "01"),
+					Snssai:   makeSnssaiWithSd(1, "010203"),
+					DnnQos:   []nfConfigApi.DnnQos{{DnnName: "internet", MbrUplink: "1 Mbps", MbrDownlink: "1 Mbps"}},
+					PccRules: []nfConfigApi.PccRule{defaultPccRule()},
+				},
+				makeDefaultPolicyControl("002", "01", 1, "010203"),
+			},
+		},
+		{
+			name: "invalid SST",
+			slices: []configmodels.Slice{
+				prepareNetworkSlice(networkSliceParams{sliceName: "bad-slice", mcc: "001", mnc: "01", sst: "", sd: "010203"}),
+			},
+			expected: []nfConfigApi.PolicyControl{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, rules := range tc.rules {
+				tc.slices[i].ApplicationFilteringRules = rules
+			}
+			c := inMemoryConfig{}
+			c.syncPolicyControl(tc.slices, tc.deviceGroups, tc.sdfComp)
+			if !reflect.DeepEqual(tc.expected, c.policyControl) {
+				t.Errorf("expected Policy Control %+v, got %+v", tc.expected, c.policyControl)
+			}
+		})
+	}
+}
+
+// TestBuildPolicyControlConfig_MissingDeviceGroup checks that a slice gets a
+// policy control configuration in the same cases as fillSlice sends it to the
+// PCF over gRPC, which skips the slices with a missing device group.
+func TestBuildPolicyControlConfig_MissingDeviceGroup(t *testing.T) {
+	deviceGroups := map[string]configmodels.DeviceGroups{
+		"dg-1": makeQosDeviceGroup("dg-1", "internet", 1000000, 1000000, nil),
+		"dg-2": makeQosDeviceGroup("dg-2", "ims", 1000, 1000, nil),
+	}
+	tests := []struct {
+		name         string
+		deviceGroups []string
+		expected     bool
+	}{
+		{name: "no device group", expected: true},
+		{name: "all device groups found", deviceGroups: []string{"dg-1", "dg-2"}, expected: true},
+		{name: "first device group missing", deviceGroups: []string{"missing-dg", "dg-1"}, expected: false},
+		{name: "last device group missing", deviceGroups: []string{"dg-1", "missing-dg"}, expected: false},
+		{name: "only device group missing", deviceGroups: []string{"missing-dg"}, expected: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slice := prepareNetworkSlice(networkSliceParams{sliceName: "slice-1", mcc: "001", mnc: "01", sst: "1", sd: "010203", deviceGroups: tc.deviceGroups})
+			policyControl, ok := buildPolicyControlConfig(slice, deviceGroups, false)
+			if ok != tc.expected || (policyControl != nil) != tc.expected {
+				t.Errorf("expected a policy control configuration %v, got %+v", tc.expected, policyControl)
+			}
+			c := inMemoryConfig{}
+			c.syncPolicyControl([]configmodels.Slice{slice}, deviceGroups, false)
+			if (len(c.policyControl) == 1) != tc.expected {
+				t.Errorf("expected the slice in the policy control configuration %v, got %+v", tc.expected, c.policyControl)
+			}
+		})
+	}
+}
+
+func TestFormatBitrate(t *testing.T) {
+	tests := []struct {
+		bps      int64
+		expected string
+	}{
+		{0, "0 bps"},
+		{999, "999 bps"},
+		{1000, "1 Kbps"},
+		{1500, "1500 bps"},
+		{2500000, "2500 Kbps"},
+		{10000000, "10 Mbps"},
+		{3000000000, "3 Gbps"},
+	}
+	for _, tc := range tests {
+		if got := formatBitrate(tc.bps); got != tc.expected {
+			t.Errorf("formatBitrate(%d): expected %q, got %q", tc.bps, tc.expected, got)
+		}
+	}
+}
@@ -22,6 +22,7 @@ type networkSliceParams struct {
 	deviceGroups []string
 	upfHostname  any
 	upfPort      string
+	upfs         []configmodels.SliceSiteInfoUpf
 	gnbNames     []string
 }
 
@@ -56,6 +57,7 @@ func prepareNetworkSlice(p networkSliceParams) configmodels.Slice {
 			},
 			GNodeBs: gnbs,
 			Upf:     upf,
+			Upfs:    p.upfs,
 		},
 	}
 }
@@ -457,6 +459,40 @@ func TestSyncSessionManagement(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "UPF list uses the preferred UPF",
+			sliceParams: []networkSliceParams{
+				{
+					sliceName:   "slice-5",
+					mcc:         "001",
+					mnc:         "01",
+					sst:         "1",
+					sd:          "010203",
+					upfHostname: "upf-legacy.local",
+					upfs: []configmodels.SliceSiteInfoUpf{
+						{UpfName: "upf-a.local", UpfPort: "8805", Priority: 1, Weight: 70},
+						{UpfName: "upf-b.local", Priority: 2, Weight: 30},
+					},
+				},
+			},
+			expectedResponse: []nfConfigApi.SessionManagement{
+				{
+					SliceName: "slice-5",
+					PlmnId: nfConfigApi.PlmnId{
+						Mcc: "001",
+						Mnc: "01",
+					},
+					Snssai: nfConfigApi.Snssai{
+						Sst: 1,
+						Sd:  sharedSd,
+					},
+					Upf: &nfConfigApi.Upf{
+						Hostname: "upf-a.local",
+						Port:     ptr(int32(8805)),
+					},
+				},
+			},
+		},
 		{
 			name: "empty device group list",
 			sliceParams: []networkSliceParams{
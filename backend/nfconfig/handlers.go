@@ -6,33 +6,155 @@
 package nfconfig
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/backend/problemdetails"
+	"github.com/omec-project/webconsole/configmodels"
 )
 
+const configRevisionHeader = "X-Config-Revision"
+
+// watchTimeout is how long a watch request waits for a new revision before
+// answering 304 Not Modified.
+var watchTimeout = 30 * time.Second
+
 func (n *NFConfigServer) GetAccessMobilityConfig(c *gin.Context) {
-	logger.NfConfigLog.Debugf("Handling GET request for access-mobility config %+v", n.inMemoryConfig.accessAndMobility)
-	c.JSON(http.StatusOK, n.inMemoryConfig.accessAndMobility)
+	n.serveConfig(c, "access-mobility", func(config *inMemoryConfig) any { return config.accessAndMobility })
 }
 
 func (n *NFConfigServer) GetPlmnConfig(c *gin.Context) {
-	logger.NfConfigLog.Debugf("Handling GET request for plmn config %+v", n.inMemoryConfig.plmn)
-	c.JSON(http.StatusOK, n.inMemoryConfig.plmn)
+	n.serveConfig(c, "plmn", func(config *inMemoryConfig) any { return config.plmn })
 }
 
 func (n *NFConfigServer) GetPlmnSnssaiConfig(c *gin.Context) {
-	logger.NfConfigLog.Debugf("Handling GET request for plmn-snssai config %+v", n.inMemoryConfig.plmnSnssai)
-	c.JSON(http.StatusOK, n.inMemoryConfig.plmnSnssai)
+	n.serveConfig(c, "plmn-snssai", func(config *inMemoryConfig) any { return config.plmnSnssai })
 }
 
 func (n *NFConfigServer) GetPolicyControlConfig(c *gin.Context) {
-	logger.NfConfigLog.Debugf("Handling GET request for policy-control config %+v", n.inMemoryConfig.policyControl)
-	c.JSON(http.StatusOK, n.inMemoryConfig.policyControl)
+	n.serveConfig(c, "policy-control", func(config *inMemoryConfig) any { return config.policyControl })
 }
 
 func (n *NFConfigServer) GetSessionManagementConfig(c *gin.Context) {
-	logger.NfConfigLog.Debugf("Handling GET request for session-management config %+v", n.inMemoryConfig.sessionManagement)
-	c.JSON(http.StatusOK, n.inMemoryConfig.sessionManagement)
+	n.serveConfig(c, "session-management", func(config *inMemoryConfig) any { return config.sessionManagement })
+}
+
+// GetGnbInventory returns the gNB inventory records, which carry the metadata
+// that AccessAndMobility has no fields for.
+func (n *NFConfigServer) GetGnbInventory(c *gin.Context) {
+	n.serveConfig(c, "gNB inventory", func(config *inMemoryConfig) any { return config.gnbInventory })
+}
+
+// GetUpfInventory returns the UPF inventory records, with the addresses, DNNs,
+// S-NSSAIs and capacity used for the UPF selection, which SessionManagement
+// has no fields for.
+func (n *NFConfigServer) GetUpfInventory(c *gin.Context) {
+	n.serveConfig(c, "UPF inventory", func(config *inMemoryConfig) any { return config.upfInventory })
+}
+
+// GetSliceUpfInventory returns every UPF of the network slices, by order of
+// preference, with the priority, weight and DNNs of the UPF selection.
+// SessionManagement only holds the preferred UPF of a slice.
+func (n *NFConfigServer) GetSliceUpfInventory(c *gin.Context) {
+	n.serveConfig(c, "slice UPF inventory", func(config *inMemoryConfig) any { return config.sliceUpfs })
+}
+
+// serveConfig writes a section of the in-memory configuration with its ETag and
+// revision. A request with an If-None-Match header matching the ETag gets a 304
+// Not Modified. With ?watch=true&revision=N, the request blocks until the
+// revision moves from N, or answers 304 Not Modified after watchTimeout.
+func (n *NFConfigServer) serveConfig(c *gin.Context, name string, section func(*inMemoryConfig) any) {
+	watch, revision, err := n.parseWatchQuery(c)
+	if err != nil {
+		logger.NfConfigLog.Warnf("Invalid watch request for %s config: %v", name, err)
+		problemdetails.Write(c, configmodels.ProblemDetails{
+			Status: http.StatusBadRequest,
+			Code:   configmodels.ProblemCodeInvalidRequest,
+			Detail: err.Error(),
+		})
+		return
+	}
+	if watch && !n.waitForRevision(c.Request.Context(), revision, watchTimeout) {
+		current, _ := n.currentRevision()
+		c.Header(configRevisionHeader, strconv.FormatInt(current, 10))
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	n.configMutex.RLock()
+	config := section(&n.inMemoryConfig)
+	current := n.revision
+	n.configMutex.RUnlock()
+	logger.NfConfigLog.Debugf("Handling GET request for %s config %+v", name, config)
+
+	body, err := json.Marshal(config)
+	if err != nil {
+		logger.NfConfigLog.Errorf("Failed to marshal %s config: %v", name, err)
+		problemdetails.Write(c, configmodels.ProblemDetails{
+			Status: http.StatusInternalServerError,
+			Code:   configmodels.ProblemCodeInternalError,
+			Detail: "failed to marshal configuration",
+		})
+		return
+	}
+	etag := configETag(body)
+	c.Header("ETag", etag)
+	c.Header(configRevisionHeader, strconv.FormatInt(current, 10))
+	if !watch && etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// parseWatchQuery returns whether the request is a watch and the revision it
+// waits to move from. Without a revision, a watch waits for the next one.
+func (n *NFConfigServer) parseWatchQuery(c *gin.Context) (bool, int64, error) {
+	watchParam := c.Query("watch")
+	if watchParam == "" {
+		return false, 0, nil
+	}
+	watch, err := strconv.ParseBool(watchParam)
+	if err != nil {
+		return false, 0, errors.New("watch must be true or false")
+	}
+	if !watch {
+		return false, 0, nil
+	}
+	revisionParam := c.Query("revision")
+	if revisionParam == "" {
+		revision, _ := n.currentRevision()
+		return true, revision, nil
+	}
+	revision, err := strconv.ParseInt(revisionParam, 10, 64)
+	if err != nil || revision < 0 {
+		return false, 0, errors.New("revision must be a non-negative integer")
+	}
+	return true, revision, nil
+}
+
+// configETag returns a strong ETag computed from the response body, so that
+// a section keeps its ETag while other sections change.
+func configETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2025 Canonical Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+package nfconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+)
+
+func newTestNFConfigServer(t *testing.T, slices []configmodels.Slice) (*NFConfigServer, *MockDBClient) {
+	gin.SetMode(gin.TestMode)
+	mockDB := &MockDBClient{Slices: slices}
+	originalDBClient := dbadapter.CommonDBClient
+	t.Cleanup(func() { dbadapter.CommonDBClient = originalDBClient })
+	dbadapter.CommonDBClient = mockDB
+	n := &NFConfigServer{Router: gin.New()}
+	n.setupRoutes()
+	if err := n.syncInMemoryConfig(); err != nil {
+		t.Fatalf("failed to sync in-memory config: %v", err)
+	}
+	return n, mockDB
+}
+
+func getConfig(n *NFConfigServer, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	n.router().ServeHTTP(w, req)
+	return w
+}
+
+func TestSyncInMemoryConfig_Revision(t *testing.T) {
+	n, mockDB := newTestNFConfigServer(t, []configmodels.Slice{makeNetworkSlice("001", "01", "1", "010203", []int32{1})})
+	if revision, _ := n.currentRevision(); revision != 1 {
+		t.Fatalf("expected revision 1 after the first sync, got %d", revision)
+	}
+
+	if err := n.syncInMemoryConfig(); err != nil {
+		t.Fatalf("failed to sync in-memory config: %v", err)
+	}
+	if revision, _ := n.currentRevision(); revision != 1 {
+		t.Errorf("expected revision to stay 1 when the configuration is unchanged, got %d", revision)
+	}
+
+	mockDB.Slices = append(mockDB.Slices, makeNetworkSlice("001", "01", "2", "010203", []int32{2}))
+	if err := n.syncInMemoryConfig(); err != nil {
+		t.Fatalf("failed to sync in-memory config: %v", err)
+	}
+	if revision, _ := n.currentRevision(); revision != 2 {
+		t.Errorf("expected revision 2 when the configuration changed, got %d", revision)
+	}
+}
+
+func TestNFConfigETag(t *testing.T) {
+	n, _ := newTestNFConfigServer(t, []configmodels.Slice{makeNetworkSlice("001", "01", "1", "010203", []int32{1})})
+	first := getConfig(n, "/nfconfig/plmn", nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, first.Code)
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected an ETag header")
+	}
+	if revision := first.Header().Get(configRevisionHeader); revision != "1" {
+		t.Errorf("expected %s 1, got %q", configRevisionHeader, revision)
+	}
+
+	testCases := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{
+			name:        "matching ETag",
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "matching weak ETag in a list",
+			ifNoneMatch: `"other", W/` + etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "wildcard",
+			ifNoneMatch: "*",
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "stale ETag",
+			ifNoneMatch: `"stale"`,
+			wantStatus:  http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := getConfig(n, "/nfconfig/plmn", map[string]string{"If-None-Match": tc.ifNoneMatch})
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected %d, got %d", tc.wantStatus, w.Code)
+			}
+			if w.Header().Get("ETag") != etag {
+				t.Errorf("expected ETag %s, got %s", etag, w.Header().Get("ETag"))
+			}
+			if tc.wantStatus == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("expected empty body, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNFConfigWatch(t *testing.T) {
+	originalWatchTimeout := watchTimeout
+	defer func() { watchTimeout = originalWatchTimeout }()
+	watchTimeout = 50 * time.Millisecond
+
+	n, _ := newTestNFConfigServer(t, []configmodels.Slice{makeNetworkSlice("001", "01", "1", "010203", []int32{1})})
+	testCases := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantRevision string
+	}{
+		{
+			name:         "revision behind returns immediately",
+			path:         "/nfconfig/plmn?watch=true&revision=0",
+			wantStatus:   http.StatusOK,
+			wantRevision: "1",
+		},
+		{
+			name:         "revision ahead from before a restart returns immediately",
+			path:         "/nfconfig/plmn?watch=true&revision=42",
+			wantStatus:   http.StatusOK,
+			wantRevision: "1",
+		},
+		{
+			name:         "current revision times out",
+			path:         "/nfconfig/plmn?watch=true&revision=1",
+			wantStatus:   http.StatusNotModified,
+			wantRevision: "1",
+		},
+		{
+			name:         "no revision waits for the next one",
+			path:         "/nfconfig/session-management?watch=true",
+			wantStatus:   http.StatusNotModified,
+			wantRevision: "1",
+		},
+		{
+			name:         "watch disabled",
+			path:         "/nfconfig/plmn?watch=false&revision=1",
+			wantStatus:   http.StatusOK,
+			wantRevision: "1",
+		},
+		{
+			name:       "invalid revision",
+			path:       "/nfconfig/plmn?watch=true&revision=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid watch",
+			path:       "/nfconfig/plmn?watch=yes",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := getConfig(n, tc.path, nil)
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected %d, got %d", tc.wantStatus, w.Code)
+			}
+			if revision := w.Header().Get(configRevisionHeader); revision != tc.wantRevision {
+				t.Errorf("expected %s %q, got %q", configRevisionHeader, tc.wantRevision, revision)
+			}
+			if tc.wantStatus == http.StatusBadRequest {
+				var problem configmodels.ProblemDetails
+				if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != configmodels.ProblemCodeInvalidRequest {
+					t.Errorf("expected an invalid-request problem, got %s", w.Body.String())
+				}
+				if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/problem+json") {
+					t.Errorf("expected a problem content type, got %s", contentType)
+				}
+			}
+		})
+	}
+}
+
+func TestNFConfigWatch_WakesUpOnSync(t *testing.T) {
+	originalWatchTimeout := watchTimeout
+	defer func() { watchTimeout = originalWatchTimeout }()
+	watchTimeout = 5 * time.Second
+
+	n, mockDB := newTestNFConfigServer(t, []configmodels.Slice{makeNetworkSlice("001", "01", "1", "010203", []int32{1})})
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- getConfig(n, "/nfconfig/plmn?watch=true&revision=1", nil)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("watch returned before the configuration changed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	mockDB.Slices = []configmodels.Slice{makeNetworkSlice("002", "02", "1", "010203", []int32{1})}
+	if err := n.syncInMemoryConfig(); err != nil {
+		t.Fatalf("failed to sync in-memory config: %v", err)
+	}
+
+	select {
+	case w := <-done:
+		if w.Code != http.StatusOK {
+			t.Errorf("expected %d, got %d", http.StatusOK, w.Code)
+		}
+		if revision := w.Header().Get(configRevisionHeader); revision != "2" {
+			t.Errorf("expected %s 2, got %q", configRevisionHeader, revision)
+		}
+		expectedBody := `[{"mcc":"002","mnc":"02"}]`
+		if w.Body.String() != expectedBody {
+			t.Errorf("expected body %s, got %s", expectedBody, w.Body.String())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("watch did not return within a second of the sync")
+	}
+}
+
+func TestNFConfigWatch_AheadOfRestartedServer(t *testing.T) {
+	originalWatchTimeout := watchTimeout
+	defer func() { watchTimeout = originalWatchTimeout }()
+	watchTimeout = 50 * time.Millisecond
+
+	// startServer syncs a new server from its boot revision, then syncs every
+	// one of changes, as a webconsole started and configured from scratch
+	startServer := func(changes ...[]configmodels.Slice) *NFConfigServer {
+		mockDB := &MockDBClient{}
+		originalDBClient := dbadapter.CommonDBClient
+		t.Cleanup(func() { dbadapter.CommonDBClient = originalDBClient })
+		dbadapter.CommonDBClient = mockDB
+		n := &NFConfigServer{Router: gin.New(), revision: bootRevision()}
+		n.setupRoutes()
+		for _, slices := range changes {
+			mockDB.Slices = slices
+			if err := n.syncInMemoryConfig(); err != nil {
+				t.Fatalf("failed to sync in-memory config: %v", err)
+			}
+		}
+		return n
+	}
+	before := startServer(
+		[]configmodels.Slice{makeNetworkSlice("001", "01", "1", "010203", []int32{1})},
+		[]configmodels.Slice{makeNetworkSlice("002", "02", "1", "010203", []int32{1})},
+	)
+	seen := getConfig(before, "/nfconfig/plmn", nil).Header().Get(configRevisionHeader)
+
+	// the restarted server goes through as many changes, to another configuration
+	time.Sleep(time.Millisecond)
+	restarted := startServer(
+		[]configmodels.Slice{makeNetworkSlice("001", "01", "1", "010203", []int32{1})},
+		[]configmodels.Slice{makeNetworkSlice("003", "03", "1", "010203", []int32{1})},
+	)
+	w := getConfig(restarted, "/nfconfig/plmn?watch=true&revision="+seen, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+	if revision := w.Header().Get(configRevisionHeader); revision == seen {
+		t.Errorf("expected the restarted server to answer another revision than %s", seen)
+	}
+	expectedBody := `[{"mcc":"003","mnc":"03"}]`
+	if w.Body.String() != expectedBody {
+		t.Errorf("expected body %s, got %s", expectedBody, w.Body.String())
+	}
+}
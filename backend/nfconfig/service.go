@@ -10,12 +10,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omec-project/webconsole/backend/factory"
 	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configapi"
 	"github.com/omec-project/webconsole/configmodels"
 	"github.com/omec-project/webconsole/dbadapter"
 	"go.mongodb.org/mongo-driver/bson"
@@ -27,6 +29,13 @@ type NFConfigServer struct {
 	Router         *gin.Engine
 	inMemoryConfig inMemoryConfig
 	syncMutex      sync.Mutex
+	// configMutex guards inMemoryConfig, revision and revisionChanged.
+	configMutex sync.RWMutex
+	// revision is incremented every time a sync changes inMemoryConfig. It
+	// starts from bootRevision.
+	revision int64
+	// revisionChanged is closed and replaced when the revision moves.
+	revisionChanged chan struct{}
 }
 
 const (
@@ -40,7 +49,7 @@ type Route struct {
 }
 
 type NFConfigInterface interface {
-	Start(ctx context.Context, syncChan <-chan struct{}) error
+	Start(ctx context.Context, syncChan <-chan string) error
 }
 
 func (n *NFConfigServer) router() *gin.Engine {
@@ -57,11 +66,14 @@ func NewNFConfigServer(config *factory.Config) (NFConfigInterface, error) {
 		router.Use(gin.Logger())
 	}
 	router.Use(gin.Recovery())
+	// the problem details of the NF configuration API carry the request ID too
+	router.Use(configapi.RequestIDMiddleware())
 	router.Use(enforceAcceptJSON())
 
 	nfconfigServer := &NFConfigServer{
-		config: config.Configuration,
-		Router: router,
+		config:   config.Configuration,
+		Router:   router,
+		revision: bootRevision(),
 	}
 
 	if err := nfconfigServer.syncInMemoryConfig(); err != nil {
@@ -73,7 +85,7 @@ func NewNFConfigServer(config *factory.Config) (NFConfigInterface, error) {
 	return nfconfigServer, nil
 }
 
-func (n *NFConfigServer) Start(ctx context.Context, syncChan <-chan struct{}) error {
+func (n *NFConfigServer) Start(ctx context.Context, syncChan <-chan string) error {
 	n.startSyncWorker(ctx, syncChan)
 	addr := ":5001"
 	srv := &http.Server{
@@ -102,7 +114,7 @@ func (n *NFConfigServer) Start(ctx context.Context, syncChan <-chan struct{}) er
 	}
 }
 
-func (n *NFConfigServer) startSyncWorker(ctx context.Context, syncChan <-chan struct{}) {
+func (n *NFConfigServer) startSyncWorker(ctx context.Context, syncChan <-chan string) {
 	go func() {
 		var currentCancel context.CancelFunc
 
@@ -114,15 +126,15 @@ func (n *NFConfigServer) startSyncWorker(ctx context.Context, syncChan <-chan st
 				}
 				return
 
-			case <-syncChan:
+			case requestID := <-syncChan:
 				// Cancel current sync if running
 				if currentCancel != nil {
-					logger.NfConfigLog.Infoln("Cancelling ongoing sync due to new trigger")
+					logger.WithRequestID(logger.NfConfigLog, requestID).Infoln("Cancelling ongoing sync due to new trigger")
 					currentCancel()
 				}
 
 				var syncCtx context.Context
-				syncCtx, currentCancel = context.WithCancel(context.Background())
+				syncCtx, currentCancel = context.WithCancel(logger.ContextWithRequestID(context.Background(), requestID))
 				go n.syncWithRetry(syncCtx)
 			}
 		}
@@ -132,19 +144,22 @@ func (n *NFConfigServer) startSyncWorker(ctx context.Context, syncChan <-chan st
 func (n *NFConfigServer) syncWithRetry(ctx context.Context) {
 	n.syncMutex.Lock()
 	defer n.syncMutex.Unlock()
-	logger.NfConfigLog.Debugln("Starting in-memory NF configuration synchronization with new context")
+	// the request ID correlates the sync with the API call that triggered it
+	log := logger.WithRequestID(logger.NfConfigLog, logger.RequestIDFromContext(ctx))
+	log.Debugln("Starting in-memory NF configuration synchronization with new context")
 	interval := 0 * time.Second
 	for {
 		select {
 		case <-ctx.Done():
-			logger.NfConfigLog.Infoln("No-op. Sync in-memory configuration was cancelled")
+			log.Infoln("No-op. Sync in-memory configuration was cancelled")
 			return
 		case <-time.After(interval):
 			err := syncInMemoryConfigFunc(n)
 			if err == nil {
+				log.Debugln("In-memory NF configuration synchronized")
 				return
 			}
-			logger.NfConfigLog.Warnf("Sync in-memory configuration failed, retrying: %v", err)
+			log.Warnf("Sync in-memory configuration failed, retrying: %v", err)
 			interval = 3 * time.Second
 		}
 	}
@@ -191,15 +206,111 @@ func (n *NFConfigServer) syncInMemoryConfig() error {
 	}
 	logger.NfConfigLog.Debugf("Parsed %d device groups", len(deviceGroups))
 
-	n.inMemoryConfig.syncPlmn(slices)
-	n.inMemoryConfig.syncPlmnSnssai(slices)
-	n.inMemoryConfig.syncAccessAndMobility(slices)
-	n.inMemoryConfig.syncSessionManagement(slices, deviceGroups)
-	n.inMemoryConfig.syncPolicyControl()
-	logger.NfConfigLog.Infoln("Updated NF in-memory configuration")
+	upfs, err := fetchInventory(configmodels.UpfDataColl, func(upf configmodels.Upf) string { return upf.Hostname })
+	if err != nil {
+		return fmt.Errorf("failed to fetch UPFs: %w", err)
+	}
+	gnbs, err := fetchInventory(configmodels.GnbDataColl, func(gnb configmodels.Gnb) string { return gnb.Name })
+	if err != nil {
+		return fmt.Errorf("failed to fetch gNBs: %w", err)
+	}
+
+	config := inMemoryConfig{}
+	config.syncPlmn(slices)
+	config.syncPlmnSnssai(slices)
+	config.syncAccessAndMobility(slices)
+	config.syncSessionManagement(slices, deviceGroups)
+	config.syncPolicyControl(slices, deviceGroups, n.config != nil && n.config.SdfComp)
+	config.syncUpfInventory(upfs)
+	config.syncGnbInventory(gnbs)
+	config.syncSliceUpfs(slices)
+	revision := n.setInMemoryConfig(config)
+	logger.NfConfigLog.Infof("Updated NF in-memory configuration. Revision: %d", revision)
 	return nil
 }
 
+// fetchInventory returns the inventory records of a collection, skipping the
+// ones without a name.
+func fetchInventory[T any](collection string, name func(T) string) ([]T, error) {
+	rawRecords, err := dbadapter.CommonDBClient.RestfulAPIGetMany(collection, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	records := make([]T, 0, len(rawRecords))
+	for _, rawRecord := range rawRecords {
+		var record T
+		if err = json.Unmarshal(configmodels.MapToByte(rawRecord), &record); err != nil {
+			logger.NfConfigLog.Warnf("Failed to unmarshal inventory record: raw=%+v, error=%v", rawRecord, err)
+			continue
+		}
+		if name(record) == "" {
+			logger.NfConfigLog.Warnf("Skipping inventory record: %+v with empty name", record)
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// bootRevision returns the revision the in-memory configuration starts from,
+// the boot time in microseconds. The revisions are not persisted, so they keep
+// increasing across restarts this way, and a watcher coming back with a
+// revision from before a restart never waits on the same revision again.
+func bootRevision() int64 {
+	return time.Now().UnixMicro()
+}
+
+// setInMemoryConfig replaces the in-memory configuration and returns its
+// revision. The revision only moves, waking up the watchers, when the
+// configuration is different from the previous one.
+func (n *NFConfigServer) setInMemoryConfig(config inMemoryConfig) int64 {
+	n.configMutex.Lock()
+	defer n.configMutex.Unlock()
+	if n.revision > 0 && reflect.DeepEqual(n.inMemoryConfig, config) {
+		return n.revision
+	}
+	n.inMemoryConfig = config
+	n.revision++
+	if n.revisionChanged != nil {
+		close(n.revisionChanged)
+	}
+	n.revisionChanged = make(chan struct{})
+	return n.revision
+}
+
+// currentRevision returns the revision of the in-memory configuration and a
+// channel closed when it moves.
+func (n *NFConfigServer) currentRevision() (int64, <-chan struct{}) {
+	n.configMutex.Lock()
+	defer n.configMutex.Unlock()
+	if n.revisionChanged == nil {
+		n.revisionChanged = make(chan struct{})
+	}
+	return n.revision, n.revisionChanged
+}
+
+// waitForRevision blocks until the revision of the in-memory configuration
+// differs from revision, the timeout expires or ctx is done. It returns whether
+// the revision differs. A revision ahead of the current one, seen before a
+// restart with the clock set back, differs at once too.
+func (n *NFConfigServer) waitForRevision(ctx context.Context, revision int64, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		current, changed := n.currentRevision()
+		if current != revision {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
 func (n *NFConfigServer) setupRoutes() {
 	api := n.Router.Group("/nfconfig")
 	for _, route := range n.getRoutes() {
@@ -213,6 +324,18 @@ func (n *NFConfigServer) getRoutes() []Route {
 			Pattern:     "/access-mobility",
 			HandlerFunc: n.GetAccessMobilityConfig,
 		},
+		{
+			Pattern:     "/inventory/gnb",
+			HandlerFunc: n.GetGnbInventory,
+		},
+		{
+			Pattern:     "/inventory/upf",
+			HandlerFunc: n.GetUpfInventory,
+		},
+		{
+			Pattern:     "/inventory/slice-upfs",
+			HandlerFunc: n.GetSliceUpfInventory,
+		},
 		{
 			Pattern:     "/plmn",
 			HandlerFunc: n.GetPlmnConfig,
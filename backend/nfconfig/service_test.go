@@ -363,7 +363,7 @@ func TestNFConfigStart(t *testing.T) {
 			defer cancel()
 
 			errChan := make(chan error, 1)
-			syncChan := make(chan struct{}, 1)
+			syncChan := make(chan string, 1)
 			go func() {
 				t.Logf("starting server")
 				err := nfconf.Start(ctx, syncChan)
@@ -404,7 +404,7 @@ func TestNFConfig_Start_ServerError(t *testing.T) {
 	defer cancel1()
 
 	errChan := make(chan error, 1)
-	syncChan := make(chan struct{}, 1)
+	syncChan := make(chan string, 1)
 	go func() {
 		errChan <- nfc1.Start(ctx1, syncChan)
 	}()
@@ -427,7 +427,7 @@ func TestNFConfig_Start_ContextCancellation(t *testing.T) {
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	errChan := make(chan error)
-	syncChan := make(chan struct{}, 1)
+	syncChan := make(chan string, 1)
 	go func() {
 		errChan <- nfc.Start(ctx, syncChan)
 	}()
@@ -537,7 +537,10 @@ func TestSyncInMemoryConfig_UpdateAllConfigs(t *testing.T) {
 					GnbNames:  []string{"test-gnb-1"},
 				},
 			},
-			expectedPolicyControl: []nfConfigApi.PolicyControl{},
+			expectedPolicyControl: []nfConfigApi.PolicyControl{
+				makeDefaultPolicyControl("123", "23", 1, "01234"),
+				makeDefaultPolicyControl("123", "23", 2, "abcd"),
+			},
 		},
 		{
 			name:                      "Empty slices",
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+// Package problemdetails writes the RFC 7807 problem details responses of the
+// configuration and NF configuration APIs.
+package problemdetails
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+const ContentType = "application/problem+json"
+
+// Write fills the generic members of problem and writes it.
+func Write(c *gin.Context, problem configmodels.ProblemDetails) {
+	Complete(c, &problem)
+	c.Header("Content-Type", ContentType)
+	c.JSON(problem.Status, problem)
+}
+
+// Complete fills the members a handler does not set itself. The request ID
+// is the one given to the request, if any.
+func Complete(c *gin.Context, problem *configmodels.ProblemDetails) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if c.Request == nil {
+		return
+	}
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.Path
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = logger.RequestIDFromContext(c.Request.Context())
+	}
+}
+
+// Code returns the default error code of a status.
+func Code(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return configmodels.ProblemCodeInvalidRequest
+	case http.StatusUnauthorized:
+		return configmodels.ProblemCodeUnauthorized
+	case http.StatusForbidden:
+		return configmodels.ProblemCodeForbidden
+	case http.StatusNotFound:
+		return configmodels.ProblemCodeNotFound
+	case http.StatusConflict:
+		return configmodels.ProblemCodeAlreadyExists
+	case http.StatusPreconditionFailed:
+		return configmodels.ProblemCodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return configmodels.ProblemCodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return configmodels.ProblemCodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return configmodels.ProblemCodeValidationFailed
+	default:
+		return configmodels.ProblemCodeInternalError
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package problemdetails
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+func TestWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name      string
+		requestID string
+		problem   configmodels.ProblemDetails
+		expected  configmodels.ProblemDetails
+	}{
+		{
+			name:    "generic members filled",
+			problem: configmodels.ProblemDetails{Status: http.StatusNotFound, Code: configmodels.ProblemCodeNotFound, Detail: "slice not found"},
+			expected: configmodels.ProblemDetails{
+				Type:     "about:blank",
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "slice not found",
+				Instance: "/config/v1/network-slice/slice1",
+				Code:     configmodels.ProblemCodeNotFound,
+			},
+		},
+		{
+			name:      "request ID of the request",
+			requestID: "req-1",
+			problem:   configmodels.ProblemDetails{Status: http.StatusBadRequest, Code: configmodels.ProblemCodeInvalidRequest},
+			expected: configmodels.ProblemDetails{
+				Type:      "about:blank",
+				Title:     "Bad Request",
+				Status:    http.StatusBadRequest,
+				Instance:  "/config/v1/network-slice/slice1",
+				Code:      configmodels.ProblemCodeInvalidRequest,
+				RequestID: "req-1",
+			},
+		},
+		{
+			name:      "request ID set by the handler",
+			requestID: "req-1",
+			problem:   configmodels.ProblemDetails{Status: http.StatusConflict, Code: configmodels.ProblemCodeAlreadyExists, RequestID: "req-2"},
+			expected: configmodels.ProblemDetails{
+				Type:      "about:blank",
+				Title:     "Conflict",
+				Status:    http.StatusConflict,
+				Instance:  "/config/v1/network-slice/slice1",
+				Code:      configmodels.ProblemCodeAlreadyExists,
+				RequestID: "req-2",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/config/v1/network-slice/slice1", nil)
+			if tc.requestID != "" {
+				c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), tc.requestID))
+			}
+
+			Write(c, tc.problem)
+
+			if w.Code != tc.expected.Status {
+				t.Errorf("expected status %d, got %d", tc.expected.Status, w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != ContentType {
+				t.Errorf("expected Content-Type %s, got %s", ContentType, contentType)
+			}
+			var problem configmodels.ProblemDetails
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to decode the problem: %v", err)
+			}
+			if !reflect.DeepEqual(problem, tc.expected) {
+				t.Errorf("expected problem %+v, got %+v", tc.expected, problem)
+			}
+		})
+	}
+}
@@ -36,11 +36,14 @@ func AddUiService(engine *gin.Engine) {
 }
 
 func isApiUrlPath(path string, method string) bool {
-	if path == "/login" && method == http.MethodPost {
+	if (path == "/login" || path == "/refresh" || path == "/logout") && method == http.MethodPost {
 		return true
 	}
 	if path == "/status" && method == http.MethodGet {
 		return true
 	}
-	return strings.HasPrefix(path, "/config/v1/") || strings.HasPrefix(path, "/api/")
+	if path == "/.well-known/jwks.json" && method == http.MethodGet {
+		return true
+	}
+	return strings.HasPrefix(path, "/config/v1/") || strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/oidc/")
 }
@@ -32,30 +32,61 @@ import (
 type WEBUI struct{}
 
 type WebUIInterface interface {
-	Start(ctx context.Context, syncChan chan<- struct{})
+	Start(ctx context.Context, syncChan chan<- string)
 }
 
-func setupAuthenticationFeature(subconfig_router *gin.Engine, nfSyncMiddelware gin.HandlerFunc) {
-	jwtSecret, err := auth.GenerateJWTSecret()
+func setupAuthenticationFeature(ctx context.Context, subconfig_router *gin.Engine, nfSyncMiddelware gin.HandlerFunc) {
+	jwtKeys, err := auth.LoadJWTKeys(factory.WebUIConfig.Configuration.JWT)
 	if err != nil {
 		logger.InitLog.Error(err)
 		return
 	}
-	configapi.AddUserAccountService(subconfig_router, jwtSecret)
-	auth.AddAuthenticationService(subconfig_router, jwtSecret)
-	authMiddleware := auth.AdminOrUserAuthMiddleware(jwtSecret)
-	configapi.AddApiService(subconfig_router, authMiddleware)
-	configapi.AddConfigV1Service(subconfig_router, nfSyncMiddelware, authMiddleware)
+	jwtKeys.StartRefresh(ctx)
+	authenticator, err := auth.NewAuthenticator(factory.WebUIConfig.Configuration.LDAP)
+	if err != nil {
+		logger.InitLog.Error(err)
+		return
+	}
+	// the failed logins are counted per client IP address, which must not be
+	// spoofed with an X-Forwarded-For header
+	if err = subconfig_router.SetTrustedProxies(factory.WebUIConfig.Configuration.LoginProtection.TrustedProxies); err != nil {
+		logger.InitLog.Error(err)
+		return
+	}
+	configapi.AddUserAccountService(subconfig_router, jwtKeys)
+	configapi.AddAuditLogService(subconfig_router, jwtKeys)
+	configapi.AddWebhookService(subconfig_router, jwtKeys)
+	auth.AddAuthenticationService(subconfig_router, authenticator, jwtKeys)
+	if oidcConfig := factory.WebUIConfig.Configuration.OIDC; oidcConfig != nil {
+		auth.AddOIDCService(subconfig_router, auth.NewOIDCProvider(oidcConfig), jwtKeys)
+	}
+	authMiddleware := auth.AdminOrUserAuthMiddleware(jwtKeys)
+	// the routes of the API and configuration services are authorized by the
+	// roles of the account, the others by their own auth wrapper
+	roleMiddleware := configapi.RoleAuthorizationMiddleware()
+	// only the authenticated requests are audited, including the ones refused
+	// for their role
+	auditLogMiddleware := configapi.AuditLogMiddleware()
+	// idempotency keys are scoped to the user, so they are checked after authentication
+	idempotencyMiddleware := configapi.IdempotencyMiddleware()
+	configapi.AddApiService(subconfig_router, authMiddleware, auditLogMiddleware, roleMiddleware, idempotencyMiddleware)
+	configapi.AddConfigV1Service(subconfig_router, nfSyncMiddelware, authMiddleware, auditLogMiddleware, roleMiddleware, idempotencyMiddleware)
 }
 
-func (webui *WEBUI) Start(ctx context.Context, syncChan chan<- struct{}) {
+func (webui *WEBUI) Start(ctx context.Context, syncChan chan<- string) {
 	subconfig_router := utilLogger.NewGinWithZap(logger.GinLog)
 	nFConfigSyncMiddleware := triggerNFConfigSyncMiddleware(syncChan)
+	// the request ID wraps every route, so it must be registered before them
+	subconfig_router.Use(configapi.RequestIDMiddleware())
 	if factory.WebUIConfig.Configuration.EnableAuthentication {
-		setupAuthenticationFeature(subconfig_router, nFConfigSyncMiddleware)
+		setupAuthenticationFeature(ctx, subconfig_router, nFConfigSyncMiddleware)
 	} else {
-		configapi.AddApiService(subconfig_router)
-		configapi.AddConfigV1Service(subconfig_router, nFConfigSyncMiddleware)
+		configapi.AddAuditLogService(subconfig_router, nil)
+		configapi.AddWebhookService(subconfig_router, nil)
+		auditLogMiddleware := configapi.AuditLogMiddleware()
+		idempotencyMiddleware := configapi.IdempotencyMiddleware()
+		configapi.AddApiService(subconfig_router, auditLogMiddleware, idempotencyMiddleware)
+		configapi.AddConfigV1Service(subconfig_router, nFConfigSyncMiddleware, auditLogMiddleware, idempotencyMiddleware)
 	}
 	AddSwaggerUiService(subconfig_router)
 	AddUiService(subconfig_router)
@@ -64,14 +95,18 @@ func (webui *WEBUI) Start(ctx context.Context, syncChan chan<- struct{}) {
 
 	configMsgChan := make(chan *configmodels.ConfigMessage, 10)
 	configapi.SetChannel(configMsgChan)
+	if err := configapi.MigrateNetworkSliceUpfs(); err != nil {
+		logger.InitLog.Errorf("failed to migrate the UPFs of the network slices: %v", err)
+	}
+	configapi.StartWebhookDispatcher(ctx)
 
 	subconfig_router.Use(cors.New(cors.Config{
 		AllowMethods: []string{"GET", "POST", "OPTIONS", "PUT", "PATCH", "DELETE"},
 		AllowHeaders: []string{
 			"Origin", "Content-Length", "Content-Type", "User-Agent",
-			"Referrer", "Host", "Token", "X-Requested-With",
+			"Referrer", "Host", "Token", "X-Requested-With", "If-Match", "Idempotency-Key", logger.RequestIDHeader,
 		},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "Idempotent-Replayed", logger.RequestIDHeader},
 		AllowCredentials: true,
 		AllowAllOrigins:  true,
 		MaxAge:           86400,
@@ -173,12 +208,13 @@ func fetchConfigAdapater() {
 	}
 }
 
-func triggerNFConfigSyncMiddleware(syncChan chan<- struct{}) gin.HandlerFunc {
+func triggerNFConfigSyncMiddleware(syncChan chan<- string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 		if isWritingMethod(c.Request.Method) && isStatusSuccess(c.Writer.Status()) {
-			syncChan <- struct{}{}
-			logger.WebUILog.Infoln("NF config sync triggered via middleware")
+			requestID := logger.RequestIDFromContext(c.Request.Context())
+			syncChan <- requestID
+			logger.WithRequestID(logger.WebUILog, requestID).Infoln("NF config sync triggered via middleware")
 		} else {
 			logger.WebUILog.Debugln("WebUI operation does not require NF configuration synchronization")
 		}
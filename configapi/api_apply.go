@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/backend/problemdetails"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+// ApplyDesiredState godoc
+//
+// @Description  Apply a desired-state document describing all gNBs, UPFs, device groups and network slices. The document is compared with the stored configuration and the resulting creates, updates and deletes are applied in dependency order in one transaction. Objects missing from the document are deleted
+// @Tags         Apply
+// @Accept       json,application/yaml
+// @Produce      json
+// @Param        dryRun     query   bool                         false   "Only return the changes without applying them"
+// @Param        content    body    configmodels.DesiredState    true    " "
+// @Security     BearerAuth
+// @Success      200  {object}  configmodels.ApplyResult  "Changes computed or applied"
+// @Failure      400  {object}  nil                       "Invalid desired state"
+// @Failure      401  {object}  nil                       "Authorization failed"
+// @Failure      403  {object}  nil                       "Forbidden"
+// @Failure      422  {object}  nil                       "Desired state is inconsistent"
+// @Failure      500  {object}  nil                       "Error applying the desired state"
+// @Router       /config/v1/apply  [post]
+func ApplyDesiredState(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a POST apply request")
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "invalid dryRun parameter", requestID)
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "failed to read request body", requestID)
+		return
+	}
+	contentType := strings.TrimSpace(strings.Split(c.GetHeader("Content-Type"), ";")[0])
+	desired, err := parseDesiredState(contentType, body)
+	if err != nil {
+		logger.ConfigLog.Errorf("invalid desired state: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	normalizeDesiredState(&desired)
+	if violations := validateDesiredState(desired); len(violations) > 0 {
+		logger.ConfigLog.Warnf("desired state is inconsistent: %+v request ID: %s", violations, requestID)
+		problemdetails.Write(c, configmodels.ProblemDetails{
+			Status:    http.StatusUnprocessableEntity,
+			Code:      configmodels.ProblemCodeValidationFailed,
+			Detail:    "desired state is inconsistent",
+			RequestID: requestID,
+			Errors:    violations,
+		})
+		return
+	}
+	current, err := loadCurrentState()
+	if err != nil {
+		logger.DbLog.Errorf("failed to load current configuration: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to load current configuration", requestID)
+		return
+	}
+	operations := diffDesiredState(current, desired)
+	result := configmodels.ApplyResult{
+		DryRun:  dryRun,
+		Changes: applyChanges(operations),
+	}
+	if dryRun || len(operations) == 0 {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	if err = applyDesiredStateOperations(c.Request.Context(), operations); err != nil {
+		logger.DbLog.Errorf("failed to apply desired state: %+v request ID: %s", err, requestID)
+		if writeVersionConflict(c, err, requestID) {
+			return
+		}
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to apply desired state", requestID)
+		return
+	}
+	result.Applied = true
+	syncErr := syncSubscribersOnApply(current, desired)
+	notifyConfigChanges(c.Request.Context(), applyConfigChanges(operations)...)
+	for _, msg := range applyConfigMessages(operations, desired) {
+		msg.RequestID = requestID
+		configChannel <- msg
+	}
+	if syncErr != nil {
+		logger.ConfigLog.Errorf("desired state applied but subscriber synchronization failed: %+v request ID: %s", syncErr, requestID)
+		problemdetails.Write(c, configmodels.ProblemDetails{
+			Status:    http.StatusInternalServerError,
+			Code:      configmodels.ProblemCodeInternalError,
+			Detail:    "desired state applied but subscriber synchronization failed",
+			RequestID: requestID,
+			Changes:   result.Changes,
+		})
+		return
+	}
+	logger.WebUILog.Infof("successfully applied desired state with %d changes", len(operations))
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockMongoClientApply struct {
+	dbadapter.DBInterface
+	docs       map[string][]map[string]interface{}
+	operations []string
+	revisions  int
+}
+
+func (m *MockMongoClientApply) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]interface{}, error) {
+	return m.docs[coll], nil
+}
+
+func (m *MockMongoClientApply) RestfulAPIGetOne(coll string, filter bson.M) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockMongoClientApply) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockMongoClientApply) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	m.revisions += len(postDataArray)
+	return nil
+}
+
+func (m *MockMongoClientApply) RestfulAPIPostWithContext(context context.Context, collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
+	m.operations = append(m.operations, "post "+collName)
+	return true, nil
+}
+
+func (m *MockMongoClientApply) RestfulAPIDeleteOneWithContext(context context.Context, collName string, filter bson.M) error {
+	if _, ok := filter["ueId"]; !ok {
+		m.operations = append(m.operations, "delete "+collName)
+	}
+	return nil
+}
+
+func (m *MockMongoClientApply) StartSession() (mongo.Session, error) {
+	return &MockSession{}, nil
+}
+
+func desiredStateSlice(name string) configmodels.Slice {
+	slice := networkSlice(name)
+	slice.SiteInfo.Upf = map[string]interface{}{"upf-name": "upf1.example.com", "upf-port": "8805"}
+	return slice
+}
+
+func desiredStateJSON(t *testing.T, state configmodels.DesiredState) string {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to marshal desired state: %v", err)
+	}
+	return string(raw)
+}
+
+func TestApplyDesiredState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	origCommonDB := dbadapter.CommonDBClient
+	origAuthDB := dbadapter.AuthDBClient
+	origChannel := configChannel
+	origConfig := factory.WebUIConfig
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		dbadapter.AuthDBClient = origAuthDB
+		configChannel = origChannel
+		factory.WebUIConfig = origConfig
+	}()
+	factory.WebUIConfig = &factory.Config{Configuration: &factory.Configuration{}}
+
+	tac := int32(1)
+	fullState := configmodels.DesiredState{
+		Gnbs:          []configmodels.Gnb{{Name: "demo-gnb1", Tac: &tac}},
+		Upfs:          []configmodels.Upf{{Hostname: "upf1.example.com", Port: "8805"}},
+		DeviceGroups:  []configmodels.DeviceGroups{deviceGroup("group1"), deviceGroup("group2")},
+		NetworkSlices: []configmodels.Slice{desiredStateSlice("slice1")},
+	}
+	// stored objects carry the bitrates converted by the handlers
+	storedState := configmodels.DesiredState{
+		DeviceGroups:  []configmodels.DeviceGroups{deviceGroup("group1"), deviceGroup("group2")},
+		NetworkSlices: []configmodels.Slice{desiredStateSlice("slice1")},
+	}
+	normalizeDesiredState(&storedState)
+	storedDocs := map[string][]map[string]interface{}{
+		configmodels.GnbDataColl: {configmodels.ToBsonM(fullState.Gnbs[0])},
+		configmodels.UpfDataColl: {configmodels.ToBsonM(fullState.Upfs[0])},
+		devGroupDataColl:         {configmodels.ToBsonM(storedState.DeviceGroups[0]), configmodels.ToBsonM(storedState.DeviceGroups[1])},
+		sliceDataColl:            {configmodels.ToBsonM(storedState.NetworkSlices[0])},
+	}
+	yamlState := `
+gnbs:
+  - name: demo-gnb1
+    tac: 1
+upfs:
+  - hostname: upf1.example.com
+    port: "8805"
+device-groups: []
+network-slices: []
+`
+
+	testCases := []struct {
+		name               string
+		route              string
+		contentType        string
+		body               string
+		storedDocs         map[string][]map[string]interface{}
+		expectedCode       int
+		expectedChanges    []configmodels.ApplyChange
+		expectedOperations []string
+		expectedMsgs       int
+		expectedBody       string
+	}{
+		{
+			name:         "dry run creates everything",
+			route:        "/config/v1/apply?dryRun=true",
+			contentType:  "application/json",
+			body:         desiredStateJSON(t, fullState),
+			storedDocs:   map[string][]map[string]interface{}{},
+			expectedCode: http.StatusOK,
+			expectedChanges: []configmodels.ApplyChange{
+				{Kind: configmodels.ApplyKindGnb, Name: "demo-gnb1", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindUpf, Name: "upf1.example.com", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindDeviceGroup, Name: "group1", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindDeviceGroup, Name: "group2", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindNetworkSlice, Name: "slice1", Operation: configmodels.ApplyOpCreate},
+			},
+		},
+		{
+			name:         "apply creates in dependency order",
+			route:        "/config/v1/apply",
+			contentType:  "application/json",
+			body:         desiredStateJSON(t, fullState),
+			storedDocs:   map[string][]map[string]interface{}{},
+			expectedCode: http.StatusOK,
+			expectedChanges: []configmodels.ApplyChange{
+				{Kind: configmodels.ApplyKindGnb, Name: "demo-gnb1", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindUpf, Name: "upf1.example.com", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindDeviceGroup, Name: "group1", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindDeviceGroup, Name: "group2", Operation: configmodels.ApplyOpCreate},
+				{Kind: configmodels.ApplyKindNetworkSlice, Name: "slice1", Operation: configmodels.ApplyOpCreate},
+			},
+			expectedOperations: []string{
+				"post " + configmodels.GnbDataColl, "post " + configmodels.UpfDataColl,
+				"post " + devGroupDataColl, "post " + devGroupDataColl, "post " + sliceDataColl,
+			},
+			expectedMsgs: 3,
+		},
+		{
+			name:            "unchanged state",
+			route:           "/config/v1/apply",
+			contentType:     "application/json",
+			body:            desiredStateJSON(t, fullState),
+			storedDocs:      storedDocs,
+			expectedCode:    http.StatusOK,
+			expectedChanges: []configmodels.ApplyChange{},
+		},
+		{
+			name:         "YAML document deletes in reverse dependency order",
+			route:        "/config/v1/apply",
+			contentType:  "application/yaml",
+			body:         yamlState,
+			storedDocs:   storedDocs,
+			expectedCode: http.StatusOK,
+			expectedChanges: []configmodels.ApplyChange{
+				{Kind: configmodels.ApplyKindNetworkSlice, Name: "slice1", Operation: configmodels.ApplyOpDelete},
+				{Kind: configmodels.ApplyKindDeviceGroup, Name: "group1", Operation: configmodels.ApplyOpDelete},
+				{Kind: configmodels.ApplyKindDeviceGroup, Name: "group2", Operation: configmodels.ApplyOpDelete},
+			},
+			expectedOperations: []string{
+				"delete " + sliceDataColl, "delete " + devGroupDataColl, "delete " + devGroupDataColl,
+			},
+			expectedMsgs: 3,
+		},
+		{
+			name:         "updated UPF port",
+			route:        "/config/v1/apply",
+			contentType:  "application/json",
+			body:         strings.Replace(desiredStateJSON(t, fullState), `"port":"8805"`, `"port":"8806"`, 1),
+			storedDocs:   storedDocs,
+			expectedCode: http.StatusOK,
+			expectedChanges: []configmodels.ApplyChange{
+				{Kind: configmodels.ApplyKindUpf, Name: "upf1.example.com", Operation: configmodels.ApplyOpUpdate, Fields: []string{"port"}},
+			},
+			expectedOperations: []string{"post " + configmodels.UpfDataColl},
+		},
+		{
+			name:         "slice refers to unknown objects",
+			route:        "/config/v1/apply",
+			contentType:  "application/json",
+			body:         desiredStateJSON(t, configmodels.DesiredState{NetworkSlices: []configmodels.Slice{desiredStateSlice("slice1")}}),
+			storedDocs:   map[string][]map[string]interface{}{},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"field":"network-slices[0].site-info.upfs[0].upf-name","message":"network slice slice1 refers to unknown UPF upf1.example.com"}`,
+		},
+		{
+			name:         "invalid UPF inventory metadata",
+			route:        "/config/v1/apply",
+			contentType:  "application/json",
+			body:         strings.Replace(desiredStateJSON(t, fullState), `"port":"8805"`, `"port":"8805","n3-address":"10.0.0"`, 1),
+			storedDocs:   map[string][]map[string]interface{}{},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"field":"upfs[0].n3-address","message":"invalid UPF N3 address '10.0.0'`,
+		},
+		{
+			name:         "unknown field",
+			route:        "/config/v1/apply",
+			contentType:  "application/json",
+			body:         `{"gnb": []}`,
+			storedDocs:   map[string][]map[string]interface{}{},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"code":"invalid-request"`,
+		},
+		{
+			name:         "unsupported content type",
+			route:        "/config/v1/apply",
+			contentType:  "text/plain",
+			body:         "{}",
+			storedDocs:   map[string][]map[string]interface{}{},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "unsupported content-type",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbClient := &MockMongoClientApply{docs: tc.storedDocs}
+			dbadapter.CommonDBClient = dbClient
+			dbadapter.AuthDBClient = dbClient
+			configChannel = make(chan *configmodels.ConfigMessage, 10)
+
+			req, err := http.NewRequest(http.MethodPost, tc.route, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", tc.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("expected body to contain %q, got %s", tc.expectedBody, w.Body.String())
+			}
+			if !reflect.DeepEqual(dbClient.operations, tc.expectedOperations) {
+				t.Errorf("expected operations %v, got %v", tc.expectedOperations, dbClient.operations)
+			}
+			if dbClient.revisions != len(tc.expectedOperations) {
+				t.Errorf("expected %d revisions, got %d", len(tc.expectedOperations), dbClient.revisions)
+			}
+			if len(configChannel) != tc.expectedMsgs {
+				t.Errorf("expected %d config messages, got %d", tc.expectedMsgs, len(configChannel))
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			var result configmodels.ApplyResult
+			if err = json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("failed to decode result: %v", err)
+			}
+			if !reflect.DeepEqual(result.Changes, tc.expectedChanges) {
+				t.Errorf("expected changes %+v, got %+v", tc.expectedChanges, result.Changes)
+			}
+			if result.Applied != (len(tc.expectedOperations) > 0) {
+				t.Errorf("unexpected applied flag %v", result.Applied)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+const (
+	defaultAuditLogPageSize = 100
+	maxAuditLogPageSize     = 1000
+)
+
+// AddAuditLogService registers the audit log routes. When jwtKeys is nil
+// authentication is disabled and the routes are not protected.
+func AddAuditLogService(engine *gin.Engine, jwtKeys *auth.JWTKeys) {
+	group := engine.Group("/config/v1")
+	addRoutes(group, getAuditLogRoutes(jwtKeys))
+}
+
+func getAuditLogRoutes(jwtKeys *auth.JWTKeys) Routes {
+	handler := GetAuditLog
+	if jwtKeys != nil {
+		handler = auth.AdminOnly(jwtKeys, GetAuditLog)
+	}
+	return Routes{
+		{
+			"GetAuditLog",
+			http.MethodGet,
+			"/audit",
+			handler,
+		},
+	}
+}
+
+// parsePageQuery parses the limit and offset query parameters.
+func parsePageQuery(values url.Values, defaultLimit, maxLimit int64) (offset, limit int64, err error) {
+	limit = defaultLimit
+	if value := values.Get("limit"); value != "" {
+		limit, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return 0, 0, fmt.Errorf("limit must be an integer between 1 and %d", maxLimit)
+		}
+	}
+	if value := values.Get("offset"); value != "" {
+		offset, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return offset, limit, nil
+}
+
+func parseAuditLogQuery(values url.Values) (auditLogQuery, error) {
+	query := auditLogQuery{username: values.Get("user")}
+	for param, target := range map[string]*time.Time{"from": &query.from, "to": &query.to} {
+		if value := values.Get(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = parsed.UTC()
+		}
+	}
+	offset, limit, err := parsePageQuery(values, defaultAuditLogPageSize, maxAuditLogPageSize)
+	if err != nil {
+		return query, err
+	}
+	query.offset, query.limit = offset, limit
+	return query, nil
+}
+
+// GetAuditLog godoc
+//
+// @Description  Return the audit log of the mutating API calls, newest first
+// @Tags         Audit
+// @Produce      json
+// @Param        from      query   string  false   "Only return entries at or after this RFC 3339 timestamp"
+// @Param        to        query   string  false   "Only return entries at or before this RFC 3339 timestamp"
+// @Param        user      query   string  false   "Only return entries of this user"
+// @Param        limit     query   int     false   "Maximum number of entries (default 100, max 1000)"
+// @Param        offset    query   int     false   "Number of entries to skip"
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.AuditLogEntry  "Audit log entries"
+// @Failure      400  {object}  nil                         "Invalid filter parameter"
+// @Failure      401  {object}  nil                         "Authorization failed"
+// @Failure      403  {object}  nil                         "Forbidden"
+// @Failure      500  {object}  nil                         "Error retrieving the audit log"
+// @Router       /config/v1/audit  [get]
+func GetAuditLog(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a GET audit log request")
+	query, err := parseAuditLogQuery(c.Request.URL.Query())
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	entries, err := getAuditLogEntries(query)
+	if err != nil {
+		logger.DbLog.Errorf("failed to retrieve audit log: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve audit log", requestID)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,541 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockMongoClientAudit struct {
+	dbadapter.DBInterface
+	documents   map[string]map[string]interface{}
+	reads       int
+	entries     []interface{}
+	auditKey    map[string]interface{}
+	pagedFilter bson.M
+	pagedSkip   int64
+	pagedLimit  int64
+}
+
+func (m *MockMongoClientAudit) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	if collName == configmodels.AuditKeyDataColl {
+		return m.auditKey, nil
+	}
+	m.reads++
+	for _, value := range filter {
+		if document, ok := m.documents[value.(string)]; ok {
+			copied := map[string]interface{}{}
+			for key, field := range document {
+				copied[key] = field
+			}
+			return copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockMongoClientAudit) RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	for _, value := range filter {
+		for _, id := range value.(bson.M)["$in"].([]string) {
+			if document, ok := m.documents[id]; ok {
+				results = append(results, document)
+			}
+		}
+	}
+	return results, nil
+}
+
+func (m *MockMongoClientAudit) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	if collName == configmodels.AuditKeyDataColl {
+		if m.auditKey != nil {
+			return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+		}
+		m.auditKey = postDataArray[0].(bson.M)
+		return nil
+	}
+	m.entries = append(m.entries, postDataArray...)
+	return nil
+}
+
+func (m *MockMongoClientAudit) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	m.pagedFilter = filter
+	m.pagedSkip = skip
+	m.pagedLimit = limit
+	var results []map[string]interface{}
+	for _, entry := range m.entries {
+		results = append(results, entry.(bson.M))
+	}
+	return results, nil
+}
+
+// resetAuditHashKey forgets the cached audit hash key, so that it is loaded
+// from the mock database of the test.
+func resetAuditHashKey(t *testing.T) {
+	auditHashKey.key = nil
+	t.Cleanup(func() { auditHashKey.key = nil })
+}
+
+func TestAuditLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	origCommonDB := dbadapter.CommonDBClient
+	origAuthDB := dbadapter.AuthDBClient
+	origWebuiDB := dbadapter.WebuiDBClient
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.WebuiDBClient = origWebuiDB
+	}()
+
+	testCases := []struct {
+		name               string
+		method             string
+		route              string
+		body               string
+		stored             map[string]map[string]interface{}
+		handlerStatus      int
+		expectedEntry      bool
+		expectedResourceID string
+		expectedBefore     bool
+		expectedAfter      bool
+	}{
+		{
+			name:               "update of an existing network slice",
+			method:             http.MethodPut,
+			route:              "/config/v1/network-slice/slice1",
+			stored:             map[string]map[string]interface{}{"slice1": {"slice-name": "slice1", "sst": "1"}},
+			handlerStatus:      http.StatusOK,
+			expectedEntry:      true,
+			expectedResourceID: "slice1",
+			expectedBefore:     true,
+			expectedAfter:      true,
+		},
+		{
+			name:               "creation of a gNB identified in the body",
+			method:             http.MethodPost,
+			route:              "/config/v1/inventory/gnb",
+			body:               `{"name": "gnb1", "tac": 1}`,
+			stored:             map[string]map[string]interface{}{},
+			handlerStatus:      http.StatusCreated,
+			expectedEntry:      true,
+			expectedResourceID: "gnb1",
+			expectedAfter:      true,
+		},
+		{
+			name:               "failed deletion of a subscriber",
+			method:             http.MethodDelete,
+			route:              "/api/subscriber/imsi-001010000000001",
+			stored:             map[string]map[string]interface{}{"imsi-001010000000001": {"ueId": "imsi-001010000000001", "encPermanentKey": "secret"}},
+			handlerStatus:      http.StatusInternalServerError,
+			expectedEntry:      true,
+			expectedResourceID: "imsi-001010000000001",
+			expectedBefore:     true,
+			expectedAfter:      true,
+		},
+		{
+			name:          "read requests are not audited",
+			method:        http.MethodGet,
+			route:         "/config/v1/network-slice/slice1",
+			stored:        map[string]map[string]interface{}{},
+			handlerStatus: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetAuditHashKey(t)
+			dbClient := &MockMongoClientAudit{documents: tc.stored}
+			dbadapter.CommonDBClient = dbClient
+			dbadapter.AuthDBClient = dbClient
+			dbadapter.WebuiDBClient = dbClient
+
+			router := gin.New()
+			router.Use(AuditLogMiddleware())
+			handler := func(c *gin.Context) {
+				c.Request = c.Request.WithContext(auth.ContextWithUser(c.Request.Context(), "janedoe", configmodels.AdminRole))
+				var body map[string]interface{}
+				if c.Request.ContentLength > 0 {
+					if err := c.ShouldBindJSON(&body); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"error": "body not restored"})
+						return
+					}
+				}
+				if tc.handlerStatus/100 == 2 {
+					dbClient.documents["slice1"] = map[string]interface{}{"slice-name": "slice1", "sst": "2"}
+					dbClient.documents["gnb1"] = map[string]interface{}{"name": "gnb1", "tac": 1}
+				}
+				c.JSON(tc.handlerStatus, gin.H{})
+			}
+			router.Handle(tc.method, "/config/v1/network-slice/:slice-name", handler)
+			router.Handle(tc.method, "/config/v1/inventory/gnb", handler)
+			router.Handle(tc.method, "/api/subscriber/:ueId", handler)
+
+			req, err := http.NewRequest(tc.method, tc.route, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.handlerStatus {
+				t.Fatalf("expected %d, got %d: %s", tc.handlerStatus, w.Code, w.Body.String())
+			}
+			if !tc.expectedEntry {
+				if len(dbClient.entries) != 0 {
+					t.Errorf("expected no audit log entry, got %+v", dbClient.entries)
+				}
+				return
+			}
+			if len(dbClient.entries) != 1 {
+				t.Fatalf("expected one audit log entry, got %d", len(dbClient.entries))
+			}
+			entry := dbClient.entries[0].(bson.M)
+			expected := bson.M{
+				"method":      tc.method,
+				"path":        tc.route,
+				"status":      tc.handlerStatus,
+				"username":    "janedoe",
+				"role":        configmodels.AdminRole,
+				"resource-id": tc.expectedResourceID,
+			}
+			for field, value := range expected {
+				if !reflect.DeepEqual(entry[field], value) {
+					t.Errorf("expected %s %v, got %v", field, value, entry[field])
+				}
+			}
+			expiresAt := entry["timestamp"].(time.Time).Add(factory.WebUIConfig.Configuration.AuditLogRetention)
+			if entry["expires-at"] != expiresAt {
+				t.Errorf("expected expires-at %v, got %v", expiresAt, entry["expires-at"])
+			}
+			if _, ok := entry["before-hash"]; ok != tc.expectedBefore {
+				t.Errorf("unexpected before-hash %v", entry["before-hash"])
+			}
+			if _, ok := entry["after-hash"]; ok != tc.expectedAfter {
+				t.Errorf("unexpected after-hash %v", entry["after-hash"])
+			}
+			if tc.expectedBefore && tc.expectedAfter {
+				unchanged := tc.handlerStatus/100 != 2
+				if (entry["before-hash"] == entry["after-hash"]) != unchanged {
+					t.Errorf("unexpected hashes before %v after %v", entry["before-hash"], entry["after-hash"])
+				}
+			}
+			if raw, _ := json.Marshal(entry); strings.Contains(string(raw), "secret") {
+				t.Errorf("audit log entry leaks the stored document: %s", raw)
+			}
+		})
+	}
+}
+
+func TestGetAuditLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddAuditLogService(router, nil)
+	origWebuiDB := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = origWebuiDB }()
+
+	timestamp := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	storedEntry := bson.M{
+		"timestamp":   timestamp,
+		"method":      http.MethodDelete,
+		"path":        "/config/v1/network-slice/slice1",
+		"status":      http.StatusOK,
+		"username":    "janedoe",
+		"resource-id": "slice1",
+		"before-hash": "abc",
+	}
+	testCases := []struct {
+		name           string
+		route          string
+		expectedCode   int
+		expectedFilter bson.M
+		expectedSkip   int64
+		expectedLimit  int64
+	}{
+		{
+			name:           "no filter",
+			route:          "/config/v1/audit",
+			expectedCode:   http.StatusOK,
+			expectedFilter: bson.M{},
+			expectedLimit:  defaultAuditLogPageSize,
+		},
+		{
+			name:         "time range and user",
+			route:        "/config/v1/audit?from=2025-03-01T00:00:00Z&to=2025-03-02T00:00:00%2B01:00&user=janedoe&limit=10&offset=20",
+			expectedCode: http.StatusOK,
+			expectedFilter: bson.M{
+				"timestamp": bson.M{
+					"$gte": time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+					"$lte": time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC),
+				},
+				"username": "janedoe",
+			},
+			expectedSkip:  20,
+			expectedLimit: 10,
+		},
+		{
+			name:         "invalid timestamp",
+			route:        "/config/v1/audit?from=yesterday",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid limit",
+			route:        "/config/v1/audit?limit=5000",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbClient := &MockMongoClientAudit{entries: []interface{}{storedEntry}}
+			dbadapter.WebuiDBClient = dbClient
+
+			req, err := http.NewRequest(http.MethodGet, tc.route, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			if !reflect.DeepEqual(dbClient.pagedFilter, tc.expectedFilter) {
+				t.Errorf("expected filter %v, got %v", tc.expectedFilter, dbClient.pagedFilter)
+			}
+			if dbClient.pagedSkip != tc.expectedSkip || dbClient.pagedLimit != tc.expectedLimit {
+				t.Errorf("expected skip %d limit %d, got %d %d", tc.expectedSkip, tc.expectedLimit, dbClient.pagedSkip, dbClient.pagedLimit)
+			}
+			var entries []configmodels.AuditLogEntry
+			if err = json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+				t.Fatalf("failed to decode audit log: %v", err)
+			}
+			expected := []configmodels.AuditLogEntry{{
+				Timestamp:  timestamp,
+				Method:     http.MethodDelete,
+				Path:       "/config/v1/network-slice/slice1",
+				Status:     http.StatusOK,
+				Username:   "janedoe",
+				ResourceID: "slice1",
+				BeforeHash: "abc",
+			}}
+			if !reflect.DeepEqual(entries, expected) {
+				t.Errorf("expected entries %+v, got %+v", expected, entries)
+			}
+		})
+	}
+}
+
+func TestAuditLogStoredInCommonDBWithoutAuthentication(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	origWebuiDB := dbadapter.WebuiDBClient
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		dbadapter.WebuiDBClient = origWebuiDB
+	}()
+	dbClient := &MockMongoClientAudit{}
+	dbadapter.CommonDBClient = dbClient
+	dbadapter.WebuiDBClient = nil
+
+	entry := configmodels.AuditLogEntry{Timestamp: time.Now(), Method: http.MethodDelete, Path: "/config/v1/network-slice/slice1"}
+	if err := storeAuditLogEntries([]configmodels.AuditLogEntry{entry}); err != nil {
+		t.Fatalf("failed to store audit log entry: %v", err)
+	}
+	if len(dbClient.entries) != 1 {
+		t.Errorf("expected the entry in the common database, got %d entries", len(dbClient.entries))
+	}
+}
+
+func TestAuditLogMiddleware_BulkSubscribers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	origAuthDB := dbadapter.AuthDBClient
+	origWebuiDB := dbadapter.WebuiDBClient
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.WebuiDBClient = origWebuiDB
+	}()
+	resetAuditHashKey(t)
+	dbClient := &MockMongoClientAudit{documents: map[string]map[string]interface{}{}}
+	dbadapter.AuthDBClient = dbClient
+	dbadapter.WebuiDBClient = dbClient
+
+	ueIds := []string{"imsi-001010000000001", "imsi-001010000000002"}
+	router := gin.New()
+	router.Use(AuditLogMiddleware())
+	router.POST("/api/subscriber:action", func(c *gin.Context) {
+		for _, ueId := range ueIds {
+			dbClient.documents[ueId] = map[string]interface{}{"ueId": ueId, "encPermanentKey": "secret"}
+		}
+		setAuditedResourceIDs(c, ueIds)
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/subscriber:bulk", strings.NewReader(`{"subscribers": []}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if len(dbClient.entries) != len(ueIds) {
+		t.Fatalf("expected an audit log entry per subscriber, got %+v", dbClient.entries)
+	}
+	for i, ueId := range ueIds {
+		entry := dbClient.entries[i].(bson.M)
+		if entry["resource-id"] != ueId {
+			t.Errorf("expected resource-id %s, got %v", ueId, entry["resource-id"])
+		}
+		if entry["after-hash"] == nil || entry["after-hash"] == "" {
+			t.Errorf("expected the hash of %s after the request, got %v", ueId, entry["after-hash"])
+		}
+		if _, ok := entry["before-hash"]; ok {
+			t.Errorf("unexpected before-hash %v", entry["before-hash"])
+		}
+	}
+	if dbClient.entries[0].(bson.M)["after-hash"] == dbClient.entries[1].(bson.M)["after-hash"] {
+		t.Errorf("expected the subscribers to hash differently")
+	}
+}
+
+func TestAuditLogMiddleware_RefusedByAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	origCommonDB := dbadapter.CommonDBClient
+	origWebuiDB := dbadapter.WebuiDBClient
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		dbadapter.WebuiDBClient = origWebuiDB
+	}()
+	resetAuditHashKey(t)
+	dbClient := &MockMongoClientAudit{documents: map[string]map[string]interface{}{
+		"slice1": {"slice-name": "slice1", "sst": "1"},
+	}}
+	dbadapter.CommonDBClient = dbClient
+	dbadapter.WebuiDBClient = dbClient
+
+	router := gin.New()
+	unauthenticated := func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "auth failed"})
+	}
+	router.Use(unauthenticated, AuditLogMiddleware())
+	router.DELETE("/config/v1/network-slice/:slice-name", func(c *gin.Context) {
+		t.Error("unexpected call of the handler")
+	})
+	router.DELETE("/config/v1/webhooks/:webhook-name", unauthenticated, auditedHandler(func(c *gin.Context) {
+		t.Error("unexpected call of the handler")
+	}))
+	for _, route := range []string{"/config/v1/network-slice/slice1", "/config/v1/webhooks/webhook1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, route, nil))
+
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected %d, got %d: %s", http.StatusUnauthorized, w.Code, w.Body.String())
+		}
+	}
+	if dbClient.reads != 0 {
+		t.Errorf("expected no read of the resources, got %d", dbClient.reads)
+	}
+	if len(dbClient.entries) != 0 {
+		t.Errorf("expected no audit log entry, got %+v", dbClient.entries)
+	}
+}
+
+func TestAuditLogMiddleware_BeforeHashOfVersionedRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	origCommonDB := dbadapter.CommonDBClient
+	origWebuiDB := dbadapter.WebuiDBClient
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		dbadapter.WebuiDBClient = origWebuiDB
+	}()
+	resetAuditHashKey(t)
+	dbClient := &MockMongoClientAudit{documents: map[string]map[string]interface{}{
+		"slice1": {"slice-name": "slice1", "sst": "1", "version": int64(1)},
+	}}
+	dbadapter.CommonDBClient = dbClient
+	dbadapter.WebuiDBClient = dbClient
+	concurrent := map[string]interface{}{"slice-name": "slice1", "sst": "2", "version": int64(2)}
+
+	router := gin.New()
+	router.Use(AuditLogMiddleware())
+	router.PUT("/config/v1/network-slice/:slice-name", func(c *gin.Context) {
+		// another request updates the slice after the middleware read it
+		dbClient.documents["slice1"] = concurrent
+		if _, err := fetchResourceVersion(c.Request.Context(), dbClient, sliceDataColl, bson.M{"slice-name": "slice1"}); err != nil {
+			t.Errorf("failed to fetch the slice: %v", err)
+		}
+		dbClient.documents["slice1"] = map[string]interface{}{"slice-name": "slice1", "sst": "3", "version": int64(3)}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/config/v1/network-slice/slice1", strings.NewReader(`{}`)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if len(dbClient.entries) != 1 {
+		t.Fatalf("expected one audit log entry, got %d", len(dbClient.entries))
+	}
+	expected, err := hashAuditDocument(concurrent)
+	if err != nil {
+		t.Fatalf("failed to hash the slice: %v", err)
+	}
+	if before := dbClient.entries[0].(bson.M)["before-hash"]; before != expected {
+		t.Errorf("expected the hash of the slice replaced by the write %s, got %v", expected, before)
+	}
+}
+
+func TestAuditResourceHash_Key(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	origWebuiDB := dbadapter.WebuiDBClient
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		dbadapter.WebuiDBClient = origWebuiDB
+	}()
+	resetAuditHashKey(t)
+	dbClient := &MockMongoClientAudit{documents: map[string]map[string]interface{}{
+		"imsi-001010000000001": {"ueId": "imsi-001010000000001", "encPermanentKey": "secret"},
+	}}
+	dbadapter.CommonDBClient = dbClient
+	dbadapter.WebuiDBClient = dbClient
+	resource := auditResource{func() dbadapter.DBInterface { return dbClient }, authSubsDataColl, "ueId", "ueId", ""}
+
+	hash, err := auditResourceHash(resource, "imsi-001010000000001")
+	if err != nil {
+		t.Fatalf("failed to hash the subscriber: %v", err)
+	}
+	if dbClient.auditKey == nil {
+		t.Fatalf("expected the audit hash key to be stored")
+	}
+	unkeyed := sha256.Sum256([]byte(`{"encPermanentKey":"secret","ueId":"imsi-001010000000001"}`))
+	if hash == hex.EncodeToString(unkeyed[:]) {
+		t.Errorf("expected a keyed hash of the subscriber")
+	}
+
+	// another replica uses the stored key
+	auditHashKey.key = nil
+	if again, err := auditResourceHash(resource, "imsi-001010000000001"); err != nil || again != hash {
+		t.Errorf("expected the hash %s with the stored key, got %s %v", hash, again, err)
+	}
+	// and a new key gives other hashes
+	auditHashKey.key = nil
+	dbClient.auditKey = nil
+	if other, err := auditResourceHash(resource, "imsi-001010000000001"); err != nil || other == hash {
+		t.Errorf("expected another hash with another key, got %s %v", other, err)
+	}
+}
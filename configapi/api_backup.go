@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+// ExportConfig godoc
+//
+// @Description  Export the configuration (gNBs, UPFs, device groups, network slices and subscribers) as a versioned backup
+// @Tags         Backup
+// @Produce      application/x-ndjson,application/gzip
+// @Param        format    query    string    false    "Backup format: jsonl (default) or tar.gz"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Backup"
+// @Failure      400  {object}  nil  "Unsupported backup format"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      500  {object}  nil  "Error exporting the configuration"
+// @Router       /config/v1/export  [get]
+func ExportConfig(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a GET export request")
+	format := c.DefaultQuery("format", backupFormatJSONL)
+	if format != backupFormatJSONL && format != backupFormatTarGzip {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("unsupported backup format %s", format), requestID)
+		return
+	}
+	header := newBackupHeader()
+	contentType := "application/x-ndjson"
+	write := writeBackupJSONL
+	if format == backupFormatTarGzip {
+		contentType = "application/gzip"
+		write = writeBackupTarGzip
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", backupFileName(header, format)))
+	c.Status(http.StatusOK)
+	if err := write(c.Request.Context(), c.Writer, header); err != nil {
+		logger.WebUILog.Errorf("failed to stream configuration backup: %+v request ID: %s", err, requestID)
+		return
+	}
+	logger.WebUILog.Infoln("successfully executed GET export request")
+}
+
+// ImportConfig godoc
+//
+// @Description  Import a backup created by the export endpoint. In merge mode the documents of the backup are added to or replace the existing ones, in replace mode the configuration is replaced by the backup, and dry-run reports the outcome of a merge without applying it
+// @Tags         Backup
+// @Accept       application/x-ndjson,application/gzip
+// @Produce      json
+// @Param        mode    query    string    false    "Import mode: merge (default), replace or dry-run"
+// @Security     BearerAuth
+// @Success      200  {object}  configmodels.ImportReport  "Import report"
+// @Failure      400  {object}  nil                        "Invalid backup or import mode"
+// @Failure      401  {object}  nil                        "Authorization failed"
+// @Failure      403  {object}  nil                        "Forbidden"
+// @Failure      413  {object}  nil                        "Backup too large"
+// @Failure      422  {object}  nil                        "Backup breaks referential integrity"
+// @Failure      500  {object}  nil                        "Error importing the configuration"
+// @Router       /config/v1/import  [post]
+func ImportConfig(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a POST import request")
+	mode := c.DefaultQuery("mode", configmodels.ImportModeMerge)
+	if mode != configmodels.ImportModeMerge && mode != configmodels.ImportModeReplace && mode != configmodels.ImportModeDryRun {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("unsupported import mode %s", mode), requestID)
+		return
+	}
+	archive, err := parseBackup(http.MaxBytesReader(c.Writer, c.Request.Body, factory.WebUIConfig.Configuration.MaxImportSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeProblem(c, http.StatusRequestEntityTooLarge, configmodels.ProblemCodePayloadTooLarge, "backup too large", requestID)
+			return
+		}
+		logger.WebUILog.Errorf("invalid backup: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	current, err := loadBackupSnapshot()
+	if err != nil {
+		logger.WebUILog.Errorf("failed to read current configuration: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to import configuration", requestID)
+		return
+	}
+	plan, err := planBackupImport(mode, archive, current)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	references, err := validateBackupReferences(plan.result)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	if len(references) > 0 {
+		logger.WebUILog.Warnf("backup breaks referential integrity: %+v request ID: %s", references, requestID)
+		writeErrorProblem(c, http.StatusUnprocessableEntity, &referenceError{
+			statusCode: http.StatusUnprocessableEntity,
+			message:    "backup breaks referential integrity",
+			references: references,
+		}, requestID)
+		return
+	}
+	if mode == configmodels.ImportModeDryRun {
+		c.JSON(http.StatusOK, plan.report)
+		return
+	}
+	msg, err := backupImportMessage(plan, archive)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	if err = applyBackupImport(c.Request.Context(), plan, archive); err != nil {
+		logger.WebUILog.Errorf("failed to import configuration: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to import configuration", requestID)
+		return
+	}
+	msg.RequestID = requestID
+	configChannel <- msg
+	notifyConfigChanges(c.Request.Context(), plan.configChanges()...)
+	plan.report.Applied = true
+	logger.WebUILog.Infof("successfully executed POST import request in %s mode", mode)
+	c.JSON(http.StatusOK, plan.report)
+}
@@ -0,0 +1,376 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockMongoClientBackup struct {
+	dbadapter.DBInterface
+	docs         map[string][]map[string]interface{}
+	clearedColls []string
+	postedDocs   map[string]int
+	putDocs      map[string]int
+}
+
+func newMockMongoClientBackup(docs map[string][]map[string]interface{}) *MockMongoClientBackup {
+	return &MockMongoClientBackup{
+		docs:       docs,
+		postedDocs: map[string]int{},
+		putDocs:    map[string]int{},
+	}
+}
+
+func (m *MockMongoClientBackup) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]interface{}, error) {
+	return m.docs[coll], nil
+}
+
+func (m *MockMongoClientBackup) RestfulAPIForEachWithContext(context context.Context, coll string, filter bson.M, fn func(map[string]interface{}) error) error {
+	for _, doc := range m.docs[coll] {
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockMongoClientBackup) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockMongoClientBackup) RestfulAPIDeleteManyWithContext(context context.Context, collName string, filter bson.M) error {
+	m.clearedColls = append(m.clearedColls, collName)
+	return nil
+}
+
+func (m *MockMongoClientBackup) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	m.postedDocs[collName] += len(postDataArray)
+	return nil
+}
+
+func (m *MockMongoClientBackup) RestfulAPIPutOneWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	m.putDocs[collName]++
+	return true, nil
+}
+
+func (m *MockMongoClientBackup) StartSession() (mongo.Session, error) {
+	return &MockSession{}, nil
+}
+
+func backupTestDocs() map[string][]map[string]interface{} {
+	return map[string][]map[string]interface{}{
+		configmodels.GnbDataColl: {{"name": "demo-gnb1", "tac": 1}},
+		configmodels.UpfDataColl: {{"hostname": "upf", "port": "8805"}},
+		devGroupDataColl: {
+			configmodels.ToBsonM(deviceGroupWithImsis("group1", []string{"001010000000001"})),
+			configmodels.ToBsonM(deviceGroupWithImsis("group2", []string{})),
+		},
+		sliceDataColl:    {configmodels.ToBsonM(networkSlice("slice1"))},
+		authSubsDataColl: {{"ueId": "imsi-001010000000001", "authenticationMethod": "5G_AKA"}},
+		amDataColl:       {{"ueId": "imsi-001010000000001", "servingPlmnId": "00101"}},
+	}
+}
+
+func exportBackup(t *testing.T, router *gin.Engine, format string) string {
+	req, err := http.NewRequest(http.MethodGet, "/config/v1/export?format="+format, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected export to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	return w.Body.String()
+}
+
+func TestExportImportConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	origAuthDB := dbadapter.AuthDBClient
+	origCommonDB := dbadapter.CommonDBClient
+	origChannel := configChannel
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.CommonDBClient = origCommonDB
+		configChannel = origChannel
+	}()
+
+	testCases := []struct {
+		name              string
+		format            string
+		mode              string
+		currentDocs       map[string][]map[string]interface{}
+		expectedCode      int
+		expectedApplied   bool
+		expectedCleared   int
+		expectedPosted    map[string]int
+		expectedPut       map[string]int
+		expectedMsgs      int
+		expectedDeleted   map[string]int
+		expectedCreated   map[string]int
+		expectedUpdated   map[string]int
+		expectedBodyMatch string
+	}{
+		{
+			name:            "replace from JSON lines into empty database",
+			format:          backupFormatJSONL,
+			mode:            configmodels.ImportModeReplace,
+			currentDocs:     map[string][]map[string]interface{}{},
+			expectedCode:    http.StatusOK,
+			expectedApplied: true,
+			expectedCleared: len(backupCollections),
+			expectedPosted:  map[string]int{configmodels.GnbDataColl: 1, devGroupDataColl: 2, sliceDataColl: 1, authSubsDataColl: 1, amDataColl: 1},
+			expectedMsgs:    1,
+			expectedCreated: map[string]int{sliceDataColl: 1, authSubsDataColl: 1},
+		},
+		{
+			name:            "replace from tar.gz deletes documents missing from the backup",
+			format:          backupFormatTarGzip,
+			mode:            configmodels.ImportModeReplace,
+			currentDocs:     map[string][]map[string]interface{}{sliceDataColl: {configmodels.ToBsonM(networkSlice("slice2"))}},
+			expectedCode:    http.StatusOK,
+			expectedApplied: true,
+			expectedCleared: len(backupCollections),
+			expectedPosted:  map[string]int{sliceDataColl: 1},
+			expectedMsgs:    1,
+			expectedDeleted: map[string]int{sliceDataColl: 1},
+		},
+		{
+			name:            "merge upserts documents",
+			format:          backupFormatJSONL,
+			mode:            configmodels.ImportModeMerge,
+			currentDocs:     backupTestDocs(),
+			expectedCode:    http.StatusOK,
+			expectedApplied: true,
+			expectedPut:     map[string]int{sliceDataColl: 1, authSubsDataColl: 1, amDataColl: 1},
+			expectedMsgs:    1,
+			expectedUpdated: map[string]int{sliceDataColl: 1, configmodels.GnbDataColl: 1},
+		},
+		{
+			name:            "dry run does not write",
+			format:          backupFormatJSONL,
+			mode:            configmodels.ImportModeDryRun,
+			currentDocs:     map[string][]map[string]interface{}{},
+			expectedCode:    http.StatusOK,
+			expectedCreated: map[string]int{devGroupDataColl: 2},
+		},
+		{
+			name:              "unknown import mode",
+			format:            backupFormatJSONL,
+			mode:              "overwrite",
+			currentDocs:       map[string][]map[string]interface{}{},
+			expectedCode:      http.StatusBadRequest,
+			expectedBodyMatch: "unsupported import mode",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := newMockMongoClientBackup(backupTestDocs())
+			dbadapter.AuthDBClient = source
+			dbadapter.CommonDBClient = source
+			backup := exportBackup(t, router, tc.format)
+
+			target := newMockMongoClientBackup(tc.currentDocs)
+			dbadapter.AuthDBClient = target
+			dbadapter.CommonDBClient = target
+			configChannel = make(chan *configmodels.ConfigMessage, 10)
+
+			req, err := http.NewRequest(http.MethodPost, "/config/v1/import?mode="+tc.mode, strings.NewReader(backup))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBodyMatch) {
+				t.Errorf("expected body to contain %q, got %s", tc.expectedBodyMatch, w.Body.String())
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			var report configmodels.ImportReport
+			if err = json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			if report.Applied != tc.expectedApplied {
+				t.Errorf("expected applied %v, got %v", tc.expectedApplied, report.Applied)
+			}
+			for coll, count := range tc.expectedCreated {
+				if report.Collections[coll].Created != count {
+					t.Errorf("expected %d created in %s, got %+v", count, coll, report.Collections[coll])
+				}
+			}
+			for coll, count := range tc.expectedUpdated {
+				if report.Collections[coll].Updated != count {
+					t.Errorf("expected %d updated in %s, got %+v", count, coll, report.Collections[coll])
+				}
+			}
+			for coll, count := range tc.expectedDeleted {
+				if report.Collections[coll].Deleted != count {
+					t.Errorf("expected %d deleted in %s, got %+v", count, coll, report.Collections[coll])
+				}
+			}
+			if len(target.clearedColls) != tc.expectedCleared {
+				t.Errorf("expected %d cleared collections, got %v", tc.expectedCleared, target.clearedColls)
+			}
+			for coll, count := range tc.expectedPosted {
+				if target.postedDocs[coll] != count {
+					t.Errorf("expected %d documents restored in %s, got %d", count, coll, target.postedDocs[coll])
+				}
+			}
+			for coll, count := range tc.expectedPut {
+				if target.putDocs[coll] != count {
+					t.Errorf("expected %d documents upserted in %s, got %d", count, coll, target.putDocs[coll])
+				}
+			}
+			if len(configChannel) != tc.expectedMsgs {
+				t.Fatalf("expected %d config messages, got %d", tc.expectedMsgs, len(configChannel))
+			}
+			if !tc.expectedApplied {
+				return
+			}
+			// the network functions are synchronized once for the whole import
+			msg := <-configChannel
+			if msg.MsgType != configmodels.Config_sync || len(msg.DevGroups) != 2 || len(msg.Slices) != 1 {
+				t.Errorf("expected the 2 device groups and the network slice in a single message, got %+v", msg)
+			}
+			if len(msg.DeletedSliceNames) != tc.expectedDeleted[sliceDataColl] {
+				t.Errorf("expected %d deleted network slices, got %v", tc.expectedDeleted[sliceDataColl], msg.DeletedSliceNames)
+			}
+		})
+	}
+}
+
+func TestImportConfig_InvalidBackup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	origAuthDB := dbadapter.AuthDBClient
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.CommonDBClient = origCommonDB
+	}()
+	header := `{"format":"webconsole-backup","version":1,"created_at":"2025-01-01T00:00:00Z"}` + "\n"
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "missing header",
+			body:         `{"collection":"webconsoleData.snapshots.sliceData","document":{"slice-name":"slice1"}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "unsupported backup format",
+		},
+		{
+			name:         "unsupported version",
+			body:         `{"format":"webconsole-backup","version":2}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "unsupported backup version 2",
+		},
+		{
+			name:         "unknown collection",
+			body:         header + `{"collection":"other","document":{"name":"x"}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "unknown collection",
+		},
+		{
+			name:         "document without key",
+			body:         header + `{"collection":"` + sliceDataColl + `","document":{"site-device-group":[]}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "missing key slice-name",
+		},
+		{
+			name:         "duplicate document",
+			body:         header + `{"collection":"` + configmodels.UpfDataColl + `","document":{"hostname":"upf1"}}` + "\n" + `{"collection":"` + configmodels.UpfDataColl + `","document":{"hostname":"upf1"}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "duplicate document upf1",
+		},
+		{
+			name:         "slice refers to missing device group",
+			body:         header + `{"collection":"` + sliceDataColl + `","document":{"slice-name":"slice1","site-device-group":["group9"]}}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `"references":[{"kind":"device-group","name":"group9","message":"network slice slice1 refers to unknown device group group9"}]`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbClient := newMockMongoClientBackup(map[string][]map[string]interface{}{})
+			dbadapter.AuthDBClient = dbClient
+			dbadapter.CommonDBClient = dbClient
+			req, err := http.NewRequest(http.MethodPost, "/config/v1/import?mode=replace", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("expected body to contain %q, got %s", tc.expectedBody, w.Body.String())
+			}
+			if len(dbClient.clearedColls) != 0 || len(dbClient.postedDocs) != 0 {
+				t.Errorf("expected no write, got cleared %v posted %v", dbClient.clearedColls, dbClient.postedDocs)
+			}
+		})
+	}
+}
+
+func TestImportConfig_TooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	origAuthDB := dbadapter.AuthDBClient
+	origCommonDB := dbadapter.CommonDBClient
+	origMaxImportSize := factory.WebUIConfig.Configuration.MaxImportSize
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.CommonDBClient = origCommonDB
+		factory.WebUIConfig.Configuration.MaxImportSize = origMaxImportSize
+	}()
+	source := newMockMongoClientBackup(backupTestDocs())
+	dbadapter.AuthDBClient = source
+	dbadapter.CommonDBClient = source
+	backup := exportBackup(t, router, backupFormatJSONL)
+	factory.WebUIConfig.Configuration.MaxImportSize = int64(len(backup) / 2)
+
+	target := newMockMongoClientBackup(map[string][]map[string]interface{}{})
+	dbadapter.AuthDBClient = target
+	dbadapter.CommonDBClient = target
+	req, err := http.NewRequest(http.MethodPost, "/config/v1/import?mode=replace", strings.NewReader(backup))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+	}
+	if len(target.clearedColls) != 0 || len(target.postedDocs) != 0 {
+		t.Errorf("expected no write, got cleared %v posted %v", target.clearedColls, target.postedDocs)
+	}
+}
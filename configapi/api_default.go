@@ -20,7 +20,6 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/omec-project/webconsole/backend/logger"
 	"github.com/omec-project/webconsole/configmodels"
 	"github.com/omec-project/webconsole/dbadapter"
@@ -73,6 +72,7 @@ func GetDeviceGroups(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Success      200  {object}  configmodels.DeviceGroups  "Device group"
+// @Header       200  {string}  ETag  "Version of the resource"
 // @Failure      401  {object}  nil                        "Authorization failed"
 // @Failure      403  {object}  nil                        "Forbidden"
 // @Failure      404  {object}  nil                        "Device group not found"
@@ -80,6 +80,7 @@ func GetDeviceGroups(c *gin.Context) {
 // @Router       /config/v1/device-group/{deviceGroupName}  [get]
 func GetDeviceGroupByName(c *gin.Context) {
 	setCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("Get Device Group by name")
 
 	var deviceGroup configmodels.DeviceGroups
@@ -91,12 +92,13 @@ func GetDeviceGroupByName(c *gin.Context) {
 	err := json.Unmarshal(configmodels.MapToByte(rawDeviceGroup), &deviceGroup)
 	if err != nil {
 		logger.WebUILog.Errorf("failed to unmarshal device group error: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve device group"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve device group", requestID)
 		return
 	}
 	if deviceGroup.DeviceGroupName == "" {
-		c.JSON(http.StatusNotFound, nil)
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("device group %s not found", c.Param("group-name")), requestID)
 	} else {
+		setETag(c, rawDeviceGroup)
 		c.JSON(http.StatusOK, deviceGroup)
 	}
 }
@@ -106,33 +108,46 @@ func GetDeviceGroupByName(c *gin.Context) {
 // @Description  Delete an existing device group
 // @Tags         Device Groups
 // @Param        deviceGroupName    path    string    true    " "
+// @Param        cascade            query   bool      false   "Remove the device group from the network slices referring to it"
+// @Param        If-Match  header  string  false  "Version the resource must be at"
 // @Security     BearerAuth
 // @Success      200  {object}  nil  "Device group deleted successfully"
 // @Failure      400  {object}  nil  "Bad request"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      409  {object}  nil  "Device group referred to by network slices"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure      500  {object}  nil  "Device Group Deletion Failed"
 // @Router       /config/v1/device-group/{deviceGroupName}  [delete]
 func DeviceGroupGroupNameDelete(c *gin.Context) {
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	logger.WebUILog.Debugln("DeviceGroupGroupNameDelete")
 	groupName, ok := c.Params.Get("group-name")
 	if !ok {
 		logger.ConfigLog.Errorf("group-name parameter is missing in the request: %s", requestID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "group-name parameter is missing",
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "group-name parameter is missing", requestID)
 		return
 	}
+	cascade, err := parseCascadeQuery(c)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	if err = setIfMatch(c, devGroupDataColl, bson.M{"group-name": groupName}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	if !cascade {
+		if err = checkNotReferenced("device group", groupName, bson.M{"site-device-group": groupName}); err != nil {
+			logger.WebUILog.Warnf("Request ID: %s Device group delete rejected: %+v", requestID, err)
+			writeErrorProblem(c, http.StatusInternalServerError, fmt.Errorf("failed to delete device group %s: %w", groupName, err), requestID)
+			return
+		}
+	}
 	logger.WebUILog.Debugf("Request ID: %s Attempting to delete device group: %s", requestID, groupName)
-	if err := deviceGroupDeleteHelper(groupName); err != nil {
+	if err := deviceGroupDeleteHelper(c.Request.Context(), groupName); err != nil {
 		logger.WebUILog.Errorf("Request ID: %s Device group delete failed: %+v", requestID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      fmt.Sprintf("Failed to delete device group %s with error: %+v.", groupName, err),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details.",
-		})
+		writeErrorProblem(c, http.StatusInternalServerError, fmt.Errorf("failed to delete device group %s: %w", groupName, err), requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
@@ -140,26 +155,21 @@ func DeviceGroupGroupNameDelete(c *gin.Context) {
 
 // DeviceGroupGroupNamePut -
 func DeviceGroupGroupNamePut(c *gin.Context) {
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	logger.WebUILog.Debugln("DeviceGroupGroupNamePut")
 	groupName, ok := c.Params.Get("group-name")
 	if !ok {
 		logger.ConfigLog.Errorf("group-name parameter is missing in the request: %s", requestID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "group-name parameter is missing",
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "group-name parameter is missing", requestID)
 		return
 	}
 	if !isValidName(groupName) {
 		logger.ConfigLog.Errorf("Request ID: %s invalid Device Group name %s. Name needs to match regular expression: %s", requestID, groupName, NAME_PATTERN)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf(
-				"Invalid Device Group name %s. Name needs to match regular expression: %s",
-				groupName, NAME_PATTERN,
-			),
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Invalid Device Group name %s. Name needs to match regular expression: %s", groupName, NAME_PATTERN), requestID)
+		return
+	}
+	if err := setIfMatch(c, devGroupDataColl, bson.M{"group-name": groupName}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 	var requestDeviceGroup configmodels.DeviceGroups
@@ -168,7 +178,7 @@ func DeviceGroupGroupNamePut(c *gin.Context) {
 	if ct == "" {
 		err := "missing Content-Type header"
 		logger.ConfigLog.Errorln(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeUnsupportedMediaType, err, requestID)
 		return
 	}
 
@@ -176,24 +186,20 @@ func DeviceGroupGroupNamePut(c *gin.Context) {
 	if ct != "application/json" {
 		err := fmt.Sprintf("unsupported content-type: %s", ct)
 		logger.ConfigLog.Errorln(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeUnsupportedMediaType, err, requestID)
 		return
 	}
 
 	if err := c.ShouldBindJSON(&requestDeviceGroup); err != nil {
 		err = fmt.Errorf("JSON bind error: %w", err)
 		logger.ConfigLog.Errorln(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 
-	if statusCode, err := deviceGroupPostHelper(requestDeviceGroup, configmodels.Put_op, groupName); err != nil {
+	if statusCode, err := deviceGroupPostHelper(c.Request.Context(), requestDeviceGroup, configmodels.Put_op, groupName); err != nil {
 		logger.WebUILog.Errorf("Device group update failed: %+v", err)
-		c.JSON(statusCode, gin.H{
-			"error":      fmt.Sprintf("Failed to update device group %s with error: %+v.", groupName, err),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details.",
-		})
+		writeErrorProblem(c, statusCode, fmt.Errorf("failed to update device group %s: %w", groupName, err), requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
@@ -210,30 +216,21 @@ func DeviceGroupGroupNamePut(c *gin.Context) {
 // @Failure      400  {object}  nil  "Invalid device group content"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      409  {object}  nil  "Device group already exists"
 // @Failure      500  {object}  nil  "Error creating device group"
 // @Router       /config/v1/device-group/{deviceGroupName}  [post]
 func DeviceGroupGroupNamePost(c *gin.Context) {
-	// TODO: Return 409 if device group already exists
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	logger.WebUILog.Debugln("DeviceGroupGroupNamePost")
 	groupName, ok := c.Params.Get("group-name")
 	if !ok {
 		logger.ConfigLog.Errorf("group-name parameter is missing in the request: %s", requestID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "group-name parameter is missing",
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "group-name parameter is missing", requestID)
 		return
 	}
 	if !isValidName(groupName) {
 		logger.ConfigLog.Errorf("invalid Device Group name %s. Name needs to match regular expression: %s", groupName, NAME_PATTERN)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf(
-				"Invalid Device Group name %s. Name needs to match regular expression: %s",
-				groupName, NAME_PATTERN,
-			),
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Invalid Device Group name %s. Name needs to match regular expression: %s", groupName, NAME_PATTERN), requestID)
 		return
 	}
 	var requestDeviceGroup configmodels.DeviceGroups
@@ -242,7 +239,7 @@ func DeviceGroupGroupNamePost(c *gin.Context) {
 	if ct == "" {
 		err := "missing Content-Type header"
 		logger.ConfigLog.Errorln(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeUnsupportedMediaType, err, requestID)
 		return
 	}
 
@@ -250,24 +247,20 @@ func DeviceGroupGroupNamePost(c *gin.Context) {
 	if ct != "application/json" {
 		err := fmt.Sprintf("unsupported content-type: %s", ct)
 		logger.ConfigLog.Errorln(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeUnsupportedMediaType, err, requestID)
 		return
 	}
 
 	if err := c.ShouldBindJSON(&requestDeviceGroup); err != nil {
 		err = fmt.Errorf("JSON bind error: %w", err)
 		logger.ConfigLog.Errorln(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 
-	if statusCode, err := deviceGroupPostHelper(requestDeviceGroup, configmodels.Post_op, groupName); err != nil {
+	if statusCode, err := deviceGroupPostHelper(c.Request.Context(), requestDeviceGroup, configmodels.Post_op, groupName); err != nil {
 		logger.WebUILog.Errorf("Device group create failed: %+v", err)
-		c.JSON(statusCode, gin.H{
-			"error":      fmt.Sprintf("Failed to create device group %s with error: %+v.", groupName, err),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details",
-		})
+		writeErrorProblem(c, statusCode, fmt.Errorf("failed to create device group %s: %w", groupName, err), requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
@@ -286,13 +279,14 @@ func DeviceGroupGroupNamePost(c *gin.Context) {
 // @Router       /config/v1/network-slice/  [get]
 func GetNetworkSlices(c *gin.Context) {
 	setCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("Get all Network Slices")
 	networkSlices := make([]string, 0)
 
 	rawNetworkSlices, errGetMany := dbadapter.CommonDBClient.RestfulAPIGetMany(sliceDataColl, bson.M{})
 	if errGetMany != nil {
 		logger.DbLog.Errorln(errGetMany)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch slices"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to fetch slices", requestID)
 		return
 	}
 
@@ -315,6 +309,7 @@ func GetNetworkSlices(c *gin.Context) {
 // @Param        sliceName    path    string    true    " "
 // @Security     BearerAuth
 // @Success      200  {object}  configmodels.Slice  "Network slice"
+// @Header       200  {string}  ETag  "Version of the resource"
 // @Failure      401  {object}  nil                 "Authorization failed"
 // @Failure      403  {object}  nil                 "Forbidden"
 // @Failure      404  {object}  nil                 "Network slices not found"
@@ -322,6 +317,7 @@ func GetNetworkSlices(c *gin.Context) {
 // @Router       /config/v1/network-slice/{sliceName}  [get]
 func GetNetworkSliceByName(c *gin.Context) {
 	setCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("Get Network Slice by name")
 	var networkSlice configmodels.Slice
 	filter := bson.M{"slice-name": c.Param("slice-name")}
@@ -332,12 +328,13 @@ func GetNetworkSliceByName(c *gin.Context) {
 	err := json.Unmarshal(configmodels.MapToByte(rawNetworkSlice), &networkSlice)
 	if err != nil {
 		logger.WebUILog.Errorf("failed to unmarshal network slice error: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve network slice"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve network slice", requestID)
 		return
 	}
 	if networkSlice.SliceName == "" {
-		c.JSON(http.StatusNotFound, nil)
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("network slice %s not found", c.Param("slice-name")), requestID)
 	} else {
+		setETag(c, rawNetworkSlice)
 		c.JSON(http.StatusOK, networkSlice)
 	}
 }
@@ -348,43 +345,36 @@ func GetNetworkSliceByName(c *gin.Context) {
 // @Tags         Network Slices
 // @Produce      json
 // @Param        sliceName    path    string    true    " "
+// @Param        If-Match  header  string  false  "Version the resource must be at"
 // @Security     BearerAuth
 // @Success      202  {object}  nil  "Network slice deleted successfully"
 // @Failure      400  {object}  nil  "Invalid network slice name provided"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure      500  {object}  nil  "Error deleting network slice"
 // @Router      /config/v1/network-slice/{sliceName}  [delete]
 func NetworkSliceSliceNameDelete(c *gin.Context) {
 	logger.WebUILog.Debugln("Received NetworkSliceSliceNameDelete")
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	sliceName, ok := c.Params.Get("slice-name")
 	if !ok {
 		logger.ConfigLog.Errorf("slice-name parameter is missing in the request: %s", requestID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "slice-name parameter is missing",
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "slice-name parameter is missing", requestID)
 		return
 	}
 	if !isValidName(sliceName) {
 		logger.ConfigLog.Errorf("invalid Network Slice name %s. Name needs to match regular expression: %s", sliceName, NAME_PATTERN)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf(
-				"Invalid slice name %s. Name needs to match regular expression: %s",
-				sliceName, NAME_PATTERN,
-			),
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Invalid slice name %s. Name needs to match regular expression: %s", sliceName, NAME_PATTERN), requestID)
+		return
+	}
+	if err := setIfMatch(c, sliceDataColl, bson.M{"slice-name": sliceName}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
-	if err := networkSliceDeleteHelper(sliceName); err != nil {
+	if err := networkSliceDeleteHelper(c.Request.Context(), sliceName); err != nil {
 		logger.WebUILog.Errorf("Network slice delete failed: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      fmt.Sprintf("Failed to delete network slice %s with error: %+v.", sliceName, err),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details",
-		})
+		writeErrorProblem(c, http.StatusInternalServerError, fmt.Errorf("failed to delete network slice %s: %w", sliceName, err), requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
@@ -401,39 +391,27 @@ func NetworkSliceSliceNameDelete(c *gin.Context) {
 // @Failure      400  {object}  nil  "Invalid network slice content"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      409  {object}  nil  "Network slice already exists"
+// @Failure      422  {object}  nil  "Network slice refers to missing or inconsistent objects"
 // @Failure      500  {object}  nil  "Error creating network slice"
 // @Router       /config/v1/network-slice/{sliceName}  [post]
 func NetworkSliceSliceNamePost(c *gin.Context) {
-	// TODO: Return 409 if network slices already exist
 	logger.ConfigLog.Debugln("Received NetworkSliceSliceNamePost")
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	sliceName, ok := c.Params.Get("slice-name")
 	if !ok {
 		logger.ConfigLog.Errorf("slice-name parameter is missing in the request: %s", requestID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "slice-name parameter is missing",
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "slice-name parameter is missing", requestID)
 		return
 	}
 	if !isValidName(sliceName) {
 		logger.ConfigLog.Errorf("invalid Network Slice name %s. Name needs to match regular expression: %s", sliceName, NAME_PATTERN)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf(
-				"Invalid slice name %s. Name needs to match regular expression: %s",
-				sliceName, NAME_PATTERN,
-			),
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Invalid slice name %s. Name needs to match regular expression: %s", sliceName, NAME_PATTERN), requestID)
 		return
 	}
 	statusCode, err := networkSlicePostHelper(c, configmodels.Post_op, sliceName)
 	if err != nil {
-		c.JSON(statusCode, gin.H{
-			"error":      fmt.Sprintf("Failed to create network slice %s with error: %+v", sliceName, err),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details",
-		})
+		writeErrorProblem(c, statusCode, fmt.Errorf("failed to create network slice %s: %w", sliceName, err), requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
@@ -442,34 +420,25 @@ func NetworkSliceSliceNamePost(c *gin.Context) {
 // NetworkSliceSliceNamePut -
 func NetworkSliceSliceNamePut(c *gin.Context) {
 	logger.ConfigLog.Debugln("Received NetworkSliceSliceNamePut")
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	sliceName, ok := c.Params.Get("slice-name")
 	if !ok {
 		logger.ConfigLog.Errorf("slice-name parameter is missing in the request: %s", requestID)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "slice-name parameter is missing",
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "slice-name parameter is missing", requestID)
 		return
 	}
 	if !isValidName(sliceName) {
 		logger.ConfigLog.Errorf("invalid Network Slice name %s. Name needs to match regular expression: %s", sliceName, NAME_PATTERN)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf(
-				"Invalid slice name %s. Name needs to match regular expression: %s",
-				sliceName, NAME_PATTERN,
-			),
-			"request_id": requestID,
-		})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Invalid slice name %s. Name needs to match regular expression: %s", sliceName, NAME_PATTERN), requestID)
+		return
+	}
+	if err := setIfMatch(c, sliceDataColl, bson.M{"slice-name": sliceName}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 	statusCode, err := networkSlicePostHelper(c, configmodels.Put_op, sliceName)
 	if err != nil {
-		c.JSON(statusCode, gin.H{
-			"error":      fmt.Sprintf("Failed to update network slice %s with error: %+v.", sliceName, err),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details",
-		})
+		writeErrorProblem(c, statusCode, fmt.Errorf("failed to update network slice %s: %w", sliceName, err), requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
@@ -6,6 +6,8 @@
 package configapi
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -59,6 +61,10 @@ type MockMongoClientManyNetworkSlices struct {
 	dbadapter.DBInterface
 }
 
+func (m *MockMongoClientManyNetworkSlices) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	return true, nil
+}
+
 func (m *MockMongoClientManyNetworkSlices) RestfulAPIGetOne(coll string, filter bson.M) (map[string]any, error) {
 	if sliceName, ok := filter["slice-name"].(string); ok {
 		ns := configmodels.ToBsonM(networkSlice(sliceName))
@@ -95,6 +101,14 @@ func (m *MockMongoClientManyNetworkSlices) Client() *mongo.Client {
 	return nil
 }
 
+func (m *MockMongoClientManyNetworkSlices) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (m *MockMongoClientManyNetworkSlices) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 func (m *MockMongoClientNoDeviceGroups) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]any, error) {
 	var results []map[string]any
 	return results, nil
@@ -274,9 +288,12 @@ func TestGetDeviceGroupByNameDoesNotExist(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to read response body: %v", err)
 	}
-	body := string(body_bytes)
-	if body != "null" {
-		t.Errorf("Expected %v, got %v", "null", body)
+	var problem configmodels.ProblemDetails
+	if err = json.Unmarshal(body_bytes, &problem); err != nil {
+		t.Fatalf("failed to unmarshal problem details: %v", err)
+	}
+	if problem.Status != http.StatusNotFound || problem.Code != configmodels.ProblemCodeNotFound || problem.RequestID == "" {
+		t.Errorf("Expected a not-found problem with a request ID, got %+v", problem)
 	}
 }
 
@@ -316,8 +333,8 @@ func TestDeviceGroupDeleteHandler_DeviceGroupExistsInNetworkSlices(t *testing.T)
 		expectedCode int
 	}{
 		{
-			name:         "Delete DG associated with NSs expects config messages sent for NSs and DG",
-			route:        "/config/v1/device-group/group1",
+			name:         "Cascade delete DG associated with NSs expects config messages sent for NSs and DG",
+			route:        "/config/v1/device-group/group1?cascade=true",
 			dbAdapter:    mock,
 			expectedCode: http.StatusOK,
 		},
@@ -575,9 +592,12 @@ func TestGetNetworkSliceByNameDoesNotExist(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to read response body: %v", err)
 	}
-	body := string(body_bytes)
-	if body != "null" {
-		t.Errorf("Expected %v, got %v", "null", body)
+	var problem configmodels.ProblemDetails
+	if err = json.Unmarshal(body_bytes, &problem); err != nil {
+		t.Fatalf("failed to unmarshal problem details: %v", err)
+	}
+	if problem.Status != http.StatusNotFound || problem.Code != configmodels.ProblemCodeNotFound || problem.RequestID == "" {
+		t.Errorf("Expected a not-found problem with a request ID, got %+v", problem)
 	}
 }
 
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+const configEventKeepAlive = 30 * time.Second
+
+// parseLastEventID returns the revision to resume the event stream from, taken
+// from the Last-Event-ID header or, for clients that cannot set it, the since
+// query parameter. resume is false when the stream starts with the next event.
+func parseLastEventID(c *gin.Context) (revision int64, resume bool, err error) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("since")
+	}
+	if lastEventID == "" {
+		return 0, false, nil
+	}
+	revision, err = strconv.ParseInt(lastEventID, 10, 64)
+	if err != nil || revision < 0 {
+		return 0, false, fmt.Errorf("last event ID must be a non-negative integer")
+	}
+	return revision, true, nil
+}
+
+func writeConfigEvent(w io.Writer, event configmodels.ConfigEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", event.Revision, data)
+	return err
+}
+
+// GetConfigEvents godoc
+//
+// @Description  Stream the configuration changes as Server-Sent Events. Every event carries its revision as the event ID; reconnecting with the Last-Event-ID header (or the since query parameter) replays the events missed since that revision
+// @Tags         Events
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header  int  false  "Revision of the last event received"
+// @Param        since          query   int  false  "Revision of the last event received, when the header cannot be set"
+// @Security     BearerAuth
+// @Success      200  {object}  configmodels.ConfigEvent  "Stream of configuration events"
+// @Failure      400  {object}  nil                       "Invalid last event ID"
+// @Failure      401  {object}  nil                       "Authorization failed"
+// @Failure      403  {object}  nil                       "Forbidden"
+// @Failure      500  {object}  nil                       "Error retrieving the missed events"
+// @Router       /config/v1/events  [get]
+func GetConfigEvents(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a GET config events request")
+	lastRevision, resume, err := parseLastEventID(c)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	// subscribe before replaying the stored events so that none is missed in between
+	subscriber := configEvents.subscribe()
+	defer configEvents.unsubscribe(subscriber)
+
+	var missed []configmodels.ConfigEvent
+	if resume {
+		missed, err = getConfigEventsSince(lastRevision, configEventPageSize)
+		if err != nil {
+			logger.DbLog.Errorf("failed to retrieve config events: %+v request ID: %s", err, requestID)
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve config events", requestID)
+			return
+		}
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	for len(missed) > 0 {
+		for _, event := range missed {
+			if err = writeConfigEvent(c.Writer, event); err != nil {
+				return
+			}
+			lastRevision = event.Revision
+		}
+		c.Writer.Flush()
+		if len(missed) < configEventPageSize {
+			break
+		}
+		missed, err = getConfigEventsSince(lastRevision, configEventPageSize)
+		if err != nil {
+			logger.DbLog.Errorf("failed to retrieve config events: %+v request ID: %s", err, requestID)
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(configEventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscriber:
+			if !ok {
+				logger.WebUILog.Warnf("config event stream closed at revision %d request ID: %s", lastRevision, requestID)
+				return
+			}
+			if event.Revision <= lastRevision {
+				continue
+			}
+			if err = writeConfigEvent(c.Writer, event); err != nil {
+				return
+			}
+			lastRevision = event.Revision
+			c.Writer.Flush()
+		case <-keepAlive.C:
+			if _, err = io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockMongoClientEvents struct {
+	dbadapter.DBInterface
+	mu      sync.Mutex
+	events  []bson.M
+	counter int64
+}
+
+func (m *MockMongoClientEvents) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, data := range postDataArray {
+		for _, event := range m.events {
+			if event["revision"] == data.(bson.M)["revision"] {
+				return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+			}
+		}
+		m.events = append(m.events, data.(bson.M))
+	}
+	return nil
+}
+
+func (m *MockMongoClientEvents) RestfulAPIFindOneAndUpdateWithContext(ctx context.Context, collName string, filter bson.M, update bson.M) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if increment, ok := update["$inc"].(bson.M); ok {
+		m.counter += increment["revision"].(int64)
+	}
+	if maximum, ok := update["$max"].(bson.M); ok {
+		m.counter = max(m.counter, maximum["revision"].(int64))
+	}
+	return map[string]interface{}{"name": filter["name"], "revision": m.counter}, nil
+}
+
+func (m *MockMongoClientEvents) RestfulAPIGetManyPaged(collName string, filter bson.M, sortBy bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	after := float64(-1)
+	if revisionFilter, ok := filter["revision"].(bson.M); ok {
+		after = float64(revisionFilter["$gt"].(int64))
+	}
+	var results []map[string]interface{}
+	for _, event := range m.events {
+		if event["revision"].(float64) > after {
+			results = append(results, event)
+		}
+	}
+	descending := sortBy[0].Value == -1
+	sort.Slice(results, func(i, j int) bool {
+		return (results[i]["revision"].(float64) > results[j]["revision"].(float64)) == descending
+	})
+	if limit > 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func configEventMessages() []*configmodels.ConfigMessage {
+	return []*configmodels.ConfigMessage{
+		{MsgType: configmodels.Device_group, MsgMethod: configmodels.Post_op, DevGroupName: "group1"},
+		{MsgType: configmodels.Network_slice, MsgMethod: configmodels.Put_op, Slice: &configmodels.Slice{SliceName: "slice1"}},
+		{MsgType: configmodels.Sub_data, MsgMethod: configmodels.Delete_op, Imsi: "imsi-001010000000001"},
+	}
+}
+
+func TestPublishConfigEvent(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	origEvents := configEvents
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		configEvents = origEvents
+	}()
+	dbClient := &MockMongoClientEvents{}
+	dbadapter.CommonDBClient = dbClient
+	configEvents = newConfigEventBroker()
+	subscriber := configEvents.subscribe()
+
+	for _, msg := range configEventMessages() {
+		PublishConfigEvent(msg)
+	}
+	PublishConfigEvent(&configmodels.ConfigMessage{MsgType: 42, MsgMethod: configmodels.Post_op})
+	// the subscribers created in bulk and the imports are sent in a single message
+	PublishConfigEvent(&configmodels.ConfigMessage{MsgType: configmodels.Sub_data, MsgMethod: configmodels.Post_op, Imsis: []string{"imsi-001010000000002", "imsi-001010000000003"}})
+	PublishConfigEvent(&configmodels.ConfigMessage{
+		MsgType:           configmodels.Config_sync,
+		MsgMethod:         configmodels.Put_op,
+		DevGroups:         []*configmodels.DeviceGroups{{DeviceGroupName: "group2"}},
+		DeletedSliceNames: []string{"slice2"},
+	})
+
+	expected := []configmodels.ConfigEvent{
+		{Revision: 1, Type: configmodels.ApplyKindDeviceGroup, Operation: configmodels.ApplyOpCreate, Name: "group1"},
+		{Revision: 2, Type: configmodels.ApplyKindNetworkSlice, Operation: configmodels.ApplyOpUpdate, Name: "slice1"},
+		{Revision: 3, Type: configmodels.ConfigEventTypeSubscriber, Operation: configmodels.ApplyOpDelete, Name: "imsi-001010000000001"},
+		{Revision: 4, Type: configmodels.ConfigEventTypeSubscriber, Operation: configmodels.ApplyOpCreate, Name: "imsi-001010000000002"},
+		{Revision: 5, Type: configmodels.ConfigEventTypeSubscriber, Operation: configmodels.ApplyOpCreate, Name: "imsi-001010000000003"},
+		{Revision: 6, Type: configmodels.ApplyKindDeviceGroup, Operation: configmodels.ApplyOpUpdate, Name: "group2"},
+		{Revision: 7, Type: configmodels.ApplyKindNetworkSlice, Operation: configmodels.ApplyOpDelete, Name: "slice2"},
+	}
+	if len(subscriber) != len(expected) {
+		t.Fatalf("expected %d events sent to the subscriber, got %d", len(expected), len(subscriber))
+	}
+	for _, expectedEvent := range expected {
+		event := <-subscriber
+		event.Timestamp = expectedEvent.Timestamp
+		if !reflect.DeepEqual(event, expectedEvent) {
+			t.Errorf("expected event %+v, got %+v", expectedEvent, event)
+		}
+	}
+	if len(dbClient.events) != len(expected) {
+		t.Fatalf("expected %d stored events, got %d", len(expected), len(dbClient.events))
+	}
+
+	// a restarted broker carries on from the last stored revision
+	configEvents = newConfigEventBroker()
+	subscriber = configEvents.subscribe()
+	PublishConfigEvent(configEventMessages()[0])
+	if event := <-subscriber; event.Revision != 8 {
+		t.Errorf("expected revision 8 after a restart, got %d", event.Revision)
+	}
+}
+
+func TestConfigEventBrokerReplicas(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = origCommonDB }()
+	dbClient := &MockMongoClientEvents{}
+	dbadapter.CommonDBClient = dbClient
+
+	// the replicas share the counter of the common database
+	replicas := []*configEventBroker{newConfigEventBroker(), newConfigEventBroker()}
+	for i, replica := range []int{0, 1, 0, 1} {
+		if err := replicas[replica].publish(configmodels.ConfigEvent{Name: "group1"}); err != nil {
+			t.Fatalf("failed to publish event: %v", err)
+		}
+		if revision := documentInt(dbClient.events[i], "revision"); revision != int64(i+1) {
+			t.Errorf("expected revision %d, got %d", i+1, revision)
+		}
+	}
+
+	// a counter lost behind the stored events catches up with them
+	dbClient.counter = 1
+	if err := replicas[0].publish(configmodels.ConfigEvent{Name: "group1"}); err != nil {
+		t.Fatalf("failed to publish event: %v", err)
+	}
+	if revision := documentInt(dbClient.events[4], "revision"); revision != 5 {
+		t.Errorf("expected revision 5, got %d", revision)
+	}
+}
+
+func TestQueueConfigEvent(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	origEvents := configEvents
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		configEvents = origEvents
+	}()
+	dbadapter.CommonDBClient = &MockMongoClientEvents{}
+	configEvents = newConfigEventBroker()
+	subscriber := configEvents.subscribe()
+	defer configEvents.unsubscribe(subscriber)
+
+	for _, msg := range configEventMessages() {
+		QueueConfigEvent(msg)
+	}
+	for revision := int64(1); revision <= int64(len(configEventMessages())); revision++ {
+		select {
+		case event := <-subscriber:
+			if event.Revision != revision {
+				t.Errorf("expected revision %d, got %d", revision, event.Revision)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for revision %d", revision)
+		}
+	}
+}
+
+func TestConfigEventBrokerDisconnectsSlowSubscriber(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	origEvents := configEvents
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		configEvents = origEvents
+	}()
+	dbadapter.CommonDBClient = &MockMongoClientEvents{}
+	configEvents = newConfigEventBroker()
+	subscriber := configEvents.subscribe()
+
+	for i := 0; i <= configEventSubscriberBuffer; i++ {
+		PublishConfigEvent(configEventMessages()[0])
+	}
+	received := 0
+	for range subscriber {
+		received++
+	}
+	if received != configEventSubscriberBuffer {
+		t.Errorf("expected %d events before the disconnection, got %d", configEventSubscriberBuffer, received)
+	}
+	configEvents.unsubscribe(subscriber)
+}
+
+func readConfigEvents(t *testing.T, scanner *bufio.Scanner, count int) []configmodels.ConfigEvent {
+	var events []configmodels.ConfigEvent
+	var id string
+	for len(events) < count && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			var event configmodels.ConfigEvent
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				t.Fatalf("failed to decode event %q: %v", line, err)
+			}
+			if id == "" {
+				t.Errorf("event %+v has no ID", event)
+			}
+			events = append(events, event)
+			id = ""
+		}
+	}
+	if len(events) != count {
+		t.Fatalf("expected %d events, got %d: %v", count, len(events), scanner.Err())
+	}
+	return events
+}
+
+func TestGetConfigEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	AddConfigV1Service(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	origCommonDB := dbadapter.CommonDBClient
+	origEvents := configEvents
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		configEvents = origEvents
+	}()
+
+	testCases := []struct {
+		name              string
+		lastEventID       string
+		query             string
+		expectedCode      int
+		expectedRevisions []int64
+	}{
+		{
+			name:              "resume from the Last-Event-ID header",
+			lastEventID:       "1",
+			expectedCode:      http.StatusOK,
+			expectedRevisions: []int64{2, 3, 4},
+		},
+		{
+			name:              "resume from the since parameter",
+			query:             "?since=0",
+			expectedCode:      http.StatusOK,
+			expectedRevisions: []int64{1, 2, 3, 4},
+		},
+		{
+			name:              "live events only",
+			expectedCode:      http.StatusOK,
+			expectedRevisions: []int64{4},
+		},
+		{
+			name:         "invalid last event ID",
+			lastEventID:  "latest",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbadapter.CommonDBClient = &MockMongoClientEvents{}
+			configEvents = newConfigEventBroker()
+			for _, msg := range configEventMessages() {
+				PublishConfigEvent(msg)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/config/v1/events"+tc.query, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			if tc.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("failed to send request: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.expectedCode {
+				t.Fatalf("expected %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+				t.Errorf("expected an event stream, got %s", contentType)
+			}
+			// the headers are only sent once the handler has subscribed
+			PublishConfigEvent(&configmodels.ConfigMessage{MsgType: configmodels.Network_slice, MsgMethod: configmodels.Delete_op, SliceName: "slice1"})
+
+			events := readConfigEvents(t, bufio.NewScanner(resp.Body), len(tc.expectedRevisions))
+			var revisions []int64
+			for _, event := range events {
+				revisions = append(revisions, event.Revision)
+			}
+			if !reflect.DeepEqual(revisions, tc.expectedRevisions) {
+				t.Errorf("expected revisions %v, got %v", tc.expectedRevisions, revisions)
+			}
+			if last := events[len(events)-1]; last.Type != configmodels.ApplyKindNetworkSlice || last.Operation != configmodels.ApplyOpDelete || last.Name != "slice1" {
+				t.Errorf("unexpected live event %+v", last)
+			}
+		})
+	}
+}
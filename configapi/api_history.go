@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+// GetNetworkSliceHistory godoc
+//
+// @Description  Return the revisions of a network slice, newest first
+// @Tags         Network Slices
+// @Produce      json
+// @Param        slice-name    path    string    true    "Name of the network slice"
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.ConfigRevision  "Revisions of the network slice"
+// @Failure      401  {object}  nil                          "Authorization failed"
+// @Failure      403  {object}  nil                          "Forbidden"
+// @Failure      500  {object}  nil                          "Error retrieving the revisions"
+// @Router       /config/v1/network-slice/{slice-name}/history  [get]
+func GetNetworkSliceHistory(c *gin.Context) {
+	getConfigRevisionHistory(c, configmodels.ApplyKindNetworkSlice, c.Param("slice-name"))
+}
+
+// RollbackNetworkSlice godoc
+//
+// @Description  Re-apply a revision of a network slice. Rolling back to a deletion deletes the network slice
+// @Tags         Network Slices
+// @Produce      json
+// @Param        slice-name    path    string    true    "Name of the network slice"
+// @Param        revision      path    integer   true    "Revision to roll back to"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Network slice rolled back"
+// @Failure      400  {object}  nil  "Invalid revision"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Revision not found"
+// @Failure      500  {object}  nil  "Error rolling back the network slice"
+// @Router       /config/v1/network-slice/{slice-name}/rollback/{revision}  [post]
+func RollbackNetworkSlice(c *gin.Context) {
+	rollbackConfigRevision(c, configmodels.ApplyKindNetworkSlice, c.Param("slice-name"), rollbackNetworkSlice)
+}
+
+// GetDeviceGroupHistory godoc
+//
+// @Description  Return the revisions of a device group, newest first
+// @Tags         Device Groups
+// @Produce      json
+// @Param        group-name    path    string    true    "Name of the device group"
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.ConfigRevision  "Revisions of the device group"
+// @Failure      401  {object}  nil                          "Authorization failed"
+// @Failure      403  {object}  nil                          "Forbidden"
+// @Failure      500  {object}  nil                          "Error retrieving the revisions"
+// @Router       /config/v1/device-group/{group-name}/history  [get]
+func GetDeviceGroupHistory(c *gin.Context) {
+	getConfigRevisionHistory(c, configmodels.ApplyKindDeviceGroup, c.Param("group-name"))
+}
+
+// RollbackDeviceGroup godoc
+//
+// @Description  Re-apply a revision of a device group. Rolling back to a deletion deletes the device group
+// @Tags         Device Groups
+// @Produce      json
+// @Param        group-name    path    string    true    "Name of the device group"
+// @Param        revision      path    integer   true    "Revision to roll back to"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Device group rolled back"
+// @Failure      400  {object}  nil  "Invalid revision"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Revision not found"
+// @Failure      500  {object}  nil  "Error rolling back the device group"
+// @Router       /config/v1/device-group/{group-name}/rollback/{revision}  [post]
+func RollbackDeviceGroup(c *gin.Context) {
+	rollbackConfigRevision(c, configmodels.ApplyKindDeviceGroup, c.Param("group-name"), rollbackDeviceGroup)
+}
+
+// GetGnbHistory godoc
+//
+// @Description  Return the revisions of a gNB, newest first
+// @Tags         gNBs
+// @Produce      json
+// @Param        gnb-name    path    string    true    "Name of the gNB"
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.ConfigRevision  "Revisions of the gNB"
+// @Failure      401  {object}  nil                          "Authorization failed"
+// @Failure      403  {object}  nil                          "Forbidden"
+// @Failure      500  {object}  nil                          "Error retrieving the revisions"
+// @Router       /config/v1/inventory/gnb/{gnb-name}/history  [get]
+func GetGnbHistory(c *gin.Context) {
+	getConfigRevisionHistory(c, configmodels.ApplyKindGnb, c.Param("gnb-name"))
+}
+
+// GetUpfHistory godoc
+//
+// @Description  Return the revisions of a UPF, newest first
+// @Tags         UPFs
+// @Produce      json
+// @Param        upf-hostname    path    string    true    "Hostname of the UPF"
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.ConfigRevision  "Revisions of the UPF"
+// @Failure      401  {object}  nil                          "Authorization failed"
+// @Failure      403  {object}  nil                          "Forbidden"
+// @Failure      500  {object}  nil                          "Error retrieving the revisions"
+// @Router       /config/v1/inventory/upf/{upf-hostname}/history  [get]
+func GetUpfHistory(c *gin.Context) {
+	getConfigRevisionHistory(c, configmodels.ApplyKindUpf, c.Param("upf-hostname"))
+}
+
+func getConfigRevisionHistory(c *gin.Context, kind, name string) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infof("received a GET history request for %s %s", kind, name)
+	revisions, err := getConfigRevisions(kind, name)
+	if err != nil {
+		logger.DbLog.Errorf("failed to retrieve revisions of %s %s: %+v request ID: %s", kind, name, err, requestID)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve revisions", requestID)
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+func rollbackConfigRevision(c *gin.Context, kind, name string, rollback func(context.Context, string, int64) (int, error)) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infof("received a POST rollback request for %s %s", kind, name)
+	revision, err := strconv.ParseInt(c.Param("revision"), 10, 64)
+	if err != nil || revision < 1 {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("invalid revision %s", c.Param("revision")), requestID)
+		return
+	}
+	statusCode, err := rollback(c.Request.Context(), name, revision)
+	if err != nil {
+		logger.ConfigLog.Errorf("failed to roll back %s %s to revision %d: %+v request ID: %s", kind, name, revision, err, requestID)
+		if statusCode == http.StatusInternalServerError {
+			writeProblem(c, statusCode, configmodels.ProblemCodeInternalError, fmt.Sprintf("failed to roll back %s %s", kind, name), requestID)
+			return
+		}
+		writeErrorProblem(c, statusCode, err, requestID)
+		return
+	}
+	logger.WebUILog.Infof("successfully rolled back %s %s to revision %d", kind, name, revision)
+	c.JSON(http.StatusOK, gin.H{})
+}
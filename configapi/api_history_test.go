@@ -0,0 +1,355 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockMongoClientHistory struct {
+	dbadapter.DBInterface
+	revisions []map[string]interface{}
+	slices    map[string]map[string]interface{}
+	deleted   []string
+}
+
+func (m *MockMongoClientHistory) matchingRevisions(filter bson.M) []map[string]interface{} {
+	var results []map[string]interface{}
+	for _, revision := range m.revisions {
+		if revision["kind"] != filter["kind"] || revision["name"] != filter["name"] {
+			continue
+		}
+		if number, ok := filter["revision"]; ok && fmt.Sprint(revision["revision"]) != fmt.Sprint(number) {
+			continue
+		}
+		results = append(results, revision)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i]["revision"].(float64) > results[j]["revision"].(float64)
+	})
+	return results
+}
+
+func (m *MockMongoClientHistory) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	results := m.matchingRevisions(filter)
+	if limit > 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (m *MockMongoClientHistory) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	if collName == configmodels.ConfigRevisionDataColl {
+		if results := m.matchingRevisions(filter); len(results) > 0 {
+			return results[0], nil
+		}
+		return nil, nil
+	}
+	if collName == sliceDataColl {
+		return m.slices[filter["slice-name"].(string)], nil
+	}
+	return nil, nil
+}
+
+func (m *MockMongoClientHistory) RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockMongoClientHistory) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, data := range postDataArray {
+		m.revisions = append(m.revisions, data.(bson.M))
+	}
+	return nil
+}
+
+func (m *MockMongoClientHistory) RestfulAPIPost(collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
+	m.slices[filter["slice-name"].(string)] = postData
+	return true, nil
+}
+
+func (m *MockMongoClientHistory) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	if collName == sliceDataColl {
+		maps.Copy(m.slices[filter["slice-name"].(string)], putData)
+	}
+	return true, nil
+}
+
+func (m *MockMongoClientHistory) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	name := filter["slice-name"].(string)
+	delete(m.slices, name)
+	m.deleted = append(m.deleted, name)
+	return nil
+}
+
+func (m *MockMongoClientHistory) Client() *mongo.Client {
+	return nil
+}
+
+func TestRecordConfigRevision(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = origCommonDB }()
+	dbClient := &MockMongoClientHistory{}
+	dbadapter.CommonDBClient = dbClient
+
+	ctx := auth.ContextWithUser(context.Background(), "janedoe", configmodels.AdminRole)
+	v1 := bson.M{"name": "gnb1", "tac": float64(1)}
+	v2 := bson.M{"name": "gnb1", "tac": float64(2)}
+	steps := []struct {
+		prev, next bson.M
+	}{
+		{nil, v1},
+		{v1, v2},
+		{v2, v2},
+		{v2, nil},
+	}
+	for _, step := range steps {
+		if err := recordConfigRevision(ctx, configmodels.ApplyKindGnb, "gnb1", step.prev, step.next); err != nil {
+			t.Fatalf("failed to record revision: %v", err)
+		}
+	}
+
+	revisions, err := getConfigRevisions(configmodels.ApplyKindGnb, "gnb1")
+	if err != nil {
+		t.Fatalf("failed to retrieve revisions: %v", err)
+	}
+	var operations []string
+	for i, revision := range revisions {
+		if revision.Revision != int64(len(revisions)-i) {
+			t.Errorf("expected revision %d, got %d", len(revisions)-i, revision.Revision)
+		}
+		if revision.Author != "janedoe" {
+			t.Errorf("expected author janedoe, got %q", revision.Author)
+		}
+		operations = append(operations, revision.Operation)
+	}
+	expectedOperations := []string{configmodels.ApplyOpDelete, configmodels.ApplyOpUpdate, configmodels.ApplyOpCreate}
+	if !reflect.DeepEqual(operations, expectedOperations) {
+		t.Fatalf("expected operations %v, got %v", expectedOperations, operations)
+	}
+	expectedDiff := []configmodels.ConfigFieldDiff{{Field: "tac", Previous: float64(1), Current: float64(2)}}
+	if !reflect.DeepEqual(revisions[1].Diff, expectedDiff) {
+		t.Errorf("expected diff %+v, got %+v", expectedDiff, revisions[1].Diff)
+	}
+	if revisions[0].Document != nil {
+		t.Errorf("expected no document for a deletion, got %+v", revisions[0].Document)
+	}
+}
+
+// MockMongoClientConcurrentHistory stores a revision of another writer before
+// each of the first concurrentWrites revisions, which then collides with it.
+type MockMongoClientConcurrentHistory struct {
+	*MockMongoClientHistory
+	concurrentWrites int
+}
+
+func (m *MockMongoClientConcurrentHistory) RestfulAPIPostManyWithContext(ctx context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	if m.concurrentWrites == 0 {
+		return m.MockMongoClientHistory.RestfulAPIPostManyWithContext(ctx, collName, filter, postDataArray)
+	}
+	m.concurrentWrites--
+	concurrent := maps.Clone(postDataArray[0].(bson.M))
+	concurrent["author"] = "johndoe"
+	m.revisions = append(m.revisions, concurrent)
+	return fmt.Errorf("RestfulAPIPostMany err: %w", mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}})
+}
+
+func TestRecordConfigRevision_ConcurrentWrites(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = origCommonDB }()
+	next := bson.M{"name": "gnb1", "tac": float64(1)}
+
+	dbClient := &MockMongoClientConcurrentHistory{MockMongoClientHistory: &MockMongoClientHistory{}, concurrentWrites: 2}
+	dbadapter.CommonDBClient = dbClient
+	if err := recordConfigRevision(context.Background(), configmodels.ApplyKindGnb, "gnb1", nil, next); err != nil {
+		t.Fatalf("expected the revision to be stored after the concurrent ones, got %v", err)
+	}
+	revisions, err := getConfigRevisions(configmodels.ApplyKindGnb, "gnb1")
+	if err != nil || len(revisions) != 3 || revisions[0].Revision != 3 {
+		t.Fatalf("expected revision 3 after 2 concurrent ones, got %+v %v", revisions, err)
+	}
+
+	// in a transaction, the conflict aborts the transaction
+	dbClient = &MockMongoClientConcurrentHistory{MockMongoClientHistory: &MockMongoClientHistory{}, concurrentWrites: 1}
+	dbadapter.CommonDBClient = dbClient
+	sc := mongo.NewSessionContext(context.Background(), &MockSession{})
+	err = recordConfigRevision(sc, configmodels.ApplyKindGnb, "gnb1", nil, next)
+	if versionConflictStatus(err, http.StatusInternalServerError) != http.StatusConflict {
+		t.Errorf("expected a concurrent update, got %v", err)
+	}
+}
+
+func TestNetworkSliceHistoryAndRollback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	origCommonDB := dbadapter.CommonDBClient
+	origChannel := configChannel
+	origConfig := factory.WebUIConfig
+	origSync := syncSubscribersOnSliceCreateOrUpdate
+	origSyncDelete := syncSubscribersOnSliceDelete
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		configChannel = origChannel
+		factory.WebUIConfig = origConfig
+		syncSubscribersOnSliceCreateOrUpdate = origSync
+		syncSubscribersOnSliceDelete = origSyncDelete
+	}()
+	factory.WebUIConfig = &factory.Config{Configuration: &factory.Configuration{}}
+	syncSubscribersOnSliceCreateOrUpdate = func(_, _ configmodels.Slice) (int, error) {
+		return http.StatusOK, nil
+	}
+	syncSubscribersOnSliceDelete = func(_, _ *configmodels.Slice) error {
+		return nil
+	}
+
+	original := networkSlice("slice1")
+	updated := networkSlice("slice1")
+	updated.SliceId.Sst = "2"
+
+	testCases := []struct {
+		name          string
+		route         string
+		expectedCode  int
+		expectedSst   string
+		expectedMsgs  int
+		expectDeleted bool
+	}{
+		{
+			name:         "roll back to the created revision",
+			route:        "/config/v1/network-slice/slice1/rollback/1",
+			expectedCode: http.StatusOK,
+			expectedSst:  original.SliceId.Sst,
+			expectedMsgs: 1,
+		},
+		{
+			name:         "roll back to the current revision",
+			route:        "/config/v1/network-slice/slice1/rollback/2",
+			expectedCode: http.StatusOK,
+			expectedSst:  updated.SliceId.Sst,
+			expectedMsgs: 1,
+		},
+		{
+			name:          "roll back to the deletion",
+			route:         "/config/v1/network-slice/slice1/rollback/3",
+			expectedCode:  http.StatusOK,
+			expectedMsgs:  1,
+			expectDeleted: true,
+		},
+		{
+			name:         "unknown revision",
+			route:        "/config/v1/network-slice/slice1/rollback/9",
+			expectedCode: http.StatusNotFound,
+			expectedSst:  updated.SliceId.Sst,
+		},
+		{
+			name:         "invalid revision",
+			route:        "/config/v1/network-slice/slice1/rollback/latest",
+			expectedCode: http.StatusBadRequest,
+			expectedSst:  updated.SliceId.Sst,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbClient := &MockMongoClientHistory{slices: map[string]map[string]interface{}{}}
+			dbadapter.CommonDBClient = dbClient
+			configChannel = make(chan *configmodels.ConfigMessage, 10)
+			steps := []struct {
+				prev, next bson.M
+			}{
+				{nil, configmodels.ToBsonM(original)},
+				{configmodels.ToBsonM(original), configmodels.ToBsonM(updated)},
+				{configmodels.ToBsonM(updated), nil},
+			}
+			for _, step := range steps {
+				if err := recordConfigRevision(context.Background(), configmodels.ApplyKindNetworkSlice, "slice1", step.prev, step.next); err != nil {
+					t.Fatalf("failed to record revision: %v", err)
+				}
+			}
+			dbClient.slices["slice1"] = configmodels.ToBsonM(updated)
+
+			req, err := http.NewRequest(http.MethodPost, tc.route, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if len(configChannel) != tc.expectedMsgs {
+				t.Errorf("expected %d config messages, got %d", tc.expectedMsgs, len(configChannel))
+			}
+			if tc.expectDeleted {
+				if !reflect.DeepEqual(dbClient.deleted, []string{"slice1"}) {
+					t.Errorf("expected slice1 to be deleted, got %v", dbClient.deleted)
+				}
+				return
+			}
+			var stored configmodels.Slice
+			if err = json.Unmarshal(configmodels.MapToByte(dbClient.slices["slice1"]), &stored); err != nil {
+				t.Fatalf("failed to decode stored slice: %v", err)
+			}
+			if stored.SliceId.Sst != tc.expectedSst {
+				t.Errorf("expected SST %s, got %s", tc.expectedSst, stored.SliceId.Sst)
+			}
+		})
+	}
+}
+
+func TestGetNetworkSliceHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = origCommonDB }()
+	dbClient := &MockMongoClientHistory{}
+	dbadapter.CommonDBClient = dbClient
+
+	slice := networkSlice("slice1")
+	if err := recordConfigRevision(context.Background(), configmodels.ApplyKindNetworkSlice, "slice1", nil, configmodels.ToBsonM(slice)); err != nil {
+		t.Fatalf("failed to record revision: %v", err)
+	}
+	if err := recordConfigRevision(context.Background(), configmodels.ApplyKindNetworkSlice, "slice2", nil, configmodels.ToBsonM(networkSlice("slice2"))); err != nil {
+		t.Fatalf("failed to record revision: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/config/v1/network-slice/slice1/history", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var revisions []configmodels.ConfigRevision
+	if err = json.Unmarshal(w.Body.Bytes(), &revisions); err != nil {
+		t.Fatalf("failed to decode revisions: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Name != "slice1" || revisions[0].Operation != configmodels.ApplyOpCreate {
+		t.Errorf("unexpected revisions %+v", revisions)
+	}
+	if !strings.Contains(w.Body.String(), `"slice-name":"slice1"`) {
+		t.Errorf("expected the revision to contain the slice, got %s", w.Body.String())
+	}
+}
@@ -6,10 +6,10 @@ package configapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omec-project/webconsole/backend/logger"
@@ -29,7 +29,7 @@ func SetChannel(cfgChannel chan *configmodels.ConfigMessage) {
 func setInventoryCorsHeader(c *gin.Context) {
 	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 	c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-	c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, If-Match, Idempotency-Key, X-Request-ID")
 	c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, DELETE")
 }
 
@@ -46,13 +46,14 @@ func setInventoryCorsHeader(c *gin.Context) {
 // @Router      /config/v1/inventory/gnb  [get]
 func GetGnbs(c *gin.Context) {
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("received a GET gNBs request")
 	var gnbs []*configmodels.Gnb
 	gnbs = make([]*configmodels.Gnb, 0)
 	rawGnbs, err := dbadapter.CommonDBClient.RestfulAPIGetMany(configmodels.GnbDataColl, bson.M{})
 	if err != nil {
 		logger.DbLog.Errorf("failed to retrieve gNBs with error: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve gNBs"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve gNBs", requestID)
 		return
 	}
 
@@ -68,12 +69,51 @@ func GetGnbs(c *gin.Context) {
 	c.JSON(http.StatusOK, gnbs)
 }
 
+// GetGnb godoc
+//
+// @Description Return the gNB, with its version in the ETag header
+// @Tags        gNBs
+// @Produce     json
+// @Param       gnb-name    path    string    true    "Name of the gNB"
+// @Security    BearerAuth
+// @Success     200  {object}  configmodels.Gnb  "gNB"
+// @Header      200  {string}  ETag  "Version of the resource"
+// @Failure     401  {object}  nil               "Authorization failed"
+// @Failure     403  {object}  nil               "Forbidden"
+// @Failure     404  {object}  nil               "gNB not found"
+// @Failure     500  {object}  nil               "Error retrieving gNB"
+// @Router      /config/v1/inventory/gnb/{gnb-name}  [get]
+func GetGnb(c *gin.Context) {
+	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a GET gNB request")
+	gnbName := c.Param("gnb-name")
+	rawGnb, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.GnbDataColl, bson.M{"name": gnbName})
+	if err != nil {
+		logger.DbLog.Errorf("failed to retrieve gNB %s with error: %+v", gnbName, err)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve gNB", requestID)
+		return
+	}
+	if len(rawGnb) == 0 {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("gNB %s not found", gnbName), requestID)
+		return
+	}
+	var gnb configmodels.Gnb
+	if err = json.Unmarshal(configmodels.MapToByte(rawGnb), &gnb); err != nil {
+		logger.DbLog.Errorf("could not unmarshal gNB %s", rawGnb)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve gNB", requestID)
+		return
+	}
+	setETag(c, rawGnb)
+	c.JSON(http.StatusOK, gnb)
+}
+
 // PostGnb godoc
 //
 // @Description Create a new gNB
 // @Tags        gNBs
 // @Produce     json
-// @Param       gnb    body    configmodels.PostGnbRequest    true    "Name and TAC of the gNB"
+// @Param       gnb    body    configmodels.PostGnbRequest    true    "Name, TAC and inventory metadata of the gNB"
 // @Security    BearerAuth
 // @Success     201  {object}  nil  "gNB successfully created"
 // @Failure     409  {object}  nil  "Resource Conflict"
@@ -84,36 +124,42 @@ func GetGnbs(c *gin.Context) {
 // @Router      /config/v1/inventory/gnb  [post]
 func PostGnb(c *gin.Context) {
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("received a POST gNB request")
 	var postGnbParams configmodels.PostGnbRequest
 	if err := c.ShouldBindJSON(&postGnbParams); err != nil {
 		logger.WebUILog.Errorf("invalid UPF gNB input parameters with error: %+v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "invalid JSON format", requestID)
 		return
 	}
 	if !isValidName(postGnbParams.Name) {
-		errorMessage := fmt.Sprintf("invalid gNB name '%s'. Name needs to match the following regular expression: %s", postGnbParams.Name, NAME_PATTERN)
-		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		err := newValidationError("name", "invalid gNB name '%s'. Name needs to match the following regular expression: %s", postGnbParams.Name, NAME_PATTERN)
+		logger.WebUILog.Errorln(err)
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
 		return
 	}
 	if postGnbParams.Tac != nil {
 		if !isValidGnbTac(*postGnbParams.Tac) {
-			errorMessage := fmt.Sprintf("invalid gNB TAC '%+v'. TAC must be an integer within the range [1, 16777215]", *postGnbParams.Tac)
-			logger.WebUILog.Errorln(errorMessage)
-			c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+			err := newValidationError("tac", "invalid gNB TAC '%+v'. TAC must be an integer within the range [1, 16777215]", *postGnbParams.Tac)
+			logger.WebUILog.Errorln(err)
+			writeErrorProblem(c, http.StatusBadRequest, err, requestID)
 			return
 		}
 	}
+	if err := validateGnbInventory(postGnbParams.GnbInventory); err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
 	gnb := configmodels.Gnb(postGnbParams)
 	if err := executeGnbTransaction(c.Request.Context(), gnb, updateGnbInNetworkSlices, postGnbOperation); err != nil {
-		if strings.Contains(err.Error(), "E11000") {
+		if dbadapter.IsDuplicateKeyError(err) {
 			logger.WebUILog.Errorf("duplicate gNB name found error: %+v", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "gNB already exists"})
+			writeProblem(c, http.StatusConflict, configmodels.ProblemCodeAlreadyExists, "gNB already exists", requestID)
 			return
 		}
 		logger.WebUILog.Errorf("failed to create gNB with name: %s with error: %+v", postGnbParams.Name, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create gNB"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to create gNB", requestID)
 		return
 	}
 	logger.WebUILog.Infof("successfully executed POST gNB %s request", postGnbParams.Name)
@@ -123,7 +169,14 @@ func PostGnb(c *gin.Context) {
 func postGnbOperation(sc mongo.SessionContext, gnb configmodels.Gnb) error {
 	filter := bson.M{"name": gnb.Name}
 	gnbDataBson := configmodels.ToBsonM(gnb)
-	return dbadapter.CommonDBClient.RestfulAPIPostManyWithContext(sc, configmodels.GnbDataColl, filter, []interface{}{gnbDataBson})
+	if err := dbadapter.CommonDBClient.RestfulAPIPostManyWithContext(sc, configmodels.GnbDataColl, filter, []interface{}{gnbDataBson}); err != nil {
+		return err
+	}
+	if err := recordConfigRevision(sc, configmodels.ApplyKindGnb, gnb.Name, nil, gnbDataBson); err != nil {
+		return err
+	}
+	notifyConfigChanges(sc, newConfigChange(configmodels.ApplyKindGnb, configmodels.ApplyOpCreate, gnb.Name))
+	return nil
 }
 
 // PutGnb godoc
@@ -132,43 +185,59 @@ func postGnbOperation(sc mongo.SessionContext, gnb configmodels.Gnb) error {
 // @Tags        gNBs
 // @Produce     json
 // @Param       gnb-name    path    string                        true    "Name of the gNB"
-// @Param       tac         body    configmodels.PutGnbRequest    true    "TAC of the gNB"
+// @Param       tac         body    configmodels.PutGnbRequest    true    "TAC and inventory metadata of the gNB"
+// @Param       If-Match  header  string  false  "Version the resource must be at"
 // @Security    BearerAuth
 // @Success     201  {object}  nil  "gNB successfully created"
 // @Failure     400  {object}  nil  "Bad request"
 // @Failure     401  {object}  nil  "Authorization failed"
 // @Failure     403  {object}  nil  "Forbidden"
+// @Failure     412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure     500  {object}  nil  "Error updating gNB"
 // @Router      /config/v1/inventory/gnb/{gnb-name}  [put]
 func PutGnb(c *gin.Context) {
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("received a PUT gNB request")
 	gnbName, _ := c.Params.Get("gnb-name")
 	if !isValidName(gnbName) {
 		errorMessage := fmt.Sprintf("invalid gNB name '%s'. Name needs to match the following regular expression: %s", gnbName, NAME_PATTERN)
 		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorMessage, requestID)
+		return
+	}
+	if err := setIfMatch(c, configmodels.GnbDataColl, bson.M{"name": gnbName}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 	var putGnbParams configmodels.PutGnbRequest
 	if err := c.ShouldBindJSON(&putGnbParams); err != nil {
 		logger.WebUILog.Errorf("invalid gNB PUT input parameters for gnbname: %s error: %+v", gnbName, err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "invalid JSON format", requestID)
 		return
 	}
 	if !isValidGnbTac(putGnbParams.Tac) {
-		errorMessage := fmt.Sprintf("invalid gNB TAC '%+v'. TAC must be an integer within the range [1, 16777215]", putGnbParams.Tac)
-		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		err := newValidationError("tac", "invalid gNB TAC '%+v'. TAC must be an integer within the range [1, 16777215]", putGnbParams.Tac)
+		logger.WebUILog.Errorln(err)
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
+	if err := validateGnbInventory(putGnbParams.GnbInventory); err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
 		return
 	}
 	putGnb := configmodels.Gnb{
-		Name: gnbName,
-		Tac:  &putGnbParams.Tac,
+		Name:         gnbName,
+		Tac:          &putGnbParams.Tac,
+		GnbInventory: putGnbParams.GnbInventory,
 	}
 	if err := executeGnbTransaction(c.Request.Context(), putGnb, updateGnbInNetworkSlices, putGnbOperation); err != nil {
 		logger.WebUILog.Errorf("failed to PUT gNB name: %s error: %+v", gnbName, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to PUT gNB"})
+		if writeVersionConflict(c, err, requestID) {
+			return
+		}
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to PUT gNB", requestID)
 		return
 	}
 	logger.WebUILog.Infof("successfully executed PUT gNB request for hostname: %s", gnbName)
@@ -177,16 +246,29 @@ func PutGnb(c *gin.Context) {
 
 func putGnbOperation(sc mongo.SessionContext, gnb configmodels.Gnb) error {
 	filter := bson.M{"name": gnb.Name}
+	if err := claimResourceVersion(sc, dbadapter.CommonDBClient, configmodels.GnbDataColl, filter); err != nil {
+		return err
+	}
+	prevGnbDataBson, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.GnbDataColl, filter)
+	if err != nil {
+		return err
+	}
 	gnbDataBson := configmodels.ToBsonM(gnb)
-	_, err := dbadapter.CommonDBClient.RestfulAPIPutOneWithContext(sc, configmodels.GnbDataColl, filter, gnbDataBson)
-	return err
+	if _, err = dbadapter.CommonDBClient.RestfulAPIPutOneWithContext(sc, configmodels.GnbDataColl, filter, gnbDataBson); err != nil {
+		return err
+	}
+	if err = recordConfigRevision(sc, configmodels.ApplyKindGnb, gnb.Name, revisionDocument(prevGnbDataBson), gnbDataBson); err != nil {
+		return err
+	}
+	notifyConfigChanges(sc, newConfigChange(configmodels.ApplyKindGnb, configChangeOperation(len(prevGnbDataBson) > 0), gnb.Name))
+	return nil
 }
 
-func updateGnbInNetworkSlices(gnb configmodels.Gnb) error {
+func updateGnbInNetworkSlices(ctx context.Context, gnb configmodels.Gnb) error {
 	filterByGnb := bson.M{
 		"site-info.gNodeBs.name": gnb.Name,
 	}
-	statusCode, err := updateInventoryInNetworkSlices(filterByGnb, func(networkSlice *configmodels.Slice) {
+	statusCode, err := updateInventoryInNetworkSlices(ctx, filterByGnb, func(networkSlice *configmodels.Slice) {
 		for i := range networkSlice.SiteInfo.GNodeBs {
 			if networkSlice.SiteInfo.GNodeBs[i].Name == gnb.Name {
 				networkSlice.SiteInfo.GNodeBs[i].Tac = *gnb.Tac
@@ -206,21 +288,33 @@ func updateGnbInNetworkSlices(gnb configmodels.Gnb) error {
 // @Tags         gNBs
 // @Produce      json
 // @Param        gnb-name    path    string    true    "Name of the gNB"
+// @Param        cascade    query   bool      false   "Remove the gNB from the network slices referring to it"
+// @Param        If-Match  header  string  false  "Version the resource must be at"
 // @Security     BearerAuth
 // @Success      200  {object}  nil  "gNB deleted"
 // @Failure      400  {object}  nil  "Bad request"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      409  {object}  nil  "gNB referred to by network slices"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure      500  {object}  nil  "Failed to delete gNB"
 // @Router       /config/v1/inventory/gnb/{gnb-name}  [delete]
 func DeleteGnb(c *gin.Context) {
 	logger.WebUILog.Infoln("received a DELETE gNB request")
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	gnbName, exists := c.Params.Get("gnb-name")
 	if !exists {
 		errorMessage := "delete gNB request is missing path param `gnb-name`"
 		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorMessage, requestID)
+		return
+	}
+	if rejectReferencedInventory(c, "gNB", gnbName, bson.M{"site-info.gNodeBs.name": gnbName}, requestID) {
+		return
+	}
+	if err := setIfMatch(c, configmodels.GnbDataColl, bson.M{"name": gnbName}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 	gnb := configmodels.Gnb{
@@ -229,23 +323,67 @@ func DeleteGnb(c *gin.Context) {
 	err := executeGnbTransaction(c.Request.Context(), gnb, removeGnbFromNetworkSlices, deleteGnbOperation)
 	if err != nil {
 		logger.WebUILog.Errorf("failed to delete GNB with name %s error: %+v", gnbName, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete gNB"})
+		if writeVersionConflict(c, err, requestID) {
+			return
+		}
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to delete gNB", requestID)
 		return
 	}
 	logger.WebUILog.Infof("successfully executed DELETE gNB %s request", gnbName)
 	c.JSON(http.StatusOK, gin.H{})
 }
 
+// rejectReferencedInventory answers 409 Conflict when network slices still
+// refer to the inventory object being deleted, unless ?cascade=true is set. It
+// reports whether the request was answered.
+func rejectReferencedInventory(c *gin.Context, label, name string, filter bson.M, requestID string) bool {
+	cascade, err := parseCascadeQuery(c)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return true
+	}
+	if cascade {
+		return false
+	}
+	if err = checkNotReferenced(label, name, filter); err == nil {
+		return false
+	}
+	logger.WebUILog.Warnf("rejected deleting %s %s: %+v", label, name, err)
+	var refErr *referenceError
+	if !errors.As(err, &refErr) {
+		err = fmt.Errorf("failed to delete %s", label)
+	}
+	writeErrorProblem(c, http.StatusInternalServerError, err, requestID)
+	return true
+}
+
 func deleteGnbOperation(sc mongo.SessionContext, gnb configmodels.Gnb) error {
 	filter := bson.M{"name": gnb.Name}
-	return dbadapter.CommonDBClient.RestfulAPIDeleteOneWithContext(sc, configmodels.GnbDataColl, filter)
+	if err := claimResourceVersionIfMatch(sc, dbadapter.CommonDBClient, configmodels.GnbDataColl, filter); err != nil {
+		return err
+	}
+	prevGnbDataBson, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.GnbDataColl, filter)
+	if err != nil {
+		return err
+	}
+	if err = dbadapter.CommonDBClient.RestfulAPIDeleteOneWithContext(sc, configmodels.GnbDataColl, filter); err != nil {
+		return err
+	}
+	if len(prevGnbDataBson) == 0 {
+		return nil
+	}
+	if err = recordConfigRevision(sc, configmodels.ApplyKindGnb, gnb.Name, revisionDocument(prevGnbDataBson), nil); err != nil {
+		return err
+	}
+	notifyConfigChanges(sc, newConfigChange(configmodels.ApplyKindGnb, configmodels.ApplyOpDelete, gnb.Name))
+	return nil
 }
 
-func removeGnbFromNetworkSlices(gnb configmodels.Gnb) error {
+func removeGnbFromNetworkSlices(ctx context.Context, gnb configmodels.Gnb) error {
 	filterByGnb := bson.M{
 		"site-info.gNodeBs.name": gnb.Name,
 	}
-	statusCode, err := updateInventoryInNetworkSlices(filterByGnb, func(networkSlice *configmodels.Slice) {
+	statusCode, err := updateInventoryInNetworkSlices(ctx, filterByGnb, func(networkSlice *configmodels.Slice) {
 		networkSlice.SiteInfo.GNodeBs = slices.DeleteFunc(networkSlice.SiteInfo.GNodeBs, func(existingGnb configmodels.SliceSiteInfoGNodeBs) bool {
 			return gnb.Name == existingGnb.Name
 		})
@@ -257,7 +395,7 @@ func removeGnbFromNetworkSlices(gnb configmodels.Gnb) error {
 	return err
 }
 
-func executeGnbTransaction(ctx context.Context, gnb configmodels.Gnb, nsOperation func(configmodels.Gnb) error, gnbOperation func(mongo.SessionContext, configmodels.Gnb) error) error {
+func executeGnbTransaction(ctx context.Context, gnb configmodels.Gnb, nsOperation func(context.Context, configmodels.Gnb) error, gnbOperation func(mongo.SessionContext, configmodels.Gnb) error) error {
 	session, err := dbadapter.CommonDBClient.StartSession()
 	if err != nil {
 		return fmt.Errorf("failed to initialize DB session: %w", err)
@@ -274,7 +412,7 @@ func executeGnbTransaction(ctx context.Context, gnb configmodels.Gnb, nsOperatio
 			}
 			return err
 		}
-		err = nsOperation(gnb)
+		err = nsOperation(sc, gnb)
 		if err != nil {
 			if abortErr := session.AbortTransaction(sc); abortErr != nil {
 				logger.DbLog.Errorf("failed to abort transaction with error: %+v", abortErr)
@@ -298,13 +436,14 @@ func executeGnbTransaction(ctx context.Context, gnb configmodels.Gnb, nsOperatio
 // @Router       /config/v1/inventory/upf  [get]
 func GetUpfs(c *gin.Context) {
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("received a GET UPFs request")
 	var upfs []*configmodels.Upf
 	upfs = make([]*configmodels.Upf, 0)
 	rawUpfs, err := dbadapter.CommonDBClient.RestfulAPIGetMany(configmodels.UpfDataColl, bson.M{})
 	if err != nil {
 		logger.DbLog.Errorf("failed to retrieve UPFs with error: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve UPFs"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve UPFs", requestID)
 		return
 	}
 
@@ -320,12 +459,51 @@ func GetUpfs(c *gin.Context) {
 	c.JSON(http.StatusOK, upfs)
 }
 
+// GetUpf godoc
+//
+// @Description  Return the UPF, with its version in the ETag header
+// @Tags         UPFs
+// @Produce      json
+// @Param        upf-hostname    path    string    true    "Name of the UPF"
+// @Security     BearerAuth
+// @Success      200  {object}  configmodels.Upf  "UPF"
+// @Header       200  {string}  ETag  "Version of the resource"
+// @Failure      401  {object}  nil               "Authorization failed"
+// @Failure      403  {object}  nil               "Forbidden"
+// @Failure      404  {object}  nil               "UPF not found"
+// @Failure      500  {object}  nil               "Error retrieving UPF"
+// @Router       /config/v1/inventory/upf/{upf-hostname}  [get]
+func GetUpf(c *gin.Context) {
+	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a GET UPF request")
+	hostname := c.Param("upf-hostname")
+	rawUpf, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.UpfDataColl, bson.M{"hostname": hostname})
+	if err != nil {
+		logger.DbLog.Errorf("failed to retrieve UPF %s with error: %+v", hostname, err)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve UPF", requestID)
+		return
+	}
+	if len(rawUpf) == 0 {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("UPF %s not found", hostname), requestID)
+		return
+	}
+	var upf configmodels.Upf
+	if err = json.Unmarshal(configmodels.MapToByte(rawUpf), &upf); err != nil {
+		logger.DbLog.Errorf("could not unmarshal UPF %s", rawUpf)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve UPF", requestID)
+		return
+	}
+	setETag(c, rawUpf)
+	c.JSON(http.StatusOK, upf)
+}
+
 // PostUpf godoc
 //
 // @Description  Create a new UPF
 // @Tags         UPFs
 // @Produce      json
-// @Param        upf  body  configmodels.PostUpfRequest  true  "Hostname and port of the UPF to create"
+// @Param        upf  body  configmodels.PostUpfRequest  true  "Hostname, port and inventory metadata of the UPF to create"
 // @Security     BearerAuth
 // @Success      201  {object}  nil  "UPF successfully created"
 // @Failure      400  {object}  nil  "Bad request"
@@ -336,35 +514,41 @@ func GetUpfs(c *gin.Context) {
 // @Router       /config/v1/inventory/upf/  [post]
 func PostUpf(c *gin.Context) {
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("received a POST UPF request")
 	var postUpfParams configmodels.PostUpfRequest
 	err := c.ShouldBindJSON(&postUpfParams)
 	if err != nil {
 		logger.WebUILog.Errorf("invalid UPF POST input parameters error: %v+", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "invalid JSON format", requestID)
 		return
 	}
 	if !isValidFQDN(postUpfParams.Hostname) {
-		errorMessage := fmt.Sprintf("invalid UPF hostname '%s'. Hostname needs to represent a valid FQDN", postUpfParams.Hostname)
-		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		err := newValidationError("hostname", "invalid UPF hostname '%s'. Hostname needs to represent a valid FQDN", postUpfParams.Hostname)
+		logger.WebUILog.Errorln(err)
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
 		return
 	}
 	if !isValidUpfPort(postUpfParams.Port) {
-		errorMessage := fmt.Sprintf("invalid UPF port '%s'. Port must be a numeric string within the range [0, 65535]", postUpfParams.Port)
-		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		err := newValidationError("port", "invalid UPF port '%s'. Port must be a numeric string within the range [0, 65535]", postUpfParams.Port)
+		logger.WebUILog.Errorln(err)
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
+	if err = validateUpfInventory(postUpfParams.UpfInventory); err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
 		return
 	}
 	upf := configmodels.Upf(postUpfParams)
 	if err = executeUpfTransaction(c.Request.Context(), upf, updateUpfInNetworkSlices, postUpfOperation); err != nil {
-		if strings.Contains(err.Error(), "E11000") {
+		if dbadapter.IsDuplicateKeyError(err) {
 			logger.WebUILog.Errorf("duplicate hostname found with error: %+v", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "UPF already exists"})
+			writeProblem(c, http.StatusConflict, configmodels.ProblemCodeAlreadyExists, "UPF already exists", requestID)
 			return
 		}
 		logger.WebUILog.Errorf("failed to create UPF with hostname: %s with error: %+v", postUpfParams.Hostname, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create UPF"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to create UPF", requestID)
 		return
 	}
 	logger.WebUILog.Infof("successfully executed POST UPF %s request", postUpfParams.Hostname)
@@ -377,7 +561,14 @@ func postUpfOperation(sc mongo.SessionContext, upf configmodels.Upf) error {
 	if upfDataBson == nil {
 		return fmt.Errorf("failed to serialize UPF")
 	}
-	return dbadapter.CommonDBClient.RestfulAPIPostManyWithContext(sc, configmodels.UpfDataColl, filter, []interface{}{upfDataBson})
+	if err := dbadapter.CommonDBClient.RestfulAPIPostManyWithContext(sc, configmodels.UpfDataColl, filter, []interface{}{upfDataBson}); err != nil {
+		return err
+	}
+	if err := recordConfigRevision(sc, configmodels.ApplyKindUpf, upf.Hostname, nil, upfDataBson); err != nil {
+		return err
+	}
+	notifyConfigChanges(sc, newConfigChange(configmodels.ApplyKindUpf, configmodels.ApplyOpCreate, upf.Hostname))
+	return nil
 }
 
 // PutUpf godoc
@@ -386,44 +577,60 @@ func postUpfOperation(sc mongo.SessionContext, upf configmodels.Upf) error {
 // @Tags         UPFs
 // @Produce      json
 // @Param        upf-hostname   path    string                       true    "Name of the UPF to update"
-// @Param        port           body    configmodels.PutUpfRequest   true    "Port of the UPF to update"
+// @Param        port           body    configmodels.PutUpfRequest   true    "Port and inventory metadata of the UPF to update"
+// @Param        If-Match  header  string  false  "Version the resource must be at"
 // @Security     BearerAuth
 // @Success      200  {object}  nil  "UPF successfully updated"
 // @Failure      400  {object}  nil  "Bad request"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure      500  {object}  nil  "Error updating UPF"
 // @Router       /config/v1/inventory/upf/{upf-hostname}  [put]
 func PutUpf(c *gin.Context) {
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("received a PUT UPF request")
 	hostname, _ := c.Params.Get("upf-hostname")
 	if !isValidFQDN(hostname) {
 		errorMessage := fmt.Sprintf("invalid UPF hostname '%s'. Hostname needs to represent a valid FQDN", hostname)
 		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorMessage, requestID)
+		return
+	}
+	if err := setIfMatch(c, configmodels.UpfDataColl, bson.M{"hostname": hostname}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 	var putUpfParams configmodels.PutUpfRequest
 	err := c.ShouldBindJSON(&putUpfParams)
 	if err != nil {
 		logger.WebUILog.Errorf("invalid UPF PUT input parameters with hostname: %s with error: %+v", hostname, err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "invalid JSON format", requestID)
 		return
 	}
 	if !isValidUpfPort(putUpfParams.Port) {
-		errorMessage := fmt.Sprintf("invalid UPF port '%s'. Port must be a numeric string within the range [0, 65535]", putUpfParams.Port)
-		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		err := newValidationError("port", "invalid UPF port '%s'. Port must be a numeric string within the range [0, 65535]", putUpfParams.Port)
+		logger.WebUILog.Errorln(err)
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
+	if err = validateUpfInventory(putUpfParams.UpfInventory); err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
 		return
 	}
 	putUpf := configmodels.Upf{
-		Hostname: hostname,
-		Port:     putUpfParams.Port,
+		Hostname:     hostname,
+		Port:         putUpfParams.Port,
+		UpfInventory: putUpfParams.UpfInventory,
 	}
 	if err := executeUpfTransaction(c.Request.Context(), putUpf, updateUpfInNetworkSlices, putUpfOperation); err != nil {
 		logger.WebUILog.Errorf("failed to PUT UPF with hostname: %s with error: %+v", hostname, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to PUT UPF"})
+		if writeVersionConflict(c, err, requestID) {
+			return
+		}
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to PUT UPF", requestID)
 		return
 	}
 	logger.WebUILog.Infof("successfully executed PUT UPF request for hostname: %s", hostname)
@@ -436,17 +643,32 @@ func putUpfOperation(sc mongo.SessionContext, upf configmodels.Upf) error {
 	if upfDataBson == nil {
 		return fmt.Errorf("failed to serialize UPF")
 	}
-	_, err := dbadapter.CommonDBClient.RestfulAPIPutOneWithContext(sc, configmodels.UpfDataColl, filter, upfDataBson)
-	return err
+	if err := claimResourceVersion(sc, dbadapter.CommonDBClient, configmodels.UpfDataColl, filter); err != nil {
+		return err
+	}
+	prevUpfDataBson, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.UpfDataColl, filter)
+	if err != nil {
+		return err
+	}
+	if _, err = dbadapter.CommonDBClient.RestfulAPIPutOneWithContext(sc, configmodels.UpfDataColl, filter, upfDataBson); err != nil {
+		return err
+	}
+	if err = recordConfigRevision(sc, configmodels.ApplyKindUpf, upf.Hostname, revisionDocument(prevUpfDataBson), upfDataBson); err != nil {
+		return err
+	}
+	notifyConfigChanges(sc, newConfigChange(configmodels.ApplyKindUpf, configChangeOperation(len(prevUpfDataBson) > 0), upf.Hostname))
+	return nil
 }
 
-func updateUpfInNetworkSlices(upf configmodels.Upf) error {
-	filterByUpf := bson.M{"site-info.upf.upf-name": upf.Hostname}
-	statusCode, err := updateInventoryInNetworkSlices(filterByUpf, func(networkSlice *configmodels.Slice) {
-		networkSlice.SiteInfo.Upf = map[string]interface{}{
-			"upf-name": upf.Hostname,
-			"upf-port": upf.Port,
+func updateUpfInNetworkSlices(ctx context.Context, upf configmodels.Upf) error {
+	statusCode, err := updateInventoryInNetworkSlices(ctx, networkSlicesByUpfFilter(upf.Hostname), func(networkSlice *configmodels.Slice) {
+		upfs := slices.Clone(networkSlice.SiteInfo.UpfList())
+		for i := range upfs {
+			if upfs[i].UpfName == upf.Hostname {
+				upfs[i].UpfPort = upf.Port
+			}
 		}
+		networkSlice.SiteInfo.SetUpfs(upfs)
 	})
 	if err != nil {
 		logger.ConfigLog.Errorf("failed to update UPF in network slices: %+v", err)
@@ -461,21 +683,33 @@ func updateUpfInNetworkSlices(upf configmodels.Upf) error {
 // @Tags         UPFs
 // @Produce      json
 // @Param        upf-hostname    path    string    true    "Name of the UPF"
+// @Param        cascade    query   bool      false   "Remove the UPF from the network slices referring to it"
+// @Param        If-Match  header  string  false  "Version the resource must be at"
 // @Security     BearerAuth
 // @Success      200  {object}  nil  "UPF deleted"
 // @Failure      400  {object}  nil  "Bad request"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      409  {object}  nil  "UPF referred to by network slices"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure      500  {object}  nil  "Failed to delete UPF"
 // @Router       /config/v1/inventory/upf/{upf-hostname}  [delete]
 func DeleteUpf(c *gin.Context) {
 	logger.WebUILog.Infoln("received a DELETE UPF request")
 	setInventoryCorsHeader(c)
+	requestID := getRequestID(c)
 	hostname, exists := c.Params.Get("upf-hostname")
 	if !exists {
 		errorMessage := "delete gNB request is missing path param `upf-hostname`"
 		logger.WebUILog.Errorln(errorMessage)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorMessage, requestID)
+		return
+	}
+	if rejectReferencedInventory(c, "UPF", hostname, networkSlicesByUpfFilter(hostname), requestID) {
+		return
+	}
+	if err := setIfMatch(c, configmodels.UpfDataColl, bson.M{"hostname": hostname}); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
 	upf := configmodels.Upf{
@@ -483,7 +717,10 @@ func DeleteUpf(c *gin.Context) {
 	}
 	if err := executeUpfTransaction(c.Request.Context(), upf, removeUpfFromNetworkSlices, deleteUpfOperation); err != nil {
 		logger.WebUILog.Errorf("failed to delete UPF with hostname: %s with error: %+v", hostname, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete UPF"})
+		if writeVersionConflict(c, err, requestID) {
+			return
+		}
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to delete UPF", requestID)
 		return
 	}
 	logger.WebUILog.Infof("successfully executed DELETE UPF request for hostname: %s", hostname)
@@ -492,13 +729,32 @@ func DeleteUpf(c *gin.Context) {
 
 func deleteUpfOperation(sc mongo.SessionContext, upf configmodels.Upf) error {
 	filter := bson.M{"hostname": upf.Hostname}
-	return dbadapter.CommonDBClient.RestfulAPIDeleteOneWithContext(sc, configmodels.UpfDataColl, filter)
+	if err := claimResourceVersionIfMatch(sc, dbadapter.CommonDBClient, configmodels.UpfDataColl, filter); err != nil {
+		return err
+	}
+	prevUpfDataBson, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.UpfDataColl, filter)
+	if err != nil {
+		return err
+	}
+	if err = dbadapter.CommonDBClient.RestfulAPIDeleteOneWithContext(sc, configmodels.UpfDataColl, filter); err != nil {
+		return err
+	}
+	if len(prevUpfDataBson) == 0 {
+		return nil
+	}
+	if err = recordConfigRevision(sc, configmodels.ApplyKindUpf, upf.Hostname, revisionDocument(prevUpfDataBson), nil); err != nil {
+		return err
+	}
+	notifyConfigChanges(sc, newConfigChange(configmodels.ApplyKindUpf, configmodels.ApplyOpDelete, upf.Hostname))
+	return nil
 }
 
-func removeUpfFromNetworkSlices(upf configmodels.Upf) error {
-	filterByUpf := bson.M{"site-info.upf.upf-name": upf.Hostname}
-	statusCode, err := updateInventoryInNetworkSlices(filterByUpf, func(networkSlice *configmodels.Slice) {
-		networkSlice.SiteInfo.Upf = nil
+func removeUpfFromNetworkSlices(ctx context.Context, upf configmodels.Upf) error {
+	statusCode, err := updateInventoryInNetworkSlices(ctx, networkSlicesByUpfFilter(upf.Hostname), func(networkSlice *configmodels.Slice) {
+		upfs := slices.DeleteFunc(slices.Clone(networkSlice.SiteInfo.UpfList()), func(sliceUpf configmodels.SliceSiteInfoUpf) bool {
+			return sliceUpf.UpfName == upf.Hostname
+		})
+		networkSlice.SiteInfo.SetUpfs(upfs)
 	})
 	if err != nil {
 		logger.ConfigLog.Errorf("failed to remove UPF from network slices: %+v", err)
@@ -507,7 +763,16 @@ func removeUpfFromNetworkSlices(upf configmodels.Upf) error {
 	return err
 }
 
-func executeUpfTransaction(ctx context.Context, upf configmodels.Upf, nsOperation func(configmodels.Upf) error, upfOperation func(mongo.SessionContext, configmodels.Upf) error) error {
+// networkSlicesByUpfFilter matches the network slices referring to a UPF,
+// in the UPF list or in the single UPF shape.
+func networkSlicesByUpfFilter(hostname string) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"site-info.upf.upf-name": hostname},
+		{"site-info.upfs.upf-name": hostname},
+	}}
+}
+
+func executeUpfTransaction(ctx context.Context, upf configmodels.Upf, nsOperation func(context.Context, configmodels.Upf) error, upfOperation func(mongo.SessionContext, configmodels.Upf) error) error {
 	session, err := dbadapter.CommonDBClient.StartSession()
 	if err != nil {
 		return fmt.Errorf("failed to initialize DB session: %w", err)
@@ -524,7 +789,7 @@ func executeUpfTransaction(ctx context.Context, upf configmodels.Upf, nsOperatio
 			}
 			return err
 		}
-		err = nsOperation(upf)
+		err = nsOperation(sc, upf)
 		if err != nil {
 			if abortErr := session.AbortTransaction(sc); abortErr != nil {
 				logger.DbLog.Errorf("failed to abort transaction with error: %+v", abortErr)
@@ -535,7 +800,7 @@ func executeUpfTransaction(ctx context.Context, upf configmodels.Upf, nsOperatio
 	})
 }
 
-func updateInventoryInNetworkSlices(filter bson.M, updateFunc func(*configmodels.Slice)) (int, error) {
+func updateInventoryInNetworkSlices(ctx context.Context, filter bson.M, updateFunc func(*configmodels.Slice)) (int, error) {
 	rawNetworkSlices, err := dbadapter.CommonDBClient.RestfulAPIGetMany(sliceDataColl, filter)
 	if err != nil {
 		return http.StatusInternalServerError, fmt.Errorf("failed to fetch network slices: %w", err)
@@ -549,7 +814,7 @@ func updateInventoryInNetworkSlices(filter bson.M, updateFunc func(*configmodels
 		}
 		prevSlice := getSliceByName(networkSlice.SliceName)
 		updateFunc(&networkSlice)
-		if statusCode, err := updateNS(networkSlice, *prevSlice); err != nil {
+		if statusCode, err := updateNS(ctx, networkSlice, *prevSlice); err != nil {
 			logger.ConfigLog.Errorf("Error updating slice %s: %+v", networkSlice.SliceName, err)
 			return statusCode, err
 		}
@@ -562,6 +827,7 @@ func updateInventoryInNetworkSlices(filter bson.M, updateFunc func(*configmodels
 		messages = append(messages, msg)
 	}
 	for _, msg := range messages {
+		msg.RequestID = logger.RequestIDFromContext(ctx)
 		configChannel <- msg
 		logger.ConfigLog.Infof("network slice [%s] update sent to config channel", msg.SliceName)
 	}
@@ -5,8 +5,11 @@ package configapi
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -118,6 +121,18 @@ func (db *MockMongoClientPutExistingUpf) RestfulAPIJSONPatchWithContext(context
 	return nil
 }
 
+func (db *MockMongoClientPutExistingUpf) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+func (db *MockMongoClientPutExistingUpf) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (db *MockMongoClientPutExistingUpf) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 func TestInventoryGetHandlers(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
@@ -156,7 +171,7 @@ func TestInventoryGetHandlers(t *testing.T) {
 			route:        "/config/v1/inventory/gnb",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to retrieve gNBs"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to retrieve gNBs","instance":"/config/v1/inventory/gnb","code":"internal-error"}`,
 		},
 		{
 			name:         "UpfEmptyDB",
@@ -184,7 +199,7 @@ func TestInventoryGetHandlers(t *testing.T) {
 			route:        "/config/v1/inventory/upf",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to retrieve UPFs"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to retrieve UPFs","instance":"/config/v1/inventory/upf","code":"internal-error"}`,
 		},
 	}
 	for _, tc := range testCases {
@@ -201,7 +216,7 @@ func TestInventoryGetHandlers(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if w.Body.String() != tc.expectedBody {
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -237,13 +252,29 @@ func TestGnbPostHandler(t *testing.T) {
 			expectedCode: http.StatusCreated,
 			expectedBody: "{}",
 		},
+		{
+			name:         "Create a new gNB with inventory metadata expects created status",
+			route:        "/config/v1/inventory/gnb",
+			dbAdapter:    &MockMongoClientEmptyDB{},
+			inputData:    `{"name": "gnb1", "tac": 123, "gnb-id": 4096, "plmn": {"mcc": "001", "mnc": "01"}, "n2-address": "192.168.0.10", "site-name": "site-1", "location": {"latitude": 45.5, "longitude": -73.6}}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: "{}",
+		},
+		{
+			name:         "Invalid gNB N2 address expects failure",
+			route:        "/config/v1/inventory/gnb",
+			dbAdapter:    &MockMongoClientEmptyDB{},
+			inputData:    `{"name": "gnb1", "tac": 123, "n2-address": "gnb1.local"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid gNB N2 address 'gnb1.local'. N2 address must be an IPv4 or IPv6 address","instance":"/config/v1/inventory/gnb","code":"validation-failed","errors":[{"field":"n2-address","message":"invalid gNB N2 address 'gnb1.local'. N2 address must be an IPv4 or IPv6 address"}]}`,
+		},
 		{
 			name:         "Create an existing gNB expects failure",
 			route:        "/config/v1/inventory/gnb",
 			dbAdapter:    &MockMongoClientDuplicateCreation{},
 			inputData:    `{"name": "gnb1", "tac": 123}`,
-			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"gNB already exists"}`,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"detail":"gNB already exists","instance":"/config/v1/inventory/gnb","code":"already-exists"}`,
 		},
 		{
 			name:         "TAC is not an integer expects failure",
@@ -251,7 +282,7 @@ func TestGnbPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"name": "gnb1", "tac": "123"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid JSON format"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid JSON format","instance":"/config/v1/inventory/gnb","code":"invalid-request"}`,
 		},
 		{
 			name:         "TAC is zero expects failure",
@@ -259,7 +290,7 @@ func TestGnbPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"name": "gnb1", "tac": 0}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid gNB TAC '0'. TAC must be an integer within the range [1, 16777215]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid gNB TAC '0'. TAC must be an integer within the range [1, 16777215]","instance":"/config/v1/inventory/gnb","code":"validation-failed","errors":[{"field":"tac","message":"invalid gNB TAC '0'. TAC must be an integer within the range [1, 16777215]"}]}`,
 		},
 		{
 			name:         "DB POST operation fails expects failure",
@@ -267,7 +298,7 @@ func TestGnbPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientDBError{},
 			inputData:    `{"name": "gnb1", "tac": 123}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to create gNB"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to create gNB","instance":"/config/v1/inventory/gnb","code":"internal-error"}`,
 		},
 		{
 			name:         "gNB name not provided expects failure",
@@ -275,7 +306,7 @@ func TestGnbPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"tac": 12}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"error\":\"invalid gNB name ''. Name needs to match the following regular expression: " + NAME_PATTERN + "\"}",
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%[1]s","instance":"/config/v1/inventory/gnb","code":"validation-failed","errors":[{"field":"name","message":"%[1]s"}]}`, fmt.Sprintf("invalid gNB name '%s'. Name needs to match the following regular expression: %s", "", NAME_PATTERN)),
 		},
 		{
 			name:         "Invalid gNB name expects failure (invalid token)",
@@ -283,7 +314,7 @@ func TestGnbPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"name": "gn!b1", "tac": 123}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"error\":\"invalid gNB name 'gn!b1'. Name needs to match the following regular expression: " + NAME_PATTERN + "\"}",
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%[1]s","instance":"/config/v1/inventory/gnb","code":"validation-failed","errors":[{"field":"name","message":"%[1]s"}]}`, fmt.Sprintf("invalid gNB name '%s'. Name needs to match the following regular expression: %s", "gn!b1", NAME_PATTERN)),
 		},
 		{
 			name:         "Invalid gNB name expects failure (invalid length)",
@@ -291,7 +322,7 @@ func TestGnbPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    "{\"name\": \"" + genLongString(257) + "\", \"tac\": 123}",
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"error\":\"invalid gNB name '" + genLongString(257) + "'. Name needs to match the following regular expression: " + NAME_PATTERN + "\"}",
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%[1]s","instance":"/config/v1/inventory/gnb","code":"validation-failed","errors":[{"field":"name","message":"%[1]s"}]}`, fmt.Sprintf("invalid gNB name '%s'. Name needs to match the following regular expression: %s", genLongString(257), NAME_PATTERN)),
 		},
 	}
 	for _, tc := range testCases {
@@ -308,7 +339,7 @@ func TestGnbPostHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if tc.expectedBody != w.Body.String() {
+			if tc.expectedBody != withoutRequestID(w.Body.String()) {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -350,7 +381,7 @@ func TestGnbPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"tac": "123"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid JSON format"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid JSON format","instance":"/config/v1/inventory/gnb/gnb1","code":"invalid-request"}`,
 		},
 		{
 			name:         "Missing TAC expects failure",
@@ -358,7 +389,7 @@ func TestGnbPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"some_param": 123}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid gNB TAC '0'. TAC must be an integer within the range [1, 16777215]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid gNB TAC '0'. TAC must be an integer within the range [1, 16777215]","instance":"/config/v1/inventory/gnb/gnb1","code":"validation-failed","errors":[{"field":"tac","message":"invalid gNB TAC '0'. TAC must be an integer within the range [1, 16777215]"}]}`,
 		},
 		{
 			name:         "DB PUT operation fails expects failure",
@@ -366,7 +397,7 @@ func TestGnbPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientDBError{},
 			inputData:    `{"tac": 123}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to PUT gNB"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to PUT gNB","instance":"/config/v1/inventory/gnb/gnb1","code":"internal-error"}`,
 		},
 		{
 			name:         "Invalid gNB name expects failure",
@@ -374,7 +405,7 @@ func TestGnbPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"tac": 123}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"error\":\"invalid gNB name 'gn!b1'. Name needs to match the following regular expression: " + NAME_PATTERN + "\"}",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid gNB name 'gn!b1'. Name needs to match the following regular expression: ` + NAME_PATTERN + `","instance":"/config/v1/inventory/gnb/gn!b1","code":"invalid-request"}`,
 		},
 	}
 	for _, tc := range testCases {
@@ -391,7 +422,7 @@ func TestGnbPutHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if tc.expectedBody != w.Body.String() {
+			if tc.expectedBody != withoutRequestID(w.Body.String()) {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -419,13 +450,29 @@ func TestUpfPostHandler(t *testing.T) {
 			expectedCode: http.StatusCreated,
 			expectedBody: "{}",
 		},
+		{
+			name:         "Create a new UPF with inventory metadata success",
+			route:        "/config/v1/inventory/upf",
+			dbAdapter:    &MockMongoClientEmptyDB{},
+			inputData:    `{"hostname": "upf1.my-domain.com", "port": "123", "n3-address": "10.0.0.1", "n4-address": "fd00::1", "dnns": ["internet"], "snssais": [{"sst": "1", "sd": "010203"}], "capacity": 10}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: "{}",
+		},
+		{
+			name:         "Invalid UPF S-NSSAI expects failure",
+			route:        "/config/v1/inventory/upf",
+			dbAdapter:    &MockMongoClientEmptyDB{},
+			inputData:    `{"hostname": "upf1.my-domain.com", "port": "123", "snssais": [{"sst": "256"}]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF S-NSSAI '{Sst:256 Sd:}'. SST must be an integer within the range [0, 255] and SD, if given, 6 hexadecimal digits","instance":"/config/v1/inventory/upf","code":"validation-failed","errors":[{"field":"snssais[0]","message":"invalid UPF S-NSSAI '{Sst:256 Sd:}'. SST must be an integer within the range [0, 255] and SD, if given, 6 hexadecimal digits"}]}`,
+		},
 		{
 			name:         "Create an existing UPF expects failure",
 			route:        "/config/v1/inventory/upf",
 			dbAdapter:    &MockMongoClientDuplicateCreation{},
 			inputData:    `{"hostname": "upf1.my-domain.com", "port": "123"}`,
-			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"UPF already exists"}`,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"detail":"UPF already exists","instance":"/config/v1/inventory/upf","code":"already-exists"}`,
 		},
 		{
 			name:         "Port is not a string expects failure",
@@ -433,7 +480,7 @@ func TestUpfPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"hostname": "upf1.my-domain.com", "port": 1234}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid JSON format"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid JSON format","instance":"/config/v1/inventory/upf","code":"invalid-request"}`,
 		},
 		{
 			name:         "Missing port expects failure",
@@ -441,7 +488,7 @@ func TestUpfPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"hostname": "upf1.my-domain.com", "some_param": "123"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid UPF port ''. Port must be a numeric string within the range [0, 65535]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF port ''. Port must be a numeric string within the range [0, 65535]","instance":"/config/v1/inventory/upf","code":"validation-failed","errors":[{"field":"port","message":"invalid UPF port ''. Port must be a numeric string within the range [0, 65535]"}]}`,
 		},
 		{
 			name:         "DB POST operation fails expects failure",
@@ -449,7 +496,7 @@ func TestUpfPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientDBError{},
 			inputData:    `{"hostname": "upf1.my-domain.com", "port": "123"}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to create UPF"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to create UPF","instance":"/config/v1/inventory/upf","code":"internal-error"}`,
 		},
 		{
 			name:         "Port cannot be converted to int expects failure",
@@ -457,7 +504,7 @@ func TestUpfPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"hostname": "upf1.my-domain.com", "port": "a"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid UPF port 'a'. Port must be a numeric string within the range [0, 65535]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF port 'a'. Port must be a numeric string within the range [0, 65535]","instance":"/config/v1/inventory/upf","code":"validation-failed","errors":[{"field":"port","message":"invalid UPF port 'a'. Port must be a numeric string within the range [0, 65535]"}]}`,
 		},
 		{
 			name:         "Hostname not provided expects failure",
@@ -465,7 +512,7 @@ func TestUpfPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"port": "a"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid UPF hostname ''. Hostname needs to represent a valid FQDN"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF hostname ''. Hostname needs to represent a valid FQDN","instance":"/config/v1/inventory/upf","code":"validation-failed","errors":[{"field":"hostname","message":"invalid UPF hostname ''. Hostname needs to represent a valid FQDN"}]}`,
 		},
 		{
 			name:         "Invalid UPF hostname expects failure",
@@ -473,7 +520,7 @@ func TestUpfPostHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"hostname": "upf1", "port": "123"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid UPF hostname 'upf1'. Hostname needs to represent a valid FQDN"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF hostname 'upf1'. Hostname needs to represent a valid FQDN","instance":"/config/v1/inventory/upf","code":"validation-failed","errors":[{"field":"hostname","message":"invalid UPF hostname 'upf1'. Hostname needs to represent a valid FQDN"}]}`,
 		},
 	}
 	for _, tc := range testCases {
@@ -490,7 +537,7 @@ func TestUpfPostHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if tc.expectedBody != w.Body.String() {
+			if tc.expectedBody != withoutRequestID(w.Body.String()) {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -532,7 +579,7 @@ func TestUpfPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"port": 1234}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid JSON format"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid JSON format","instance":"/config/v1/inventory/upf/upf1.my-domain.com","code":"invalid-request"}`,
 		},
 		{
 			name:         "Missing port expects failure",
@@ -540,7 +587,7 @@ func TestUpfPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"some_param": "123"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid UPF port ''. Port must be a numeric string within the range [0, 65535]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF port ''. Port must be a numeric string within the range [0, 65535]","instance":"/config/v1/inventory/upf/upf1.my-domain.com","code":"validation-failed","errors":[{"field":"port","message":"invalid UPF port ''. Port must be a numeric string within the range [0, 65535]"}]}`,
 		},
 		{
 			name:         "DB PUT operation fails expects failure",
@@ -548,7 +595,7 @@ func TestUpfPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientDBError{},
 			inputData:    `{"port": "123"}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to PUT UPF"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to PUT UPF","instance":"/config/v1/inventory/upf/upf1.my-domain.com","code":"internal-error"}`,
 		},
 		{
 			name:         "Port cannot be converted to int expects failure",
@@ -556,7 +603,7 @@ func TestUpfPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"port": "a"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid UPF port 'a'. Port must be a numeric string within the range [0, 65535]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF port 'a'. Port must be a numeric string within the range [0, 65535]","instance":"/config/v1/inventory/upf/upf1.my-domain.com","code":"validation-failed","errors":[{"field":"port","message":"invalid UPF port 'a'. Port must be a numeric string within the range [0, 65535]"}]}`,
 		},
 		{
 			name:         "Invalid UPF hostname expects failure",
@@ -564,7 +611,7 @@ func TestUpfPutHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"port": "123"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: `{"error":"invalid UPF hostname 'upf1'. Hostname needs to represent a valid FQDN"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid UPF hostname 'upf1'. Hostname needs to represent a valid FQDN","instance":"/config/v1/inventory/upf/upf1","code":"invalid-request"}`,
 		},
 	}
 	for _, tc := range testCases {
@@ -581,7 +628,7 @@ func TestUpfPutHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if tc.expectedBody != w.Body.String() {
+			if tc.expectedBody != withoutRequestID(w.Body.String()) {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -612,7 +659,7 @@ func TestInventoryDeleteHandlers(t *testing.T) {
 			route:        "/config/v1/inventory/gnb/gnb1",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to delete gNB"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to delete gNB","instance":"/config/v1/inventory/gnb/gnb1","code":"internal-error"}`,
 		},
 		{
 			name:         "Delete UPF Success",
@@ -626,7 +673,7 @@ func TestInventoryDeleteHandlers(t *testing.T) {
 			route:        "/config/v1/inventory/upf/upf1",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to delete UPF"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to delete UPF","instance":"/config/v1/inventory/upf/upf1","code":"internal-error"}`,
 		},
 	}
 	for _, tc := range testCases {
@@ -643,9 +690,105 @@ func TestInventoryDeleteHandlers(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if tc.expectedBody != w.Body.String() {
+			if tc.expectedBody != withoutRequestID(w.Body.String()) {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
 	}
 }
+
+type MockMongoClientSliceWithUpfs struct {
+	MockMongoClientEmptyDB
+	networkSlice configmodels.Slice
+	postedSlices []configmodels.Slice
+}
+
+func (m *MockMongoClientSliceWithUpfs) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]interface{}, error) {
+	if coll != sliceDataColl {
+		return nil, nil
+	}
+	return []map[string]interface{}{configmodels.ToBsonM(m.networkSlice)}, nil
+}
+
+func (m *MockMongoClientSliceWithUpfs) RestfulAPIGetOne(coll string, filter bson.M) (map[string]interface{}, error) {
+	if coll != sliceDataColl {
+		return map[string]interface{}{}, nil
+	}
+	return configmodels.ToBsonM(m.networkSlice), nil
+}
+
+func (m *MockMongoClientSliceWithUpfs) RestfulAPIPost(coll string, filter bson.M, data map[string]interface{}) (bool, error) {
+	if coll == sliceDataColl {
+		var networkSlice configmodels.Slice
+		if err := json.Unmarshal(configmodels.MapToByte(data), &networkSlice); err != nil {
+			return false, err
+		}
+		m.postedSlices = append(m.postedSlices, networkSlice)
+	}
+	return true, nil
+}
+
+func (m *MockMongoClientSliceWithUpfs) RestfulAPICompareAndSwapWithContext(context context.Context, coll string, filter bson.M, data map[string]interface{}) (bool, error) {
+	return m.RestfulAPIPost(coll, filter, data)
+}
+
+func TestUpfChangesInNetworkSlices(t *testing.T) {
+	upfs := []configmodels.SliceSiteInfoUpf{
+		{UpfName: "upf1.my-domain.com", UpfPort: "8805", Priority: 1},
+		{UpfName: "upf2.my-domain.com", UpfPort: "8805", Priority: 2, Dnns: []string{"internet"}},
+	}
+	testCases := []struct {
+		name         string
+		operation    func(context.Context, configmodels.Upf) error
+		upf          configmodels.Upf
+		expectedUpfs []configmodels.SliceSiteInfoUpf
+		expectedUpf  map[string]interface{}
+	}{
+		{
+			name:      "update the port of the preferred UPF",
+			operation: updateUpfInNetworkSlices,
+			upf:       configmodels.Upf{Hostname: "upf1.my-domain.com", Port: "9000"},
+			expectedUpfs: []configmodels.SliceSiteInfoUpf{
+				{UpfName: "upf1.my-domain.com", UpfPort: "9000", Priority: 1},
+				{UpfName: "upf2.my-domain.com", UpfPort: "8805", Priority: 2, Dnns: []string{"internet"}},
+			},
+			expectedUpf: map[string]interface{}{"upf-name": "upf1.my-domain.com", "upf-port": "9000"},
+		},
+		{
+			name:         "remove the preferred UPF",
+			operation:    removeUpfFromNetworkSlices,
+			upf:          configmodels.Upf{Hostname: "upf1.my-domain.com"},
+			expectedUpfs: []configmodels.SliceSiteInfoUpf{{UpfName: "upf2.my-domain.com", UpfPort: "8805", Priority: 2, Dnns: []string{"internet"}}},
+			expectedUpf:  map[string]interface{}{"upf-name": "upf2.my-domain.com", "upf-port": "8805"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			networkSlice := configmodels.Slice{
+				SliceName: "slice-1",
+				SliceId:   configmodels.SliceSliceId{Sst: "1", Sd: "010203"},
+			}
+			networkSlice.SiteInfo.SetUpfs(upfs)
+			mock := &MockMongoClientSliceWithUpfs{networkSlice: networkSlice}
+			origChannel := configChannel
+			configChannel = make(chan *configmodels.ConfigMessage, 1)
+			originalDBClient := dbadapter.CommonDBClient
+			defer func() { configChannel = origChannel; dbadapter.CommonDBClient = originalDBClient }()
+			dbadapter.CommonDBClient = mock
+
+			if err := tc.operation(context.Background(), tc.upf); err != nil {
+				t.Fatalf("failed to update network slices: %v", err)
+			}
+			if len(mock.postedSlices) != 1 {
+				t.Fatalf("expected one network slice to be stored, got %d", len(mock.postedSlices))
+			}
+			siteInfo := mock.postedSlices[0].SiteInfo
+			if !reflect.DeepEqual(tc.expectedUpfs, siteInfo.Upfs) {
+				t.Errorf("expected UPFs %+v, got %+v", tc.expectedUpfs, siteInfo.Upfs)
+			}
+			if !reflect.DeepEqual(tc.expectedUpf, siteInfo.Upf) {
+				t.Errorf("expected UPF %+v, got %+v", tc.expectedUpf, siteInfo.Upf)
+			}
+		})
+	}
+}
@@ -10,14 +10,15 @@ package configapi
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/omec-project/openapi/models"
 	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/backend/problemdetails"
 	"github.com/omec-project/webconsole/backend/webui_context"
 	"github.com/omec-project/webconsole/configmodels"
 	"github.com/omec-project/webconsole/dbadapter"
@@ -45,15 +46,15 @@ func sliceToByte(data []map[string]interface{}) ([]byte, error) {
 func setCorsHeader(c *gin.Context) {
 	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 	c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-	c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+	c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match, Idempotency-Key, X-Request-ID")
 	c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 }
 
-func sendResponseToClient(c *gin.Context, response *http.Response) {
+func sendResponseToClient(c *gin.Context, response *http.Response, requestID string) {
 	var jsonData interface{}
 	if err := json.NewDecoder(response.Body).Decode(&jsonData); err != nil {
 		logger.DbLog.Errorf("failed to decode response: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decode response"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to decode response", requestID)
 		return
 	}
 	c.JSON(response.StatusCode, jsonData)
@@ -252,40 +253,99 @@ func GetSampleJSON(c *gin.Context) {
 
 // GetSubscribers godoc
 //
-// @Description  Return the list of subscribers
+// @Description  Return the list of subscribers. When any of the paging or filter parameters is provided the response is a page of subscribers ordered by IMSI, otherwise the full list is returned
 // @Tags         Subscribers
 // @Produce      json
+// @Param        limit          query   int     false   "Maximum number of subscribers in the page (default 100, max 1000)"
+// @Param        page_token     query   string  false   "Token returned as next_page_token by the previous page"
+// @Param        imsi_prefix    query   string  false   "Only return IMSIs starting with these digits"
+// @Param        imsi_from      query   string  false   "Only return IMSIs greater than or equal to this IMSI"
+// @Param        imsi_to        query   string  false   "Only return IMSIs less than or equal to this IMSI"
+// @Param        plmn_id        query   string  false   "Only return subscribers served by this PLMN (MCC+MNC)"
+// @Param        device_group   query   string  false   "Only return subscribers that belong to this device group"
 // @Security     BearerAuth
-// @Success      200  {object}  configmodels.SubsListIE  "List of subscribers. Null if there are no subscribers"
-// @Failure      401  {object}  nil                      "Authorization failed"
-// @Failure      403  {object}  nil                      "Forbidden"
-// @Failure      500  {object}  nil                      "Error retrieving subscribers"
+// @Success      200  {object}  configmodels.SubsListPage  "Page of subscribers, or the list of subscribers (null if there are no subscribers) when no paging or filter parameter is provided"
+// @Failure      400  {object}  nil                        "Invalid paging or filter parameter"
+// @Failure      401  {object}  nil                        "Authorization failed"
+// @Failure      403  {object}  nil                        "Forbidden"
+// @Failure      404  {object}  nil                        "Device group not found"
+// @Failure      500  {object}  nil                        "Error retrieving subscribers"
 // @Router      /api/subscriber/  [get]
 func GetSubscribers(c *gin.Context) {
 	setCorsHeader(c)
+	requestID := getRequestID(c)
 
 	logger.WebUILog.Infoln("Get All Subscribers List")
 
+	if isSubscriberListQuery(c.Request.URL.Query()) {
+		getSubscribersPage(c, requestID)
+		return
+	}
+
 	subsList := make([]configmodels.SubsListIE, 0)
 	amDataList, errGetMany := dbadapter.CommonDBClient.RestfulAPIGetMany(amDataColl, bson.M{})
 	if errGetMany != nil {
 		logger.DbLog.Errorf("failed to retrieve subscribers list with error: %+v", errGetMany)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscribers list"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscribers list", requestID)
 		return
 	}
 	for _, amData := range amDataList {
-		tmp := configmodels.SubsListIE{
-			UeId: amData["ueId"].(string),
-		}
+		subsList = append(subsList, toSubsListIE(amData))
+	}
 
-		if servingPlmnId, plmnIdExists := amData["servingPlmnId"]; plmnIdExists {
-			tmp.PlmnID = servingPlmnId.(string)
-		}
+	c.JSON(http.StatusOK, subsList)
+}
 
-		subsList = append(subsList, tmp)
+func getSubscribersPage(c *gin.Context, requestID string) {
+	query, err := parseSubscriberListQuery(c.Request.URL.Query())
+	if err != nil {
+		logger.WebUILog.Errorf("invalid subscriber list query: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	filter, statusCode, err := subscriberListFilter(query)
+	if err != nil {
+		logger.WebUILog.Errorf("failed to build subscriber list filter: %+v request ID: %s", err, requestID)
+		writeErrorProblem(c, statusCode, err, requestID)
+		return
+	}
+	total, err := dbadapter.CommonDBClient.RestfulAPICount(amDataColl, filter)
+	if err != nil {
+		logger.DbLog.Errorf("failed to count subscribers: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscribers list", requestID)
+		return
+	}
+	// fetch one more subscriber than requested to know whether there is a next page
+	amDataList, err := dbadapter.CommonDBClient.RestfulAPIGetManyPaged(amDataColl, withPageCursor(filter, query.after), bson.D{{Key: "ueId", Value: 1}}, 0, query.limit+1)
+	if err != nil {
+		logger.DbLog.Errorf("failed to retrieve subscribers page: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscribers list", requestID)
+		return
+	}
+	page := configmodels.SubsListPage{
+		Subscribers: make([]configmodels.SubsListIE, 0, len(amDataList)),
+		Total:       total,
+	}
+	if int64(len(amDataList)) > query.limit {
+		amDataList = amDataList[:query.limit]
+		lastUeId, _ := amDataList[len(amDataList)-1]["ueId"].(string)
+		page.NextPageToken = encodePageToken(lastUeId)
 	}
+	for _, amData := range amDataList {
+		page.Subscribers = append(page.Subscribers, toSubsListIE(amData))
+	}
+	c.JSON(http.StatusOK, page)
+}
 
-	c.JSON(http.StatusOK, subsList)
+func toSubsListIE(amData map[string]interface{}) configmodels.SubsListIE {
+	subsListIE := configmodels.SubsListIE{}
+	if ueId, ok := amData["ueId"].(string); ok {
+		subsListIE.UeId = ueId
+	}
+	if servingPlmnId, ok := amData["servingPlmnId"].(string); ok {
+		subsListIE.PlmnID = servingPlmnId
+	}
+	return subsListIE
 }
 
 // GetSubscriberByID godoc
@@ -296,6 +356,7 @@ func GetSubscribers(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Success      200  {object}  nil  "Subscriber"
+// @Header       200  {string}  ETag  "Version of the resource"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
 // @Failure      404  {object}  nil  "Subscriber not found"
@@ -303,6 +364,7 @@ func GetSubscribers(c *gin.Context) {
 // @Router      /api/subscriber/{imsi}  [get]
 func GetSubscriberByID(c *gin.Context) {
 	setCorsHeader(c)
+	requestID := getRequestID(c)
 
 	logger.WebUILog.Infoln("Get One Subscriber Data")
 
@@ -314,37 +376,37 @@ func GetSubscriberByID(c *gin.Context) {
 	authSubsDataInterface, err := dbadapter.AuthDBClient.RestfulAPIGetOne(authSubsDataColl, filterUeIdOnly)
 	if err != nil {
 		logger.DbLog.Errorf("failed to fetch authentication subscription data from DB: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch the requested subscriber record from DB"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to fetch the requested subscriber record from DB", requestID)
 		return
 	}
 	amDataDataInterface, err := dbadapter.CommonDBClient.RestfulAPIGetOne(amDataColl, filterUeIdOnly)
 	if err != nil {
 		logger.DbLog.Errorf("failed to fetch am data from DB: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch the requested subscriber record from DB"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to fetch the requested subscriber record from DB", requestID)
 		return
 	}
 	smDataDataInterface, err := dbadapter.CommonDBClient.RestfulAPIGetMany(smDataColl, filterUeIdOnly)
 	if err != nil {
 		logger.DbLog.Errorf("failed to fetch sm data from DB: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch the requested subscriber record from DB"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to fetch the requested subscriber record from DB", requestID)
 		return
 	}
 	smfSelDataInterface, err := dbadapter.CommonDBClient.RestfulAPIGetOne(smfSelDataColl, filterUeIdOnly)
 	if err != nil {
 		logger.DbLog.Errorf("failed to fetch smf selection data from DB: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch the requested subscriber record from DB"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to fetch the requested subscriber record from DB", requestID)
 		return
 	}
 	amPolicyDataInterface, err := dbadapter.CommonDBClient.RestfulAPIGetOne(amPolicyDataColl, filterUeIdOnly)
 	if err != nil {
 		logger.DbLog.Errorf("failed to fetch am policy data from DB: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch the requested subscriber record from DB"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to fetch the requested subscriber record from DB", requestID)
 		return
 	}
 	smPolicyDataInterface, err := dbadapter.CommonDBClient.RestfulAPIGetOne(smPolicyDataColl, filterUeIdOnly)
 	if err != nil {
 		logger.DbLog.Errorf("failed to fetch sm policy data from DB: %+v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch the requested subscriber record from DB"})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to fetch the requested subscriber record from DB", requestID)
 		return
 	}
 	// If all fetched data is empty, return 404 error
@@ -355,7 +417,7 @@ func GetSubscriberByID(c *gin.Context) {
 		amPolicyDataInterface == nil &&
 		smPolicyDataInterface == nil {
 		logger.WebUILog.Errorf("subscriber with ID %s not found", ueId)
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("subscriber with ID %s not found", ueId)})
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("subscriber with ID %s not found", ueId), requestID)
 		return
 	}
 
@@ -364,7 +426,7 @@ func GetSubscriberByID(c *gin.Context) {
 		err := json.Unmarshal(configmodels.MapToByte(authSubsDataInterface), &authSubsData)
 		if err != nil {
 			logger.WebUILog.Errorf("error unmarshalling authentication subscription data: %+v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscriber"})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscriber", requestID)
 			return
 		}
 	}
@@ -374,7 +436,7 @@ func GetSubscriberByID(c *gin.Context) {
 		err := json.Unmarshal(configmodels.MapToByte(amDataDataInterface), &amDataData)
 		if err != nil {
 			logger.WebUILog.Errorf("error unmarshalling access and mobility subscription data: %+v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscriber"})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscriber", requestID)
 			return
 		}
 	}
@@ -384,13 +446,13 @@ func GetSubscriberByID(c *gin.Context) {
 		bytesData, err := sliceToByte(smDataDataInterface)
 		if err != nil {
 			logger.WebUILog.Errorf("failed to convert slice to byte: %+v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscriber"})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscriber", requestID)
 			return
 		}
 		err = json.Unmarshal(bytesData, &smDataData)
 		if err != nil {
 			logger.WebUILog.Errorf("error unmarshalling session management subscription data: %+v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscriber"})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscriber", requestID)
 			return
 		}
 	}
@@ -400,7 +462,7 @@ func GetSubscriberByID(c *gin.Context) {
 		err := json.Unmarshal(configmodels.MapToByte(smfSelDataInterface), &smfSelData)
 		if err != nil {
 			logger.WebUILog.Errorf("error unmarshalling smf selection subscription data: %+v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscriber"})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscriber", requestID)
 			return
 		}
 	}
@@ -410,7 +472,7 @@ func GetSubscriberByID(c *gin.Context) {
 		err := json.Unmarshal(configmodels.MapToByte(amPolicyDataInterface), &amPolicyData)
 		if err != nil {
 			logger.WebUILog.Errorf("error unmarshalling am policy data: %+v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscriber"})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscriber", requestID)
 			return
 		}
 	}
@@ -420,7 +482,7 @@ func GetSubscriberByID(c *gin.Context) {
 		err := json.Unmarshal(configmodels.MapToByte(smPolicyDataInterface), &smPolicyData)
 		if err != nil {
 			logger.WebUILog.Errorf("error unmarshalling sm policy data: %+v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve subscriber"})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to retrieve subscriber", requestID)
 			return
 		}
 	}
@@ -435,6 +497,7 @@ func GetSubscriberByID(c *gin.Context) {
 		SmPolicyData:                      smPolicyData,
 	}
 
+	setETag(c, amDataDataInterface)
 	c.JSON(http.StatusOK, subsData)
 }
 
@@ -454,18 +517,18 @@ func GetSubscriberByID(c *gin.Context) {
 // @Router      /api/subscriber/{imsi}  [post]
 func PostSubscriberByID(c *gin.Context) {
 	setCorsHeader(c)
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	var subsOverrideData configmodels.SubsOverrideData
 	if err := c.ShouldBindJSON(&subsOverrideData); err != nil {
 		logger.WebUILog.Errorf("Post One Subscriber Data - ShouldBindJSON failed: %+v request ID: %s", err, requestID)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: failed to parse JSON.", "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "Invalid request body: failed to parse JSON.", requestID)
 		return
 	}
 	logger.WebUILog.Infof("%+v", subsOverrideData)
 
 	ueId := c.Param("ueId")
 	if ueId == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing ueId in request URL", "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "Missing ueId in request URL", requestID)
 		return
 	}
 
@@ -477,51 +540,26 @@ func PostSubscriberByID(c *gin.Context) {
 	subscriber, err := dbadapter.CommonDBClient.RestfulAPIGetOne(amDataColl, filter)
 	if err != nil {
 		logger.DbLog.Errorf("failed querying subscriber existence for IMSI: %s; Error: %+v", ueId, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to check subscriber: %s existence", ueId), "request_id": requestID})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("failed to check subscriber: %s existence", ueId), requestID)
 		return
 	} else if subscriber != nil {
 		logger.WebUILog.Errorf("subscriber %s already exists", ueId)
-		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("subscriber %s already exists", ueId), "request_id": requestID})
+		writeProblem(c, http.StatusConflict, configmodels.ProblemCodeAlreadyExists, fmt.Sprintf("subscriber %s already exists", ueId), requestID)
 		return
 	}
 	if subsOverrideData.OPc == "" || subsOverrideData.Key == "" || subsOverrideData.SequenceNumber == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required authentication data: OPc and Key must be provided", "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "Missing required authentication data: OPc and Key must be provided", requestID)
 		return
 	}
 
-	authSubsData := models.AuthenticationSubscription{
-		AuthenticationManagementField: "8000",
-		AuthenticationMethod:          "5G_AKA",
-		Milenage: &models.Milenage{
-			Op: &models.Op{
-				EncryptionAlgorithm: 0,
-				EncryptionKey:       0,
-				OpValue:             "",
-			},
-		},
-		Opc: &models.Opc{
-			OpcValue:            subsOverrideData.OPc,
-			EncryptionAlgorithm: 0,
-			EncryptionKey:       0,
-		},
-		PermanentKey: &models.PermanentKey{
-			PermanentKeyValue:   subsOverrideData.Key,
-			EncryptionAlgorithm: 0,
-			EncryptionKey:       0,
-		},
-		SequenceNumber: subsOverrideData.SequenceNumber,
-	}
+	authSubsData := newAuthenticationSubscription(subsOverrideData.OPc, subsOverrideData.Key, subsOverrideData.SequenceNumber)
 
 	logger.WebUILog.Infof("%+v", authSubsData)
 	logger.WebUILog.Infof("Using OPc: %s, Key: %s, SeqNo: %s", subsOverrideData.OPc, subsOverrideData.Key, subsOverrideData.SequenceNumber)
 
 	err = handleSubscriberPost(ueId, &authSubsData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      fmt.Sprintf("Failed to create subscriber %s", ueId),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details",
-		})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("Failed to create subscriber %s", ueId), requestID)
 		return
 	}
 	logger.WebUILog.Infoln("Subscriber %s created successfully", ueId)
@@ -531,35 +569,195 @@ func PostSubscriberByID(c *gin.Context) {
 		MsgMethod:   configmodels.Post_op,
 		AuthSubData: &authSubsData,
 		Imsi:        ueId,
+		RequestID:   requestID,
 	}
 	configChannel <- &msg
+	notifyConfigChanges(c.Request.Context(), newConfigChange(configmodels.ConfigEventTypeSubscriber, configmodels.ApplyOpCreate, ueId))
 
 	c.JSON(http.StatusCreated, gin.H{})
 }
 
+// PostSubscriberAction dispatches custom methods on the subscriber collection (/api/subscriber:{action})
+func PostSubscriberAction(c *gin.Context) {
+	switch c.Param("action") {
+	case ":bulk":
+		PostSubscribersBulk(c)
+	default:
+		setCorsHeader(c)
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("unknown subscriber action %s", c.Param("action")), getRequestID(c))
+	}
+}
+
+// PostSubscribersBulk godoc
+//
+// @Description  Create subscribers in bulk from a JSON list, a CSV file (imsi,ki,opc,sqn) or a JSON IMSI range specification. The subscribers are written in a single transaction and can optionally be added to an existing device group
+// @Tags         Subscribers
+// @Accept       json,text/csv
+// @Param        device-group    query   string                                  false   "Device group the created IMSIs are added to"
+// @Param        content         body    []configmodels.BulkSubscriber           true    "Subscribers, or a configmodels.BulkSubscriberRange"
+// @Security     BearerAuth
+// @Success      201  {object}  configmodels.BulkSubscriberReport  "All subscribers created"
+// @Success      207  {object}  configmodels.BulkSubscriberReport  "Some subscribers were rejected"
+// @Failure      400  {object}  nil                                "Invalid request"
+// @Failure      401  {object}  nil                                "Authorization failed"
+// @Failure      403  {object}  nil                                "Forbidden"
+// @Failure      404  {object}  nil                                "Device group not found"
+// @Failure      415  {object}  nil                                "Unsupported content type"
+// @Failure      422  {object}  configmodels.BulkSubscriberReport  "No subscriber could be created"
+// @Failure      500  {object}  nil                                "Error creating subscribers"
+// @Router       /api/subscriber:bulk  [post]
+func PostSubscribersBulk(c *gin.Context) {
+	setCorsHeader(c)
+	logger.WebUILog.Infoln("Post Subscribers in Bulk")
+	requestID := getRequestID(c)
+
+	body, err := c.GetRawData()
+	if err != nil {
+		logger.WebUILog.Errorf("Post Subscribers in Bulk - failed to read body: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "Invalid request body", requestID)
+		return
+	}
+	var subscribers []configmodels.BulkSubscriber
+	switch c.ContentType() {
+	case "application/json":
+		subscribers, err = parseBulkSubscriberJSON(body)
+	case "text/csv":
+		subscribers, err = parseBulkSubscriberCSV(body)
+	default:
+		writeProblem(c, http.StatusUnsupportedMediaType, configmodels.ProblemCodeUnsupportedMediaType, "Content-Type must be application/json or text/csv", requestID)
+		return
+	}
+	if err != nil {
+		logger.WebUILog.Errorf("Post Subscribers in Bulk - invalid request: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Invalid request body: %s", err), requestID)
+		return
+	}
+	if len(subscribers) == 0 || len(subscribers) > maxBulkSubscribers {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Request must contain between 1 and %d subscribers", maxBulkSubscribers), requestID)
+		return
+	}
+
+	var deviceGroup *configmodels.DeviceGroups
+	if groupName := c.Query("device-group"); groupName != "" {
+		rawDeviceGroup, err := dbadapter.CommonDBClient.RestfulAPIGetOne(devGroupDataColl, bson.M{"group-name": groupName})
+		if err != nil {
+			logger.DbLog.Errorf("failed to fetch device group %s: %+v request ID: %s", groupName, err, requestID)
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("failed to fetch device group %s", groupName), requestID)
+			return
+		}
+		if len(rawDeviceGroup) == 0 {
+			writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("device group %s not found", groupName), requestID)
+			return
+		}
+		deviceGroup = &configmodels.DeviceGroups{}
+		if err = json.Unmarshal(configmodels.MapToByte(rawDeviceGroup), deviceGroup); err != nil {
+			logger.DbLog.Errorf("could not unmarshal device group %s: %+v request ID: %s", groupName, err, requestID)
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("failed to fetch device group %s", groupName), requestID)
+			return
+		}
+	}
+
+	report, rows, updatedDeviceGroup, err := handleSubscriberBulkPost(c.Request.Context(), subscribers, deviceGroup)
+	if err != nil {
+		writeBulkSubscriberProblem(c, http.StatusInternalServerError, "Failed to create subscribers", report, requestID)
+		return
+	}
+	logger.WebUILog.Infof("created %d subscribers in bulk, rejected %d", report.Created, report.Rejected)
+	ueIds := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ueIds = append(ueIds, row.ueId)
+	}
+	setAuditedResourceIDs(c, ueIds)
+
+	var changes []configmodels.ConfigChange
+	if updatedDeviceGroup != nil {
+		if statusCode, err := syncDeviceGroupSubscriber(updatedDeviceGroup, deviceGroup); err != nil {
+			logger.WebUILog.Errorf("failed to sync device group %s subscribers: %+v request ID: %s", updatedDeviceGroup.DeviceGroupName, err, requestID)
+			writeBulkSubscriberProblem(c, statusCode, fmt.Sprintf("Subscribers created but device group %s could not be synchronized", updatedDeviceGroup.DeviceGroupName), report, requestID)
+			return
+		}
+		msg := configmodels.ConfigMessage{
+			MsgType:      configmodels.Device_group,
+			MsgMethod:    configmodels.Put_op,
+			DevGroup:     updatedDeviceGroup,
+			DevGroupName: updatedDeviceGroup.DeviceGroupName,
+			RequestID:    requestID,
+		}
+		configChannel <- &msg
+		changes = append(changes, newConfigChange(configmodels.ApplyKindDeviceGroup, configmodels.ApplyOpUpdate, updatedDeviceGroup.DeviceGroupName))
+	}
+	if len(rows) > 0 {
+		// a single message for the whole request, which can create thousands
+		// of subscribers
+		imsis := make([]string, 0, len(rows))
+		for _, row := range rows {
+			imsis = append(imsis, row.ueId)
+			changes = append(changes, newConfigChange(configmodels.ConfigEventTypeSubscriber, configmodels.ApplyOpCreate, row.ueId))
+		}
+		msg := configmodels.ConfigMessage{
+			MsgType:   configmodels.Sub_data,
+			MsgMethod: configmodels.Post_op,
+			Imsis:     imsis,
+			RequestID: requestID,
+		}
+		configChannel <- &msg
+	}
+	notifyConfigChanges(c.Request.Context(), changes...)
+
+	switch {
+	case report.Rejected == 0:
+		c.JSON(http.StatusCreated, report)
+	case report.Created > 0:
+		c.JSON(http.StatusMultiStatus, report)
+	default:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	}
+}
+
+// bulkSubscriberProblem is the problem details of a failed bulk request, with
+// the report of the subscribers processed before the failure.
+type bulkSubscriberProblem struct {
+	configmodels.ProblemDetails
+	Report *configmodels.BulkSubscriberReport `json:"report,omitempty"`
+}
+
+func writeBulkSubscriberProblem(c *gin.Context, status int, detail string, report *configmodels.BulkSubscriberReport, requestID string) {
+	problem := configmodels.ProblemDetails{
+		Status:    status,
+		Code:      problemdetails.Code(status),
+		Detail:    detail,
+		RequestID: requestID,
+	}
+	problemdetails.Complete(c, &problem)
+	c.Header("Content-Type", problemdetails.ContentType)
+	c.JSON(status, bulkSubscriberProblem{ProblemDetails: problem, Report: report})
+}
+
 // PutSubscriberByID godoc
 //
 // @Description  Update subscriber information by IMSI (UE ID)
 // @Tags         Subscribers
 // @Param        imsi       path    string                           true    "IMSI (UE ID)"
 // @Param        content    body    configmodels.SubsData            true    "Updated subscriber details"
+// @Param        If-Match  header  string  false  "Version the resource must be at"
 // @Security     BearerAuth
 // @Success      204  {object}  nil  "Subscriber updated successfully"
 // @Failure      400  {object}  nil  "Invalid subscriber content"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
 // @Failure      404  {object}  nil  "Subscriber not found"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure      500  {object}  nil  "Error updating subscriber"
 // @Router       /api/subscriber/{imsi}  [put]
 func PutSubscriberByID(c *gin.Context) {
 	setCorsHeader(c)
 	logger.WebUILog.Infoln("Put One Subscriber Data")
 	setCorsHeader(c)
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 	var subsOverrideData configmodels.SubsOverrideData
 	if err := c.ShouldBindJSON(&subsOverrideData); err != nil {
 		logger.WebUILog.Errorf("Put One Subscriber Data - ShouldBindJSON failed: %+v request ID: %s", err, requestID)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: failed to parse JSON.", "request_id": requestID})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "Invalid request body: failed to parse JSON.", requestID)
 		return
 	}
 
@@ -570,48 +768,29 @@ func PutSubscriberByID(c *gin.Context) {
 	subscriber, err := dbadapter.CommonDBClient.RestfulAPIGetOne(amDataColl, filter)
 	if err != nil {
 		logger.DbLog.Errorf("failed querying subscriber existence for IMSI: %s; Error: %+v", ueId, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to check subscriber: %s existence", ueId), "request_id": requestID})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("failed to check subscriber: %s existence", ueId), requestID)
 		return
 	}
 	if subscriber == nil {
 		logger.WebUILog.Errorf("subscriber %s does not exist", ueId)
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("subscriber %s does not exist", ueId)})
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("subscriber %s does not exist", ueId), requestID)
 		return
 	}
-	if subsOverrideData.OPc == "" || subsOverrideData.Key == "" || subsOverrideData.SequenceNumber == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required authentication data: OPc, Key and Sequence number must be provided", "request_id": requestID})
+	if err = setIfMatch(c, amDataColl, filter); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
 		return
 	}
-	authSubsData := models.AuthenticationSubscription{
-		AuthenticationManagementField: "8000",
-		AuthenticationMethod:          "5G_AKA",
-		Milenage: &models.Milenage{
-			Op: &models.Op{
-				EncryptionAlgorithm: 0,
-				EncryptionKey:       0,
-				OpValue:             "",
-			},
-		},
-		Opc: &models.Opc{
-			EncryptionAlgorithm: 0,
-			EncryptionKey:       0,
-			OpcValue:            subsOverrideData.OPc,
-		},
-		PermanentKey: &models.PermanentKey{
-			EncryptionAlgorithm: 0,
-			EncryptionKey:       0,
-			PermanentKeyValue:   subsOverrideData.Key,
-		},
-		SequenceNumber: subsOverrideData.SequenceNumber,
+	if subsOverrideData.OPc == "" || subsOverrideData.Key == "" || subsOverrideData.SequenceNumber == "" {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "Missing required authentication data: OPc, Key and Sequence number must be provided", requestID)
+		return
 	}
+	authSubsData := newAuthenticationSubscription(subsOverrideData.OPc, subsOverrideData.Key, subsOverrideData.SequenceNumber)
 
-	err = handleSubscriberPut(ueId, &authSubsData)
+	err = handleSubscriberPut(c.Request.Context(), ueId, &authSubsData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      fmt.Sprintf("Failed to update subscriber %s", ueId),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details",
-		})
+		if !writeVersionConflict(c, err, requestID) {
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("Failed to update subscriber %s", ueId), requestID)
+		}
 		return
 	}
 	logger.WebUILog.Infof("Subscriber %s updated successfully", ueId)
@@ -621,16 +800,109 @@ func PutSubscriberByID(c *gin.Context) {
 		MsgMethod:   configmodels.Put_op,
 		AuthSubData: &authSubsData,
 		Imsi:        ueId,
+		RequestID:   requestID,
 	}
 	configChannel <- &msg
+	notifyConfigChanges(c.Request.Context(), newConfigChange(configmodels.ConfigEventTypeSubscriber, configmodels.ApplyOpUpdate, ueId))
 
 	c.JSON(http.StatusNoContent, gin.H{})
 }
 
-// Patch subscriber by IMSI(ueId) and PlmnID(servingPlmnId)
+// PatchSubscriberByID godoc
+//
+// @Description  Partially update subscriber information by IMSI (UE ID). Accepts a JSON Merge Patch (application/merge-patch+json) or a JSON Patch (application/json-patch+json) applied to the AuthenticationSubscription and AccessAndMobilitySubscriptionData sections of the subscriber
+// @Tags         Subscribers
+// @Accept       application/merge-patch+json,application/json-patch+json
+// @Param        imsi       path    string    true    "IMSI (UE ID)"
+// @Param        content    body    object    true    "Merge patch or JSON patch document"
+// @Param        If-Match  header  string  false  "Version the resource must be at"
+// @Security     BearerAuth
+// @Success      204  {object}  nil  "Subscriber updated successfully"
+// @Failure      400  {object}  nil  "Invalid patch document"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Subscriber not found"
+// @Failure      409  {object}  nil  "JSON patch test operation failed, or concurrent update"
+// @Failure      415  {object}  nil  "Unsupported patch content type"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
+// @Failure      422  {object}  nil  "Patched subscriber is invalid"
+// @Failure      500  {object}  nil  "Error updating subscriber"
+// @Router       /api/subscriber/{imsi}  [patch]
 func PatchSubscriberByID(c *gin.Context) {
 	setCorsHeader(c)
 	logger.WebUILog.Infoln("Patch One Subscriber Data")
+	requestID := getRequestID(c)
+
+	body, err := c.GetRawData()
+	if err != nil {
+		logger.WebUILog.Errorf("Patch One Subscriber Data - failed to read body: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "Invalid request body", requestID)
+		return
+	}
+	var patch *subscriberPatch
+	switch c.ContentType() {
+	case mergePatchContentType:
+		patch, err = parseSubscriberMergePatch(body)
+	case jsonPatchContentType:
+		patch, err = parseSubscriberJSONPatch(body)
+	default:
+		writeProblem(c, http.StatusUnsupportedMediaType, configmodels.ProblemCodeUnsupportedMediaType, fmt.Sprintf("Content-Type must be %s or %s", mergePatchContentType, jsonPatchContentType), requestID)
+		return
+	}
+	if err != nil {
+		logger.WebUILog.Errorf("Patch One Subscriber Data - invalid patch: %+v request ID: %s", err, requestID)
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("Invalid patch document: %s", err), requestID)
+		return
+	}
+
+	ueId := c.Param("ueId")
+	logger.WebUILog.Infoln("Received Patch Subscriber Data:", ueId)
+
+	filter := bson.M{"ueId": ueId}
+	subscriber, err := dbadapter.CommonDBClient.RestfulAPIGetOne(amDataColl, filter)
+	if err != nil {
+		logger.DbLog.Errorf("failed querying subscriber existence for IMSI: %s; Error: %+v", ueId, err)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("failed to check subscriber: %s existence", ueId), requestID)
+		return
+	}
+	if subscriber == nil {
+		logger.WebUILog.Errorf("subscriber %s does not exist", ueId)
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("subscriber %s does not exist", ueId), requestID)
+		return
+	}
+	if err = setIfMatch(c, amDataColl, filter); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+
+	authSubsData, err := handleSubscriberPatch(c.Request.Context(), ueId, patch)
+	if err != nil {
+		var patchErr *subscriberPatchError
+		var validationErr *validationError
+		switch {
+		case writeVersionConflict(c, err, requestID):
+		case errors.As(err, &patchErr):
+			writeProblem(c, patchErr.statusCode, patchErr.code, patchErr.message, requestID)
+		case errors.As(err, &validationErr):
+			writeErrorProblem(c, http.StatusUnprocessableEntity, validationErr, requestID)
+		default:
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("Failed to update subscriber %s", ueId), requestID)
+		}
+		return
+	}
+	logger.WebUILog.Infof("Subscriber %s patched successfully", ueId)
+
+	msg := configmodels.ConfigMessage{
+		MsgType:     configmodels.Sub_data,
+		MsgMethod:   configmodels.Put_op,
+		AuthSubData: authSubsData,
+		Imsi:        ueId,
+		RequestID:   requestID,
+	}
+	configChannel <- &msg
+	notifyConfigChanges(c.Request.Context(), newConfigChange(configmodels.ConfigEventTypeSubscriber, configmodels.ApplyOpUpdate, ueId))
+
+	c.JSON(http.StatusNoContent, gin.H{})
 }
 
 // DeleteSubscriberByID godoc
@@ -638,33 +910,45 @@ func PatchSubscriberByID(c *gin.Context) {
 // @Description  Delete an existing subscriber
 // @Tags         Subscribers
 // @Param        imsi    path    string    true    "IMSI (UE ID)"
+// @Param        If-Match  header  string  false  "Version the resource must be at"
 // @Security     BearerAuth
 // @Success      204  {object}  nil  "Subscriber deleted successfully"
 // @Failure      401  {object}  nil  "Authorization failed"
 // @Failure      403  {object}  nil  "Forbidden"
+// @Failure      412  {object}  nil  "Resource not at the version given in If-Match"
 // @Failure      500  {object}  nil  "Error deleting subscriber"
 // @Router       /api/subscriber/{imsi}  [delete]
 func DeleteSubscriberByID(c *gin.Context) {
 	setCorsHeader(c)
 	logger.WebUILog.Infoln("Delete One Subscriber Data")
-	requestID := uuid.New().String()
+	requestID := getRequestID(c)
 
 	ueId := c.Param("ueId")
+	filter := bson.M{"ueId": ueId}
+	if err := setIfMatch(c, amDataColl, filter); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	if err := checkResourceVersionIfMatch(c.Request.Context(), dbadapter.CommonDBClient, amDataColl, filter); err != nil {
+		logger.DbLog.Errorf("failed to check subscriber %s for deletion: %+v", ueId, err)
+		if !writeVersionConflict(c, err, requestID) {
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("Failed to delete subscriber %s", ueId), requestID)
+		}
+		return
+	}
 
 	imsi := strings.TrimPrefix(ueId, "imsi-")
-	statusCode, err := updateSubscriberInDeviceGroups(imsi)
+	statusCode, err := updateSubscriberInDeviceGroups(c.Request.Context(), imsi)
 	if err != nil {
 		logger.WebUILog.Errorf("Failed to update subscriber: %+v request ID: %s", err, requestID)
-		c.JSON(statusCode, gin.H{"error": "error deleting subscriber. Please check the log for details.", "request_id": requestID})
+		writeProblem(c, statusCode, problemdetails.Code(statusCode), "error deleting subscriber. Please check the log for details.", requestID)
 		return
 	}
-	if err = handleSubscriberDelete(ueId); err != nil {
+	if err = handleSubscriberDelete(c.Request.Context(), ueId); err != nil {
 		logger.WebUILog.Errorf("Error deleting subscriber: %s", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      fmt.Sprintf("Failed to delete subscriber %s", ueId),
-			"request_id": requestID,
-			"message":    "Please refer to the log with the provided Request ID for details",
-		})
+		if !writeVersionConflict(c, err, requestID) {
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, fmt.Sprintf("Failed to delete subscriber %s", ueId), requestID)
+		}
 		return
 	}
 	logger.WebUILog.Infof("Subscriber %s deleted successfully", ueId)
@@ -673,14 +957,17 @@ func DeleteSubscriberByID(c *gin.Context) {
 		MsgType:   configmodels.Sub_data,
 		MsgMethod: configmodels.Delete_op,
 		Imsi:      ueId,
+		RequestID: requestID,
 	}
 	configChannel <- &msg
+	notifyConfigChanges(c.Request.Context(), newConfigChange(configmodels.ConfigEventTypeSubscriber, configmodels.ApplyOpDelete, ueId))
 
 	c.JSON(http.StatusNoContent, gin.H{})
 }
 
 func GetRegisteredUEContext(c *gin.Context) {
 	setCorsHeader(c)
+	requestID := getRequestID(c)
 
 	logger.WebUILog.Infoln("Get Registered UE Context")
 
@@ -702,19 +989,18 @@ func GetRegisteredUEContext(c *gin.Context) {
 		resp, err := httpsClient.Get(requestUri)
 		if err != nil {
 			logger.WebUILog.Error(err)
-			c.JSON(http.StatusInternalServerError, gin.H{})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, err.Error(), requestID)
 			return
 		}
-		sendResponseToClient(c, resp)
+		sendResponseToClient(c, resp, requestID)
 	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"cause": "No AMF Found",
-		})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "No AMF Found", requestID)
 	}
 }
 
 func GetUEPDUSessionInfo(c *gin.Context) {
 	setCorsHeader(c)
+	requestID := getRequestID(c)
 
 	logger.WebUILog.Infoln("Get UE PDU Session Info")
 
@@ -723,7 +1009,7 @@ func GetUEPDUSessionInfo(c *gin.Context) {
 
 	smContextRef, smContextRefExists := c.Params.Get("smContextRef")
 	if !smContextRefExists {
-		c.JSON(http.StatusBadRequest, gin.H{})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "smContextRef parameter is missing", requestID)
 		return
 	}
 
@@ -733,14 +1019,12 @@ func GetUEPDUSessionInfo(c *gin.Context) {
 		resp, err := httpsClient.Get(requestUri)
 		if err != nil {
 			logger.WebUILog.Error(err)
-			c.JSON(http.StatusInternalServerError, gin.H{})
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, err.Error(), requestID)
 			return
 		}
 
-		sendResponseToClient(c, resp)
+		sendResponseToClient(c, resp, requestID)
 	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"cause": "No SMF Found",
-		})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "No SMF Found", requestID)
 	}
 }
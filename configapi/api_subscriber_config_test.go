@@ -5,6 +5,7 @@ package configapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -65,6 +66,14 @@ func (m *MockMongoClientDeviceGroupsWithSubscriber) RestfulAPIDeleteOne(coll str
 	return nil
 }
 
+func (m *MockMongoClientDeviceGroupsWithSubscriber) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (m *MockMongoClientDeviceGroupsWithSubscriber) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 func (m *MockMongoClientManySubscribers) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 	ueIds := []string{"208930100007487", "208930100007488"}
@@ -342,13 +351,20 @@ func TestGetSubscriberByID(t *testing.T) {
 		expectedAuthPostDataDetails   []map[string]interface{}
 	}{
 		{
-			name:                 "No subscriber data found",
-			ueId:                 "imsi-2089300007487",
-			route:                "/api/subscriber/:ueId",
-			commonDbAdapter:      &MockCommonDBClientEmpty{PostDataCommon: &postDataCommon},
-			authDbAdapter:        &MockAuthDBClientEmpty{PostDataAuth: &postDataAuth},
-			expectedHTTPStatus:   http.StatusNotFound,
-			expectedFullResponse: map[string]interface{}{"error": "subscriber with ID imsi-2089300007487 not found"},
+			name:               "No subscriber data found",
+			ueId:               "imsi-2089300007487",
+			route:              "/api/subscriber/:ueId",
+			commonDbAdapter:    &MockCommonDBClientEmpty{PostDataCommon: &postDataCommon},
+			authDbAdapter:      &MockAuthDBClientEmpty{PostDataAuth: &postDataAuth},
+			expectedHTTPStatus: http.StatusNotFound,
+			expectedFullResponse: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Not Found",
+				"status":   http.StatusNotFound,
+				"detail":   "subscriber with ID imsi-2089300007487 not found",
+				"instance": "/api/subscriber/imsi-2089300007487",
+				"code":     "not-found",
+			},
 			expectedCommonPostDataDetails: []map[string]interface{}{
 				{"coll": "subscriptionData.provisionedData.amData", "filter": map[string]interface{}{"ueId": "imsi-2089300007487"}},
 				{"coll": "subscriptionData.provisionedData.smData", "filter": map[string]interface{}{"ueId": "imsi-2089300007487"}},
@@ -507,6 +523,7 @@ func TestGetSubscriberByID(t *testing.T) {
 			if err := json.Unmarshal([]byte(responseContent), &actual); err != nil {
 				t.Fatalf("Failed to unmarshal actual response: %v. Raw response: %s", err, responseContent)
 			}
+			delete(actual, "request_id")
 			expectedResponse, err := json.Marshal(tt.expectedFullResponse)
 			if err != nil {
 				t.Fatalf("failed to marshal expected response: %v", err)
@@ -584,7 +601,7 @@ func TestSubscriberGetHandlers(t *testing.T) {
 			route:        "/api/subscriber",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"error":"failed to retrieve subscribers list"}`,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"failed to retrieve subscribers list","instance":"/api/subscriber","code":"internal-error"}`,
 		},
 	}
 	for _, tc := range testCases {
@@ -603,7 +620,7 @@ func TestSubscriberGetHandlers(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if w.Body.String() != tc.expectedBody {
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -1010,3 +1027,531 @@ func deviceGroupWithoutImsi() *configmodels.DeviceGroups {
 	tmp.Imsis = slices.Delete(tmp.Imsis, 0, 1)
 	return &tmp
 }
+
+func TestSubscriberPatchHandler(t *testing.T) {
+	existingSubscriber := func(coll string, filter bson.M) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"ueId":             "imsi-208930100007487",
+			"subscribedUeAmbr": map[string]interface{}{"uplink": "1 Mbps", "downlink": "1 Mbps"},
+		}, nil
+	}
+	noSubscriber := func(coll string, filter bson.M) (map[string]interface{}, error) {
+		return nil, nil
+	}
+	testCases := []struct {
+		name          string
+		contentType   string
+		body          string
+		commonGetOne  func(coll string, filter bson.M) (map[string]interface{}, error)
+		expectedCode  int
+		expectedBody  string
+		expectMessage bool
+	}{
+		{
+			name:          "unsupported content type",
+			contentType:   "application/json",
+			body:          `{"AuthenticationSubscription": {"sequenceNumber": "16f3b3f70fc3"}}`,
+			commonGetOne:  existingSubscriber,
+			expectedCode:  http.StatusUnsupportedMediaType,
+			expectedBody:  "Content-Type must be application/merge-patch+json or application/json-patch+json",
+			expectMessage: false,
+		},
+		{
+			name:          "invalid patch document",
+			contentType:   mergePatchContentType,
+			body:          `{"SmPolicyData": {}}`,
+			commonGetOne:  existingSubscriber,
+			expectedCode:  http.StatusBadRequest,
+			expectedBody:  "unsupported patch field: SmPolicyData",
+			expectMessage: false,
+		},
+		{
+			name:          "subscriber does not exist",
+			contentType:   mergePatchContentType,
+			body:          `{"AuthenticationSubscription": {"sequenceNumber": "16f3b3f70fc3"}}`,
+			commonGetOne:  noSubscriber,
+			expectedCode:  http.StatusNotFound,
+			expectedBody:  "subscriber imsi-208930100007487 does not exist",
+			expectMessage: false,
+		},
+		{
+			name:          "merge patch success",
+			contentType:   mergePatchContentType,
+			body:          `{"AuthenticationSubscription": {"sequenceNumber": "16f3b3f70fc3"}}`,
+			commonGetOne:  existingSubscriber,
+			expectedCode:  http.StatusNoContent,
+			expectMessage: true,
+		},
+		{
+			name:          "failed JSON patch test",
+			contentType:   jsonPatchContentType,
+			body:          `[{"op": "test", "path": "/AuthenticationSubscription/sequenceNumber", "value": "000000000000"}]`,
+			commonGetOne:  existingSubscriber,
+			expectedCode:  http.StatusConflict,
+			expectedBody:  `"code":"patch-test-failed"`,
+			expectMessage: false,
+		},
+		{
+			name:          "JSON patch path missing in subscriber",
+			contentType:   jsonPatchContentType,
+			body:          `[{"op": "remove", "path": "/AccessAndMobilitySubscriptionData/nssai"}]`,
+			commonGetOne:  existingSubscriber,
+			expectedCode:  http.StatusBadRequest,
+			expectedBody:  "patch does not apply to AccessAndMobilitySubscriptionData",
+			expectMessage: false,
+		},
+		{
+			name:          "patched subscriber invalid",
+			contentType:   mergePatchContentType,
+			body:          `{"AuthenticationSubscription": {"opc": null}}`,
+			commonGetOne:  existingSubscriber,
+			expectedCode:  http.StatusUnprocessableEntity,
+			expectedBody:  `"field":"AuthenticationSubscription.opc.opcValue"`,
+			expectMessage: false,
+		},
+		{
+			name:          "JSON patch success",
+			contentType:   jsonPatchContentType,
+			body:          `[{"op": "replace", "path": "/AccessAndMobilitySubscriptionData/subscribedUeAmbr/uplink", "value": "2 Mbps"}]`,
+			commonGetOne:  existingSubscriber,
+			expectedCode:  http.StatusNoContent,
+			expectMessage: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.Default()
+			AddApiService(router)
+
+			authDB := &mockDB{
+				getOneFunc: func(coll string, filter bson.M) (map[string]interface{}, error) {
+					return map[string]interface{}{
+						"ueId":           "imsi-208930100007487",
+						"opc":            map[string]interface{}{"opcValue": "981d464c7c52eb6e5036234984ad0bcf"},
+						"permanentKey":   map[string]interface{}{"permanentKeyValue": "5122250214c33e723a5dd523fc145fc0"},
+						"sequenceNumber": "16f3b3f70fc3",
+					}, nil
+				},
+				replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+					return true, nil
+				},
+			}
+			commonDB := &mockDB{
+				getOneFunc: tc.commonGetOne,
+				replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+					return true, nil
+				},
+			}
+			origAuthDB := dbadapter.AuthDBClient
+			origCommonDB := dbadapter.CommonDBClient
+			origChannel := configChannel
+			configChannel = make(chan *configmodels.ConfigMessage, 1)
+			defer func() {
+				configChannel = origChannel
+				dbadapter.AuthDBClient = origAuthDB
+				dbadapter.CommonDBClient = origCommonDB
+			}()
+			dbadapter.AuthDBClient = authDB
+			dbadapter.CommonDBClient = commonDB
+
+			req, err := http.NewRequest(http.MethodPatch, "/api/subscriber/imsi-208930100007487", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", tc.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
+			}
+			select {
+			case msg := <-configChannel:
+				if !tc.expectMessage {
+					t.Errorf("expected no message in configChannel, but got %+v", msg)
+					return
+				}
+				if msg.MsgType != configmodels.Sub_data || msg.MsgMethod != configmodels.Put_op || msg.Imsi != "imsi-208930100007487" {
+					t.Errorf("unexpected config message %+v", msg)
+				}
+				if msg.AuthSubData == nil || msg.AuthSubData.SequenceNumber != "16f3b3f70fc3" {
+					t.Errorf("expected patched authentication data in config message, got %+v", msg.AuthSubData)
+				}
+			default:
+				if tc.expectMessage {
+					t.Error("expected message in configChannel, but got none")
+				}
+			}
+		})
+	}
+}
+
+type MockMongoClientBulkSubscribers struct {
+	MockMongoClientEmptyDB
+	existingUeIds []string
+	postedDocs    map[string][]interface{}
+	putDocs       map[string]map[string]interface{}
+}
+
+func (m *MockMongoClientBulkSubscribers) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	if coll == amDataColl {
+		for _, ueId := range m.existingUeIds {
+			results = append(results, map[string]interface{}{"ueId": ueId})
+		}
+	}
+	return results, nil
+}
+
+func (m *MockMongoClientBulkSubscribers) RestfulAPIGetOne(coll string, filter bson.M) (map[string]interface{}, error) {
+	if coll == devGroupDataColl && filter["group-name"] == "group1" {
+		return configmodels.ToBsonM(deviceGroupWithImsis("group1", []string{"001010000000099"})), nil
+	}
+	return nil, nil
+}
+
+func (m *MockMongoClientBulkSubscribers) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	m.postedDocs[collName] = append(m.postedDocs[collName], postDataArray...)
+	return nil
+}
+
+func (m *MockMongoClientBulkSubscribers) RestfulAPIPutOneWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	m.putDocs[collName] = putData
+	return true, nil
+}
+
+func TestSubscriberBulkPostHandler(t *testing.T) {
+	validRow := func(imsi string) string {
+		return fmt.Sprintf(`{"ueId": "%s", "opc": "981d464c7c52eb6e5036234984ad0bcf", "key": "5122250214c33e723a5dd523fc145fc0", "sequenceNumber": "16f3b3f70fc2"}`, imsi)
+	}
+	testCases := []struct {
+		name                string
+		route               string
+		contentType         string
+		body                string
+		existingUeIds       []string
+		expectedCode        int
+		expectedStatuses    []string
+		expectedAuthDocs    int
+		expectedGroupImsis  []string
+		expectedMsgs        int
+		expectedBodyContent string
+	}{
+		{
+			name:             "JSON list all created",
+			route:            "/api/subscriber:bulk",
+			contentType:      "application/json",
+			body:             "[" + validRow("imsi-001010000000001") + "," + validRow("001010000000002") + "]",
+			expectedCode:     http.StatusCreated,
+			expectedStatuses: []string{configmodels.BulkResultCreated, configmodels.BulkResultCreated},
+			expectedAuthDocs: 2,
+			expectedMsgs:     1,
+		},
+		{
+			name:        "CSV with device group",
+			route:       "/api/subscriber:bulk?device-group=group1",
+			contentType: "text/csv",
+			body: "imsi,ki,opc,sqn\n" +
+				"001010000000001,5122250214c33e723a5dd523fc145fc0,981d464c7c52eb6e5036234984ad0bcf,16f3b3f70fc2\n",
+			expectedCode:       http.StatusCreated,
+			expectedStatuses:   []string{configmodels.BulkResultCreated},
+			expectedAuthDocs:   1,
+			expectedGroupImsis: []string{"001010000000099", "001010000000001"},
+			expectedMsgs:       2,
+		},
+		{
+			name:             "partially rejected",
+			route:            "/api/subscriber:bulk",
+			contentType:      "application/json",
+			body:             "[" + validRow("imsi-001010000000001") + "," + validRow("imsi-001010000000002") + "," + validRow("imsi-001010000000001") + `,{"ueId": "imsi-1"}]`,
+			existingUeIds:    []string{"imsi-001010000000002"},
+			expectedCode:     http.StatusMultiStatus,
+			expectedStatuses: []string{configmodels.BulkResultCreated, configmodels.BulkResultConflict, configmodels.BulkResultInvalid, configmodels.BulkResultInvalid},
+			expectedAuthDocs: 1,
+			expectedMsgs:     1,
+		},
+		{
+			name:             "nothing created",
+			route:            "/api/subscriber:bulk",
+			contentType:      "application/json",
+			body:             "[" + validRow("imsi-001010000000002") + "]",
+			existingUeIds:    []string{"imsi-001010000000002"},
+			expectedCode:     http.StatusUnprocessableEntity,
+			expectedStatuses: []string{configmodels.BulkResultConflict},
+		},
+		{
+			name:             "range specification",
+			route:            "/api/subscriber:bulk",
+			contentType:      "application/json",
+			body:             `{"startImsi": "001010000000001", "count": 3, "sequenceNumber": "16f3b3f70fc2", "keyDerivation": {"method": "hmac-sha256", "masterKey": "000102030405060708090a0b0c0d0e0f", "op": "cdc202d5123e20f62b6d676ac72cb318"}}`,
+			expectedCode:     http.StatusCreated,
+			expectedStatuses: []string{configmodels.BulkResultCreated, configmodels.BulkResultCreated, configmodels.BulkResultCreated},
+			expectedAuthDocs: 3,
+			expectedMsgs:     1,
+		},
+		{
+			name:                "device group not found",
+			route:               "/api/subscriber:bulk?device-group=group2",
+			contentType:         "application/json",
+			body:                "[" + validRow("imsi-001010000000001") + "]",
+			expectedCode:        http.StatusNotFound,
+			expectedBodyContent: "device group group2 not found",
+		},
+		{
+			name:                "unsupported content type",
+			route:               "/api/subscriber:bulk",
+			contentType:         "text/plain",
+			body:                "001010000000001",
+			expectedCode:        http.StatusUnsupportedMediaType,
+			expectedBodyContent: "Content-Type must be application/json or text/csv",
+		},
+		{
+			name:                "unknown action",
+			route:               "/api/subscriber:import",
+			contentType:         "application/json",
+			body:                "[]",
+			expectedCode:        http.StatusNotFound,
+			expectedBodyContent: "unknown subscriber action :import",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.Default()
+			AddApiService(router)
+
+			dbClient := &MockMongoClientBulkSubscribers{
+				existingUeIds: tc.existingUeIds,
+				postedDocs:    map[string][]interface{}{},
+				putDocs:       map[string]map[string]interface{}{},
+			}
+			origAuthDB := dbadapter.AuthDBClient
+			origCommonDB := dbadapter.CommonDBClient
+			origChannel := configChannel
+			configChannel = make(chan *configmodels.ConfigMessage, 10)
+			defer func() {
+				configChannel = origChannel
+				dbadapter.AuthDBClient = origAuthDB
+				dbadapter.CommonDBClient = origCommonDB
+			}()
+			dbadapter.AuthDBClient = dbClient
+			dbadapter.CommonDBClient = dbClient
+
+			req, err := http.NewRequest(http.MethodPost, tc.route, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", tc.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("Expected `%v`, got `%v` body: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBodyContent) {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedBodyContent, w.Body.String())
+			}
+			if tc.expectedStatuses != nil {
+				var report configmodels.BulkSubscriberReport
+				if err = json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+					t.Fatalf("failed to unmarshal report: %v", err)
+				}
+				var statuses []string
+				for _, result := range report.Results {
+					statuses = append(statuses, result.Status)
+				}
+				if !reflect.DeepEqual(statuses, tc.expectedStatuses) {
+					t.Errorf("expected statuses %v, got %v", tc.expectedStatuses, statuses)
+				}
+			}
+			if len(dbClient.postedDocs[authSubsDataColl]) != tc.expectedAuthDocs || len(dbClient.postedDocs[amDataColl]) != tc.expectedAuthDocs {
+				t.Errorf("expected %d documents per collection, got %d auth and %d amData", tc.expectedAuthDocs, len(dbClient.postedDocs[authSubsDataColl]), len(dbClient.postedDocs[amDataColl]))
+			}
+			if tc.expectedGroupImsis != nil {
+				var deviceGroup configmodels.DeviceGroups
+				if err = json.Unmarshal(configmodels.MapToByte(dbClient.putDocs[devGroupDataColl]), &deviceGroup); err != nil {
+					t.Fatalf("failed to unmarshal device group: %v", err)
+				}
+				if !reflect.DeepEqual(deviceGroup.Imsis, tc.expectedGroupImsis) {
+					t.Errorf("expected device group IMSIs %v, got %v", tc.expectedGroupImsis, deviceGroup.Imsis)
+				}
+				if len(dbClient.postedDocs[configmodels.ConfigRevisionDataColl]) != 1 {
+					t.Errorf("expected a revision of the device group, got %v", dbClient.postedDocs[configmodels.ConfigRevisionDataColl])
+				}
+			}
+			if len(configChannel) != tc.expectedMsgs {
+				t.Errorf("expected %d config messages, got %d", tc.expectedMsgs, len(configChannel))
+			}
+			for len(configChannel) > 0 {
+				msg := <-configChannel
+				if msg.MsgType == configmodels.Sub_data && len(msg.Imsis) != tc.expectedAuthDocs {
+					t.Errorf("expected the message of the %d created subscribers, got %v", tc.expectedAuthDocs, msg.Imsis)
+				}
+			}
+		})
+	}
+}
+
+type MockMongoClientPagedSubscribers struct {
+	dbadapter.DBInterface
+	subscribers []map[string]interface{}
+	countFilter bson.M
+	pageFilter  bson.M
+	pageLimit   int64
+}
+
+func (m *MockMongoClientPagedSubscribers) RestfulAPICount(collName string, filter bson.M) (int64, error) {
+	m.countFilter = filter
+	return int64(len(m.subscribers)), nil
+}
+
+func (m *MockMongoClientPagedSubscribers) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	m.pageFilter = filter
+	m.pageLimit = limit
+	if int64(len(m.subscribers)) > limit {
+		return m.subscribers[:limit], nil
+	}
+	return m.subscribers, nil
+}
+
+func (m *MockMongoClientPagedSubscribers) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	if collName == devGroupDataColl && filter["group-name"] == "group1" {
+		return configmodels.ToBsonM(deviceGroupWithImsis("group1", []string{"208930100007487"})), nil
+	}
+	return nil, nil
+}
+
+func TestGetSubscribersPaged(t *testing.T) {
+	subscribers := []map[string]interface{}{
+		{"ueId": "imsi-208930100007487", "servingPlmnId": "20893"},
+		{"ueId": "imsi-208930100007488", "servingPlmnId": "20893"},
+		{"ueId": "imsi-208930100007489", "servingPlmnId": "20893"},
+	}
+	testCases := []struct {
+		name                string
+		query               string
+		expectedCode        int
+		expectedPage        *configmodels.SubsListPage
+		expectedCountFilter bson.M
+		expectedPageFilter  bson.M
+		expectedLimit       int64
+		expectedBody        string
+	}{
+		{
+			name:         "first page",
+			query:        "?limit=2",
+			expectedCode: http.StatusOK,
+			expectedPage: &configmodels.SubsListPage{
+				Subscribers: []configmodels.SubsListIE{
+					{UeId: "imsi-208930100007487", PlmnID: "20893"},
+					{UeId: "imsi-208930100007488", PlmnID: "20893"},
+				},
+				Total:         3,
+				NextPageToken: encodePageToken("imsi-208930100007488"),
+			},
+			expectedCountFilter: bson.M{},
+			expectedPageFilter:  bson.M{},
+			expectedLimit:       3,
+		},
+		{
+			name:                "next page",
+			query:               "?limit=2&page_token=" + encodePageToken("imsi-208930100007488"),
+			expectedCode:        http.StatusOK,
+			expectedCountFilter: bson.M{},
+			expectedPageFilter:  bson.M{"$and": []bson.M{{}, {"ueId": bson.M{"$gt": "imsi-208930100007488"}}}},
+			expectedLimit:       3,
+		},
+		{
+			name:                "IMSI prefix and PLMN filters",
+			query:               "?imsi_prefix=20893&plmn_id=20893",
+			expectedCode:        http.StatusOK,
+			expectedCountFilter: bson.M{"ueId": bson.M{"$regex": "^imsi-20893"}, "servingPlmnId": "20893"},
+			expectedPageFilter:  bson.M{"ueId": bson.M{"$regex": "^imsi-20893"}, "servingPlmnId": "20893"},
+			expectedLimit:       defaultSubscriberPageSize + 1,
+		},
+		{
+			name:                "IMSI range filter",
+			query:               "?imsi_from=208930100007487&imsi_to=imsi-208930100007488",
+			expectedCode:        http.StatusOK,
+			expectedCountFilter: bson.M{"ueId": bson.M{"$gte": "imsi-208930100007487", "$lte": "imsi-208930100007488"}},
+			expectedPageFilter:  bson.M{"ueId": bson.M{"$gte": "imsi-208930100007487", "$lte": "imsi-208930100007488"}},
+			expectedLimit:       defaultSubscriberPageSize + 1,
+		},
+		{
+			name:                "device group filter",
+			query:               "?device_group=group1",
+			expectedCode:        http.StatusOK,
+			expectedCountFilter: bson.M{"ueId": bson.M{"$in": []string{"imsi-208930100007487"}}},
+			expectedPageFilter:  bson.M{"ueId": bson.M{"$in": []string{"imsi-208930100007487"}}},
+			expectedLimit:       defaultSubscriberPageSize + 1,
+		},
+		{
+			name:         "device group not found",
+			query:        "?device_group=group2",
+			expectedCode: http.StatusNotFound,
+			expectedBody: "device group group2 not found",
+		},
+		{
+			name:         "invalid limit",
+			query:        "?limit=0",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "limit must be an integer between 1 and 1000",
+		},
+		{
+			name:         "invalid page token",
+			query:        "?page_token=abc",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid page_token",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.Default()
+			AddApiService(router)
+			dbClient := &MockMongoClientPagedSubscribers{subscribers: subscribers}
+			origDBClient := dbadapter.CommonDBClient
+			defer func() { dbadapter.CommonDBClient = origDBClient }()
+			dbadapter.CommonDBClient = dbClient
+
+			req, err := http.NewRequest(http.MethodGet, "/api/subscriber"+tc.query, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("Expected `%v`, got `%v` body: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			if tc.expectedPage != nil {
+				var page configmodels.SubsListPage
+				if err = json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+					t.Fatalf("failed to unmarshal page: %v", err)
+				}
+				if !reflect.DeepEqual(page, *tc.expectedPage) {
+					t.Errorf("expected page %+v, got %+v", *tc.expectedPage, page)
+				}
+			}
+			if !reflect.DeepEqual(dbClient.countFilter, tc.expectedCountFilter) {
+				t.Errorf("expected count filter %v, got %v", tc.expectedCountFilter, dbClient.countFilter)
+			}
+			if !reflect.DeepEqual(dbClient.pageFilter, tc.expectedPageFilter) {
+				t.Errorf("expected page filter %v, got %v", tc.expectedPageFilter, dbClient.pageFilter)
+			}
+			if dbClient.pageLimit != tc.expectedLimit {
+				t.Errorf("expected limit %d, got %d", tc.expectedLimit, dbClient.pageLimit)
+			}
+		})
+	}
+}
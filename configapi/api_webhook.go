@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultWebhookDeliveryPageSize = 100
+	maxWebhookDeliveryPageSize     = 1000
+)
+
+// AddWebhookService registers the webhook routes. When jwtKeys is nil
+// authentication is disabled and the routes are not protected.
+func AddWebhookService(engine *gin.Engine, jwtKeys *auth.JWTKeys) {
+	group := engine.Group("/config/v1")
+	addRoutes(group, getWebhookRoutes(jwtKeys))
+}
+
+func getWebhookRoutes(jwtKeys *auth.JWTKeys) Routes {
+	webhookRoutes := Routes{
+		{"GetWebhooks", http.MethodGet, "/webhooks", GetWebhooks},
+		{"PostWebhook", http.MethodPost, "/webhooks", PostWebhook},
+		{"GetWebhook", http.MethodGet, "/webhooks/:webhook-name", GetWebhook},
+		{"DeleteWebhook", http.MethodDelete, "/webhooks/:webhook-name", DeleteWebhook},
+		{"GetWebhookDeliveries", http.MethodGet, "/webhooks/:webhook-name/deliveries", GetWebhookDeliveries},
+	}
+	for i := range webhookRoutes {
+		webhookRoutes[i].HandlerFunc = auditedHandler(webhookRoutes[i].HandlerFunc)
+		if jwtKeys != nil {
+			webhookRoutes[i].HandlerFunc = auth.AdminOnly(jwtKeys, webhookRoutes[i].HandlerFunc)
+		}
+	}
+	return webhookRoutes
+}
+
+// withoutSecret hides the secret of a webhook in the API responses.
+func withoutSecret(webhook configmodels.Webhook) configmodels.Webhook {
+	webhook.Secret = ""
+	return webhook
+}
+
+func webhookInternalError(c *gin.Context, message string, err error, requestID string) {
+	logger.DbLog.Errorf("%s: %+v request ID: %s", message, err, requestID)
+	writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, message, requestID)
+}
+
+// GetWebhooks godoc
+//
+// @Description  Return the list of webhooks, without their secrets
+// @Tags         Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.Webhook  "List of webhooks"
+// @Failure      401  {object}  nil                   "Authorization failed"
+// @Failure      403  {object}  nil                   "Forbidden"
+// @Failure      500  {object}  nil                   "Error retrieving webhooks"
+// @Router       /config/v1/webhooks  [get]
+func GetWebhooks(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a GET webhooks request")
+	webhooks, err := getWebhooks()
+	if err != nil {
+		webhookInternalError(c, "failed to retrieve webhooks", err, requestID)
+		return
+	}
+	response := make([]configmodels.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, withoutSecret(webhook))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWebhook godoc
+//
+// @Description  Return a webhook, without its secret
+// @Tags         Webhooks
+// @Produce      json
+// @Param        webhook-name  path  string  true  "Name of the webhook"
+// @Security     BearerAuth
+// @Success      200  {object}  configmodels.Webhook  "Webhook"
+// @Failure      401  {object}  nil                   "Authorization failed"
+// @Failure      403  {object}  nil                   "Forbidden"
+// @Failure      404  {object}  nil                   "Webhook not found"
+// @Failure      500  {object}  nil                   "Error retrieving the webhook"
+// @Router       /config/v1/webhooks/{webhook-name}  [get]
+func GetWebhook(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	name := c.Param("webhook-name")
+	logger.WebUILog.Infof("received a GET webhook %s request", name)
+	webhook, err := getWebhook(name)
+	if err != nil {
+		webhookInternalError(c, "failed to retrieve webhook", err, requestID)
+		return
+	}
+	if webhook == nil {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("webhook %s not found", name), requestID)
+		return
+	}
+	c.JSON(http.StatusOK, withoutSecret(*webhook))
+}
+
+// PostWebhook godoc
+//
+// @Description  Register a webhook notified of the configuration changes. The payloads are signed with the HMAC-SHA256 of the secret in the X-Webconsole-Signature header
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhook  body  configmodels.Webhook  true  "Webhook"
+// @Security     BearerAuth
+// @Success      201  {object}  nil  "Webhook created"
+// @Failure      400  {object}  nil  "Invalid webhook"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      409  {object}  nil  "Webhook already exists"
+// @Failure      500  {object}  nil  "Error creating the webhook"
+// @Router       /config/v1/webhooks  [post]
+func PostWebhook(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("received a POST webhook request")
+	var webhook configmodels.Webhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "invalid JSON format", requestID)
+		return
+	}
+	webhook.ReadOnly = false
+	if err := validateWebhook(webhook); err != nil {
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
+	existing, err := getWebhook(webhook.Name)
+	if err != nil {
+		webhookInternalError(c, "failed to create webhook", err, requestID)
+		return
+	}
+	if existing != nil {
+		writeProblem(c, http.StatusConflict, configmodels.ProblemCodeAlreadyExists, fmt.Sprintf("webhook %s already exists", webhook.Name), requestID)
+		return
+	}
+	if _, err = dbadapter.CommonDBClient.RestfulAPIPost(configmodels.WebhookDataColl, bson.M{"name": webhook.Name}, configmodels.ToBsonM(webhook)); err != nil {
+		webhookInternalError(c, "failed to create webhook", err, requestID)
+		return
+	}
+	logger.WebUILog.Infof("successfully created webhook %s", webhook.Name)
+	c.JSON(http.StatusCreated, gin.H{})
+}
+
+// DeleteWebhook godoc
+//
+// @Description  Delete a webhook registered through the API. Its pending deliveries fail
+// @Tags         Webhooks
+// @Produce      json
+// @Param        webhook-name  path  string  true  "Name of the webhook"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Webhook deleted"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Webhook not found"
+// @Failure      409  {object}  nil  "Webhook defined in the configuration file"
+// @Failure      500  {object}  nil  "Error deleting the webhook"
+// @Router       /config/v1/webhooks/{webhook-name}  [delete]
+func DeleteWebhook(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	name := c.Param("webhook-name")
+	logger.WebUILog.Infof("received a DELETE webhook %s request", name)
+	webhook, err := getWebhook(name)
+	if err != nil {
+		webhookInternalError(c, "failed to delete webhook", err, requestID)
+		return
+	}
+	if webhook == nil {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, fmt.Sprintf("webhook %s not found", name), requestID)
+		return
+	}
+	if webhook.ReadOnly {
+		writeProblem(c, http.StatusConflict, configmodels.ProblemCodeReadOnly, fmt.Sprintf("webhook %s is defined in the configuration file", name), requestID)
+		return
+	}
+	if err = dbadapter.CommonDBClient.RestfulAPIDeleteOne(configmodels.WebhookDataColl, bson.M{"name": name}); err != nil {
+		webhookInternalError(c, "failed to delete webhook", err, requestID)
+		return
+	}
+	logger.WebUILog.Infof("successfully deleted webhook %s", name)
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// GetWebhookDeliveries godoc
+//
+// @Description  Return the deliveries of a webhook, newest first
+// @Tags         Webhooks
+// @Produce      json
+// @Param        webhook-name  path   string  true   "Name of the webhook"
+// @Param        status        query  string  false  "Only return deliveries with this status: pending, delivered or failed"
+// @Param        limit         query  int     false  "Maximum number of deliveries (default 100, max 1000)"
+// @Param        offset        query  int     false  "Number of deliveries to skip"
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.WebhookDelivery  "Webhook deliveries"
+// @Failure      400  {object}  nil                           "Invalid query parameter"
+// @Failure      401  {object}  nil                           "Authorization failed"
+// @Failure      403  {object}  nil                           "Forbidden"
+// @Failure      500  {object}  nil                           "Error retrieving the deliveries"
+// @Router       /config/v1/webhooks/{webhook-name}/deliveries  [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	setCorsHeader(c)
+	requestID := getRequestID(c)
+	name := c.Param("webhook-name")
+	logger.WebUILog.Infof("received a GET webhook %s deliveries request", name)
+	status := c.Query("status")
+	validStatuses := []string{configmodels.WebhookDeliveryPending, configmodels.WebhookDeliveryDelivered, configmodels.WebhookDeliveryFailed}
+	if status != "" && !slices.Contains(validStatuses, status) {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, fmt.Sprintf("status must be one of %v", validStatuses), requestID)
+		return
+	}
+	offset, limit, err := parsePageQuery(c.Request.URL.Query(), defaultWebhookDeliveryPageSize, maxWebhookDeliveryPageSize)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, err.Error(), requestID)
+		return
+	}
+	deliveries, err := getWebhookDeliveries(name, status, offset, limit)
+	if err != nil {
+		webhookInternalError(c, "failed to retrieve webhook deliveries", err, requestID)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
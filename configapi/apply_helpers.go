@@ -0,0 +1,489 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/omec-project/openapi/models"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/yaml.v2"
+)
+
+type applyKind struct {
+	collection string
+	key        string
+}
+
+var applyKinds = map[string]applyKind{
+	configmodels.ApplyKindGnb:          {collection: configmodels.GnbDataColl, key: "name"},
+	configmodels.ApplyKindUpf:          {collection: configmodels.UpfDataColl, key: "hostname"},
+	configmodels.ApplyKindDeviceGroup:  {collection: devGroupDataColl, key: "group-name"},
+	configmodels.ApplyKindNetworkSlice: {collection: sliceDataColl, key: "slice-name"},
+}
+
+// applyOperation is a change of the diff together with the document to write
+// and the document it replaces.
+type applyOperation struct {
+	change   configmodels.ApplyChange
+	document bson.M
+	previous bson.M
+}
+
+func parseDesiredState(contentType string, body []byte) (configmodels.DesiredState, error) {
+	var state configmodels.DesiredState
+	switch contentType {
+	case "application/json":
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		var document interface{}
+		if err := yaml.Unmarshal(body, &document); err != nil {
+			return state, fmt.Errorf("invalid YAML: %w", err)
+		}
+		converted, err := json.Marshal(yamlToJSONValue(document))
+		if err != nil {
+			return state, fmt.Errorf("invalid YAML: %w", err)
+		}
+		body = converted
+	default:
+		return state, fmt.Errorf("unsupported content-type: %s", contentType)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&state); err != nil {
+		return state, fmt.Errorf("invalid desired state: %w", err)
+	}
+	return state, nil
+}
+
+// yamlToJSONValue converts the map[interface{}]interface{} values produced by
+// yaml.v2 into values that can be encoded as JSON.
+func yamlToJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = yamlToJSONValue(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = yamlToJSONValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// normalizeDesiredState applies the conversions done by the device group and
+// network slice handlers so that the desired state compares with stored objects.
+func normalizeDesiredState(state *configmodels.DesiredState) {
+	for i := range state.DeviceGroups {
+		normalizeDeviceGroupQos(&state.DeviceGroups[i].IpDomainExpanded)
+	}
+	for i := range state.NetworkSlices {
+		networkSlice := &state.NetworkSlices[i]
+		normalizeApplicationFilteringRules(networkSlice)
+		networkSlice.SiteInfo.NormalizeUpfs()
+		slices.Sort(networkSlice.SiteDeviceGroup)
+		networkSlice.SiteDeviceGroup = slices.Compact(networkSlice.SiteDeviceGroup)
+	}
+}
+
+func sliceSnssai(networkSlice configmodels.Slice) (*models.Snssai, error) {
+	if networkSlice.SliceId.Sst == "" {
+		return nil, fmt.Errorf("missing SST in slice %s", networkSlice.SliceName)
+	}
+	sVal, err := strconv.ParseUint(networkSlice.SliceId.Sst, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse SST %s in slice %s", networkSlice.SliceId.Sst, networkSlice.SliceName)
+	}
+	return &models.Snssai{
+		Sd:  networkSlice.SliceId.Sd,
+		Sst: int32(sVal),
+	}, nil
+}
+
+// validateDesiredState checks every object of the desired state and the
+// references between them. It returns one error per violation, with the JSON
+// path of the field in the desired state.
+func validateDesiredState(state configmodels.DesiredState) []configmodels.FieldError {
+	var violations []configmodels.FieldError
+	addViolation := func(field, format string, args ...interface{}) {
+		violations = append(violations, configmodels.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	// addError adds an error of a validator, under the field of the object
+	addError := func(object string, err error) {
+		field := object
+		var validationErr *validationError
+		if errors.As(err, &validationErr) {
+			field += "." + validationErr.field
+		}
+		addViolation(field, "%s", err.Error())
+	}
+
+	gnbNames := map[string]bool{}
+	for i, gnb := range state.Gnbs {
+		object := fmt.Sprintf("gnbs[%d]", i)
+		if !isValidName(gnb.Name) {
+			addViolation(object+".name", "invalid gNB name '%s'. Name needs to match the following regular expression: %s", gnb.Name, NAME_PATTERN)
+		}
+		if gnb.Tac != nil && !isValidGnbTac(*gnb.Tac) {
+			addViolation(object+".tac", "invalid TAC %d for gNB %s. TAC must be an integer within the range [1, 16777215]", *gnb.Tac, gnb.Name)
+		}
+		if err := validateGnbInventory(gnb.GnbInventory); err != nil {
+			addError(object, err)
+		}
+		if gnbNames[gnb.Name] {
+			addViolation(object+".name", "duplicate gNB %s", gnb.Name)
+		}
+		gnbNames[gnb.Name] = true
+	}
+	upfNames := map[string]bool{}
+	for i, upf := range state.Upfs {
+		object := fmt.Sprintf("upfs[%d]", i)
+		if !isValidFQDN(upf.Hostname) {
+			addViolation(object+".hostname", "invalid UPF hostname '%s'. Hostname needs to represent a valid FQDN", upf.Hostname)
+		}
+		if !isValidUpfPort(upf.Port) {
+			addViolation(object+".port", "invalid port '%s' for UPF %s. Port must be a numeric string within the range [0, 65535]", upf.Port, upf.Hostname)
+		}
+		if err := validateUpfInventory(upf.UpfInventory); err != nil {
+			addError(object, err)
+		}
+		if upfNames[upf.Hostname] {
+			addViolation(object+".hostname", "duplicate UPF %s", upf.Hostname)
+		}
+		upfNames[upf.Hostname] = true
+	}
+	groupNames := map[string]bool{}
+	for i, deviceGroup := range state.DeviceGroups {
+		field := fmt.Sprintf("device-groups[%d].group-name", i)
+		if !isValidName(deviceGroup.DeviceGroupName) {
+			addViolation(field, "invalid device group name '%s'. Name needs to match the following regular expression: %s", deviceGroup.DeviceGroupName, NAME_PATTERN)
+		}
+		if groupNames[deviceGroup.DeviceGroupName] {
+			addViolation(field, "duplicate device group %s", deviceGroup.DeviceGroupName)
+		}
+		groupNames[deviceGroup.DeviceGroupName] = true
+	}
+	sliceNames := map[string]bool{}
+	for i, networkSlice := range state.NetworkSlices {
+		object := fmt.Sprintf("network-slices[%d]", i)
+		name := networkSlice.SliceName
+		if !isValidName(name) {
+			addViolation(object+".slice-name", "invalid network slice name '%s'. Name needs to match the following regular expression: %s", name, NAME_PATTERN)
+		}
+		if sliceNames[name] {
+			addViolation(object+".slice-name", "duplicate network slice %s", name)
+		}
+		sliceNames[name] = true
+		if _, err := sliceSnssai(networkSlice); err != nil {
+			addViolation(object+".slice-id.sst", "%s", err.Error())
+		}
+		for j, groupName := range networkSlice.SiteDeviceGroup {
+			if !groupNames[groupName] {
+				addViolation(fmt.Sprintf("%s.site-device-group[%d]", object, j), "network slice %s refers to unknown device group %s", name, groupName)
+			}
+		}
+		for j, gnb := range networkSlice.SiteInfo.GNodeBs {
+			field := fmt.Sprintf("%s.site-info.gNodeBs[%d]", object, j)
+			if !isValidGnbTac(gnb.Tac) {
+				addViolation(field+".tac", "invalid TAC %d for gNB %s in network slice %s", gnb.Tac, gnb.Name, name)
+			}
+			if !gnbNames[gnb.Name] {
+				addViolation(field+".name", "network slice %s refers to unknown gNB %s", name, gnb.Name)
+			}
+		}
+		if err := validateSliceUpfs(name, networkSlice.SiteInfo.UpfList()); err != nil {
+			addError(object, err)
+		}
+		for j, upf := range networkSlice.SiteInfo.UpfList() {
+			if upf.UpfName != "" && !upfNames[upf.UpfName] {
+				addViolation(fmt.Sprintf("%s.site-info.upfs[%d].upf-name", object, j), "network slice %s refers to unknown UPF %s", name, upf.UpfName)
+			}
+		}
+	}
+	return violations
+}
+
+func loadCurrentState() (configmodels.DesiredState, error) {
+	var state configmodels.DesiredState
+	rawGnbs, err := dbadapter.CommonDBClient.RestfulAPIGetMany(configmodels.GnbDataColl, bson.M{})
+	if err != nil {
+		return state, fmt.Errorf("failed to retrieve gNBs: %w", err)
+	}
+	if state.Gnbs, err = decodeDocuments[configmodels.Gnb](rawGnbs); err != nil {
+		return state, fmt.Errorf("failed to decode gNBs: %w", err)
+	}
+	rawUpfs, err := dbadapter.CommonDBClient.RestfulAPIGetMany(configmodels.UpfDataColl, bson.M{})
+	if err != nil {
+		return state, fmt.Errorf("failed to retrieve UPFs: %w", err)
+	}
+	if state.Upfs, err = decodeDocuments[configmodels.Upf](rawUpfs); err != nil {
+		return state, fmt.Errorf("failed to decode UPFs: %w", err)
+	}
+	rawDeviceGroups, err := dbadapter.CommonDBClient.RestfulAPIGetMany(devGroupDataColl, bson.M{})
+	if err != nil {
+		return state, fmt.Errorf("failed to retrieve device groups: %w", err)
+	}
+	if state.DeviceGroups, err = decodeDocuments[configmodels.DeviceGroups](rawDeviceGroups); err != nil {
+		return state, fmt.Errorf("failed to decode device groups: %w", err)
+	}
+	rawNetworkSlices, err := dbadapter.CommonDBClient.RestfulAPIGetMany(sliceDataColl, bson.M{})
+	if err != nil {
+		return state, fmt.Errorf("failed to retrieve network slices: %w", err)
+	}
+	if state.NetworkSlices, err = decodeDocuments[configmodels.Slice](rawNetworkSlices); err != nil {
+		return state, fmt.Errorf("failed to decode network slices: %w", err)
+	}
+	return state, nil
+}
+
+// diffObjects compares the current and desired objects of a kind by name and
+// returns the creates and updates, followed separately by the deletes.
+func diffObjects[T any](kind string, current, desired []T, name func(T) string) ([]applyOperation, []applyOperation) {
+	currentByName := map[string]bson.M{}
+	for _, item := range current {
+		currentByName[name(item)] = configmodels.ToBsonM(item)
+	}
+	var upserts, deletes []applyOperation
+	desiredNames := map[string]bool{}
+	for _, item := range desired {
+		itemName := name(item)
+		desiredNames[itemName] = true
+		document := configmodels.ToBsonM(item)
+		prev, ok := currentByName[itemName]
+		if !ok {
+			upserts = append(upserts, applyOperation{
+				change:   configmodels.ApplyChange{Kind: kind, Name: itemName, Operation: configmodels.ApplyOpCreate},
+				document: document,
+			})
+			continue
+		}
+		if fields := changedFields(prev, document); len(fields) > 0 {
+			upserts = append(upserts, applyOperation{
+				change:   configmodels.ApplyChange{Kind: kind, Name: itemName, Operation: configmodels.ApplyOpUpdate, Fields: fields},
+				document: document,
+				previous: prev,
+			})
+		}
+	}
+	for _, item := range current {
+		itemName := name(item)
+		if !desiredNames[itemName] {
+			deletes = append(deletes, applyOperation{
+				change:   configmodels.ApplyChange{Kind: kind, Name: itemName, Operation: configmodels.ApplyOpDelete},
+				previous: currentByName[itemName],
+			})
+		}
+	}
+	return upserts, deletes
+}
+
+func changedFields(prev, next bson.M) []string {
+	var fields []string
+	for key, value := range next {
+		if !reflect.DeepEqual(prev[key], value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// diffDesiredState returns the operations turning the current state into the
+// desired one, in dependency order: creates and updates of inventory, device
+// groups and network slices, then deletes in the reverse order.
+func diffDesiredState(current, desired configmodels.DesiredState) []applyOperation {
+	gnbUpserts, gnbDeletes := diffObjects(configmodels.ApplyKindGnb, current.Gnbs, desired.Gnbs,
+		func(gnb configmodels.Gnb) string { return gnb.Name })
+	upfUpserts, upfDeletes := diffObjects(configmodels.ApplyKindUpf, current.Upfs, desired.Upfs,
+		func(upf configmodels.Upf) string { return upf.Hostname })
+	groupUpserts, groupDeletes := diffObjects(configmodels.ApplyKindDeviceGroup, current.DeviceGroups, desired.DeviceGroups,
+		func(deviceGroup configmodels.DeviceGroups) string { return deviceGroup.DeviceGroupName })
+	sliceUpserts, sliceDeletes := diffObjects(configmodels.ApplyKindNetworkSlice, current.NetworkSlices, desired.NetworkSlices,
+		func(networkSlice configmodels.Slice) string { return networkSlice.SliceName })
+
+	var operations []applyOperation
+	for _, group := range [][]applyOperation{
+		gnbUpserts, upfUpserts, groupUpserts, sliceUpserts,
+		sliceDeletes, groupDeletes, upfDeletes, gnbDeletes,
+	} {
+		operations = append(operations, group...)
+	}
+	return operations
+}
+
+func applyChanges(operations []applyOperation) []configmodels.ApplyChange {
+	changes := make([]configmodels.ApplyChange, 0, len(operations))
+	for _, operation := range operations {
+		changes = append(changes, operation.change)
+	}
+	return changes
+}
+
+func applyDesiredStateOperations(ctx context.Context, operations []applyOperation) error {
+	sessionRunner := dbadapter.GetSessionRunner(dbadapter.CommonDBClient)
+	return sessionRunner(ctx, func(sc mongo.SessionContext) error {
+		for _, operation := range operations {
+			kind := applyKinds[operation.change.Kind]
+			filter := bson.M{kind.key: operation.change.Name}
+			var err error
+			if operation.change.Operation == configmodels.ApplyOpDelete {
+				err = dbadapter.CommonDBClient.RestfulAPIDeleteOneWithContext(sc, kind.collection, filter)
+			} else if err = claimResourceVersion(sc, dbadapter.CommonDBClient, kind.collection, filter); err == nil {
+				_, err = dbadapter.CommonDBClient.RestfulAPIPostWithContext(sc, kind.collection, filter, operation.document)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s %s %s: %w", operation.change.Operation, operation.change.Kind, operation.change.Name, err)
+			}
+			if err = recordConfigRevision(sc, operation.change.Kind, operation.change.Name, operation.previous, operation.document); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// subscriberAssociation is the network slice and device group a subscriber is provisioned for.
+type subscriberAssociation struct {
+	networkSlice configmodels.Slice
+	deviceGroup  configmodels.DeviceGroups
+}
+
+func subscriberAssociations(state configmodels.DesiredState) map[string]subscriberAssociation {
+	deviceGroups := map[string]configmodels.DeviceGroups{}
+	for _, deviceGroup := range state.DeviceGroups {
+		deviceGroups[deviceGroup.DeviceGroupName] = deviceGroup
+	}
+	associations := map[string]subscriberAssociation{}
+	for _, networkSlice := range state.NetworkSlices {
+		for _, groupName := range networkSlice.SiteDeviceGroup {
+			deviceGroup, ok := deviceGroups[groupName]
+			if !ok {
+				continue
+			}
+			for _, imsi := range deviceGroup.Imsis {
+				associations[imsi] = subscriberAssociation{networkSlice: networkSlice, deviceGroup: deviceGroup}
+			}
+		}
+	}
+	return associations
+}
+
+// syncSubscribersOnApply provisions the policy and subscription data of the
+// subscribers whose network slice or device group changed, and removes the data
+// of the subscribers that no longer belong to a network slice.
+func syncSubscribersOnApply(current, desired configmodels.DesiredState) error {
+	rwLock.Lock()
+	defer rwLock.Unlock()
+	prevAssociations := subscriberAssociations(current)
+	nextAssociations := subscriberAssociations(desired)
+	var errorOccurred bool
+	for imsi, prev := range prevAssociations {
+		next, ok := nextAssociations[imsi]
+		if ok && next.networkSlice.SiteInfo.Plmn == prev.networkSlice.SiteInfo.Plmn {
+			continue
+		}
+		if err := removeSubscriberEntriesRelatedToDeviceGroups(prev.networkSlice.SiteInfo.Plmn.Mcc, prev.networkSlice.SiteInfo.Plmn.Mnc, imsi); err != nil {
+			logger.ConfigLog.Errorf("failed to remove subscriber data for IMSI %s: %+v", imsi, err)
+			errorOccurred = true
+		}
+	}
+	for imsi, next := range nextAssociations {
+		if prev, ok := prevAssociations[imsi]; ok && reflect.DeepEqual(prev, next) {
+			continue
+		}
+		subscriberAuthData := DatabaseSubscriberAuthenticationData{}
+		if subscriberAuthData.SubscriberAuthenticationDataGet("imsi-"+imsi) == nil {
+			continue
+		}
+		snssai, err := sliceSnssai(next.networkSlice)
+		if err != nil {
+			return err
+		}
+		err = updatePolicyAndProvisionedData(
+			imsi,
+			next.networkSlice.SiteInfo.Plmn.Mcc,
+			next.networkSlice.SiteInfo.Plmn.Mnc,
+			snssai,
+			next.deviceGroup.IpDomainExpanded.Dnn,
+			next.deviceGroup.IpDomainExpanded.UeDnnQos,
+		)
+		if err != nil {
+			logger.DbLog.Errorf("updatePolicyAndProvisionedData failed for IMSI %s: %+v", imsi, err)
+			errorOccurred = true
+		}
+	}
+	if errorOccurred {
+		return fmt.Errorf("subscriber synchronization failed, please check logs")
+	}
+	return nil
+}
+
+// applyConfigMessages builds the config messages for the applied device group and network slice changes.
+func applyConfigMessages(operations []applyOperation, desired configmodels.DesiredState) []*configmodels.ConfigMessage {
+	msgMethods := map[string]int{
+		configmodels.ApplyOpCreate: configmodels.Post_op,
+		configmodels.ApplyOpUpdate: configmodels.Put_op,
+		configmodels.ApplyOpDelete: configmodels.Delete_op,
+	}
+	var messages []*configmodels.ConfigMessage
+	for _, operation := range operations {
+		msgMethod := msgMethods[operation.change.Operation]
+		switch operation.change.Kind {
+		case configmodels.ApplyKindDeviceGroup:
+			msg := &configmodels.ConfigMessage{
+				MsgType:      configmodels.Device_group,
+				MsgMethod:    msgMethod,
+				DevGroupName: operation.change.Name,
+			}
+			if i := slices.IndexFunc(desired.DeviceGroups, func(deviceGroup configmodels.DeviceGroups) bool {
+				return deviceGroup.DeviceGroupName == operation.change.Name
+			}); i >= 0 {
+				msg.DevGroup = &desired.DeviceGroups[i]
+			}
+			messages = append(messages, msg)
+		case configmodels.ApplyKindNetworkSlice:
+			msg := &configmodels.ConfigMessage{
+				MsgType:   configmodels.Network_slice,
+				MsgMethod: msgMethod,
+				SliceName: operation.change.Name,
+			}
+			if i := slices.IndexFunc(desired.NetworkSlices, func(networkSlice configmodels.Slice) bool {
+				return networkSlice.SliceName == operation.change.Name
+			}); i >= 0 {
+				msg.Slice = &desired.NetworkSlices[i]
+			}
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func applyConfigChanges(operations []applyOperation) []configmodels.ConfigChange {
+	changes := make([]configmodels.ConfigChange, 0, len(operations))
+	for _, operation := range operations {
+		changes = append(changes, newConfigChange(operation.change.Kind, operation.change.Operation, operation.change.Name))
+	}
+	return changes
+}
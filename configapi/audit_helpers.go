@@ -0,0 +1,432 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const maxAuditBodyPeek = 64 * 1024
+
+// auditResource locates the stored document a route writes to. The resource
+// id is taken from the param path parameter or, for routes creating a
+// resource, from the bodyField of the JSON request body.
+type auditResource struct {
+	dbClient   func() dbadapter.DBInterface
+	collection string
+	key        string
+	param      string
+	bodyField  string
+}
+
+func commonDBClient() dbadapter.DBInterface { return dbadapter.CommonDBClient }
+
+func authDBClient() dbadapter.DBInterface { return dbadapter.AuthDBClient }
+
+func webuiDBClient() dbadapter.DBInterface { return dbadapter.WebuiDBClient }
+
+var auditResources = map[string]auditResource{
+	"/config/v1/device-group/:group-name":                     {commonDBClient, devGroupDataColl, "group-name", "group-name", ""},
+	"/config/v1/device-group/:group-name/rollback/:revision":  {commonDBClient, devGroupDataColl, "group-name", "group-name", ""},
+	"/config/v1/network-slice/:slice-name":                    {commonDBClient, sliceDataColl, "slice-name", "slice-name", ""},
+	"/config/v1/network-slice/:slice-name/rollback/:revision": {commonDBClient, sliceDataColl, "slice-name", "slice-name", ""},
+	"/config/v1/inventory/gnb":                                {commonDBClient, configmodels.GnbDataColl, "name", "", "name"},
+	"/config/v1/inventory/gnb/:gnb-name":                      {commonDBClient, configmodels.GnbDataColl, "name", "gnb-name", ""},
+	"/config/v1/inventory/upf":                                {commonDBClient, configmodels.UpfDataColl, "hostname", "", "hostname"},
+	"/config/v1/inventory/upf/:upf-hostname":                  {commonDBClient, configmodels.UpfDataColl, "hostname", "upf-hostname", ""},
+	"/config/v1/webhooks":                                     {commonDBClient, configmodels.WebhookDataColl, "name", "", "name"},
+	"/config/v1/webhooks/:webhook-name":                       {commonDBClient, configmodels.WebhookDataColl, "name", "webhook-name", ""},
+	"/api/subscriber/:ueId":                                   {authDBClient, authSubsDataColl, "ueId", "ueId", ""},
+	"/config/v1/account":                                      {webuiDBClient, configmodels.UserAccountDataColl, "username", "", "username"},
+	"/config/v1/account/:username":                            {webuiDBClient, configmodels.UserAccountDataColl, "username", "username", ""},
+	"/config/v1/account/:username/change_password":            {webuiDBClient, configmodels.UserAccountDataColl, "username", "username", ""},
+	"/config/v1/account/:username/unlock":                     {webuiDBClient, configmodels.UserAccountDataColl, "username", "username", ""},
+	"/config/v1/account/:username/tokens/:token-id":           {webuiDBClient, configmodels.ApiTokenDataColl, "id", "token-id", ""},
+	"/config/v1/account/:username/roles":                      {webuiDBClient, configmodels.UserAccountDataColl, "username", "username", ""},
+	"/config/v1/role/:role-name":                              {webuiDBClient, configmodels.RoleDataColl, "name", "role-name", ""},
+}
+
+// auditBulkResources are the routes writing to many resources at once. Their
+// handlers give the ids of the written resources with setAuditedResourceIDs,
+// and an entry is stored for each of them.
+var auditBulkResources = map[string]auditResource{
+	"/api/subscriber:action": {authDBClient, authSubsDataColl, "ueId", "", ""},
+}
+
+const auditedResourceIDsKey = "auditedResourceIDs"
+
+// setAuditedResourceIDs gives the audit log the ids of the resources written by
+// a bulk request.
+func setAuditedResourceIDs(c *gin.Context, ids []string) {
+	c.Set(auditedResourceIDsKey, ids)
+}
+
+// auditLogDBClient returns the database holding the audit log. The webui
+// database is only connected when authentication is enabled, otherwise the
+// audit log is kept in the common database.
+func auditLogDBClient() dbadapter.DBInterface {
+	if dbadapter.WebuiDBClient != nil {
+		return dbadapter.WebuiDBClient
+	}
+	return dbadapter.CommonDBClient
+}
+
+func isAuditedMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut ||
+		method == http.MethodDelete || method == http.MethodPatch
+}
+
+// auditResourceID returns the id of the resource a request writes to. The
+// request body is read to find the id of a created resource and restored for
+// the handler.
+func auditResourceID(c *gin.Context, resource auditResource) string {
+	if resource.param != "" {
+		return c.Param(resource.param)
+	}
+	if c.Request.Body == nil {
+		return ""
+	}
+	peeked, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBodyPeek))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(peeked), c.Request.Body))
+	if err != nil {
+		return ""
+	}
+	var body map[string]interface{}
+	if err = json.Unmarshal(peeked, &body); err != nil {
+		return ""
+	}
+	id, _ := body[resource.bodyField].(string)
+	return id
+}
+
+// auditResourceHash returns the hash of the stored resource, or an empty string
+// if it does not exist.
+func auditResourceHash(resource auditResource, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	rawDocument, err := resource.dbClient().RestfulAPIGetOne(resource.collection, bson.M{resource.key: id})
+	if err != nil {
+		return "", err
+	}
+	if len(rawDocument) == 0 {
+		return "", nil
+	}
+	return hashAuditDocument(rawDocument)
+}
+
+// auditResourceHashes returns the hashes of the stored resources by id, without
+// the ones that do not exist.
+func auditResourceHashes(resource auditResource, ids []string) (map[string]string, error) {
+	rawDocuments, err := resource.dbClient().RestfulAPIGetMany(resource.collection, bson.M{resource.key: bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(rawDocuments))
+	for _, rawDocument := range rawDocuments {
+		id, _ := rawDocument[resource.key].(string)
+		if hashes[id], err = hashAuditDocument(rawDocument); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// hashAuditDocument returns the HMAC-SHA-256 of a stored document. A keyed hash
+// is used since the documents hold secrets, such as the subscriber keys, that
+// could otherwise be guessed from their hashes.
+func hashAuditDocument(rawDocument map[string]interface{}) (string, error) {
+	key, err := loadAuditHashKey()
+	if err != nil {
+		return "", err
+	}
+	delete(rawDocument, "_id")
+	// map keys are marshalled in sorted order, so equal documents hash the same
+	document, err := json.Marshal(rawDocument)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(document)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// auditHashKey is the key of the audit log hashes. It is generated once and
+// kept in the database of the audit log, so that the hashes of all the
+// replicas and restarts can be compared.
+var auditHashKey struct {
+	mu  sync.Mutex
+	key []byte
+}
+
+const (
+	auditHashKeyName   = "audit-hash"
+	auditHashKeyLength = 32
+)
+
+func loadAuditHashKey() ([]byte, error) {
+	auditHashKey.mu.Lock()
+	defer auditHashKey.mu.Unlock()
+	if auditHashKey.key != nil {
+		return auditHashKey.key, nil
+	}
+	filter := bson.M{"name": auditHashKeyName}
+	// another replica may store its key first, which is then used
+	for range 2 {
+		rawKey, err := auditLogDBClient().RestfulAPIGetOne(configmodels.AuditKeyDataColl, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the audit hash key: %w", err)
+		}
+		if encodedKey, ok := rawKey["key"].(string); ok {
+			key, err := base64.StdEncoding.DecodeString(encodedKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode the audit hash key: %w", err)
+			}
+			auditHashKey.key = key
+			return key, nil
+		}
+		key := make([]byte, auditHashKeyLength)
+		if _, err = rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate the audit hash key: %w", err)
+		}
+		document := bson.M{"name": auditHashKeyName, "key": base64.StdEncoding.EncodeToString(key)}
+		err = auditLogDBClient().RestfulAPIPostMany(configmodels.AuditKeyDataColl, filter, []interface{}{document})
+		if err == nil {
+			auditHashKey.key = key
+			return key, nil
+		}
+		if !dbadapter.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("failed to store the audit hash key: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("failed to store the audit hash key: stored concurrently")
+}
+
+func newAuditLogEntry(c *gin.Context) configmodels.AuditLogEntry {
+	entry := configmodels.AuditLogEntry{
+		Timestamp: time.Now().UTC(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		RequestID: logger.RequestIDFromContext(c.Request.Context()),
+	}
+	var params []string
+	for _, param := range c.Params {
+		params = append(params, param.Value)
+	}
+	entry.ResourceID = strings.Join(params, "/")
+	return entry
+}
+
+// AuditLogMiddleware stores an audit log entry for every mutating API call,
+// with the authenticated user, the outcome and the hashes of the resource
+// before and after the call. The bulk calls store an entry per written
+// resource. It is registered after the authentication middleware, so that
+// the requests refused by it are neither read nor stored.
+func AuditLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auditRequest(c, c.Next)
+	}
+}
+
+// auditedHandler audits the calls of handler like AuditLogMiddleware, for the
+// routes authorized by their own auth wrapper, which must wrap it.
+func auditedHandler(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auditRequest(c, func() { handler(c) })
+	}
+}
+
+func auditRequest(c *gin.Context, next func()) {
+	if !isAuditedMethod(c.Request.Method) || c.FullPath() == "" {
+		next()
+		return
+	}
+	entry := newAuditLogEntry(c)
+	resource, hasResource := auditResources[c.FullPath()]
+	var audited *auditedResource
+	if hasResource {
+		entry.ResourceID = auditResourceID(c, resource)
+		beforeHash, err := auditResourceHash(resource, entry.ResourceID)
+		if err != nil {
+			logger.WebUILog.Warnf("failed to hash %s %s before the request: %+v", resource.collection, entry.ResourceID, err)
+		}
+		entry.BeforeHash = beforeHash
+		audited = &auditedResource{collection: resource.collection, key: resource.key, id: entry.ResourceID}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), auditedResourceKey{}, audited))
+	}
+
+	next()
+
+	entry.Status = c.Writer.Status()
+	entry.Username = auth.UsernameFromContext(c.Request.Context())
+	if role, ok := auth.RoleFromContext(c.Request.Context()); ok {
+		entry.Role = &role
+	}
+	if hasResource {
+		// the document read by a conditional write is the one it replaced,
+		// which another request may have changed since the read above
+		if audited.read {
+			entry.BeforeHash = audited.beforeHash
+		}
+		afterHash, err := auditResourceHash(resource, entry.ResourceID)
+		if err != nil {
+			logger.WebUILog.Warnf("failed to hash %s %s after the request: %+v", resource.collection, entry.ResourceID, err)
+		}
+		entry.AfterHash = afterHash
+	}
+	entries := []configmodels.AuditLogEntry{entry}
+	if resource, ok := auditBulkResources[c.FullPath()]; ok {
+		entries = bulkAuditLogEntries(c, resource, entry)
+	}
+	if err := storeAuditLogEntries(entries); err != nil {
+		logger.WebUILog.Errorf("failed to store audit log entry for %s %s: %+v", entry.Method, entry.Path, err)
+	}
+}
+
+// auditedResource is the resource written by an audited request, with the
+// hash of its document as last read by the handler before a conditional
+// write.
+type auditedResource struct {
+	collection string
+	key        string
+	id         string
+	read       bool
+	beforeHash string
+}
+
+type auditedResourceKey struct{}
+
+// recordAuditedRead records the document of the resource matched by filter,
+// read by the handler of an audited request to check its version. The write
+// is conditional on that version, so the document is the one it replaces.
+func recordAuditedRead(ctx context.Context, collName string, filter bson.M, document map[string]interface{}) {
+	audited, ok := ctx.Value(auditedResourceKey{}).(*auditedResource)
+	if !ok || audited.collection != collName || len(filter) != 1 || filter[audited.key] != audited.id {
+		return
+	}
+	if len(document) == 0 {
+		audited.read, audited.beforeHash = true, ""
+		return
+	}
+	beforeHash, err := hashAuditDocument(maps.Clone(document))
+	if err != nil {
+		logger.WebUILog.Warnf("failed to hash %s %s before the write: %+v", collName, audited.id, err)
+		return
+	}
+	audited.read, audited.beforeHash = true, beforeHash
+}
+
+// bulkAuditLogEntries returns an entry for each resource written by a bulk
+// request, or entry if it wrote none.
+func bulkAuditLogEntries(c *gin.Context, resource auditResource, entry configmodels.AuditLogEntry) []configmodels.AuditLogEntry {
+	ids := c.GetStringSlice(auditedResourceIDsKey)
+	if len(ids) == 0 {
+		return []configmodels.AuditLogEntry{entry}
+	}
+	hashes, err := auditResourceHashes(resource, ids)
+	if err != nil {
+		logger.WebUILog.Warnf("failed to hash %s after the request: %+v", resource.collection, err)
+	}
+	entries := make([]configmodels.AuditLogEntry, 0, len(ids))
+	for _, id := range ids {
+		resourceEntry := entry
+		resourceEntry.ResourceID = id
+		resourceEntry.AfterHash = hashes[id]
+		entries = append(entries, resourceEntry)
+	}
+	return entries
+}
+
+func storeAuditLogEntries(entries []configmodels.AuditLogEntry) error {
+	documents := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		documents = append(documents, auditLogDocument(entry))
+	}
+	return auditLogDBClient().RestfulAPIPostMany(configmodels.AuditLogDataColl, nil, documents)
+}
+
+func auditLogDocument(entry configmodels.AuditLogEntry) bson.M {
+	// the timestamp is stored as a date so that it can be filtered by range,
+	// and the entry is kept for the audit log retention from it
+	document := bson.M{
+		"timestamp":  entry.Timestamp,
+		"expires-at": entry.Timestamp.Add(factory.WebUIConfig.Configuration.AuditLogRetention),
+		"method":     entry.Method,
+		"path":       entry.Path,
+		"status":     entry.Status,
+	}
+	for field, value := range map[string]string{
+		"username":    entry.Username,
+		"resource-id": entry.ResourceID,
+		"before-hash": entry.BeforeHash,
+		"after-hash":  entry.AfterHash,
+		"request-id":  entry.RequestID,
+	} {
+		if value != "" {
+			document[field] = value
+		}
+	}
+	if entry.Role != nil {
+		document["role"] = *entry.Role
+	}
+	return document
+}
+
+type auditLogQuery struct {
+	from     time.Time
+	to       time.Time
+	username string
+	limit    int64
+	offset   int64
+}
+
+func auditLogFilter(query auditLogQuery) bson.M {
+	filter := bson.M{}
+	timestampFilter := bson.M{}
+	if !query.from.IsZero() {
+		timestampFilter["$gte"] = query.from
+	}
+	if !query.to.IsZero() {
+		timestampFilter["$lte"] = query.to
+	}
+	if len(timestampFilter) > 0 {
+		filter["timestamp"] = timestampFilter
+	}
+	if query.username != "" {
+		filter["username"] = query.username
+	}
+	return filter
+}
+
+// getAuditLogEntries returns the audit log entries matching query, newest first.
+func getAuditLogEntries(query auditLogQuery) ([]configmodels.AuditLogEntry, error) {
+	rawEntries, err := auditLogDBClient().RestfulAPIGetManyPaged(configmodels.AuditLogDataColl, auditLogFilter(query),
+		bson.D{{Key: "timestamp", Value: -1}}, query.offset, query.limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve audit log entries: %w", err)
+	}
+	return decodeDocuments[configmodels.AuditLogEntry](rawEntries)
+}
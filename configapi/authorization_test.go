@@ -8,11 +8,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omec-project/webconsole/backend/auth"
 	"github.com/omec-project/webconsole/configmodels"
 	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 const (
@@ -20,18 +22,31 @@ const (
 	bearer      = "Bearer "
 )
 
-var mockJWTSecret = []byte("mockSecret")
+var mockJWTKeys = auth.NewHMACJWTKeys([]byte("mockSecret"))
 
 func MockOperation(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"Result": "Operation Executed"})
 }
 
+// MockMongoClientActiveSessions is MockMongoClientSuccess where the session of
+// every token is active.
+type MockMongoClientActiveSessions struct {
+	MockMongoClientSuccess
+}
+
+func (db *MockMongoClientActiveSessions) RestfulAPIGetOne(coll string, filter bson.M) (map[string]interface{}, error) {
+	if coll == configmodels.SessionDataColl {
+		return map[string]interface{}{"id": filter["id"], "username": "janedoe", "expires-at": time.Now().Add(time.Hour)}, nil
+	}
+	return db.MockMongoClientSuccess.RestfulAPIGetOne(coll, filter)
+}
+
 func setUpRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
-	dbadapter.WebuiDBClient = &MockMongoClientSuccess{}
-	router.Use(auth.AdminOrUserAuthMiddleware(mockJWTSecret))
-	AddUserAccountService(router, mockJWTSecret)
+	dbadapter.WebuiDBClient = &MockMongoClientActiveSessions{}
+	router.Use(auth.AdminOrUserAuthMiddleware(mockJWTKeys))
+	AddUserAccountService(router, mockJWTKeys)
 	AddApiService(router)
 	AddConfigV1Service(router)
 	return router
@@ -40,12 +55,12 @@ func setUpRouter() *gin.Engine {
 func setUpMockedRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
-	dbadapter.WebuiDBClient = &MockMongoClientSuccess{}
-	router.GET("/config/v1/account", auth.AdminOnly(mockJWTSecret, MockOperation))
-	router.GET("/config/v1/account/:username", auth.AdminOrMe(mockJWTSecret, MockOperation))
-	router.DELETE("/config/v1/account/:username", auth.AdminOnly(mockJWTSecret, MockOperation))
-	router.POST("/config/v1/account/:username/change_password", auth.AdminOrMe(mockJWTSecret, MockOperation))
-	router.POST("/config/v1/account", auth.AdminOrFirstUser(mockJWTSecret, MockOperation))
+	dbadapter.WebuiDBClient = &MockMongoClientActiveSessions{}
+	router.GET("/config/v1/account", auth.AdminOnly(mockJWTKeys, MockOperation))
+	router.GET("/config/v1/account/:username", auth.AdminOrMe(mockJWTKeys, MockOperation))
+	router.DELETE("/config/v1/account/:username", auth.AdminOnly(mockJWTKeys, MockOperation))
+	router.POST("/config/v1/account/:username/change_password", auth.AdminOrMe(mockJWTKeys, MockOperation))
+	router.POST("/config/v1/account", auth.AdminOrFirstUser(mockJWTKeys, MockOperation))
 	return router
 }
 
@@ -266,6 +281,40 @@ func TestAdminOrUserAuthorizationMiddleware_TokenValidation(t *testing.T) {
 	}
 }
 
+func TestAdminOrUserAuthorizationMiddleware_InvalidRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	dbadapter.WebuiDBClient = &MockMongoClientActiveSessions{}
+	authenticated := false
+	router.GET("/config/v1/device-group", func(c *gin.Context) {
+		c.Next()
+		authenticated = auth.UsernameFromContext(c.Request.Context()) != ""
+	}, auth.AdminOrUserAuthMiddleware(mockJWTKeys), MockOperation)
+	req, err := http.NewRequest(http.MethodGet, "/config/v1/device-group", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	jwtToken, err := auth.GenerateJWT("janedoe", 42, "session1", mockJWTKeys)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	req.Header.Set("Authorization", bearer+jwtToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected `%v`, got `%v`", http.StatusForbidden, w.Code)
+	}
+	expectedBody := `{"error":"forbidden: admin or user access required"}`
+	if w.Body.String() != expectedBody {
+		t.Errorf("Expected `%v`, got `%v`", expectedBody, w.Body.String())
+	}
+	if authenticated {
+		t.Errorf("Expected the request refused for its role not to be authenticated")
+	}
+}
+
 func TestGetUserAccounts_AdminOnlyAuthorizationMiddleware(t *testing.T) {
 	router := setUpMockedRouter()
 
@@ -297,7 +346,7 @@ func TestGetUserAccounts_AdminOnlyAuthorizationMiddleware(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to create request: %v", err)
 			}
-			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, mockJWTSecret)
+			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, "session1", mockJWTKeys)
 			if err != nil {
 				t.Fatalf("failed to generate token: %v", err)
 			}
@@ -362,7 +411,7 @@ func TestGetUserAccount_AdminOrMeAuthorizationMiddleware(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to create request: %v", err)
 			}
-			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, mockJWTSecret)
+			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, "session1", mockJWTKeys)
 			if err != nil {
 				t.Fatalf("failed to generate token: %v", err)
 			}
@@ -434,7 +483,7 @@ func TestCreateUserAccount_AdminAuthorizationMiddleware(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to create request: %v", err)
 			}
-			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, mockJWTSecret)
+			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, "session1", mockJWTKeys)
 			if err != nil {
 				t.Fatalf("failed to generate token: %v", err)
 			}
@@ -499,7 +548,7 @@ func TestDeleteUserAccount_AdminOnlyAuthorizationMiddleware(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to create request: %v", err)
 			}
-			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, mockJWTSecret)
+			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, "session1", mockJWTKeys)
 			if err != nil {
 				t.Fatalf("failed to generate token: %v", err)
 			}
@@ -564,7 +613,7 @@ func TestChangePassword_AdminOrMeAuthorizationMiddleware(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to create request: %v", err)
 			}
-			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, mockJWTSecret)
+			jwtToken, err := auth.GenerateJWT(tc.username, tc.role, "session1", mockJWTKeys)
 			if err != nil {
 				t.Fatalf("failed to generate token: %v", err)
 			}
@@ -0,0 +1,619 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	backupFormatJSONL    = "jsonl"
+	backupFormatTarGzip  = "tar.gz"
+	backupManifestName   = "manifest.json"
+	maxBackupLineSize    = 16 * 1024 * 1024
+	backupKeySeparator   = "\x00"
+	backupFileNameLayout = "20060102T150405Z"
+)
+
+type backupCollection struct {
+	name   string
+	authDB bool
+	// keys identify a document. The first key is mandatory, the others are
+	// matched as absent when a document does not carry them.
+	keys []string
+}
+
+// backupCollections lists the exported collections in restore order:
+// inventory first, then the objects referring to it.
+var backupCollections = []backupCollection{
+	{name: configmodels.GnbDataColl, keys: []string{"name"}},
+	{name: configmodels.UpfDataColl, keys: []string{"hostname"}},
+	{name: devGroupDataColl, keys: []string{"group-name"}},
+	{name: sliceDataColl, keys: []string{"slice-name"}},
+	{name: authSubsDataColl, authDB: true, keys: []string{"ueId"}},
+	{name: amDataColl, keys: []string{"ueId", "servingPlmnId"}},
+	{name: smDataColl, keys: []string{"ueId", "servingPlmnId"}},
+	{name: smfSelDataColl, keys: []string{"ueId", "servingPlmnId"}},
+	{name: amPolicyDataColl, keys: []string{"ueId"}},
+	{name: smPolicyDataColl, keys: []string{"ueId"}},
+}
+
+func findBackupCollection(name string) (backupCollection, bool) {
+	for _, coll := range backupCollections {
+		if coll.name == name {
+			return coll, true
+		}
+	}
+	return backupCollection{}, false
+}
+
+func (coll backupCollection) dbClient() dbadapter.DBInterface {
+	if coll.authDB {
+		return dbadapter.AuthDBClient
+	}
+	return dbadapter.CommonDBClient
+}
+
+func (coll backupCollection) keyFilter(doc map[string]interface{}) (bson.M, error) {
+	filter := bson.M{}
+	for i, key := range coll.keys {
+		value, ok := doc[key]
+		if !ok || value == nil {
+			if i == 0 {
+				return nil, fmt.Errorf("document in %s is missing key %s", coll.name, key)
+			}
+			filter[key] = bson.M{"$exists": false}
+			continue
+		}
+		filter[key] = value
+	}
+	return filter, nil
+}
+
+func (coll backupCollection) documentKey(doc map[string]interface{}) (string, error) {
+	parts := make([]string, 0, len(coll.keys))
+	for i, key := range coll.keys {
+		value, ok := doc[key]
+		if !ok || value == nil {
+			if i == 0 {
+				return "", fmt.Errorf("document in %s is missing key %s", coll.name, key)
+			}
+			parts = append(parts, "")
+			continue
+		}
+		parts = append(parts, fmt.Sprint(value))
+	}
+	return strings.Join(parts, backupKeySeparator), nil
+}
+
+// backupSnapshot holds the documents of every backup collection.
+type backupSnapshot map[string][]map[string]interface{}
+
+func loadBackupSnapshot() (backupSnapshot, error) {
+	snapshot := backupSnapshot{}
+	for _, coll := range backupCollections {
+		docs, err := coll.dbClient().RestfulAPIGetMany(coll.name, bson.M{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read collection %s: %w", coll.name, err)
+		}
+		snapshot[coll.name] = docs
+	}
+	return snapshot, nil
+}
+
+// forEachBackupDocument calls fn with every document of the backup collections,
+// in restore order, as they are read from the database.
+func forEachBackupDocument(ctx context.Context, fn func(coll backupCollection, doc map[string]interface{}) error) error {
+	for _, coll := range backupCollections {
+		err := coll.dbClient().RestfulAPIForEachWithContext(ctx, coll.name, bson.M{}, func(doc map[string]interface{}) error {
+			return fn(coll, doc)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export collection %s: %w", coll.name, err)
+		}
+	}
+	return nil
+}
+
+func newBackupHeader() configmodels.BackupHeader {
+	return configmodels.BackupHeader{
+		Format:    configmodels.BackupFormat,
+		Version:   configmodels.BackupVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func backupFileName(header configmodels.BackupHeader, format string) string {
+	return fmt.Sprintf("webconsole-backup-%s.%s", header.CreatedAt.Format(backupFileNameLayout), format)
+}
+
+// writeBackupJSONL streams the backup as JSON lines: the header, then one
+// record per document.
+func writeBackupJSONL(ctx context.Context, w io.Writer, header configmodels.BackupHeader) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+	return forEachBackupDocument(ctx, func(coll backupCollection, doc map[string]interface{}) error {
+		document, err := bson.MarshalExtJSON(doc, false, false)
+		if err != nil {
+			return fmt.Errorf("failed to encode document in %s: %w", coll.name, err)
+		}
+		return encoder.Encode(configmodels.BackupRecord{
+			Collection: coll.name,
+			Document:   document,
+		})
+	})
+}
+
+// writeBackupTarGzip streams the backup as a tar.gz archive: the manifest,
+// then one <collection>/<n>.json entry per document.
+func writeBackupTarGzip(ctx context.Context, w io.Writer, header configmodels.BackupHeader) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	manifest, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	if err = writeTarEntry(tarWriter, backupManifestName, manifest, header.CreatedAt); err != nil {
+		return err
+	}
+	entries := map[string]int{}
+	err = forEachBackupDocument(ctx, func(coll backupCollection, doc map[string]interface{}) error {
+		document, err := bson.MarshalExtJSON(doc, false, false)
+		if err != nil {
+			return fmt.Errorf("failed to encode document in %s: %w", coll.name, err)
+		}
+		entries[coll.name]++
+		return writeTarEntry(tarWriter, fmt.Sprintf("%s/%d.json", coll.name, entries[coll.name]), document, header.CreatedAt)
+	})
+	if err != nil {
+		return err
+	}
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func writeTarEntry(tarWriter *tar.Writer, name string, content []byte, modTime time.Time) error {
+	entryHeader := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	}
+	if err := tarWriter.WriteHeader(entryHeader); err != nil {
+		return err
+	}
+	_, err := tarWriter.Write(content)
+	return err
+}
+
+// parseBackup decodes a JSON lines or tar.gz backup as it is read. The format
+// is detected from the gzip magic number.
+func parseBackup(r io.Reader) (backupSnapshot, error) {
+	reader := bufio.NewReader(r)
+	if magic, _ := reader.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return parseBackupTarGzip(reader)
+	}
+	return parseBackupJSONL(reader)
+}
+
+func validateBackupHeader(header configmodels.BackupHeader) error {
+	if header.Format != configmodels.BackupFormat {
+		return fmt.Errorf("unsupported backup format %q", header.Format)
+	}
+	if header.Version != configmodels.BackupVersion {
+		return fmt.Errorf("unsupported backup version %d", header.Version)
+	}
+	return nil
+}
+
+func parseBackupDocument(collection string, raw []byte) (map[string]interface{}, error) {
+	coll, ok := findBackupCollection(collection)
+	if !ok {
+		return nil, fmt.Errorf("unknown collection %q", collection)
+	}
+	var doc bson.M
+	if err := bson.UnmarshalExtJSON(raw, false, &doc); err != nil {
+		return nil, fmt.Errorf("invalid document in %s: %w", collection, err)
+	}
+	delete(doc, "_id")
+	if _, err := coll.documentKey(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func parseBackupJSONL(r io.Reader) (backupSnapshot, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBackupLineSize)
+	snapshot := backupSnapshot{}
+	headerRead := false
+	line := 0
+	for scanner.Scan() {
+		if scanner.Err() != nil {
+			// the last line is cut short by the read error
+			break
+		}
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if !headerRead {
+			var header configmodels.BackupHeader
+			if err := json.Unmarshal(raw, &header); err != nil {
+				return nil, fmt.Errorf("line %d: invalid backup header: %w", line, err)
+			}
+			if err := validateBackupHeader(header); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			headerRead = true
+			continue
+		}
+		var record configmodels.BackupRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return nil, fmt.Errorf("line %d: invalid record: %w", line, err)
+		}
+		doc, err := parseBackupDocument(record.Collection, record.Document)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		snapshot[record.Collection] = append(snapshot[record.Collection], doc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if !headerRead {
+		return nil, errors.New("backup is empty")
+	}
+	return snapshot, nil
+}
+
+func parseBackupTarGzip(r io.Reader) (backupSnapshot, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip archive: %w", err)
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	snapshot := backupSnapshot{}
+	manifestRead := false
+	for {
+		entryHeader, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if entryHeader.Typeflag != tar.TypeReg {
+			continue
+		}
+		if entryHeader.Size > maxBackupLineSize {
+			return nil, fmt.Errorf("archive entry %s is too large", entryHeader.Name)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive entry %s: %w", entryHeader.Name, err)
+		}
+		if entryHeader.Name == backupManifestName {
+			var header configmodels.BackupHeader
+			if err = json.Unmarshal(content, &header); err != nil {
+				return nil, fmt.Errorf("invalid backup manifest: %w", err)
+			}
+			if err = validateBackupHeader(header); err != nil {
+				return nil, err
+			}
+			manifestRead = true
+			continue
+		}
+		collection, file, ok := strings.Cut(entryHeader.Name, "/")
+		if !ok || !strings.HasSuffix(file, ".json") {
+			return nil, fmt.Errorf("unexpected archive entry %s", entryHeader.Name)
+		}
+		doc, err := parseBackupDocument(collection, content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entryHeader.Name, err)
+		}
+		snapshot[collection] = append(snapshot[collection], doc)
+	}
+	if !manifestRead {
+		return nil, errors.New("backup manifest is missing")
+	}
+	return snapshot, nil
+}
+
+// backupImportPlan is the outcome of an import: the resulting content of every
+// collection and the documents removed by a replace.
+type backupImportPlan struct {
+	mode      string
+	result    backupSnapshot
+	deleted   backupSnapshot
+	revisions []configRevisionChange
+	report    configmodels.ImportReport
+}
+
+func planBackupImport(mode string, archive, current backupSnapshot) (*backupImportPlan, error) {
+	plan := &backupImportPlan{
+		mode:    mode,
+		result:  backupSnapshot{},
+		deleted: backupSnapshot{},
+		report: configmodels.ImportReport{
+			Mode:        mode,
+			Collections: map[string]configmodels.ImportCollectionReport{},
+		},
+	}
+	for _, coll := range backupCollections {
+		existing := map[string]map[string]interface{}{}
+		var existingOrder []string
+		for _, doc := range current[coll.name] {
+			key, err := coll.documentKey(doc)
+			if err != nil {
+				return nil, err
+			}
+			existing[key] = doc
+			existingOrder = append(existingOrder, key)
+		}
+		imported := map[string]bool{}
+		collReport := configmodels.ImportCollectionReport{Documents: len(archive[coll.name])}
+		for _, doc := range archive[coll.name] {
+			key, _ := coll.documentKey(doc)
+			if imported[key] {
+				return nil, fmt.Errorf("duplicate document %s in %s", strings.ReplaceAll(key, backupKeySeparator, "/"), coll.name)
+			}
+			imported[key] = true
+			if _, ok := existing[key]; ok {
+				collReport.Updated++
+			} else {
+				collReport.Created++
+			}
+			plan.addRevision(coll.name, existing[key], doc)
+		}
+		resulting := slices.Clone(archive[coll.name])
+		for _, key := range existingOrder {
+			if imported[key] {
+				continue
+			}
+			if mode == configmodels.ImportModeReplace {
+				plan.deleted[coll.name] = append(plan.deleted[coll.name], existing[key])
+				plan.addRevision(coll.name, existing[key], nil)
+				collReport.Deleted++
+				continue
+			}
+			resulting = append(resulting, existing[key])
+		}
+		plan.result[coll.name] = resulting
+		plan.report.Collections[coll.name] = collReport
+	}
+	return plan, nil
+}
+
+// addRevision records the change of a gNB, UPF, device group or network slice
+// made by the import. Documents of other collections have no revisions.
+func (plan *backupImportPlan) addRevision(collection string, prev, next map[string]interface{}) {
+	for kindName, kind := range applyKinds {
+		if kind.collection != collection {
+			continue
+		}
+		change := configRevisionChange{kind: kindName, prev: revisionDocument(prev), next: revisionDocument(next)}
+		document := next
+		if document == nil {
+			document = prev
+		}
+		change.name, _ = document[kind.key].(string)
+		plan.revisions = append(plan.revisions, change)
+	}
+}
+
+func (plan *backupImportPlan) configChanges() []configmodels.ConfigChange {
+	changes := make([]configmodels.ConfigChange, 0, len(plan.revisions))
+	for _, revision := range plan.revisions {
+		operation := configChangeOperation(revision.prev != nil)
+		if revision.next == nil {
+			operation = configmodels.ApplyOpDelete
+		}
+		changes = append(changes, newConfigChange(revision.kind, operation, revision.name))
+	}
+	return changes
+}
+
+func decodeDocuments[T any](docs []map[string]interface{}) ([]T, error) {
+	items := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		raw, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		var item T
+		if err = json.Unmarshal(raw, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// validateBackupReferences checks that the resulting configuration only refers to
+// objects that exist in it: device groups, gNBs and UPFs used by network slices
+// and subscribers assigned to device groups. It returns the missing objects.
+func validateBackupReferences(result backupSnapshot) ([]configmodels.ConfigReference, error) {
+	gnbs, err := decodeDocuments[configmodels.Gnb](result[configmodels.GnbDataColl])
+	if err != nil {
+		return nil, fmt.Errorf("invalid gNB: %w", err)
+	}
+	upfs, err := decodeDocuments[configmodels.Upf](result[configmodels.UpfDataColl])
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPF: %w", err)
+	}
+	deviceGroups, err := decodeDocuments[configmodels.DeviceGroups](result[devGroupDataColl])
+	if err != nil {
+		return nil, fmt.Errorf("invalid device group: %w", err)
+	}
+	networkSlices, err := decodeDocuments[configmodels.Slice](result[sliceDataColl])
+	if err != nil {
+		return nil, fmt.Errorf("invalid network slice: %w", err)
+	}
+	gnbNames := map[string]bool{}
+	for _, gnb := range gnbs {
+		gnbNames[gnb.Name] = true
+	}
+	upfNames := map[string]bool{}
+	for _, upf := range upfs {
+		upfNames[upf.Hostname] = true
+	}
+	groupNames := map[string]bool{}
+	for _, deviceGroup := range deviceGroups {
+		groupNames[deviceGroup.DeviceGroupName] = true
+	}
+	subscribers := map[string]bool{}
+	for _, doc := range result[authSubsDataColl] {
+		if ueId, ok := doc["ueId"].(string); ok {
+			subscribers[ueId] = true
+		}
+	}
+
+	var references []configmodels.ConfigReference
+	addReference := func(kind, name, format string, args ...interface{}) {
+		references = append(references, configmodels.ConfigReference{Kind: kind, Name: name, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, networkSlice := range networkSlices {
+		for _, groupName := range networkSlice.SiteDeviceGroup {
+			if !groupNames[groupName] {
+				addReference(configmodels.ApplyKindDeviceGroup, groupName, "network slice %s refers to unknown device group %s", networkSlice.SliceName, groupName)
+			}
+		}
+		for _, gnb := range networkSlice.SiteInfo.GNodeBs {
+			if !gnbNames[gnb.Name] {
+				addReference(configmodels.ApplyKindGnb, gnb.Name, "network slice %s refers to unknown gNB %s", networkSlice.SliceName, gnb.Name)
+			}
+		}
+		for _, upf := range networkSlice.SiteInfo.UpfList() {
+			if !upfNames[upf.UpfName] {
+				addReference(configmodels.ApplyKindUpf, upf.UpfName, "network slice %s refers to unknown UPF %s", networkSlice.SliceName, upf.UpfName)
+			}
+		}
+	}
+	for _, deviceGroup := range deviceGroups {
+		for _, imsi := range deviceGroup.Imsis {
+			if !subscribers["imsi-"+imsi] {
+				addReference(configmodels.ConfigEventTypeSubscriber, imsi, "device group %s refers to unknown subscriber %s", deviceGroup.DeviceGroupName, imsi)
+			}
+		}
+	}
+	return references, nil
+}
+
+func applyBackupImport(ctx context.Context, plan *backupImportPlan, archive backupSnapshot) error {
+	rwLock.Lock()
+	defer rwLock.Unlock()
+	apply := func(sc mongo.SessionContext, authDB bool) error {
+		for _, coll := range backupCollections {
+			if coll.authDB != authDB {
+				continue
+			}
+			if err := applyBackupCollection(sc, coll, plan.mode, archive[coll.name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return runAuthAndCommonTransactionWithContext(ctx,
+		func(sc mongo.SessionContext) error { return apply(sc, true) },
+		func(sc mongo.SessionContext) error {
+			if err := apply(sc, false); err != nil {
+				return err
+			}
+			for _, change := range plan.revisions {
+				if err := recordConfigRevision(sc, change.kind, change.name, change.prev, change.next); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+func applyBackupCollection(sc mongo.SessionContext, coll backupCollection, mode string, docs []map[string]interface{}) error {
+	client := coll.dbClient()
+	if mode == configmodels.ImportModeReplace {
+		if err := client.RestfulAPIDeleteManyWithContext(sc, coll.name, bson.M{}); err != nil {
+			return fmt.Errorf("failed to clear collection %s: %w", coll.name, err)
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		items := make([]interface{}, 0, len(docs))
+		for _, doc := range docs {
+			items = append(items, doc)
+		}
+		if err := client.RestfulAPIPostManyWithContext(sc, coll.name, nil, items); err != nil {
+			return fmt.Errorf("failed to restore collection %s: %w", coll.name, err)
+		}
+		return nil
+	}
+	for _, doc := range docs {
+		filter, err := coll.keyFilter(doc)
+		if err != nil {
+			return err
+		}
+		if _, err = client.RestfulAPIPutOneWithContext(sc, coll.name, filter, doc); err != nil {
+			return fmt.Errorf("failed to restore document in %s: %w", coll.name, err)
+		}
+	}
+	return nil
+}
+
+// backupImportMessage builds the config message announcing the imported and
+// deleted device groups and network slices at once, so that the network
+// functions are synchronized once for the whole import.
+func backupImportMessage(plan *backupImportPlan, archive backupSnapshot) (*configmodels.ConfigMessage, error) {
+	msg := &configmodels.ConfigMessage{
+		MsgType:   configmodels.Config_sync,
+		MsgMethod: configmodels.Put_op,
+	}
+	deviceGroups, err := decodeDocuments[configmodels.DeviceGroups](archive[devGroupDataColl])
+	if err != nil {
+		return nil, err
+	}
+	for i := range deviceGroups {
+		msg.DevGroups = append(msg.DevGroups, &deviceGroups[i])
+	}
+	networkSlices, err := decodeDocuments[configmodels.Slice](archive[sliceDataColl])
+	if err != nil {
+		return nil, err
+	}
+	for i := range networkSlices {
+		msg.Slices = append(msg.Slices, &networkSlices[i])
+	}
+	for _, doc := range plan.deleted[devGroupDataColl] {
+		if groupName, ok := doc["group-name"].(string); ok {
+			msg.DeletedDevGroupNames = append(msg.DeletedDevGroupNames, groupName)
+		}
+	}
+	for _, doc := range plan.deleted[sliceDataColl] {
+		if sliceName, ok := doc["slice-name"].(string); ok {
+			msg.DeletedSliceNames = append(msg.DeletedSliceNames, sliceName)
+		}
+	}
+	return msg, nil
+}
@@ -67,6 +67,10 @@ func (db *MockMongoClientDBError) RestfulAPIPostManyWithContext(context context.
 	return errors.New("DB error")
 }
 
+func (db *MockMongoClientDBError) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return nil, errors.New("DB error")
+}
+
 func (db *MockMongoClientDBError) RestfulAPICount(collName string, filter bson.M) (int64, error) {
 	return 0, errors.New("DB error")
 }
@@ -88,10 +92,19 @@ func (db *MockMongoClientEmptyDB) RestfulAPIGetMany(coll string, filter bson.M)
 	return results, nil
 }
 
+func (db *MockMongoClientEmptyDB) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	return results, nil
+}
+
 func (db *MockMongoClientEmptyDB) RestfulAPIPutOneWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
 	return false, nil
 }
 
+func (db *MockMongoClientEmptyDB) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	return true, nil
+}
+
 func (db *MockMongoClientEmptyDB) RestfulAPIPost(collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
 	return true, nil
 }
@@ -138,11 +151,11 @@ func (db *MockMongoClientDuplicateCreation) RestfulAPIGetMany(coll string, filte
 }
 
 func (db *MockMongoClientDuplicateCreation) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
-	return errors.New("E11000")
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
 }
 
 func (db *MockMongoClientDuplicateCreation) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
-	return errors.New("E11000")
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
 }
 
 func (db *MockMongoClientDuplicateCreation) RestfulAPICount(collName string, filter bson.M) (int64, error) {
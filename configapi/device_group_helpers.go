@@ -5,6 +5,7 @@
 package configapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -29,25 +30,26 @@ const (
 	GBPS = 1000000000
 )
 
-func deviceGroupDeleteHelper(groupName string) error {
+func deviceGroupDeleteHelper(ctx context.Context, groupName string) error {
 	logger.ConfigLog.Infof("received Delete Group %s request", groupName)
-	if err := updateDeviceGroupInNetworkSlices(groupName); err != nil {
+	if err := updateDeviceGroupInNetworkSlices(ctx, groupName); err != nil {
 		return fmt.Errorf("error updating device group: %s in network slices: %+v", groupName, err)
 	}
-	if err := handleDeviceGroupDelete(groupName); err != nil {
+	if err := handleDeviceGroupDelete(ctx, groupName); err != nil {
 		return fmt.Errorf("error deleting device group %s: %+v", groupName, err)
 	}
 	var msg configmodels.ConfigMessage
 	msg.MsgType = configmodels.Device_group
 	msg.MsgMethod = configmodels.Delete_op
 	msg.DevGroupName = groupName
+	msg.RequestID = logger.RequestIDFromContext(ctx)
 	configChannel <- &msg
 
 	logger.ConfigLog.Infof("successfully Added Device Group [%s] with delete_op to config channel", groupName)
 	return nil
 }
 
-func updateDeviceGroupInNetworkSlices(groupName string) error {
+func updateDeviceGroupInNetworkSlices(ctx context.Context, groupName string) error {
 	filterByDeviceGroup := bson.M{"site-device-group": groupName}
 	rawNetworkSlices, err := dbadapter.CommonDBClient.RestfulAPIGetMany(sliceDataColl, filterByDeviceGroup)
 	if err != nil {
@@ -66,7 +68,7 @@ func updateDeviceGroupInNetworkSlices(groupName string) error {
 		networkSlice.SiteDeviceGroup = slices.DeleteFunc(networkSlice.SiteDeviceGroup, func(existingDG string) bool {
 			return groupName == existingDG
 		})
-		if statusCode, err := updateNS(networkSlice, *prevSlice); err != nil {
+		if statusCode, err := updateNS(ctx, networkSlice, *prevSlice); err != nil {
 			logger.ConfigLog.Errorf("Error updating slice: %s status code: %d error: %+v", networkSlice.SliceName, statusCode, err)
 			errorOccurred = true
 			continue
@@ -76,6 +78,7 @@ func updateDeviceGroupInNetworkSlices(groupName string) error {
 			MsgType:   configmodels.Network_slice,
 			Slice:     &networkSlice,
 			SliceName: networkSlice.SliceName,
+			RequestID: logger.RequestIDFromContext(ctx),
 		}
 		configChannel <- msg
 		logger.ConfigLog.Infof("network slice [%s] update sent to config channel", networkSlice.SliceName)
@@ -86,7 +89,7 @@ func updateDeviceGroupInNetworkSlices(groupName string) error {
 	return nil
 }
 
-func deviceGroupPostHelper(requestDeviceGroup configmodels.DeviceGroups, msgOp int, groupName string) (int, error) {
+func deviceGroupPostHelper(ctx context.Context, requestDeviceGroup configmodels.DeviceGroups, msgOp int, groupName string) (int, error) {
 	logger.ConfigLog.Infof("received device group: %s", groupName)
 
 	ipdomain := &requestDeviceGroup.IpDomainExpanded
@@ -100,29 +103,28 @@ func deviceGroupPostHelper(requestDeviceGroup configmodels.DeviceGroups, msgOp i
 	logger.ConfigLog.Infof("ip mtu: %v", ipdomain.Mtu)
 	logger.ConfigLog.Infof("device Group Name: %s", groupName)
 
-	if ipdomain.UeDnnQos != nil {
-		ipdomain.UeDnnQos.DnnMbrDownlink = convertToBps(ipdomain.UeDnnQos.DnnMbrDownlink, ipdomain.UeDnnQos.BitrateUnit)
-		if ipdomain.UeDnnQos.DnnMbrDownlink < 0 {
-			ipdomain.UeDnnQos.DnnMbrDownlink = math.MaxInt64
-		}
-		logger.ConfigLog.Infof("MbrDownLink: %v", ipdomain.UeDnnQos.DnnMbrDownlink)
-		ipdomain.UeDnnQos.DnnMbrUplink = convertToBps(ipdomain.UeDnnQos.DnnMbrUplink, ipdomain.UeDnnQos.BitrateUnit)
-		if ipdomain.UeDnnQos.DnnMbrUplink < 0 {
-			ipdomain.UeDnnQos.DnnMbrUplink = math.MaxInt64
-		}
-		logger.ConfigLog.Infof("MbrUpLink: %v", ipdomain.UeDnnQos.DnnMbrUplink)
-	}
+	normalizeDeviceGroupQos(ipdomain)
+	requestDeviceGroup.DeviceGroupName = groupName
+	return deviceGroupStoreHelper(ctx, requestDeviceGroup, msgOp)
+}
 
+// deviceGroupStoreHelper creates or updates a normalized device group and sends
+// it to the config channel. A POST only creates, it fails if the device group
+// exists.
+func deviceGroupStoreHelper(ctx context.Context, requestDeviceGroup configmodels.DeviceGroups, msgOp int) (int, error) {
+	groupName := requestDeviceGroup.DeviceGroupName
 	prevDevGroup := getDeviceGroupByName(groupName)
-	requestDeviceGroup.DeviceGroupName = groupName
+	if msgOp == configmodels.Post_op && prevDevGroup != nil && prevDevGroup.DeviceGroupName != "" {
+		return http.StatusConflict, fmt.Errorf("device group %s already exists", groupName)
+	}
 	if prevDevGroup == nil {
 		logger.ConfigLog.Infof("creating new device group %s", groupName)
-		statusCode, err := createDG(&requestDeviceGroup)
+		statusCode, err := createDG(ctx, &requestDeviceGroup)
 		if err != nil {
 			return statusCode, err
 		}
 	} else {
-		statusCode, err := updateDG(&requestDeviceGroup, prevDevGroup)
+		statusCode, err := updateDG(ctx, &requestDeviceGroup, prevDevGroup)
 		if err != nil {
 			return statusCode, err
 		}
@@ -133,21 +135,39 @@ func deviceGroupPostHelper(requestDeviceGroup configmodels.DeviceGroups, msgOp i
 	msg.MsgMethod = msgOp
 	msg.DevGroup = &requestDeviceGroup
 	msg.DevGroupName = groupName
+	msg.RequestID = logger.RequestIDFromContext(ctx)
 	configChannel <- &msg
 	logger.ConfigLog.Infof("successfully added Device Group [%s] to config channel", groupName)
 	return http.StatusOK, nil
 }
 
-func createDG(devGroup *configmodels.DeviceGroups) (int, error) {
-	if statusCode, err := handleDeviceGroupPost(devGroup, nil); err != nil {
+// normalizeDeviceGroupQos converts the DNN bitrates of the request to bps.
+func normalizeDeviceGroupQos(ipdomain *configmodels.DeviceGroupsIpDomainExpanded) {
+	if ipdomain.UeDnnQos == nil {
+		return
+	}
+	ipdomain.UeDnnQos.DnnMbrDownlink = convertToBps(ipdomain.UeDnnQos.DnnMbrDownlink, ipdomain.UeDnnQos.BitrateUnit)
+	if ipdomain.UeDnnQos.DnnMbrDownlink < 0 {
+		ipdomain.UeDnnQos.DnnMbrDownlink = math.MaxInt64
+	}
+	logger.ConfigLog.Infof("MbrDownLink: %v", ipdomain.UeDnnQos.DnnMbrDownlink)
+	ipdomain.UeDnnQos.DnnMbrUplink = convertToBps(ipdomain.UeDnnQos.DnnMbrUplink, ipdomain.UeDnnQos.BitrateUnit)
+	if ipdomain.UeDnnQos.DnnMbrUplink < 0 {
+		ipdomain.UeDnnQos.DnnMbrUplink = math.MaxInt64
+	}
+	logger.ConfigLog.Infof("MbrUpLink: %v", ipdomain.UeDnnQos.DnnMbrUplink)
+}
+
+func createDG(ctx context.Context, devGroup *configmodels.DeviceGroups) (int, error) {
+	if statusCode, err := handleDeviceGroupPost(ctx, devGroup, nil); err != nil {
 		logger.ConfigLog.Errorf("error creating device group %+v: %+v", devGroup, err)
 		return statusCode, err
 	}
 	return http.StatusOK, nil
 }
 
-func updateDG(devGroup *configmodels.DeviceGroups, prevDevGroup *configmodels.DeviceGroups) (int, error) {
-	if statusCode, err := handleDeviceGroupPost(devGroup, prevDevGroup); err != nil {
+func updateDG(ctx context.Context, devGroup *configmodels.DeviceGroups, prevDevGroup *configmodels.DeviceGroups) (int, error) {
+	if statusCode, err := handleDeviceGroupPost(ctx, devGroup, prevDevGroup); err != nil {
 		logger.ConfigLog.Errorf("error updating device group %+v: %+v", devGroup, err)
 		return statusCode, err
 	}
@@ -170,16 +190,22 @@ func convertToBps(val int64, unit string) int64 {
 	}
 }
 
-func handleDeviceGroupPost(devGroup *configmodels.DeviceGroups, prevDevGroup *configmodels.DeviceGroups) (int, error) {
+func handleDeviceGroupPost(ctx context.Context, devGroup *configmodels.DeviceGroups, prevDevGroup *configmodels.DeviceGroups) (int, error) {
 	filter := bson.M{"group-name": devGroup.DeviceGroupName}
 	devGroupDataBsonA := configmodels.ToBsonM(devGroup)
-	result, err := dbadapter.CommonDBClient.RestfulAPIPost(devGroupDataColl, filter, devGroupDataBsonA)
+	err := putResourceVersion(ctx, dbadapter.CommonDBClient, devGroupDataColl, filter, devGroupDataBsonA)
 	if err != nil {
 		logger.DbLog.Errorf("failed to post device group data for %s: %+v", devGroup.DeviceGroupName, err)
+		return versionConflictStatus(err, http.StatusInternalServerError), err
+	}
+	var prevDevGroupBsonA bson.M
+	if prevDevGroup != nil && prevDevGroup.DeviceGroupName != "" {
+		prevDevGroupBsonA = configmodels.ToBsonM(prevDevGroup)
+	}
+	if err = recordConfigRevision(ctx, configmodels.ApplyKindDeviceGroup, devGroup.DeviceGroupName, prevDevGroupBsonA, devGroupDataBsonA); err != nil {
+		logger.DbLog.Errorln(err)
 		return http.StatusInternalServerError, err
 	}
-	logger.DbLog.Infof("DB operation result for device group %s: %v",
-		devGroup.DeviceGroupName, result)
 
 	statusCode, err := syncDeviceGroupSubscriber(devGroup, prevDevGroup)
 	if err != nil {
@@ -187,6 +213,7 @@ func handleDeviceGroupPost(devGroup *configmodels.DeviceGroups, prevDevGroup *co
 		return statusCode, err
 	}
 	logger.DbLog.Debugf("succeeded to post device group data for %s", devGroup.DeviceGroupName)
+	notifyConfigChanges(ctx, newConfigChange(configmodels.ApplyKindDeviceGroup, configChangeOperation(prevDevGroupBsonA != nil), devGroup.DeviceGroupName))
 	return http.StatusOK, nil
 }
 
@@ -250,16 +277,24 @@ func syncDeviceGroupSubscriber(devGroup *configmodels.DeviceGroups, prevDevGroup
 	}
 }
 
-func handleDeviceGroupDelete(groupName string) error {
+func handleDeviceGroupDelete(ctx context.Context, groupName string) error {
+	prevDevGroup := getDeviceGroupByName(groupName)
 	rwLock.Lock()
 	defer rwLock.Unlock()
 	filter := bson.M{"group-name": groupName}
-	err := dbadapter.CommonDBClient.RestfulAPIDeleteOne(devGroupDataColl, filter)
+	err := deleteResourceVersion(ctx, dbadapter.CommonDBClient, devGroupDataColl, filter)
 	if err != nil {
 		logger.DbLog.Errorf("failed to delete device group data for %s: %+v", groupName, err)
 		return err
 	}
+	if prevDevGroup != nil && prevDevGroup.DeviceGroupName != "" {
+		if err = recordConfigRevision(ctx, configmodels.ApplyKindDeviceGroup, groupName, configmodels.ToBsonM(prevDevGroup), nil); err != nil {
+			logger.DbLog.Errorln(err)
+			return err
+		}
+	}
 	logger.DbLog.Debugf("succeeded to device group data for %s", groupName)
+	notifyConfigChanges(ctx, newConfigChange(configmodels.ApplyKindDeviceGroup, configmodels.ApplyOpDelete, groupName))
 	return nil
 }
 
@@ -4,6 +4,7 @@
 package configapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -25,6 +26,10 @@ type MockMongoDGPost struct {
 	dbadapter.DBInterface
 }
 
+func (m *MockMongoDGPost) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	return nil, nil
+}
+
 func (m *MockMongoDGPost) RestfulAPIPost(coll string, filter primitive.M, data map[string]interface{}) (bool, error) {
 	params := map[string]interface{}{
 		"coll":   coll,
@@ -39,6 +44,14 @@ func (m *MockMongoDGPost) RestfulAPIGetMany(collName string, filter bson.M) ([]m
 	return []map[string]interface{}{}, nil
 }
 
+func (m *MockMongoDGPost) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (m *MockMongoDGPost) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 type MockMongoDeviceGroupGetOne struct {
 	dbadapter.DBInterface
 	testGroup configmodels.DeviceGroups
@@ -79,7 +92,7 @@ func Test_handleDeviceGroupPost(t *testing.T) {
 			}()
 			dbadapter.CommonDBClient = mockDB
 
-			statusCode, err := handleDeviceGroupPost(&dg, nil)
+			statusCode, err := handleDeviceGroupPost(context.Background(), &dg, nil)
 			if err != nil {
 				t.Fatalf("Could not handle device group post: %+v status code: %d", err, statusCode)
 			}
@@ -144,10 +157,22 @@ func (m *MockMongoDeviceGroupCombined) Client() *mongo.Client {
 	return nil
 }
 
+func (m *MockMongoDeviceGroupCombined) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	return m.RestfulAPIPost(collName, filter, putData)
+}
+
 func (m *MockMongoDeviceGroupCombined) RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error) {
 	return []map[string]interface{}{}, nil
 }
 
+func (m *MockMongoDeviceGroupCombined) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (m *MockMongoDeviceGroupCombined) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 func Test_handleDeviceGroupPost_alreadyExists(t *testing.T) {
 	deviceGroups := []configmodels.DeviceGroups{
 		deviceGroup("group1"),
@@ -175,7 +200,7 @@ func Test_handleDeviceGroupPost_alreadyExists(t *testing.T) {
 			}()
 			dbadapter.CommonDBClient = mock
 
-			statusCode, err := handleDeviceGroupPost(&dg, &dg)
+			statusCode, err := handleDeviceGroupPost(context.Background(), &dg, &dg)
 			if err != nil {
 				t.Fatalf("handleDeviceGroupPost returned error: %+v statusCode: %d", err, statusCode)
 			}
@@ -188,7 +213,7 @@ func Test_handleDeviceGroupPost_alreadyExists(t *testing.T) {
 				t.Errorf("Expected collection %v, got %v", devGroupDataColl, postData[0]["coll"])
 			}
 
-			expectedFilter := bson.M{"group-name": dg.DeviceGroupName}
+			expectedFilter := versionFilter(bson.M{"group-name": dg.DeviceGroupName}, 0)
 			if !reflect.DeepEqual(postData[0]["filter"], expectedFilter) {
 				t.Errorf("Expected filter %v, got %v", expectedFilter, postData[0]["filter"])
 			}
@@ -221,7 +246,7 @@ func Test_handleDeviceGroupDelete(t *testing.T) {
 			}()
 			dbadapter.CommonDBClient = &MockMongoDeleteOne{}
 
-			err := handleDeviceGroupDelete(testGroup.DeviceGroupName)
+			err := handleDeviceGroupDelete(context.Background(), testGroup.DeviceGroupName)
 			if err != nil {
 				t.Fatalf("handleDeviceGroupDelete failed: %v", err)
 			}
@@ -321,3 +346,29 @@ func TestDeviceGroupPostHandler_DeviceGroupNameValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestDeviceGroupPostHandler_AlreadyExists(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	origChannel := configChannel
+	configChannel = make(chan *configmodels.ConfigMessage, 1)
+	originalDBClient := dbadapter.CommonDBClient
+	defer func() { configChannel = origChannel; dbadapter.CommonDBClient = originalDBClient }()
+	dbadapter.CommonDBClient = &MockMongoClientFoundDeviceGroup{}
+
+	req := httptest.NewRequest(http.MethodPost, "/config/v1/device-group/group1", strings.NewReader(DEVICE_GROUP_CONFIG))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected `%v`, got `%v`", http.StatusConflict, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"code":"already-exists"`) {
+		t.Errorf("Expected an already-exists problem, got `%v`", w.Body.String())
+	}
+	if len(configChannel) != 0 {
+		t.Error("Expected no config message for an existing device group")
+	}
+}
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	configEventSubscriberBuffer = 64
+	configEventPageSize         = 500
+	configEventQueueSize        = 1024
+	// maxConfigEventInserts bounds the attempts to store an event while the
+	// counter is behind the stored revisions
+	maxConfigEventInserts  = 5
+	configEventCounterName = "config-event"
+)
+
+// configEventBroker numbers the configuration changes, stores them and fans
+// them out to the event stream subscribers. A subscriber that does not keep up
+// is disconnected and is expected to resume from the last event it received.
+// The revisions are taken from a counter in the common database, so that the
+// replicas of webconsole never give the same revision to two events.
+type configEventBroker struct {
+	mu            sync.Mutex
+	counterSeeded bool
+	subscribers   map[chan configmodels.ConfigEvent]struct{}
+}
+
+func newConfigEventBroker() *configEventBroker {
+	return &configEventBroker{subscribers: map[chan configmodels.ConfigEvent]struct{}{}}
+}
+
+var configEvents = newConfigEventBroker()
+
+var configEventOperations = map[int]string{
+	configmodels.Post_op:   configmodels.ApplyOpCreate,
+	configmodels.Put_op:    configmodels.ApplyOpUpdate,
+	configmodels.Delete_op: configmodels.ApplyOpDelete,
+}
+
+// newConfigEvents describes a configuration message as events, without their
+// revisions: one event, or one per subscriber of a message about several and
+// one per device group and network slice of a Config_sync message.
+func newConfigEvents(msg *configmodels.ConfigMessage) ([]configmodels.ConfigEvent, error) {
+	event := configmodels.ConfigEvent{Timestamp: time.Now().UTC()}
+	operation, ok := configEventOperations[msg.MsgMethod]
+	if !ok {
+		return nil, fmt.Errorf("unknown message method %d", msg.MsgMethod)
+	}
+	event.Operation = operation
+	switch msg.MsgType {
+	case configmodels.Device_group:
+		event.Type = configmodels.ApplyKindDeviceGroup
+		event.Name = msg.DevGroupName
+		if event.Name == "" && msg.DevGroup != nil {
+			event.Name = msg.DevGroup.DeviceGroupName
+		}
+	case configmodels.Network_slice:
+		event.Type = configmodels.ApplyKindNetworkSlice
+		event.Name = msg.SliceName
+		if event.Name == "" && msg.Slice != nil {
+			event.Name = msg.Slice.SliceName
+		}
+	case configmodels.Sub_data:
+		event.Type = configmodels.ConfigEventTypeSubscriber
+		if len(msg.Imsis) > 0 {
+			events := make([]configmodels.ConfigEvent, 0, len(msg.Imsis))
+			for _, imsi := range msg.Imsis {
+				event.Name = imsi
+				events = append(events, event)
+			}
+			return events, nil
+		}
+		event.Name = msg.Imsi
+	case configmodels.Config_sync:
+		return newConfigSyncEvents(event, msg), nil
+	default:
+		return nil, fmt.Errorf("unknown message type %d", msg.MsgType)
+	}
+	return []configmodels.ConfigEvent{event}, nil
+}
+
+func newConfigSyncEvents(event configmodels.ConfigEvent, msg *configmodels.ConfigMessage) []configmodels.ConfigEvent {
+	var events []configmodels.ConfigEvent
+	operation := event.Operation
+	add := func(kind, operation, name string) {
+		event.Type, event.Operation, event.Name = kind, operation, name
+		events = append(events, event)
+	}
+	for _, deviceGroup := range msg.DevGroups {
+		add(configmodels.ApplyKindDeviceGroup, operation, deviceGroup.DeviceGroupName)
+	}
+	for _, networkSlice := range msg.Slices {
+		add(configmodels.ApplyKindNetworkSlice, operation, networkSlice.SliceName)
+	}
+	for _, groupName := range msg.DeletedDevGroupNames {
+		add(configmodels.ApplyKindDeviceGroup, configmodels.ApplyOpDelete, groupName)
+	}
+	for _, sliceName := range msg.DeletedSliceNames {
+		add(configmodels.ApplyKindNetworkSlice, configmodels.ApplyOpDelete, sliceName)
+	}
+	return events
+}
+
+// latestConfigEventRevision returns the revision of the last stored event, or 0
+// if there is none.
+func latestConfigEventRevision() (int64, error) {
+	rawEvents, err := dbadapter.CommonDBClient.RestfulAPIGetManyPaged(configmodels.ConfigEventDataColl,
+		bson.M{}, bson.D{{Key: "revision", Value: -1}}, 0, 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve latest config event: %w", err)
+	}
+	events, err := decodeDocuments[configmodels.ConfigEvent](rawEvents)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode latest config event: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+	return events[0].Revision, nil
+}
+
+// getConfigEventsSince returns up to limit events following revision, oldest
+// first.
+func getConfigEventsSince(revision int64, limit int64) ([]configmodels.ConfigEvent, error) {
+	rawEvents, err := dbadapter.CommonDBClient.RestfulAPIGetManyPaged(configmodels.ConfigEventDataColl,
+		bson.M{"revision": bson.M{"$gt": revision}}, bson.D{{Key: "revision", Value: 1}}, 0, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve config events: %w", err)
+	}
+	return decodeDocuments[configmodels.ConfigEvent](rawEvents)
+}
+
+// nextConfigEventRevision takes the next revision from the counter. The counter
+// is first brought up to the last stored revision, for the events stored before
+// it existed.
+func (b *configEventBroker) nextConfigEventRevision() (int64, error) {
+	filter := bson.M{"name": configEventCounterName}
+	if !b.counterSeeded {
+		revision, err := latestConfigEventRevision()
+		if err != nil {
+			return 0, err
+		}
+		_, err = dbadapter.CommonDBClient.RestfulAPIFindOneAndUpdateWithContext(context.Background(), configmodels.ConfigEventCounterColl,
+			filter, bson.M{"$max": bson.M{"revision": revision}})
+		if err != nil {
+			return 0, fmt.Errorf("failed to seed the config event counter: %w", err)
+		}
+		b.counterSeeded = true
+	}
+	counter, err := dbadapter.CommonDBClient.RestfulAPIFindOneAndUpdateWithContext(context.Background(), configmodels.ConfigEventCounterColl,
+		filter, bson.M{"$inc": bson.M{"revision": int64(1)}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to take the next config event revision: %w", err)
+	}
+	return documentInt(counter, "revision"), nil
+}
+
+// publish assigns the next revision to the event, stores it and sends it to the
+// subscribers.
+func (b *configEventBroker) publish(event configmodels.ConfigEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored := false
+	for range maxConfigEventInserts {
+		revision, err := b.nextConfigEventRevision()
+		if err != nil {
+			return err
+		}
+		event.Revision = revision
+		err = dbadapter.CommonDBClient.RestfulAPIPostMany(configmodels.ConfigEventDataColl, nil,
+			[]interface{}{configmodels.ToBsonM(event)})
+		if err == nil {
+			stored = true
+			break
+		}
+		if !dbadapter.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to store config event %d: %w", event.Revision, err)
+		}
+		// the counter was reset behind the stored events
+		b.counterSeeded = false
+	}
+	if !stored {
+		return fmt.Errorf("failed to store config event: revisions already taken")
+	}
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			logger.WebUILog.Warnf("config event subscriber is too slow, disconnecting it at revision %d", event.Revision)
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return nil
+}
+
+func (b *configEventBroker) subscribe() chan configmodels.ConfigEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subscriber := make(chan configmodels.ConfigEvent, configEventSubscriberBuffer)
+	b.subscribers[subscriber] = struct{}{}
+	return subscriber
+}
+
+func (b *configEventBroker) unsubscribe(subscriber chan configmodels.ConfigEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[subscriber]; ok {
+		delete(b.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+// PublishConfigEvent notifies the event stream subscribers of a configuration
+// message.
+func PublishConfigEvent(msg *configmodels.ConfigMessage) {
+	events, err := newConfigEvents(msg)
+	if err != nil {
+		logger.ConfigLog.Warnf("config message is not published as an event: %+v", err)
+		return
+	}
+	for _, event := range events {
+		if err = configEvents.publish(event); err != nil {
+			logger.ConfigLog.Errorf("failed to publish %s event for %s %s: %+v", event.Operation, event.Type, event.Name, err)
+		}
+	}
+}
+
+var (
+	configEventQueue          = make(chan *configmodels.ConfigMessage, configEventQueueSize)
+	startConfigEventPublisher sync.Once
+)
+
+// QueueConfigEvent publishes a configuration message in the background, in the
+// order of the calls, so that storing its events does not hold up the
+// configuration of the network functions. It only blocks while
+// configEventQueueSize messages are waiting.
+func QueueConfigEvent(msg *configmodels.ConfigMessage) {
+	startConfigEventPublisher.Do(func() {
+		go func() {
+			for msg := range configEventQueue {
+				PublishConfigEvent(msg)
+			}
+		}()
+	})
+	configEventQueue <- msg
+}
@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	errorApiTokenNotFound  = "API token not found"
+	errorCreateApiToken    = "failed to create API token"
+	errorDeleteApiToken    = "failed to revoke API token"
+	errorRetrieveApiTokens = "failed to retrieve API tokens"
+)
+
+// GetApiTokens godoc
+//
+// @Description  Return the API tokens of a user account, without the tokens themselves
+// @Tags         User Accounts
+// @Produce      json
+// @Param        username    path    string    true    "Username of the user account"
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.ApiTokenResponse  "List of API tokens"
+// @Failure      401  {object}  nil                            "Authorization failed"
+// @Failure      403  {object}  nil                            "Forbidden"
+// @Failure      404  {object}  nil                            "User account not found. Or Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil                            "Error retrieving API tokens"
+// @Router      /config/v1/account/{username}/tokens  [get]
+func GetApiTokens(c *gin.Context) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("get API tokens")
+	username := c.Param("username")
+	dbUserAccount, err := fetchDBUserAccount(username)
+	if err != nil {
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccount, requestID)
+		return
+	}
+	if dbUserAccount == nil {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorUsernameNotFound, requestID)
+		return
+	}
+	rawTokens, err := dbadapter.WebuiDBClient.RestfulAPIGetMany(configmodels.ApiTokenDataColl, bson.M{"username": username})
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveApiTokens, requestID)
+		return
+	}
+	apiTokens, err := decodeDocuments[configmodels.DBApiToken](rawTokens)
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveApiTokens, requestID)
+		return
+	}
+	tokenResponses := make([]configmodels.ApiTokenResponse, 0, len(apiTokens))
+	for _, apiToken := range apiTokens {
+		tokenResponses = append(tokenResponses, apiTokenResponse(apiToken))
+	}
+	c.JSON(http.StatusOK, tokenResponses)
+}
+
+// CreateApiToken godoc
+//
+// @Description  Create a long-lived API token authenticating as a user account, typically a service account used by automation. The token is only returned in this response
+// @Tags         User Accounts
+// @Accept       json
+// @Produce      json
+// @Param        username    path    string                                true    "Username of the user account"
+// @Param        params      body    configmodels.CreateApiTokenParams    true    "Name, scopes and expiry of the token"
+// @Security     BearerAuth
+// @Success      201  {object}  configmodels.CreateApiTokenResponse  "API token created"
+// @Failure      400  {object}  nil                                  "Bad request"
+// @Failure      401  {object}  nil                                  "Authorization failed"
+// @Failure      403  {object}  nil                                  "Forbidden"
+// @Failure      404  {object}  nil                                  "User account not found. Or Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil                                  "Failed to create the API token"
+// @Router      /config/v1/account/{username}/tokens  [post]
+func CreateApiToken(c *gin.Context) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("create API token")
+	username := c.Param("username")
+	var params configmodels.CreateApiTokenParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorInvalidDataProvided, requestID)
+		return
+	}
+	now := time.Now().UTC()
+	if err := validateApiTokenParams(params, now); err != nil {
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
+	dbUserAccount, err := fetchDBUserAccount(username)
+	if err != nil {
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccount, requestID)
+		return
+	}
+	if dbUserAccount == nil {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorUsernameNotFound, requestID)
+		return
+	}
+	token, tokenHash, err := auth.GenerateAPIToken()
+	if err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorCreateApiToken, requestID)
+		return
+	}
+	apiToken := configmodels.DBApiToken{
+		Id:        uuid.New().String(),
+		Username:  username,
+		Name:      params.Name,
+		TokenHash: tokenHash,
+		Scopes:    params.Scopes,
+		CreatedAt: now,
+		ExpiresAt: params.ExpiresAt,
+	}
+	err = dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.ApiTokenDataColl, nil, []interface{}{apiTokenDocument(apiToken)})
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorCreateApiToken, requestID)
+		return
+	}
+	logger.WebUILog.Infof("created API token %s for user account %s", apiToken.Id, username)
+	c.JSON(http.StatusCreated, configmodels.CreateApiTokenResponse{
+		ApiTokenResponse: apiTokenResponse(apiToken),
+		Token:            token,
+	})
+}
+
+// DeleteApiToken godoc
+//
+// @Description  Revoke an API token. Requests authenticated with it are refused from then on
+// @Tags         User Accounts
+// @Produce      json
+// @Param        username    path    string    true    "Username of the user account"
+// @Param        tokenId     path    string    true    "ID of the API token"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "API token revoked"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "API token not found. Or Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil  "Failed to revoke the API token"
+// @Router      /config/v1/account/{username}/tokens/{tokenId}  [delete]
+func DeleteApiToken(c *gin.Context) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("delete API token")
+	filter := bson.M{"id": c.Param("token-id"), "username": c.Param("username")}
+	rawToken, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.ApiTokenDataColl, filter)
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveApiTokens, requestID)
+		return
+	}
+	if len(rawToken) == 0 {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorApiTokenNotFound, requestID)
+		return
+	}
+	if err = dbadapter.WebuiDBClient.RestfulAPIDeleteOne(configmodels.ApiTokenDataColl, filter); err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorDeleteApiToken, requestID)
+		return
+	}
+	logger.WebUILog.Infof("revoked API token %s of user account %s", c.Param("token-id"), c.Param("username"))
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func validateApiTokenParams(params configmodels.CreateApiTokenParams, now time.Time) error {
+	if params.Name == "" {
+		return newValidationError("name", "name is required")
+	}
+	for _, scope := range params.Scopes {
+		if !slices.Contains(configmodels.ApiTokenScopes, scope) {
+			return newValidationError("scopes", "unknown scope %s, expected one of %v", scope, configmodels.ApiTokenScopes)
+		}
+	}
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(now) {
+		return newValidationError("expires-at", "expires-at must be in the future")
+	}
+	return nil
+}
+
+// apiTokenDocument returns the stored form of an API token. The expiry is
+// stored as a date so that MongoDB removes expired tokens.
+func apiTokenDocument(apiToken configmodels.DBApiToken) bson.M {
+	document := bson.M{
+		"id":         apiToken.Id,
+		"username":   apiToken.Username,
+		"name":       apiToken.Name,
+		"token-hash": apiToken.TokenHash,
+		"created-at": apiToken.CreatedAt,
+	}
+	if len(apiToken.Scopes) > 0 {
+		document["scopes"] = apiToken.Scopes
+	}
+	if apiToken.ExpiresAt != nil {
+		document["expires-at"] = *apiToken.ExpiresAt
+	}
+	return document
+}
+
+func apiTokenResponse(apiToken configmodels.DBApiToken) configmodels.ApiTokenResponse {
+	return configmodels.ApiTokenResponse{
+		Id:        apiToken.Id,
+		Name:      apiToken.Name,
+		Scopes:    apiToken.Scopes,
+		CreatedAt: apiToken.CreatedAt,
+		ExpiresAt: apiToken.ExpiresAt,
+	}
+}
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientApiTokens struct {
+	dbadapter.DBInterface
+	accounts []string
+	tokens   []map[string]interface{}
+	deleted  []bson.M
+}
+
+func (db *MockMongoClientApiTokens) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	if collName == configmodels.UserAccountDataColl {
+		for _, username := range db.accounts {
+			if filter["username"] == username {
+				return map[string]interface{}{"username": username, "role": configmodels.UserRole, "service-account": true}, nil
+			}
+		}
+		return map[string]interface{}{}, nil
+	}
+	for _, token := range db.tokens {
+		if token["id"] == filter["id"] && token["username"] == filter["username"] {
+			return token, nil
+		}
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (db *MockMongoClientApiTokens) RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error) {
+	var tokens []map[string]interface{}
+	for _, token := range db.tokens {
+		if token["username"] == filter["username"] {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (db *MockMongoClientApiTokens) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, postData := range postDataArray {
+		db.tokens = append(db.tokens, postData.(bson.M))
+	}
+	return nil
+}
+
+func (db *MockMongoClientApiTokens) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	db.deleted = append(db.deleted, filter)
+	return nil
+}
+
+func TestCreateApiTokenHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/config/v1/account/:username/tokens", CreateApiToken)
+
+	testCases := []struct {
+		name         string
+		username     string
+		inputData    string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "TokenWithScopesAndExpiry",
+			username:     "ci-bot",
+			inputData:    `{"name": "pipeline", "scopes": ["subscribers:write", "slices:write"], "expires-at": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "TokenWithoutScopes",
+			username:     "ci-bot",
+			inputData:    `{"name": "pipeline"}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "MissingName",
+			username:     "ci-bot",
+			inputData:    `{"scopes": ["read-only"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"errors":[{"field":"name","message":"name is required"}]`,
+		},
+		{
+			name:         "UnknownScope",
+			username:     "ci-bot",
+			inputData:    `{"name": "pipeline", "scopes": ["admin"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"scopes"`,
+		},
+		{
+			name:         "ExpiryInThePast",
+			username:     "ci-bot",
+			inputData:    `{"name": "pipeline", "expires-at": "2020-01-01T00:00:00Z"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"errors":[{"field":"expires-at","message":"expires-at must be in the future"}]`,
+		},
+		{
+			name:         "AccountNotFound",
+			username:     "unknown",
+			inputData:    `{"name": "pipeline"}`,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `"code":"not-found"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.WebuiDBClient
+			defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+			dbClient := &MockMongoClientApiTokens{accounts: []string{"ci-bot"}}
+			dbadapter.WebuiDBClient = dbClient
+			req := httptest.NewRequest(http.MethodPost, "/config/v1/account/"+tc.username+"/tokens", strings.NewReader(tc.inputData))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Fatalf("Expected `%v`, got `%v`: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if tc.expectedCode != http.StatusCreated {
+				if !strings.Contains(w.Body.String(), tc.expectedBody) {
+					t.Errorf("Expected body containing `%v`, got `%v`", tc.expectedBody, w.Body.String())
+				}
+				if len(dbClient.tokens) != 0 {
+					t.Errorf("Expected no stored token, got %v", dbClient.tokens)
+				}
+				return
+			}
+			var response configmodels.CreateApiTokenResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Token == "" || response.Id == "" || response.Name != "pipeline" {
+				t.Errorf("Unexpected response %+v", response)
+			}
+			if len(dbClient.tokens) != 1 {
+				t.Fatalf("Expected one stored token, got %d", len(dbClient.tokens))
+			}
+			stored := dbClient.tokens[0]
+			sum := sha256.Sum256([]byte(response.Token))
+			if stored["token-hash"] != hex.EncodeToString(sum[:]) {
+				t.Errorf("Expected the token to be stored hashed, got %v", stored)
+			}
+			if stored["username"] != "ci-bot" || stored["id"] != response.Id {
+				t.Errorf("Unexpected stored token %v", stored)
+			}
+			if _, ok := stored["expires-at"]; ok != (response.ExpiresAt != nil) {
+				t.Errorf("Expected the expiry to be stored only if set, got %v", stored)
+			}
+		})
+	}
+}
+
+func TestGetApiTokensHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/config/v1/account/:username/tokens", GetApiTokens)
+	originalDBClient := dbadapter.WebuiDBClient
+	defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+	dbadapter.WebuiDBClient = &MockMongoClientApiTokens{
+		accounts: []string{"ci-bot"},
+		tokens: []map[string]interface{}{
+			{"id": "token1", "username": "ci-bot", "name": "pipeline", "token-hash": "hash1", "scopes": []interface{}{"read-only"}, "created-at": "2025-01-01T00:00:00Z"},
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/config/v1/account/ci-bot/tokens", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	expectedBody := `[{"id":"token1","name":"pipeline","scopes":["read-only"],"created-at":"2025-01-01T00:00:00Z"}]`
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected `%v`, got `%v`", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != expectedBody {
+		t.Errorf("Expected `%v`, got `%v`", expectedBody, w.Body.String())
+	}
+}
+
+func TestDeleteApiTokenHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.DELETE("/config/v1/account/:username/tokens/:token-id", DeleteApiToken)
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			path:         "/config/v1/account/ci-bot/tokens/token1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "TokenNotFound",
+			path:         "/config/v1/account/ci-bot/tokens/token2",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "TokenOfAnotherAccount",
+			path:         "/config/v1/account/other/tokens/token1",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.WebuiDBClient
+			defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+			dbClient := &MockMongoClientApiTokens{
+				accounts: []string{"ci-bot", "other"},
+				tokens:   []map[string]interface{}{{"id": "token1", "username": "ci-bot", "name": "pipeline", "token-hash": "hash1"}},
+			}
+			dbadapter.WebuiDBClient = dbClient
+			req := httptest.NewRequest(http.MethodDelete, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
+			}
+			if deleted := len(dbClient.deleted) > 0; deleted != (tc.expectedCode == http.StatusOK) {
+				t.Errorf("Unexpected deletions %v", dbClient.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	errorBuiltInRole      = "built-in roles cannot be changed"
+	errorRoleNotFound     = "role not found"
+	errorRetrieveRoles    = "failed to retrieve roles"
+	errorUpdateRole       = "failed to update role"
+	errorAssignAdmin      = "the admin role belongs to the admin account and cannot be assigned"
+	errorAdminAccountRole = "the roles of the admin account cannot be changed"
+)
+
+// GetRoles godoc
+//
+// @Description  Return the built-in and custom roles
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   configmodels.Role  "List of roles"
+// @Failure      401  {object}  nil                "Authorization failed"
+// @Failure      403  {object}  nil                "Forbidden"
+// @Failure      404  {object}  nil                "Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil                "Error retrieving roles"
+// @Router       /config/v1/role  [get]
+func GetRoles(c *gin.Context) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("get roles")
+	rawRoles, err := dbadapter.WebuiDBClient.RestfulAPIGetMany(configmodels.RoleDataColl, bson.M{})
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveRoles, requestID)
+		return
+	}
+	customRoles, err := decodeDocuments[configmodels.Role](rawRoles)
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveRoles, requestID)
+		return
+	}
+	roles := make([]configmodels.Role, 0, len(configmodels.BuiltInRoles)+len(customRoles))
+	for _, role := range configmodels.BuiltInRoles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	roles = append(roles, customRoles...)
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetRole godoc
+//
+// @Description  Return a built-in or custom role
+// @Tags         Roles
+// @Produce      json
+// @Param        roleName    path    string    true    "Name of the role"
+// @Security     BearerAuth
+// @Success      200  {object}  configmodels.Role  "Role"
+// @Failure      401  {object}  nil                "Authorization failed"
+// @Failure      403  {object}  nil                "Forbidden"
+// @Failure      404  {object}  nil                "Role not found. Or Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil                "Error retrieving the role"
+// @Router       /config/v1/role/{roleName}  [get]
+func GetRole(c *gin.Context) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("get role")
+	role, err := getRole(c.Param("role-name"))
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveRoles, requestID)
+		return
+	}
+	if role == nil {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorRoleNotFound, requestID)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// PostRole godoc
+//
+// @Description  Create a custom role. A permission allows a verb (read, create, update or delete) on a resource type (network-slice, device-group, subscriber, inventory or configuration), optionally only on the named network slices or device groups
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Param        roleName    path    string               true    "Name of the role"
+// @Param        content     body    configmodels.Role    true    " "
+// @Security     BearerAuth
+// @Success      201  {object}  nil  "Role created"
+// @Failure      400  {object}  nil  "Invalid role"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Page not found if enableAuthentication is disabled"
+// @Failure      409  {object}  nil  "Role already exists"
+// @Failure      500  {object}  nil  "Error creating the role"
+// @Router       /config/v1/role/{roleName}  [post]
+func PostRole(c *gin.Context) {
+	storeRole(c, configmodels.Post_op)
+}
+
+// PutRole godoc
+//
+// @Description  Create or replace a custom role. Accounts with the role get the new permissions from their next request
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Param        roleName    path    string               true    "Name of the role"
+// @Param        content     body    configmodels.Role    true    " "
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Role created or replaced"
+// @Failure      400  {object}  nil  "Invalid role"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil  "Error updating the role"
+// @Router       /config/v1/role/{roleName}  [put]
+func PutRole(c *gin.Context) {
+	storeRole(c, configmodels.Put_op)
+}
+
+func storeRole(c *gin.Context, msgOp int) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("store role")
+	roleName := c.Param("role-name")
+	var role configmodels.Role
+	if err := c.ShouldBindJSON(&role); err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorInvalidDataProvided, requestID)
+		return
+	}
+	if _, ok := configmodels.BuiltInRoles[roleName]; ok {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorBuiltInRole, requestID)
+		return
+	}
+	role.Name = roleName
+	role.BuiltIn = false
+	if err := validateRole(role); err != nil {
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
+	filter := bson.M{"name": roleName}
+	if msgOp == configmodels.Post_op {
+		err := dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.RoleDataColl, filter, []interface{}{configmodels.ToBsonM(role)})
+		if dbadapter.IsDuplicateKeyError(err) {
+			writeProblem(c, http.StatusConflict, configmodels.ProblemCodeAlreadyExists, fmt.Sprintf("role %s already exists", roleName), requestID)
+			return
+		}
+		if err != nil {
+			logger.DbLog.Errorln(err.Error())
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateRole, requestID)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+		return
+	}
+	if _, err := dbadapter.WebuiDBClient.RestfulAPIPutOne(configmodels.RoleDataColl, filter, configmodels.ToBsonM(role)); err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateRole, requestID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// DeleteRole godoc
+//
+// @Description  Delete a custom role. A role assigned to accounts cannot be deleted
+// @Tags         Roles
+// @Produce      json
+// @Param        roleName    path    string    true    "Name of the role"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Role deleted"
+// @Failure      400  {object}  nil  "Built-in roles cannot be deleted"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Role not found. Or Page not found if enableAuthentication is disabled"
+// @Failure      409  {object}  nil  "Role still assigned to accounts"
+// @Failure      500  {object}  nil  "Error deleting the role"
+// @Router       /config/v1/role/{roleName}  [delete]
+func DeleteRole(c *gin.Context) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("delete role")
+	roleName := c.Param("role-name")
+	if _, ok := configmodels.BuiltInRoles[roleName]; ok {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorBuiltInRole, requestID)
+		return
+	}
+	role, err := getRole(roleName)
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveRoles, requestID)
+		return
+	}
+	if role == nil {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorRoleNotFound, requestID)
+		return
+	}
+	assigned, err := dbadapter.WebuiDBClient.RestfulAPICount(configmodels.UserAccountDataColl, bson.M{"roles": roleName})
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateRole, requestID)
+		return
+	}
+	if assigned > 0 {
+		writeProblem(c, http.StatusConflict, configmodels.ProblemCodeStillReferenced,
+			fmt.Sprintf("role %s is assigned to %d accounts", roleName, assigned), requestID)
+		return
+	}
+	if err = dbadapter.WebuiDBClient.RestfulAPIDeleteOne(configmodels.RoleDataColl, bson.M{"name": roleName}); err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateRole, requestID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// AssignUserAccountRoles godoc
+//
+// @Description  Replace the roles of a user account. An account without roles has the user role
+// @Tags         User Accounts
+// @Accept       json
+// @Produce      json
+// @Param        username    path    string                            true    "Username of the user account"
+// @Param        params      body    configmodels.AssignRolesParams    true    "Names of the roles"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "Roles assigned"
+// @Failure      400  {object}  nil  "Bad request"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "User account not found. Or Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil  "Failed to update the user account"
+// @Router       /config/v1/account/{username}/roles  [put]
+func AssignUserAccountRoles(c *gin.Context) {
+	requestID := getRequestID(c)
+	logger.WebUILog.Infoln("assign user account roles")
+	username := c.Param("username")
+	var params configmodels.AssignRolesParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.WebUILog.Errorln(err.Error())
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorInvalidDataProvided, requestID)
+		return
+	}
+	if err := validateAssignedRoles(params.Roles); err != nil {
+		writeAssignedRolesProblem(c, err, requestID)
+		return
+	}
+	dbUserAccount, err := fetchDBUserAccount(username)
+	if err != nil {
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccount, requestID)
+		return
+	}
+	if dbUserAccount == nil {
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorUsernameNotFound, requestID)
+		return
+	}
+	if dbUserAccount.Role == configmodels.AdminRole {
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorAdminAccountRole, requestID)
+		return
+	}
+	roles := params.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	_, err = dbadapter.WebuiDBClient.RestfulAPIPost(configmodels.UserAccountDataColl, bson.M{"username": username}, bson.M{"roles": roles})
+	if err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateUserAccount, requestID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func validateRole(role configmodels.Role) error {
+	if !isValidName(role.Name) {
+		return newValidationError("name", "invalid role name %s", role.Name)
+	}
+	if len(role.Permissions) == 0 {
+		return newValidationError("permissions", "a role needs at least one permission")
+	}
+	for i, permission := range role.Permissions {
+		if !slices.Contains(configmodels.Resources, permission.Resource) {
+			return newValidationError(fmt.Sprintf("permissions[%d].resource", i), "unknown resource %s, expected one of %v", permission.Resource, configmodels.Resources)
+		}
+		if !slices.Contains(configmodels.Verbs, permission.Verb) {
+			return newValidationError(fmt.Sprintf("permissions[%d].verb", i), "unknown verb %s, expected one of %v", permission.Verb, configmodels.Verbs)
+		}
+		if len(permission.Names) > 0 && permission.Resource != configmodels.ResourceNetworkSlice && permission.Resource != configmodels.ResourceDeviceGroup {
+			return newValidationError(fmt.Sprintf("permissions[%d].names", i), "only network slice and device group permissions can be restricted to names")
+		}
+	}
+	return nil
+}
+
+// validateAssignedRoles checks that the roles exist and can be assigned.
+func validateAssignedRoles(roleNames []string) error {
+	for _, roleName := range roleNames {
+		if roleName == configmodels.RoleAdmin {
+			return newValidationError("roles", errorAssignAdmin)
+		}
+		role, err := getRole(roleName)
+		if err != nil {
+			return err
+		}
+		if role == nil {
+			return newValidationError("roles", "role %s not found", roleName)
+		}
+	}
+	return nil
+}
+
+// writeAssignedRolesProblem writes the problem details of an error returned by
+// validateAssignedRoles: invalid roles are a bad request, failing to look them
+// up an internal error.
+func writeAssignedRolesProblem(c *gin.Context, err error, requestID string) {
+	var validationErr *validationError
+	if errors.As(err, &validationErr) {
+		writeErrorProblem(c, http.StatusBadRequest, err, requestID)
+		return
+	}
+	logger.DbLog.Errorln(err.Error())
+	writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveRoles, requestID)
+}
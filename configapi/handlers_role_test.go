@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+)
+
+func TestPostRoleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/config/v1/role/:role-name", PostRole)
+
+	testCases := []struct {
+		name         string
+		roleName     string
+		inputData    string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "ScopedRole",
+			roleName:     "slice1-operator",
+			inputData:    `{"permissions": [{"resource": "network-slice", "verb": "update", "names": ["slice1"]}, {"resource": "subscriber", "verb": "read"}]}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "BuiltInRole",
+			roleName:     "viewer",
+			inputData:    `{"permissions": [{"resource": "subscriber", "verb": "read"}]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: errorBuiltInRole,
+		},
+		{
+			name:         "NoPermissions",
+			roleName:     "empty",
+			inputData:    `{"permissions": []}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"permissions"`,
+		},
+		{
+			name:         "UnknownVerb",
+			roleName:     "auditor",
+			inputData:    `{"permissions": [{"resource": "subscriber", "verb": "list"}]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"permissions[0].verb"`,
+		},
+		{
+			name:         "NamesOnSubscribers",
+			roleName:     "auditor",
+			inputData:    `{"permissions": [{"resource": "subscriber", "verb": "read", "names": ["imsi-1"]}]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"permissions[0].names"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.WebuiDBClient
+			defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+			dbClient := &MockMongoClientRoles{}
+			dbadapter.WebuiDBClient = dbClient
+			req := httptest.NewRequest(http.MethodPost, "/config/v1/role/"+tc.roleName, strings.NewReader(tc.inputData))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Fatalf("Expected `%v`, got `%v`: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("Expected body containing `%v`, got `%v`", tc.expectedBody, w.Body.String())
+			}
+			if stored := len(dbClient.posted) == 1; stored != (tc.expectedCode == http.StatusCreated) {
+				t.Fatalf("Unexpected stored roles %v", dbClient.posted)
+			}
+			if tc.expectedCode == http.StatusCreated && dbClient.posted[0]["name"] != tc.roleName {
+				t.Errorf("Expected the role to be stored with name %s, got %v", tc.roleName, dbClient.posted[0])
+			}
+		})
+	}
+}
+
+func TestDeleteRoleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.DELETE("/config/v1/role/:role-name", DeleteRole)
+
+	testCases := []struct {
+		name         string
+		roleName     string
+		assigned     int64
+		expectedCode int
+	}{
+		{name: "Success", roleName: "auditor", expectedCode: http.StatusOK},
+		{name: "StillAssigned", roleName: "auditor", assigned: 2, expectedCode: http.StatusConflict},
+		{name: "BuiltInRole", roleName: "admin", expectedCode: http.StatusBadRequest},
+		{name: "NotFound", roleName: "unknown", expectedCode: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.WebuiDBClient
+			defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+			dbClient := &MockMongoClientRoles{
+				roles:    []map[string]interface{}{{"name": "auditor", "permissions": []interface{}{}}},
+				assigned: tc.assigned,
+			}
+			dbadapter.WebuiDBClient = dbClient
+			req := httptest.NewRequest(http.MethodDelete, "/config/v1/role/"+tc.roleName, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
+			}
+			if deleted := len(dbClient.deleted) > 0; deleted != (tc.expectedCode == http.StatusOK) {
+				t.Errorf("Unexpected deletions %v", dbClient.deleted)
+			}
+		})
+	}
+}
+
+func TestAssignUserAccountRolesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.PUT("/config/v1/account/:username/roles", AssignUserAccountRoles)
+
+	testCases := []struct {
+		name         string
+		username     string
+		inputData    string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "BuiltInAndCustomRoles",
+			username:     "operator",
+			inputData:    `{"roles": ["viewer", "auditor"]}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "UnknownRole",
+			username:     "operator",
+			inputData:    `{"roles": ["unknown"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"errors":[{"field":"roles","message":"role unknown not found"}]`,
+		},
+		{
+			name:         "AdminRole",
+			username:     "operator",
+			inputData:    `{"roles": ["admin"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: errorAssignAdmin,
+		},
+		{
+			name:         "AdminAccount",
+			username:     "admin",
+			inputData:    `{"roles": ["viewer"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: errorAdminAccountRole,
+		},
+		{
+			name:         "AccountNotFound",
+			username:     "unknown",
+			inputData:    `{"roles": ["viewer"]}`,
+			expectedCode: http.StatusNotFound,
+			expectedBody: errorUsernameNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.WebuiDBClient
+			defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+			dbClient := &MockMongoClientRoles{
+				accounts: []map[string]interface{}{
+					{"username": "admin", "role": configmodels.AdminRole},
+					{"username": "operator", "role": configmodels.UserRole},
+				},
+				roles: []map[string]interface{}{{"name": "auditor", "permissions": []interface{}{}}},
+			}
+			dbadapter.WebuiDBClient = dbClient
+			req := httptest.NewRequest(http.MethodPut, "/config/v1/account/"+tc.username+"/roles", strings.NewReader(tc.inputData))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Fatalf("Expected `%v`, got `%v`: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("Expected body containing `%v`, got `%v`", tc.expectedBody, w.Body.String())
+			}
+			if stored := len(dbClient.posted) == 1; stored != (tc.expectedCode == http.StatusOK) {
+				t.Errorf("Unexpected stored roles %v", dbClient.posted)
+			}
+		})
+	}
+}
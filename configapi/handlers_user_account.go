@@ -7,9 +7,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"regexp"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
 	"github.com/omec-project/webconsole/backend/logger"
 	"github.com/omec-project/webconsole/configmodels"
 	"github.com/omec-project/webconsole/dbadapter"
@@ -20,6 +20,8 @@ const (
 	errorCreateUserAccount    = "failed to create user account"
 	errorDeleteAdminAccount   = "deleting an admin user account is not allowed"
 	errorDeleteUserAccount    = "failed to delete user account"
+	errorFirstAccountRoles    = "the first user account is the admin account, it cannot be given roles"
+	errorFirstServiceAccount  = "the first user account cannot be a service account"
 	errorIncorrectCredentials = "incorrect username or password. Try again"
 	errorInvalidDataProvided  = "invalid data provided"
 	errorInvalidPassword      = "password must have 8 or more characters, must include at least one capital letter, one lowercase letter, and either a number or a symbol."
@@ -27,6 +29,9 @@ const (
 	errorMissingUsername      = "username is required"
 	errorRetrieveUserAccount  = "failed to retrieve user account"
 	errorRetrieveUserAccounts = "failed to retrieve user accounts"
+	errorServiceAccountPasswd = "service accounts have no password, they authenticate with API tokens"
+	errorSSOAccountPasswd     = "accounts of an identity provider have no password, they log in with the provider"
+	errorUnlockUserAccount    = "failed to unlock user account"
 	errorUpdateUserAccount    = "failed to update user account"
 	errorUsernameNotFound     = "username not found"
 )
@@ -44,11 +49,12 @@ const (
 // @Failure      500  {object}  nil                                  "Error retrieving user accounts"
 // @Router       /config/v1/account/  [get]
 func GetUserAccounts(c *gin.Context) {
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("get user accounts")
 	rawUsers, err := dbadapter.WebuiDBClient.RestfulAPIGetMany(configmodels.UserAccountDataColl, bson.M{})
 	if err != nil {
 		logger.DbLog.Errorln(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccounts})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccounts, requestID)
 		return
 	}
 	userResponses := make([]*configmodels.GetUserAccountResponse, 0, len(rawUsers))
@@ -60,8 +66,10 @@ func GetUserAccounts(c *gin.Context) {
 			continue
 		}
 		userResponse := &configmodels.GetUserAccountResponse{
-			Username: dbUserAccount.Username,
-			Role:     dbUserAccount.Role,
+			Username:       dbUserAccount.Username,
+			Role:           dbUserAccount.Role,
+			ServiceAccount: dbUserAccount.ServiceAccount,
+			Roles:          dbUserAccount.AccountRoles(),
 		}
 		userResponses = append(userResponses, userResponse)
 	}
@@ -82,20 +90,23 @@ func GetUserAccounts(c *gin.Context) {
 // @Failure      500  {object}  nil                                  "Error retrieving user account"
 // @Router      /config/v1/account/{username}  [get]
 func GetUserAccount(c *gin.Context) {
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("get user account")
 	username := c.Param("username")
 	dbUserAccount, err := fetchDBUserAccount(username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccount, requestID)
 		return
 	}
 	if dbUserAccount == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": errorUsernameNotFound})
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorUsernameNotFound, requestID)
 		return
 	}
 	userResponse := configmodels.GetUserAccountResponse{
-		Username: dbUserAccount.Username,
-		Role:     dbUserAccount.Role,
+		Username:       dbUserAccount.Username,
+		Role:           dbUserAccount.Role,
+		ServiceAccount: dbUserAccount.ServiceAccount,
+		Roles:          dbUserAccount.AccountRoles(),
 	}
 	c.JSON(http.StatusOK, userResponse)
 }
@@ -135,52 +146,77 @@ func fetchDBUserAccount(username string) (*configmodels.DBUserAccount, error) {
 // @Failure      500  {object}  nil  "Failed to create the user account"
 // @Router      /config/v1/account/  [post]
 func CreateUserAccount(c *gin.Context) {
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("create user account")
 	var createUserParams configmodels.CreateUserAccountParams
 	err := c.ShouldBindJSON(&createUserParams)
 	if err != nil {
 		logger.WebUILog.Errorln(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorInvalidDataProvided})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorInvalidDataProvided, requestID)
 		return
 	}
 	if createUserParams.Username == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMissingUsername})
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("username", errorMissingUsername), requestID)
 		return
 	}
-	if createUserParams.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMissingPassword})
+	if createUserParams.ServiceAccount && createUserParams.Password != "" {
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("password", errorServiceAccountPasswd), requestID)
 		return
 	}
-	if !validatePassword(createUserParams.Password) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorInvalidPassword})
+	if !createUserParams.ServiceAccount && createUserParams.Password == "" {
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("password", errorMissingPassword), requestID)
+		return
+	}
+	if !createUserParams.ServiceAccount && !validatePassword(createUserParams.Password) {
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("password", errorInvalidPassword), requestID)
 		return
 	}
 	newUserRole := configmodels.UserRole
 	isFirstAccountIssued, err := isFirstAccountIssued()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccounts})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccounts, requestID)
 		return
 	}
 	if !isFirstAccountIssued {
+		if createUserParams.ServiceAccount {
+			writeErrorProblem(c, http.StatusBadRequest, newValidationError("service-account", errorFirstServiceAccount), requestID)
+			return
+		}
+		if len(createUserParams.Roles) > 0 {
+			writeErrorProblem(c, http.StatusBadRequest, newValidationError("roles", errorFirstAccountRoles), requestID)
+			return
+		}
 		newUserRole = configmodels.AdminRole
 	}
-	dbUser, err := configmodels.CreateNewDBUserAccount(createUserParams.Username, createUserParams.Password, newUserRole)
-	if err != nil {
-		logger.WebUILog.Errorln(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorCreateUserAccount})
+	if err = validateAssignedRoles(createUserParams.Roles); err != nil {
+		writeAssignedRolesProblem(c, err, requestID)
 		return
 	}
+	dbUser := &configmodels.DBUserAccount{
+		Username:       createUserParams.Username,
+		Role:           newUserRole,
+		ServiceAccount: true,
+	}
+	if !createUserParams.ServiceAccount {
+		dbUser, err = configmodels.CreateNewDBUserAccount(createUserParams.Username, createUserParams.Password, newUserRole)
+		if err != nil {
+			logger.WebUILog.Errorln(err.Error())
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorCreateUserAccount, requestID)
+			return
+		}
+	}
+	dbUser.Roles = createUserParams.Roles
 
 	filter := bson.M{"username": dbUser.Username}
 	err = dbadapter.WebuiDBClient.RestfulAPIPostMany(configmodels.UserAccountDataColl, filter, []interface{}{configmodels.ToBsonM(dbUser)})
 	if err != nil {
-		if strings.Contains(err.Error(), "E11000") {
+		if dbadapter.IsDuplicateKeyError(err) {
 			logger.DbLog.Errorln("Duplicate username found:", err)
-			c.JSON(http.StatusConflict, gin.H{"error": "user account already exists"})
+			writeProblem(c, http.StatusConflict, configmodels.ProblemCodeAlreadyExists, "user account already exists", requestID)
 			return
 		}
 		logger.DbLog.Errorln(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorCreateUserAccount})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorCreateUserAccount, requestID)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{})
@@ -201,26 +237,39 @@ func CreateUserAccount(c *gin.Context) {
 // @Failure      500  {object}  nil  "Failed to delete the user account"
 // @Router      /config/v1/account/{username}  [delete]
 func DeleteUserAccount(c *gin.Context) {
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("delete user account")
 	username := c.Param("username")
 	dbUserAccount, err := fetchDBUserAccount(username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccount, requestID)
 		return
 	}
 	if dbUserAccount == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": errorUsernameNotFound})
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorUsernameNotFound, requestID)
 		return
 	}
 	if dbUserAccount.Role == configmodels.AdminRole {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorDeleteAdminAccount})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorDeleteAdminAccount, requestID)
 		return
 	}
 	filter := bson.M{"username": username}
+	// the tokens and sessions are revoked first, they would be valid again if
+	// an account with the same name was created
+	if err = dbadapter.WebuiDBClient.RestfulAPIDeleteMany(configmodels.ApiTokenDataColl, filter); err != nil {
+		logger.DbLog.Errorln(err)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorDeleteUserAccount, requestID)
+		return
+	}
+	if err = auth.RevokeSessions(username); err != nil {
+		logger.DbLog.Errorln(err)
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorDeleteUserAccount, requestID)
+		return
+	}
 	err = dbadapter.WebuiDBClient.RestfulAPIDeleteOne(configmodels.UserAccountDataColl, filter)
 	if err != nil {
 		logger.DbLog.Errorln(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorDeleteUserAccount})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorDeleteUserAccount, requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
@@ -228,7 +277,7 @@ func DeleteUserAccount(c *gin.Context) {
 
 // ChangeUserAccountPasssword godoc
 //
-// @Description  Create a new user account
+// @Description  Change the password of a user account. The account is logged out of all its sessions
 // @Tags         User Accounts
 // @Produce      json
 // @Param        username    path    string                               true    "Username"
@@ -242,43 +291,82 @@ func DeleteUserAccount(c *gin.Context) {
 // @Failure      500  {object}  nil  "Failed to update the user account"
 // @Router      /config/v1/account/{username}/change_password  [post]
 func ChangeUserAccountPasssword(c *gin.Context) {
+	requestID := getRequestID(c)
 	logger.WebUILog.Infoln("change user password")
 	username := c.Param("username")
 	var changePasswordParams configmodels.ChangePasswordParams
 	err := c.ShouldBindJSON(&changePasswordParams)
 	if err != nil {
 		logger.WebUILog.Errorln(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorInvalidDataProvided})
+		writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, errorInvalidDataProvided, requestID)
 		return
 	}
 	if changePasswordParams.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorMissingPassword})
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("password", errorMissingPassword), requestID)
 		return
 	}
 	if !validatePassword(changePasswordParams.Password) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errorInvalidPassword})
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("password", errorInvalidPassword), requestID)
 		return
 	}
 	dbUser, err := fetchDBUserAccount(username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorRetrieveUserAccount})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorRetrieveUserAccount, requestID)
 		return
 	}
 	if dbUser == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": errorUsernameNotFound})
+		writeProblem(c, http.StatusNotFound, configmodels.ProblemCodeNotFound, errorUsernameNotFound, requestID)
+		return
+	}
+	if dbUser.ServiceAccount {
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("password", errorServiceAccountPasswd), requestID)
+		return
+	}
+	if dbUser.OIDCSubject != "" || dbUser.LDAPDN != "" {
+		writeErrorProblem(c, http.StatusBadRequest, newValidationError("password", errorSSOAccountPasswd), requestID)
 		return
 	}
 	newPasswordDbUser, err := configmodels.CreateNewDBUserAccount(dbUser.Username, changePasswordParams.Password, dbUser.Role)
 	if err != nil {
 		logger.WebUILog.Errorln(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorUpdateUserAccount})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateUserAccount, requestID)
 		return
 	}
 	filter := bson.M{"username": newPasswordDbUser.Username}
 	_, err = dbadapter.WebuiDBClient.RestfulAPIPost(configmodels.UserAccountDataColl, filter, configmodels.ToBsonM(newPasswordDbUser))
 	if err != nil {
 		logger.DbLog.Errorln(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": errorUpdateUserAccount})
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateUserAccount, requestID)
+		return
+	}
+	if err = auth.RevokeSessions(username); err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUpdateUserAccount, requestID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// UnlockUserAccount godoc
+//
+// @Description  Unlock a user account locked out after too many failed logins, and reset its count of failed logins. The account does not need to exist yet: directory users can be locked out before their first log in
+// @Tags         User Accounts
+// @Produce      json
+// @Param        username    path    string    true    "Username"
+// @Security     BearerAuth
+// @Success      200  {object}  nil  "User account unlocked"
+// @Failure      401  {object}  nil  "Authorization failed"
+// @Failure      403  {object}  nil  "Forbidden"
+// @Failure      404  {object}  nil  "Page not found if enableAuthentication is disabled"
+// @Failure      500  {object}  nil  "Failed to unlock the user account"
+// @Router      /config/v1/account/{username}/unlock  [post]
+func UnlockUserAccount(c *gin.Context) {
+	requestID := getRequestID(c)
+	username := c.Param("username")
+	logger.WebUILog.Infof("unlock user account %s", username)
+	if err := auth.UnlockAccount(username); err != nil {
+		logger.DbLog.Errorln(err.Error())
+		writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, errorUnlockUserAccount, requestID)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{})
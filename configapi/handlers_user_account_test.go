@@ -4,6 +4,7 @@
 package configapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
 	"github.com/omec-project/webconsole/dbadapter"
 	"go.mongodb.org/mongo-driver/bson"
 	"golang.org/x/crypto/bcrypt"
@@ -72,6 +74,14 @@ func (db *MockMongoClientSuccess) RestfulAPIPost(collName string, filter bson.M,
 	return true, nil
 }
 
+func (db *MockMongoClientSuccess) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
+func (db *MockMongoClientSuccess) RestfulAPIDeleteMany(collName string, filter bson.M) error {
+	return nil
+}
+
 func (db *MockMongoClientSuccess) RestfulAPICount(collName string, filter bson.M) (int64, error) {
 	return 5, nil
 }
@@ -87,6 +97,10 @@ func (db *MockMongoClientRegularUser) RestfulAPIDeleteOne(collName string, filte
 	return nil
 }
 
+func (db *MockMongoClientRegularUser) RestfulAPIDeleteMany(collName string, filter bson.M) error {
+	return nil
+}
+
 func TestGetUserAccountsHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
@@ -102,13 +116,13 @@ func TestGetUserAccountsHandler(t *testing.T) {
 			name:         "DBError",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorRetrieveUserAccounts),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account","code":"internal-error"}`, errorRetrieveUserAccounts),
 		},
 		{
 			name:         "DBReturnsOneInvalidUser",
 			dbAdapter:    &MockMongoClientInvalidUser{},
 			expectedCode: http.StatusOK,
-			expectedBody: `[{"username":"janedoe","role":1}]`,
+			expectedBody: `[{"username":"janedoe","role":1,"roles":["admin"]}]`,
 		},
 		{
 			name:         "NoUsersInDB",
@@ -120,7 +134,7 @@ func TestGetUserAccountsHandler(t *testing.T) {
 			name:         "SuccessManyUsers",
 			dbAdapter:    &MockMongoClientSuccess{},
 			expectedCode: http.StatusOK,
-			expectedBody: `[{"username":"johndoe","role":0},{"username":"janedoe","role":1}]`,
+			expectedBody: `[{"username":"johndoe","role":0,"roles":["user"]},{"username":"janedoe","role":1,"roles":["admin"]}]`,
 		},
 	}
 	for _, tc := range testCases {
@@ -137,7 +151,7 @@ func TestGetUserAccountsHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if w.Body.String() != tc.expectedBody {
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -161,25 +175,25 @@ func TestGetUserAccountHandler(t *testing.T) {
 			name:         "GetUserAccountSuccess",
 			dbAdapter:    &MockMongoClientSuccess{},
 			expectedCode: http.StatusOK,
-			expectedBody: `{"username":"janedoe","role":1}`,
+			expectedBody: `{"username":"janedoe","role":1,"roles":["admin"]}`,
 		},
 		{
 			name:         "DBError",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorRetrieveUserAccount),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account/janedoe","code":"internal-error"}`, errorRetrieveUserAccount),
 		},
 		{
 			name:         "UserNotFound",
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			expectedCode: http.StatusNotFound,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorUsernameNotFound),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Not Found","status":404,"detail":"%s","instance":"/config/v1/account/janedoe","code":"not-found"}`, errorUsernameNotFound),
 		},
 		{
 			name:         "InvalidUser",
 			dbAdapter:    &MockMongoClientInvalidUser{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorRetrieveUserAccount),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account/janedoe","code":"internal-error"}`, errorRetrieveUserAccount),
 		},
 	}
 	for _, tc := range testCases {
@@ -196,7 +210,7 @@ func TestGetUserAccountHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if w.Body.String() != tc.expectedBody {
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -220,21 +234,21 @@ func TestCreateUserAccountHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientSuccess{},
 			inputData:    `{"password" : "Admin1234"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorMissingUsername),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"validation-failed","errors":[{"field":"username","message":"%s"}]}`, errorMissingUsername, errorMissingUsername),
 		},
 		{
 			name:         "UserThatAlreadyExists",
 			dbAdapter:    &MockMongoClientDuplicateCreation{},
 			inputData:    `{"username": "janedoe", "password" : "Admin1234"}`,
 			expectedCode: http.StatusConflict,
-			expectedBody: `{"error":"user account already exists"}`,
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"detail":"user account already exists","instance":"/config/v1/account","code":"already-exists"}`,
 		},
 		{
 			name:         "RequestWithoutPassword",
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"username": "adminadmin"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorMissingPassword),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"validation-failed","errors":[{"field":"password","message":"%s"}]}`, errorMissingPassword, errorMissingPassword),
 		},
 		{
 			name:         "SuccessfulRequest",
@@ -248,21 +262,63 @@ func TestCreateUserAccountHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientDBError{},
 			inputData:    `{"username": "adminadmin", "password" : "Admin1234"}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorRetrieveUserAccounts),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account","code":"internal-error"}`, errorRetrieveUserAccounts),
 		},
 		{
 			name:         "InvalidPassword",
 			dbAdapter:    &MockMongoClientSuccess{},
 			inputData:    `{"username": "adminadmin", "password" : "1234"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorInvalidPassword),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"validation-failed","errors":[{"field":"password","message":"%s"}]}`, errorInvalidPassword, errorInvalidPassword),
+		},
+		{
+			name:         "ServiceAccount",
+			dbAdapter:    &MockMongoClientSuccess{},
+			inputData:    `{"username": "ci-bot", "service-account": true}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: `{}`,
+		},
+		{
+			name:         "ServiceAccountWithPassword",
+			dbAdapter:    &MockMongoClientSuccess{},
+			inputData:    `{"username": "ci-bot", "password": "Admin1234", "service-account": true}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"validation-failed","errors":[{"field":"password","message":"%s"}]}`, errorServiceAccountPasswd, errorServiceAccountPasswd),
+		},
+		{
+			name:         "FirstAccountIsServiceAccount",
+			dbAdapter:    &MockMongoClientEmptyDB{},
+			inputData:    `{"username": "ci-bot", "service-account": true}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"validation-failed","errors":[{"field":"service-account","message":"%s"}]}`, errorFirstServiceAccount, errorFirstServiceAccount),
+		},
+		{
+			name:         "AccountWithRoles",
+			dbAdapter:    &MockMongoClientSuccess{},
+			inputData:    `{"username": "operator", "password": "Admin1234", "roles": ["viewer", "subscriber-operator"]}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: `{}`,
+		},
+		{
+			name:         "AccountWithAdminRole",
+			dbAdapter:    &MockMongoClientSuccess{},
+			inputData:    `{"username": "operator", "password": "Admin1234", "roles": ["admin"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"validation-failed","errors":[{"field":"roles","message":"%s"}]}`, errorAssignAdmin, errorAssignAdmin),
+		},
+		{
+			name:         "FirstAccountWithRoles",
+			dbAdapter:    &MockMongoClientEmptyDB{},
+			inputData:    `{"username": "adminadmin", "password": "Admin1234", "roles": ["viewer"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"validation-failed","errors":[{"field":"roles","message":"%s"}]}`, errorFirstAccountRoles, errorFirstAccountRoles),
 		},
 		{
 			name:         "InvalidJsonProvided",
 			dbAdapter:    &MockMongoClientSuccess{},
 			inputData:    `{"username": "adminadmin", "password": 1234}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorInvalidDataProvided),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account","code":"invalid-request"}`, errorInvalidDataProvided),
 		},
 	}
 	for _, tc := range testCases {
@@ -280,7 +336,7 @@ func TestCreateUserAccountHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if w.Body.String() != tc.expectedBody {
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -308,25 +364,25 @@ func TestDeleteUserAccountHandler(t *testing.T) {
 			name:         "DeleteAdminUser",
 			dbAdapter:    &MockMongoClientSuccess{},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorDeleteAdminAccount),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account/janedoe","code":"invalid-request"}`, errorDeleteAdminAccount),
 		},
 		{
 			name:         "DeleteInvalidUser",
 			dbAdapter:    &MockMongoClientInvalidUser{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorRetrieveUserAccount),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account/janedoe","code":"internal-error"}`, errorRetrieveUserAccount),
 		},
 		{
 			name:         "UserNotFound",
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			expectedCode: http.StatusNotFound,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorUsernameNotFound),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Not Found","status":404,"detail":"%s","instance":"/config/v1/account/janedoe","code":"not-found"}`, errorUsernameNotFound),
 		},
 		{
 			name:         "DBError",
 			dbAdapter:    &MockMongoClientDBError{},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorRetrieveUserAccount),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account/janedoe","code":"internal-error"}`, errorRetrieveUserAccount),
 		},
 	}
 	for _, tc := range testCases {
@@ -342,7 +398,7 @@ func TestDeleteUserAccountHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if w.Body.String() != tc.expectedBody {
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
 		})
@@ -373,35 +429,35 @@ func TestChangePasswordHandler(t *testing.T) {
 			dbAdapter:    &MockMongoClientDBError{},
 			inputData:    `{"password": "Admin1234"}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorRetrieveUserAccount),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account/janedoe/change_password","code":"internal-error"}`, errorRetrieveUserAccount),
 		},
 		{
 			name:         "UserDoesNotExist",
 			dbAdapter:    &MockMongoClientEmptyDB{},
 			inputData:    `{"password": "Admin1234"}`,
 			expectedCode: http.StatusNotFound,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorUsernameNotFound),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Not Found","status":404,"detail":"%s","instance":"/config/v1/account/janedoe/change_password","code":"not-found"}`, errorUsernameNotFound),
 		},
 		{
 			name:         "InvalidPassword",
 			dbAdapter:    nil,
 			inputData:    `{"password": "1234"}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorInvalidPassword),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account/janedoe/change_password","code":"validation-failed","errors":[{"field":"password","message":"%s"}]}`, errorInvalidPassword, errorInvalidPassword),
 		},
 		{
 			name:         "NoPasswordProvided",
 			dbAdapter:    nil,
 			inputData:    `{}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorMissingPassword),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account/janedoe/change_password","code":"validation-failed","errors":[{"field":"password","message":"%s"}]}`, errorMissingPassword, errorMissingPassword),
 		},
 		{
 			name:         "InvalidData",
 			dbAdapter:    nil,
 			inputData:    `{"password": 1234}`,
 			expectedCode: http.StatusBadRequest,
-			expectedBody: fmt.Sprintf(`{"error":"%s"}`, errorInvalidDataProvided),
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"%s","instance":"/config/v1/account/janedoe/change_password","code":"invalid-request"}`, errorInvalidDataProvided),
 		},
 	}
 	for _, tc := range testCases {
@@ -418,9 +474,69 @@ func TestChangePasswordHandler(t *testing.T) {
 			if tc.expectedCode != w.Code {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
 			}
-			if w.Body.String() != tc.expectedBody {
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type MockMongoClientLoginFailures struct {
+	dbadapter.DBInterface
+	err           error
+	deletedColl   string
+	deletedFilter bson.M
+}
+
+func (db *MockMongoClientLoginFailures) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	db.deletedColl, db.deletedFilter = collName, filter
+	return db.err
+}
+
+func TestUnlockUserAccountHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/config/v1/account/:username/unlock", UnlockUserAccount)
+
+	testCases := []struct {
+		name         string
+		dbAdapter    *MockMongoClientLoginFailures
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Success",
+			dbAdapter:    &MockMongoClientLoginFailures{},
+			expectedCode: http.StatusOK,
+			expectedBody: "{}",
+		},
+		{
+			name:         "DBError",
+			dbAdapter:    &MockMongoClientLoginFailures{err: errors.New("DB error")},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: fmt.Sprintf(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"%s","instance":"/config/v1/account/JaneDoe/unlock","code":"internal-error"}`, errorUnlockUserAccount),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbadapter.WebuiDBClient = tc.dbAdapter
+			req, err := http.NewRequest(http.MethodPost, "/config/v1/account/JaneDoe/unlock", nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
+			}
+			if withoutRequestID(w.Body.String()) != tc.expectedBody {
 				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
 			}
+			if tc.dbAdapter.deletedColl != configmodels.LoginFailureDataColl || tc.dbAdapter.deletedFilter["key"] != "account:janedoe" {
+				t.Errorf("Expected the failed logins of janedoe to be deleted, got %s %v", tc.dbAdapter.deletedColl, tc.dbAdapter.deletedFilter)
+			}
 		})
 	}
 }
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func configRevisionFilter(kind, name string) bson.M {
+	return bson.M{"kind": kind, "name": name}
+}
+
+func configFieldDiffs(prev, next bson.M) []configmodels.ConfigFieldDiff {
+	var diffs []configmodels.ConfigFieldDiff
+	for _, field := range changedFields(prev, next) {
+		diffs = append(diffs, configmodels.ConfigFieldDiff{
+			Field:    field,
+			Previous: prev[field],
+			Current:  next[field],
+		})
+	}
+	return diffs
+}
+
+// maxConfigRevisionAttempts bounds the attempts to store a revision while
+// concurrent writes to the same object store theirs.
+const maxConfigRevisionAttempts = 5
+
+// recordConfigRevision stores a write to an object as its next revision. prev is
+// nil when the object is created and next is nil when it is deleted. Writes
+// that do not change the object are not recorded.
+//
+// The revisions of an object are unique, so that two concurrent writes cannot
+// store the same one. Outside of a transaction the next revision is retried. In
+// a transaction, the conflict aborts the transaction, which cannot be retried
+// from here, so it is reported as a concurrent update.
+func recordConfigRevision(ctx context.Context, kind, name string, prev, next bson.M) error {
+	operation := configmodels.ApplyOpUpdate
+	switch {
+	case prev == nil:
+		operation = configmodels.ApplyOpCreate
+	case next == nil:
+		operation = configmodels.ApplyOpDelete
+	}
+	diff := configFieldDiffs(prev, next)
+	if operation == configmodels.ApplyOpUpdate && len(diff) == 0 {
+		return nil
+	}
+	revision := configmodels.ConfigRevision{
+		Kind:      kind,
+		Name:      name,
+		Operation: operation,
+		Author:    auth.UsernameFromContext(ctx),
+		Timestamp: time.Now().UTC(),
+		Diff:      diff,
+		Document:  next,
+	}
+	inTransaction := mongo.SessionFromContext(ctx) != nil
+	for range maxConfigRevisionAttempts {
+		latest, err := latestConfigRevision(kind, name)
+		if err != nil {
+			return err
+		}
+		revision.Revision = latest + 1
+		err = dbadapter.CommonDBClient.RestfulAPIPostManyWithContext(ctx, configmodels.ConfigRevisionDataColl, nil,
+			[]interface{}{configmodels.ToBsonM(revision)})
+		if err == nil {
+			logger.DbLog.Debugf("stored revision %d of %s %s", revision.Revision, kind, name)
+			return nil
+		}
+		if inTransaction && (dbadapter.IsDuplicateKeyError(err) || dbadapter.IsWriteConflictError(err)) {
+			return &versionConflictError{
+				statusCode: http.StatusConflict,
+				message:    fmt.Sprintf("%s %s was updated concurrently", kind, name),
+			}
+		}
+		if !dbadapter.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to store revision %d of %s %s: %w", revision.Revision, kind, name, err)
+		}
+		logger.DbLog.Debugf("revision %d of %s %s was stored concurrently", revision.Revision, kind, name)
+	}
+	return fmt.Errorf("failed to store a revision of %s %s: too many concurrent updates", kind, name)
+}
+
+// latestConfigRevision returns the latest revision of an object, 0 if it has none.
+func latestConfigRevision(kind, name string) (int64, error) {
+	latest, err := dbadapter.CommonDBClient.RestfulAPIGetManyPaged(configmodels.ConfigRevisionDataColl,
+		configRevisionFilter(kind, name), bson.D{{Key: "revision", Value: -1}}, 0, 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve latest revision of %s %s: %w", kind, name, err)
+	}
+	revisions, err := decodeDocuments[configmodels.ConfigRevision](latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode latest revision of %s %s: %w", kind, name, err)
+	}
+	if len(revisions) == 0 {
+		return 0, nil
+	}
+	return revisions[0].Revision, nil
+}
+
+// configRevisionChange is a write to be recorded as a revision.
+type configRevisionChange struct {
+	kind string
+	name string
+	prev bson.M
+	next bson.M
+}
+
+// revisionDocument returns a stored document in the form the revisions are
+// compared in, without the fields added by the database and the resource
+// version, or nil if it does not exist.
+func revisionDocument(rawDocument map[string]interface{}) bson.M {
+	if len(rawDocument) == 0 {
+		return nil
+	}
+	document := bson.M{}
+	for field, value := range rawDocument {
+		if field != "_id" && field != resourceVersionField {
+			document[field] = value
+		}
+	}
+	return configmodels.ToBsonM(document)
+}
+
+// getConfigRevisions returns the revisions of an object, newest first.
+func getConfigRevisions(kind, name string) ([]configmodels.ConfigRevision, error) {
+	rawRevisions, err := dbadapter.CommonDBClient.RestfulAPIGetManyPaged(configmodels.ConfigRevisionDataColl,
+		configRevisionFilter(kind, name), bson.D{{Key: "revision", Value: -1}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocuments[configmodels.ConfigRevision](rawRevisions)
+}
+
+func getConfigRevision(kind, name string, revision int64) (*configmodels.ConfigRevision, error) {
+	filter := configRevisionFilter(kind, name)
+	filter["revision"] = revision
+	rawRevision, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.ConfigRevisionDataColl, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawRevision) == 0 {
+		return nil, nil
+	}
+	var configRevision configmodels.ConfigRevision
+	if err = json.Unmarshal(configmodels.MapToByte(rawRevision), &configRevision); err != nil {
+		return nil, err
+	}
+	return &configRevision, nil
+}
+
+// rollbackNetworkSlice re-applies a revision of a network slice through the
+// network slice helpers. Rolling back to a deletion deletes the slice.
+func rollbackNetworkSlice(ctx context.Context, sliceName string, revision int64) (int, error) {
+	configRevision, err := getConfigRevision(configmodels.ApplyKindNetworkSlice, sliceName, revision)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to retrieve revision %d: %w", revision, err)
+	}
+	if configRevision == nil {
+		return http.StatusNotFound, fmt.Errorf("revision %d of network slice %s not found", revision, sliceName)
+	}
+	prevSlice := getSliceByName(sliceName)
+	if configRevision.Operation == configmodels.ApplyOpDelete {
+		if prevSlice == nil || prevSlice.SliceName == "" {
+			return http.StatusOK, nil
+		}
+		if err = networkSliceDeleteHelper(ctx, sliceName); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		return http.StatusOK, nil
+	}
+	var networkSlice configmodels.Slice
+	if err = json.Unmarshal(configmodels.MapToByte(configRevision.Document), &networkSlice); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to decode revision %d: %w", revision, err)
+	}
+	msgOp := configmodels.Post_op
+	if prevSlice != nil && prevSlice.SliceName != "" {
+		msgOp = configmodels.Put_op
+	}
+	return networkSliceStoreHelper(ctx, networkSlice, msgOp)
+}
+
+// rollbackDeviceGroup re-applies a revision of a device group through the
+// device group helpers. Rolling back to a deletion deletes the device group.
+func rollbackDeviceGroup(ctx context.Context, groupName string, revision int64) (int, error) {
+	configRevision, err := getConfigRevision(configmodels.ApplyKindDeviceGroup, groupName, revision)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to retrieve revision %d: %w", revision, err)
+	}
+	if configRevision == nil {
+		return http.StatusNotFound, fmt.Errorf("revision %d of device group %s not found", revision, groupName)
+	}
+	prevDevGroup := getDeviceGroupByName(groupName)
+	if configRevision.Operation == configmodels.ApplyOpDelete {
+		if prevDevGroup == nil || prevDevGroup.DeviceGroupName == "" {
+			return http.StatusOK, nil
+		}
+		if err = deviceGroupDeleteHelper(ctx, groupName); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		return http.StatusOK, nil
+	}
+	var deviceGroup configmodels.DeviceGroups
+	if err = json.Unmarshal(configmodels.MapToByte(configRevision.Document), &deviceGroup); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to decode revision %d: %w", revision, err)
+	}
+	msgOp := configmodels.Post_op
+	if prevDevGroup != nil && prevDevGroup.DeviceGroupName != "" {
+		msgOp = configmodels.Put_op
+	}
+	return deviceGroupStoreHelper(ctx, deviceGroup, msgOp)
+}
@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyKeyStatusField = "status"
+)
+
+// IdempotencyMiddleware makes write requests sent with an Idempotency-Key
+// header safe to retry. The first request with a key is handled and its
+// response stored; a retry with the same key and request gets the stored
+// response back without being applied again, for the configured
+// idempotency-key-ttl. Keys are scoped to the authenticated user.
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" || !isAuditedMethod(c.Request.Method) || dbadapter.CommonDBClient == nil {
+			c.Next()
+			return
+		}
+		requestID := getRequestID(c)
+		if !isValidIdempotencyKey(key) {
+			writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest,
+				fmt.Sprintf("invalid %s header: printable ASCII without spaces, up to %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength), requestID)
+			c.Abort()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			writeProblem(c, http.StatusBadRequest, configmodels.ProblemCodeInvalidRequest, "failed to read request body", requestID)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		username := auth.UsernameFromContext(c.Request.Context())
+		scopedKey := username + "/" + key
+		fingerprint := requestFingerprint(username, c.Request.Method, c.Request.URL.RequestURI(), body)
+		record, err := claimIdempotencyKey(scopedKey, fingerprint, time.Now())
+		if err != nil {
+			logger.DbLog.Errorf("failed to claim idempotency key %s: %+v request ID: %s", key, err, requestID)
+			writeErrorProblem(c, http.StatusInternalServerError, err, requestID)
+			c.Abort()
+			return
+		}
+		if record != nil {
+			replayIdempotentResponse(c, record, fingerprint, requestID)
+			c.Abort()
+			return
+		}
+
+		filter := bson.M{"key": scopedKey}
+		release := func() {
+			if err := dbadapter.CommonDBClient.RestfulAPIDeleteOne(configmodels.IdempotencyKeyDataColl, filter); err != nil {
+				logger.DbLog.Errorf("failed to release idempotency key %s: %+v request ID: %s", key, err, requestID)
+			}
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			// the request may not have been applied, a retry runs it again
+			release()
+			return
+		}
+		response := map[string]interface{}{
+			idempotencyKeyStatusField: recorder.Status(),
+			"content-type":            recorder.Header().Get("Content-Type"),
+			"body":                    recorder.body.String(),
+		}
+		if _, err = dbadapter.CommonDBClient.RestfulAPIPutOne(configmodels.IdempotencyKeyDataColl, filter, response); err != nil {
+			logger.DbLog.Errorf("failed to store the response of idempotency key %s: %+v request ID: %s", key, err, requestID)
+		}
+	}
+}
+
+// isValidIdempotencyKey reports whether a caller supplied idempotency key can
+// be stored and logged as is: printable ASCII without spaces, up to 255
+// characters.
+func isValidIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for _, r := range key {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestFingerprint identifies the request a key was first used for, so that
+// reusing the key for another request is refused instead of replayed.
+func requestFingerprint(username, method, uri string, body []byte) string {
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(username), []byte(method), []byte(uri), body} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// claimIdempotencyKey records that a request with the given key is being
+// handled. It returns nil if the request should go ahead, or the record of the
+// earlier request that claimed the key.
+func claimIdempotencyKey(key, fingerprint string, now time.Time) (map[string]interface{}, error) {
+	filter := bson.M{"key": key}
+	record, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.IdempotencyKeyDataColl, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(record) > 0 && !now.Before(idempotencyKeyExpiry(record)) {
+		// MongoDB removes expired keys periodically, one may still be there
+		if err = dbadapter.CommonDBClient.RestfulAPIDeleteOne(configmodels.IdempotencyKeyDataColl, filter); err != nil {
+			return nil, err
+		}
+		record = nil
+	}
+	if len(record) > 0 {
+		return record, nil
+	}
+	claim := bson.M{
+		"key":         key,
+		"fingerprint": fingerprint,
+		"expires-at":  now.Add(factory.WebUIConfig.Configuration.IdempotencyKeyTTL),
+	}
+	err = dbadapter.CommonDBClient.RestfulAPIPostMany(configmodels.IdempotencyKeyDataColl, bson.M{}, []interface{}{claim})
+	if err == nil {
+		return nil, nil
+	}
+	if !dbadapter.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+	// a concurrent request with the same key claimed it first
+	record, err = dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.IdempotencyKeyDataColl, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, fmt.Errorf("idempotency key %s was released concurrently", key)
+	}
+	return record, nil
+}
+
+func idempotencyKeyExpiry(record map[string]interface{}) time.Time {
+	switch expiresAt := record["expires-at"].(type) {
+	case primitive.DateTime:
+		return expiresAt.Time()
+	case time.Time:
+		return expiresAt
+	}
+	return time.Time{}
+}
+
+// replayIdempotentResponse answers a request whose key was already claimed:
+// with the stored response if it is a retry of the completed request, or with
+// a problem if the request is still in progress or the key was used for
+// another request.
+func replayIdempotentResponse(c *gin.Context, record map[string]interface{}, fingerprint, requestID string) {
+	if record["fingerprint"] != fingerprint {
+		writeProblem(c, http.StatusUnprocessableEntity, configmodels.ProblemCodeIdempotencyKeyReused,
+			fmt.Sprintf("the %s was already used for a different request", idempotencyKeyHeader), requestID)
+		return
+	}
+	status := int(documentInt(record, idempotencyKeyStatusField))
+	if status == 0 {
+		writeProblem(c, http.StatusConflict, configmodels.ProblemCodeIdempotencyKeyInUse,
+			fmt.Sprintf("a request with this %s is still in progress, retry later", idempotencyKeyHeader), requestID)
+		return
+	}
+	contentType, _ := record["content-type"].(string)
+	body, _ := record["body"].(string)
+	logger.WebUILog.Infof("replaying the response to a request with the same %s request ID: %s", idempotencyKeyHeader, requestID)
+	c.Header(idempotentReplayedHeader, "true")
+	c.Data(status, contentType, []byte(body))
+}
+
+// responseRecorder keeps a copy of the response written by the handler.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientIdempotencyKeys struct {
+	MockMongoClientEmptyDB
+	records map[string]map[string]interface{}
+}
+
+func (m *MockMongoClientIdempotencyKeys) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	return maps.Clone(m.records[filter["key"].(string)]), nil
+}
+
+func (m *MockMongoClientIdempotencyKeys) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, postData := range postDataArray {
+		record := postData.(bson.M)
+		m.records[record["key"].(string)] = record
+	}
+	return nil
+}
+
+func (m *MockMongoClientIdempotencyKeys) RestfulAPIPutOne(collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	maps.Copy(m.records[filter["key"].(string)], putData)
+	return true, nil
+}
+
+func (m *MockMongoClientIdempotencyKeys) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	delete(m.records, filter["key"].(string))
+	return nil
+}
+
+func idempotencyTestRouter(status *int, calls *int) *gin.Engine {
+	router := gin.New()
+	router.Use(IdempotencyMiddleware())
+	router.POST("/config/v1/network-slice/:slice-name", func(c *gin.Context) {
+		*calls++
+		c.JSON(*status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name            string
+		key             string
+		firstStatus     int
+		retryBody       string
+		expectedStatus  int
+		expectedBody    string
+		expectedCalls   int
+		expectReplayed  bool
+		expectedRecords int
+	}{
+		{
+			name:            "retry replays the stored response",
+			key:             "deploy-42",
+			firstStatus:     http.StatusOK,
+			retryBody:       `{"site-info":{}}`,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"call":1}`,
+			expectedCalls:   1,
+			expectReplayed:  true,
+			expectedRecords: 1,
+		},
+		{
+			name:            "client errors are replayed",
+			key:             "deploy-42",
+			firstStatus:     http.StatusBadRequest,
+			retryBody:       `{"site-info":{}}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `{"call":1}`,
+			expectedCalls:   1,
+			expectReplayed:  true,
+			expectedRecords: 1,
+		},
+		{
+			name:            "server errors are not stored",
+			key:             "deploy-42",
+			firstStatus:     http.StatusInternalServerError,
+			retryBody:       `{"site-info":{}}`,
+			expectedStatus:  http.StatusInternalServerError,
+			expectedBody:    `{"call":2}`,
+			expectedCalls:   2,
+			expectedRecords: 0,
+		},
+		{
+			name:            "key reused for another request",
+			key:             "deploy-42",
+			firstStatus:     http.StatusOK,
+			retryBody:       `{"site-info":{"site-name":"other"}}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedBody:    `"code":"idempotency-key-reused"`,
+			expectedCalls:   1,
+			expectedRecords: 1,
+		},
+		{
+			name:            "invalid key",
+			key:             "deploy 42",
+			firstStatus:     http.StatusOK,
+			retryBody:       `{"site-info":{}}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    `"code":"invalid-request"`,
+			expectedCalls:   0,
+			expectedRecords: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			origDBClient := dbadapter.CommonDBClient
+			defer func() { dbadapter.CommonDBClient = origDBClient }()
+			dbClient := &MockMongoClientIdempotencyKeys{records: map[string]map[string]interface{}{}}
+			dbadapter.CommonDBClient = dbClient
+			status, calls := tc.firstStatus, 0
+			router := idempotencyTestRouter(&status, &calls)
+
+			for i, body := range []string{`{"site-info":{}}`, tc.retryBody} {
+				req := httptest.NewRequest(http.MethodPost, "/config/v1/network-slice/slice1", strings.NewReader(body))
+				req.Header.Set(idempotencyKeyHeader, tc.key)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if i == 0 {
+					continue
+				}
+				if w.Code != tc.expectedStatus {
+					t.Errorf("Expected `%v`, got `%v`", tc.expectedStatus, w.Code)
+				}
+				if !strings.Contains(w.Body.String(), tc.expectedBody) {
+					t.Errorf("Expected body containing `%v`, got `%v`", tc.expectedBody, w.Body.String())
+				}
+				if replayed := w.Header().Get(idempotentReplayedHeader) == "true"; replayed != tc.expectReplayed {
+					t.Errorf("Expected replayed `%v`, got `%v`", tc.expectReplayed, replayed)
+				}
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected the handler to be called %d times, got %d", tc.expectedCalls, calls)
+			}
+			if len(dbClient.records) != tc.expectedRecords {
+				t.Errorf("Expected %d stored keys, got %d", tc.expectedRecords, len(dbClient.records))
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareKeyInUse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	origDBClient := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = origDBClient }()
+	body := `{"site-info":{}}`
+	dbClient := &MockMongoClientIdempotencyKeys{records: map[string]map[string]interface{}{
+		"/deploy-42": {
+			"key":         "/deploy-42",
+			"fingerprint": requestFingerprint("", http.MethodPost, "/config/v1/network-slice/slice1", []byte(body)),
+			"expires-at":  time.Now().Add(time.Hour),
+		},
+	}}
+	dbadapter.CommonDBClient = dbClient
+	status, calls := http.StatusOK, 0
+	router := idempotencyTestRouter(&status, &calls)
+
+	req := httptest.NewRequest(http.MethodPost, "/config/v1/network-slice/slice1", strings.NewReader(body))
+	req.Header.Set(idempotencyKeyHeader, "deploy-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected `%v`, got `%v`", http.StatusConflict, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"code":"idempotency-key-in-use"`) {
+		t.Errorf("Expected an idempotency-key-in-use problem, got `%v`", w.Body.String())
+	}
+	if calls != 0 {
+		t.Errorf("Expected the handler not to be called, got %d calls", calls)
+	}
+}
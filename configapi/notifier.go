@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+const (
+	pebbleNetworkSliceCreateKey = "aetherproject.org/webconsole/networkslice/create"
+	pebbleNetworkSliceDeleteKey = "aetherproject.org/webconsole/networkslice/delete"
+)
+
+// configNotifier is told about the configuration changes once they are
+// stored. ctx is the context of the write, which may be a transaction.
+type configNotifier interface {
+	notify(ctx context.Context, changes []configmodels.ConfigChange) error
+}
+
+var configNotifiers = []configNotifier{pebbleNotifier{}}
+
+func newConfigChange(kind, operation, name string) configmodels.ConfigChange {
+	return configmodels.ConfigChange{
+		Kind:      kind,
+		Operation: operation,
+		Name:      name,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// configChangeOperation returns the operation of a write, given whether the
+// object existed before it.
+func configChangeOperation(existed bool) string {
+	if existed {
+		return configmodels.ApplyOpUpdate
+	}
+	return configmodels.ApplyOpCreate
+}
+
+// notifyConfigChanges passes the changes to every notifier. Notification
+// failures do not fail the write.
+func notifyConfigChanges(ctx context.Context, changes ...configmodels.ConfigChange) {
+	if len(changes) == 0 {
+		return
+	}
+	for _, notifier := range configNotifiers {
+		if err := notifier.notify(ctx, changes); err != nil {
+			logger.ConfigLog.Warnf("sending %T notification failed: %s. continuing silently", notifier, err.Error())
+		}
+	}
+}
+
+// pebbleNotifier sends a Pebble custom notice when network slices are created,
+// updated or deleted, if enabled in the configuration.
+type pebbleNotifier struct{}
+
+func (pebbleNotifier) notify(_ context.Context, changes []configmodels.ConfigChange) error {
+	if !factory.WebUIConfig.Configuration.SendPebbleNotifications {
+		return nil
+	}
+	var keys []string
+	for _, change := range changes {
+		if change.Kind != configmodels.ApplyKindNetworkSlice {
+			continue
+		}
+		key := pebbleNetworkSliceCreateKey
+		if change.Operation == configmodels.ApplyOpDelete {
+			key = pebbleNetworkSliceDeleteKey
+		}
+		// a single notice per key is enough for the whole batch
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		if err := sendPebbleNotification(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sendPebbleNotification(key string) error {
+	cmd := execCommand("pebble", "notify", key)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("couldn't execute a pebble notify: %+v", err)
+	}
+	logger.ConfigLog.Infoln("custom Pebble notification sent")
+	return nil
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/problemdetails"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+// validationError reports an invalid field of a request. Its message is the
+// one the API has always returned, the field is the JSON path of the value.
+type validationError struct {
+	field   string
+	message string
+}
+
+func (e *validationError) Error() string {
+	return e.message
+}
+
+func newValidationError(field, format string, args ...interface{}) error {
+	return &validationError{field: field, message: fmt.Sprintf(format, args...)}
+}
+
+// writeProblem writes an RFC 7807 problem details response.
+func writeProblem(c *gin.Context, status int, code, detail, requestID string) {
+	problemdetails.Write(c, configmodels.ProblemDetails{
+		Status:    status,
+		Code:      code,
+		Detail:    detail,
+		RequestID: requestID,
+	})
+}
+
+// writeErrorProblem writes the problem details of err, as returned by the
+// helpers along with a status code. Validation and reference errors keep
+// their details, version conflicts their own status, other errors get a code
+// derived from the status.
+func writeErrorProblem(c *gin.Context, status int, err error, requestID string) {
+	var validationErr *validationError
+	var refErr *referenceError
+	var conflictErr *versionConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		code := configmodels.ProblemCodePreconditionFailed
+		if conflictErr.statusCode == http.StatusConflict {
+			code = configmodels.ProblemCodeConcurrentUpdate
+		}
+		writeProblem(c, conflictErr.statusCode, code, conflictErr.message, requestID)
+	case errors.As(err, &refErr):
+		code := configmodels.ProblemCodeInvalidReferences
+		if refErr.statusCode == http.StatusConflict {
+			code = configmodels.ProblemCodeStillReferenced
+		}
+		problemdetails.Write(c, configmodels.ProblemDetails{
+			Status:     refErr.statusCode,
+			Code:       code,
+			Detail:     refErr.message,
+			RequestID:  requestID,
+			References: refErr.references,
+		})
+	case errors.As(err, &validationErr):
+		problemdetails.Write(c, configmodels.ProblemDetails{
+			Status:    status,
+			Code:      configmodels.ProblemCodeValidationFailed,
+			Detail:    validationErr.message,
+			RequestID: requestID,
+			Errors:    []configmodels.FieldError{{Field: validationErr.field, Message: validationErr.message}},
+		})
+	default:
+		writeProblem(c, status, problemdetails.Code(status), err.Error(), requestID)
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/problemdetails"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+var requestIDMember = regexp.MustCompile(`,"request_id":"[0-9a-f-]{36}"`)
+
+// withoutRequestID removes the random request ID from a problem details body.
+func withoutRequestID(body string) string {
+	return requestIDMember.ReplaceAllString(body, "")
+}
+
+func TestWriteErrorProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name     string
+		status   int
+		err      error
+		expected configmodels.ProblemDetails
+	}{
+		{
+			name:   "validation error",
+			status: http.StatusBadRequest,
+			err:    fmt.Errorf("failed to create UPF: %w", newValidationError("port", "invalid UPF port '%s'", "a")),
+			expected: configmodels.ProblemDetails{
+				Type:      "about:blank",
+				Title:     "Bad Request",
+				Status:    http.StatusBadRequest,
+				Detail:    "invalid UPF port 'a'",
+				Instance:  "/test",
+				Code:      configmodels.ProblemCodeValidationFailed,
+				RequestID: "request-1",
+				Errors:    []configmodels.FieldError{{Field: "port", Message: "invalid UPF port 'a'"}},
+			},
+		},
+		{
+			name:   "reference error",
+			status: http.StatusInternalServerError,
+			err: &referenceError{
+				statusCode: http.StatusConflict,
+				message:    "UPF upf1 is referred to by network slices",
+				references: []configmodels.ConfigReference{{Kind: configmodels.ApplyKindNetworkSlice, Name: "slice1", Message: "network slice slice1 refers to UPF upf1"}},
+			},
+			expected: configmodels.ProblemDetails{
+				Type:       "about:blank",
+				Title:      "Conflict",
+				Status:     http.StatusConflict,
+				Detail:     "UPF upf1 is referred to by network slices",
+				Instance:   "/test",
+				Code:       configmodels.ProblemCodeStillReferenced,
+				RequestID:  "request-1",
+				References: []configmodels.ConfigReference{{Kind: configmodels.ApplyKindNetworkSlice, Name: "slice1", Message: "network slice slice1 refers to UPF upf1"}},
+			},
+		},
+		{
+			name:   "version conflict",
+			status: http.StatusInternalServerError,
+			err:    fmt.Errorf("failed to update network slice slice1: %w", &versionConflictError{statusCode: http.StatusPreconditionFailed, message: "the resource does not match the If-Match precondition"}),
+			expected: configmodels.ProblemDetails{
+				Type:      "about:blank",
+				Title:     "Precondition Failed",
+				Status:    http.StatusPreconditionFailed,
+				Detail:    "the resource does not match the If-Match precondition",
+				Instance:  "/test",
+				Code:      configmodels.ProblemCodePreconditionFailed,
+				RequestID: "request-1",
+			},
+		},
+		{
+			name:   "other error",
+			status: http.StatusNotFound,
+			err:    errors.New("device group group1 not found"),
+			expected: configmodels.ProblemDetails{
+				Type:      "about:blank",
+				Title:     "Not Found",
+				Status:    http.StatusNotFound,
+				Detail:    "device group group1 not found",
+				Instance:  "/test",
+				Code:      configmodels.ProblemCodeNotFound,
+				RequestID: "request-1",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/test", nil)
+
+			writeErrorProblem(c, tc.status, tc.err, "request-1")
+
+			if w.Code != tc.expected.Status {
+				t.Errorf("Expected `%v`, got `%v`", tc.expected.Status, w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != problemdetails.ContentType {
+				t.Errorf("Expected Content-Type `%v`, got `%v`", problemdetails.ContentType, contentType)
+			}
+			var problem configmodels.ProblemDetails
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal problem details: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, problem) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// referenceError reports the references that prevent a change: 422 when an
+// object refers to missing ones, 409 when a deleted object is still referred to.
+type referenceError struct {
+	statusCode int
+	message    string
+	references []configmodels.ConfigReference
+}
+
+func (e *referenceError) Error() string {
+	return fmt.Sprintf("%s: %+v", e.message, e.references)
+}
+
+// parseCascadeQuery returns the value of the ?cascade query parameter, which
+// allows deleting an object still referred to by network slices.
+func parseCascadeQuery(c *gin.Context) (bool, error) {
+	cascadeParam := c.Query("cascade")
+	if cascadeParam == "" {
+		return false, nil
+	}
+	cascade, err := strconv.ParseBool(cascadeParam)
+	if err != nil {
+		return false, errors.New("cascade must be true or false")
+	}
+	return cascade, nil
+}
+
+// validateSliceReferences checks that the device groups, gNBs and UPFs of a
+// network slice exist, and that its gNB TACs match the inventory.
+func validateSliceReferences(networkSlice configmodels.Slice) error {
+	var references []configmodels.ConfigReference
+	addReference := func(kind, name, format string, args ...interface{}) {
+		references = append(references, configmodels.ConfigReference{Kind: kind, Name: name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(networkSlice.SiteDeviceGroup) > 0 {
+		deviceGroups, err := fetchByName[configmodels.DeviceGroups](devGroupDataColl, "group-name", networkSlice.SiteDeviceGroup,
+			func(deviceGroup configmodels.DeviceGroups) string { return deviceGroup.DeviceGroupName })
+		if err != nil {
+			return err
+		}
+		for _, groupName := range networkSlice.SiteDeviceGroup {
+			if _, ok := deviceGroups[groupName]; !ok {
+				addReference(configmodels.ApplyKindDeviceGroup, groupName, "device group %s does not exist", groupName)
+			}
+		}
+	}
+
+	if len(networkSlice.SiteInfo.GNodeBs) > 0 {
+		gnbNames := make([]string, 0, len(networkSlice.SiteInfo.GNodeBs))
+		for _, gnb := range networkSlice.SiteInfo.GNodeBs {
+			gnbNames = append(gnbNames, gnb.Name)
+		}
+		gnbs, err := fetchByName[configmodels.Gnb](configmodels.GnbDataColl, "name", gnbNames,
+			func(gnb configmodels.Gnb) string { return gnb.Name })
+		if err != nil {
+			return err
+		}
+		for _, sliceGnb := range networkSlice.SiteInfo.GNodeBs {
+			gnb, ok := gnbs[sliceGnb.Name]
+			if !ok {
+				addReference(configmodels.ApplyKindGnb, sliceGnb.Name, "gNB %s does not exist", sliceGnb.Name)
+				continue
+			}
+			if gnb.Tac != nil && *gnb.Tac != sliceGnb.Tac {
+				addReference(configmodels.ApplyKindGnb, sliceGnb.Name, "TAC %d of gNB %s does not match its inventory TAC %d", sliceGnb.Tac, sliceGnb.Name, *gnb.Tac)
+			}
+		}
+	}
+
+	if sliceUpfs := networkSlice.SiteInfo.UpfList(); len(sliceUpfs) > 0 {
+		upfNames := make([]string, 0, len(sliceUpfs))
+		for _, upf := range sliceUpfs {
+			upfNames = append(upfNames, upf.UpfName)
+		}
+		upfs, err := fetchByName[configmodels.Upf](configmodels.UpfDataColl, "hostname", upfNames,
+			func(upf configmodels.Upf) string { return upf.Hostname })
+		if err != nil {
+			return err
+		}
+		for _, upfName := range upfNames {
+			if _, ok := upfs[upfName]; !ok {
+				addReference(configmodels.ApplyKindUpf, upfName, "UPF %s does not exist", upfName)
+			}
+		}
+	}
+
+	if len(references) > 0 {
+		return &referenceError{
+			statusCode: http.StatusUnprocessableEntity,
+			message:    fmt.Sprintf("network slice %s refers to missing or inconsistent objects", networkSlice.SliceName),
+			references: references,
+		}
+	}
+	return nil
+}
+
+// checkNotReferenced fails with a 409 referenceError when network slices
+// matching filter still refer to an object. label names the object kind in
+// the messages.
+func checkNotReferenced(label, name string, filter bson.M) error {
+	rawNetworkSlices, err := dbadapter.CommonDBClient.RestfulAPIGetMany(sliceDataColl, filter)
+	if err != nil {
+		return fmt.Errorf("failed to fetch network slices: %w", err)
+	}
+	networkSlices, err := decodeDocuments[configmodels.Slice](rawNetworkSlices)
+	if err != nil {
+		return fmt.Errorf("failed to decode network slices: %w", err)
+	}
+	if len(networkSlices) == 0 {
+		return nil
+	}
+	references := make([]configmodels.ConfigReference, 0, len(networkSlices))
+	for _, networkSlice := range networkSlices {
+		references = append(references, configmodels.ConfigReference{
+			Kind:    configmodels.ApplyKindNetworkSlice,
+			Name:    networkSlice.SliceName,
+			Message: fmt.Sprintf("network slice %s refers to %s %s", networkSlice.SliceName, label, name),
+		})
+	}
+	return &referenceError{
+		statusCode: http.StatusConflict,
+		message:    fmt.Sprintf("%s %s is referred to by network slices. Use ?cascade=true to remove it from them", label, name),
+		references: references,
+	}
+}
+
+// fetchByName returns the documents of a collection whose key is one of names,
+// indexed by name.
+func fetchByName[T any](collection, key string, names []string, name func(T) string) (map[string]T, error) {
+	rawDocuments, err := dbadapter.CommonDBClient.RestfulAPIGetMany(collection, bson.M{key: bson.M{"$in": names}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", collection, err)
+	}
+	documents, err := decodeDocuments[T](rawDocuments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", collection, err)
+	}
+	byName := make(map[string]T, len(documents))
+	for _, document := range documents {
+		byName[name(document)] = document
+	}
+	return byName, nil
+}
@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientSliceInventory struct {
+	MockMongoClientEmptyDB
+	gnbs          []configmodels.Gnb
+	upfs          []configmodels.Upf
+	deviceGroups  []configmodels.DeviceGroups
+	networkSlices []configmodels.Slice
+}
+
+func (m *MockMongoClientSliceInventory) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]interface{}, error) {
+	var documents []interface{}
+	switch coll {
+	case configmodels.GnbDataColl:
+		for _, gnb := range m.gnbs {
+			documents = append(documents, gnb)
+		}
+	case configmodels.UpfDataColl:
+		for _, upf := range m.upfs {
+			documents = append(documents, upf)
+		}
+	case devGroupDataColl:
+		for _, deviceGroup := range m.deviceGroups {
+			documents = append(documents, deviceGroup)
+		}
+	case sliceDataColl:
+		for _, networkSlice := range m.networkSlices {
+			documents = append(documents, networkSlice)
+		}
+	}
+	var results []map[string]interface{}
+	for _, document := range documents {
+		results = append(results, configmodels.ToBsonM(document))
+	}
+	return results, nil
+}
+
+func TestNetworkSlicePostHandler_References(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	tac := int32(1)
+	inventory := &MockMongoClientSliceInventory{
+		gnbs:         []configmodels.Gnb{{Name: "gnb1", Tac: &tac}, {Name: "gnb2"}},
+		upfs:         []configmodels.Upf{{Hostname: "upf1.my-domain.com", Port: "8805"}},
+		deviceGroups: []configmodels.DeviceGroups{{DeviceGroupName: "group1"}},
+	}
+
+	testCases := []struct {
+		name               string
+		inputData          string
+		expectedCode       int
+		expectedReferences []configmodels.ConfigReference
+	}{
+		{
+			name:         "all references exist",
+			inputData:    `{"slice-id": {"sst": "1"}, "site-device-group": ["group1"], "site-info": {"gNodeBs": [{"name": "gnb1", "tac": 1}, {"name": "gnb2", "tac": 7}], "upf": {"upf-name": "upf1.my-domain.com"}}}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "missing references and TAC mismatch",
+			inputData:    `{"slice-id": {"sst": "1"}, "site-device-group": ["group1", "group2"], "site-info": {"gNodeBs": [{"name": "gnb1", "tac": 2}, {"name": "gnb3", "tac": 3}], "upfs": [{"upf-name": "upf1.my-domain.com"}, {"upf-name": "upf2.my-domain.com"}]}}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedReferences: []configmodels.ConfigReference{
+				{Kind: configmodels.ApplyKindDeviceGroup, Name: "group2", Message: "device group group2 does not exist"},
+				{Kind: configmodels.ApplyKindGnb, Name: "gnb1", Message: "TAC 2 of gNB gnb1 does not match its inventory TAC 1"},
+				{Kind: configmodels.ApplyKindGnb, Name: "gnb3", Message: "gNB gnb3 does not exist"},
+				{Kind: configmodels.ApplyKindUpf, Name: "upf2.my-domain.com", Message: "UPF upf2.my-domain.com does not exist"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			origChannel := configChannel
+			configChannel = make(chan *configmodels.ConfigMessage, 1)
+			originalDBClient := dbadapter.CommonDBClient
+			defer func() { configChannel = origChannel; dbadapter.CommonDBClient = originalDBClient }()
+			dbadapter.CommonDBClient = inventory
+			req, err := http.NewRequest(http.MethodPost, "/config/v1/network-slice/slice-1", strings.NewReader(tc.inputData))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+			if tc.expectedCode != w.Code {
+				t.Fatalf("Expected `%v`, got `%v` with body `%v`", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if tc.expectedReferences == nil {
+				return
+			}
+			var body struct {
+				References []configmodels.ConfigReference `json:"references"`
+			}
+			if err = json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal body: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expectedReferences, body.References) {
+				t.Errorf("Expected references %+v, got %+v", tc.expectedReferences, body.References)
+			}
+		})
+	}
+}
+
+func TestDeleteHandlers_ReferencedObjects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	referencingSlice := configmodels.Slice{SliceName: "slice1"}
+
+	testCases := []struct {
+		name         string
+		route        string
+		dbAdapter    dbadapter.DBInterface
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Delete referenced gNB expects conflict",
+			route:        "/config/v1/inventory/gnb/gnb1",
+			dbAdapter:    &MockMongoClientSliceInventory{networkSlices: []configmodels.Slice{referencingSlice}},
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"detail":"gNB gnb1 is referred to by network slices. Use ?cascade=true to remove it from them","instance":"/config/v1/inventory/gnb/gnb1","code":"still-referenced","references":[{"kind":"network-slice","name":"slice1","message":"network slice slice1 refers to gNB gnb1"}]}`,
+		},
+		{
+			name:         "Delete referenced UPF expects conflict",
+			route:        "/config/v1/inventory/upf/upf1.my-domain.com",
+			dbAdapter:    &MockMongoClientSliceInventory{networkSlices: []configmodels.Slice{referencingSlice}},
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"detail":"UPF upf1.my-domain.com is referred to by network slices. Use ?cascade=true to remove it from them","instance":"/config/v1/inventory/upf/upf1.my-domain.com","code":"still-referenced","references":[{"kind":"network-slice","name":"slice1","message":"network slice slice1 refers to UPF upf1.my-domain.com"}]}`,
+		},
+		{
+			name:         "Delete unreferenced gNB expects OK status",
+			route:        "/config/v1/inventory/gnb/gnb1",
+			dbAdapter:    &MockMongoClientSliceInventory{},
+			expectedCode: http.StatusOK,
+			expectedBody: "{}",
+		},
+		{
+			name:         "Invalid cascade expects failure",
+			route:        "/config/v1/inventory/upf/upf1.my-domain.com?cascade=maybe",
+			dbAdapter:    &MockMongoClientSliceInventory{},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"cascade must be true or false","instance":"/config/v1/inventory/upf/upf1.my-domain.com","code":"invalid-request"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.CommonDBClient
+			defer func() { dbadapter.CommonDBClient = originalDBClient }()
+			dbadapter.CommonDBClient = tc.dbAdapter
+			req, err := http.NewRequest(http.MethodDelete, tc.route, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+			if tc.expectedCode != w.Code {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedCode, w.Code)
+			}
+			if tc.expectedBody != withoutRequestID(w.Body.String()) {
+				t.Errorf("Expected `%v`, got `%v`", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeviceGroupDeleteHandler_ReferencedDeviceGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+	originalDBClient := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = originalDBClient }()
+	dbadapter.CommonDBClient = &MockMongoClientManyNetworkSlices{}
+
+	req, err := http.NewRequest(http.MethodDelete, "/config/v1/device-group/group1", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected `%v`, got `%v`", http.StatusConflict, w.Code)
+	}
+	var body struct {
+		References []configmodels.ConfigReference `json:"references"`
+	}
+	if err = json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	var sliceNames []string
+	for _, reference := range body.References {
+		sliceNames = append(sliceNames, reference.Name)
+	}
+	expected := []string{"slice1", "slice2", "slice3"}
+	if !reflect.DeepEqual(expected, sliceNames) {
+		t.Errorf("Expected references to %v, got %+v", expected, body.References)
+	}
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/omec-project/webconsole/backend/logger"
+)
+
+const (
+	requestIDContextKey = "request_id"
+	maxRequestIDLength  = 128
+)
+
+// RequestIDMiddleware gives every request an ID, taken from the X-Request-ID
+// header when the caller sets a valid one. The ID is stored in the gin and
+// request contexts, returned in the X-Request-ID response header and logged
+// with the outcome of the request.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(logger.RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set(requestIDContextKey, requestID)
+		c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), requestID))
+		c.Header(logger.RequestIDHeader, requestID)
+
+		c.Next()
+
+		logger.WebUILog.Infow("handled request",
+			"request_id", requestID,
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency", time.Since(start).String(),
+		)
+	}
+}
+
+// isValidRequestID reports whether a caller supplied request ID can be logged
+// and echoed as is: printable ASCII without spaces, up to 128 characters.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// getRequestID returns the ID the middleware gave the request. Handlers
+// served without the middleware get a new ID.
+func getRequestID(c *gin.Context) string {
+	if requestID := c.GetString(requestIDContextKey); requestID != "" {
+		return requestID
+	}
+	if c.Request != nil {
+		if requestID := logger.RequestIDFromContext(c.Request.Context()); requestID != "" {
+			return requestID
+		}
+	}
+	return uuid.New().String()
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name          string
+		header        string
+		expectGiven   bool
+		expectNewUUID bool
+	}{
+		{
+			name:        "valid request ID is kept",
+			header:      "client-request-42",
+			expectGiven: true,
+		},
+		{
+			name:          "missing request ID is generated",
+			header:        "",
+			expectNewUUID: true,
+		},
+		{
+			name:          "request ID with spaces is replaced",
+			header:        "bad request id",
+			expectNewUUID: true,
+		},
+		{
+			name:          "too long request ID is replaced",
+			header:        strings.Repeat("a", maxRequestIDLength+1),
+			expectNewUUID: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestIDMiddleware())
+			var handlerID, contextID string
+			router.GET("/test", func(c *gin.Context) {
+				handlerID = getRequestID(c)
+				contextID = logger.RequestIDFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tc.header != "" {
+				req.Header.Set(logger.RequestIDHeader, tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			responseID := w.Header().Get(logger.RequestIDHeader)
+			if tc.expectGiven && responseID != tc.header {
+				t.Errorf("Expected request ID `%v`, got `%v`", tc.header, responseID)
+			}
+			if tc.expectNewUUID {
+				if _, err := uuid.Parse(responseID); err != nil {
+					t.Errorf("Expected a generated UUID, got `%v`", responseID)
+				}
+			}
+			if handlerID != responseID || contextID != responseID {
+				t.Errorf("Expected handler and context request IDs `%v`, got `%v` and `%v`", responseID, handlerID, contextID)
+			}
+		})
+	}
+}
+
+func TestRequestIDPropagatedToConfigMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	AddConfigV1Service(router)
+
+	originalDbAdapter := dbadapter.CommonDBClient
+	dbadapter.CommonDBClient = &MockMongoClientEmptyDB{}
+	origChannel := configChannel
+	configChannel = make(chan *configmodels.ConfigMessage, 10)
+	defer func() {
+		configChannel = origChannel
+		dbadapter.CommonDBClient = originalDbAdapter
+	}()
+	req := httptest.NewRequest(http.MethodDelete, "/config/v1/device-group/group1", nil)
+	req.Header.Set(logger.RequestIDHeader, "delete-group1")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected `%v`, got `%v`", http.StatusOK, w.Code)
+	}
+	select {
+	case msg := <-configChannel:
+		if msg.RequestID != "delete-group1" {
+			t.Errorf("Expected config message request ID `delete-group1`, got `%v`", msg.RequestID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for device group deletion message")
+	}
+}
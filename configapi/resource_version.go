@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// resourceVersionField is the document field holding the version of network
+// slices, device groups, gNBs, UPFs and subscribers. It is incremented by every
+// update and returned as the ETag of the resource. Documents written before it
+// was introduced have no version, which counts as version 0.
+const resourceVersionField = "resource-version"
+
+// versionConflictError reports an update refused because the resource is no
+// longer at the expected version: 412 when the If-Match precondition of the
+// request does not hold, 409 when a concurrent update got in first.
+type versionConflictError struct {
+	statusCode int
+	message    string
+}
+
+func (e *versionConflictError) Error() string {
+	return e.message
+}
+
+// versionConflictStatus returns the status code of a version conflict, or
+// fallback for other errors.
+func versionConflictStatus(err error, fallback int) int {
+	var conflictErr *versionConflictError
+	if errors.As(err, &conflictErr) {
+		return conflictErr.statusCode
+	}
+	return fallback
+}
+
+// writeVersionConflict answers the request with the problem details of err if
+// it is a version conflict. It reports whether the request was answered.
+func writeVersionConflict(c *gin.Context, err error, requestID string) bool {
+	var conflictErr *versionConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+	writeErrorProblem(c, conflictErr.statusCode, conflictErr, requestID)
+	return true
+}
+
+// resourceVersion returns the version stored in a document.
+func resourceVersion(document map[string]interface{}) int64 {
+	return documentInt(document, resourceVersionField)
+}
+
+// documentInt returns an integer field of a document, whichever integer type
+// it was decoded as, or 0 if it is not set.
+func documentInt(document map[string]interface{}, field string) int64 {
+	switch value := document[field].(type) {
+	case int64:
+		return value
+	case int32:
+		return int64(value)
+	case int:
+		return int64(value)
+	case float64:
+		return int64(value)
+	}
+	return 0
+}
+
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// setETag returns the version of a document in the ETag header.
+func setETag(c *gin.Context, document map[string]interface{}) {
+	c.Header("ETag", formatETag(resourceVersion(document)))
+}
+
+// versionFilter narrows filter to the given version of the resource.
+func versionFilter(filter bson.M, version int64) bson.M {
+	versioned := maps.Clone(filter)
+	if version == 0 {
+		versioned[resourceVersionField] = bson.M{"$exists": false}
+	} else {
+		versioned[resourceVersionField] = version
+	}
+	return versioned
+}
+
+// ifMatch is the If-Match precondition of a request on one resource.
+type ifMatch struct {
+	collName string
+	filter   bson.M
+	any      bool
+	etags    []string
+}
+
+type ifMatchKey struct{}
+
+// setIfMatch attaches the If-Match header of the request to its context, as
+// the precondition of updating or deleting the resource matched by filter.
+// The resources changed as a side effect, such as the network slices updated
+// along with a gNB, are not subject to it.
+func setIfMatch(c *gin.Context, collName string, filter bson.M) error {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil
+	}
+	precondition := &ifMatch{collName: collName, filter: filter}
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.TrimSpace(etag)
+		switch {
+		case etag == "*":
+			precondition.any = true
+		case isQuotedETag(etag):
+			precondition.etags = append(precondition.etags, etag)
+		case strings.HasPrefix(etag, "W/") && isQuotedETag(etag[2:]):
+			// If-Match uses the strong comparison, a weak tag never matches
+		default:
+			return fmt.Errorf("invalid If-Match header: %s", header)
+		}
+	}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ifMatchKey{}, precondition))
+	return nil
+}
+
+func isQuotedETag(etag string) bool {
+	return len(etag) >= 2 && strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`)
+}
+
+// ifMatchFor returns the If-Match precondition of the request on the resource
+// matched by filter, if any.
+func ifMatchFor(ctx context.Context, collName string, filter bson.M) *ifMatch {
+	precondition, ok := ctx.Value(ifMatchKey{}).(*ifMatch)
+	if !ok || precondition.collName != collName || !reflect.DeepEqual(precondition.filter, filter) {
+		return nil
+	}
+	return precondition
+}
+
+// matches reports whether the current document of the resource satisfies the
+// precondition. A missing resource never does.
+func (m *ifMatch) matches(current map[string]interface{}) bool {
+	if len(current) == 0 {
+		return false
+	}
+	return m.any || slices.Contains(m.etags, formatETag(resourceVersion(current)))
+}
+
+// fetchResourceVersion reads the resource matched by filter, nil if it does
+// not exist, and checks the If-Match precondition of the request against it.
+func fetchResourceVersion(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M) (map[string]interface{}, error) {
+	current, err := client.RestfulAPIGetOne(collName, filter)
+	if err != nil {
+		return nil, err
+	}
+	recordAuditedRead(ctx, collName, filter, current)
+	if precondition := ifMatchFor(ctx, collName, filter); precondition != nil && !precondition.matches(current) {
+		return nil, &versionConflictError{
+			statusCode: http.StatusPreconditionFailed,
+			message:    "the resource does not match the If-Match precondition",
+		}
+	}
+	return current, nil
+}
+
+// concurrentUpdateError reports a write refused because the resource matched
+// by filter is no longer at the version read by fetchResourceVersion.
+func concurrentUpdateError(ctx context.Context, collName string, filter bson.M) error {
+	if ifMatchFor(ctx, collName, filter) != nil {
+		return &versionConflictError{
+			statusCode: http.StatusPreconditionFailed,
+			message:    "the resource was updated concurrently and no longer matches the If-Match precondition",
+		}
+	}
+	return &versionConflictError{
+		statusCode: http.StatusConflict,
+		message:    "the resource was updated concurrently, retry the request",
+	}
+}
+
+// claimResourceVersion is the compare-and-swap guarding an update of the
+// resource matched by filter, for the updates done in a transaction. It checks
+// the If-Match precondition of the request against the stored version, then
+// increments the version only if it is still the one checked, so that of two
+// concurrent updates only one goes through. A resource that does not exist yet
+// has no version to claim.
+func claimResourceVersion(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M) error {
+	current, err := fetchResourceVersion(ctx, client, collName, filter)
+	if err != nil || len(current) == 0 {
+		return err
+	}
+	version := resourceVersion(current)
+	swapped, err := client.RestfulAPICompareAndSwapWithContext(ctx, collName, versionFilter(filter, version), bson.M{resourceVersionField: version + 1})
+	if err != nil {
+		return err
+	}
+	if !swapped {
+		return concurrentUpdateError(ctx, collName, filter)
+	}
+	return nil
+}
+
+// putResourceVersion writes document, merged into the stored one, as the new
+// state of the resource matched by filter. Outside a transaction, the version
+// is checked and incremented by the write itself, so that a failed update
+// leaves the resource as it was. A resource that does not exist yet is
+// created.
+func putResourceVersion(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M, document map[string]interface{}) error {
+	current, err := fetchResourceVersion(ctx, client, collName, filter)
+	if err != nil {
+		return err
+	}
+	return writeResourceVersion(ctx, client, collName, filter, current, document)
+}
+
+// writeResourceVersion writes document over current, the state read by
+// fetchResourceVersion, only if the resource is still at its version.
+func writeResourceVersion(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M, current map[string]interface{}, document map[string]interface{}) error {
+	if len(current) == 0 {
+		_, err := client.RestfulAPIPost(collName, filter, document)
+		return err
+	}
+	version := resourceVersion(current)
+	versioned := maps.Clone(document)
+	versioned[resourceVersionField] = version + 1
+	swapped, err := client.RestfulAPICompareAndSwapWithContext(ctx, collName, versionFilter(filter, version), versioned)
+	if err != nil {
+		return err
+	}
+	if !swapped {
+		return concurrentUpdateError(ctx, collName, filter)
+	}
+	return nil
+}
+
+// replaceResourceVersion is writeResourceVersion for a document computed from
+// current as a whole, such as a patched one: the fields it lacks are removed.
+func replaceResourceVersion(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M, current map[string]interface{}, document map[string]interface{}) error {
+	version := resourceVersion(current)
+	versioned := maps.Clone(document)
+	versioned[resourceVersionField] = version + 1
+	replaced, err := client.RestfulAPIReplaceOneWithContext(ctx, collName, versionFilter(filter, version), versioned)
+	if err != nil {
+		return err
+	}
+	if !replaced {
+		return concurrentUpdateError(ctx, collName, filter)
+	}
+	return nil
+}
+
+// claimResourceVersionIfMatch claims the version of a resource about to be
+// deleted in a transaction, when the request has an If-Match precondition on
+// it.
+func claimResourceVersionIfMatch(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M) error {
+	if ifMatchFor(ctx, collName, filter) == nil {
+		return nil
+	}
+	return claimResourceVersion(ctx, client, collName, filter)
+}
+
+// checkResourceVersionIfMatch checks the If-Match precondition of the request
+// on the resource matched by filter, if it has one, so that a deletion done
+// outside a transaction is refused before anything else is written.
+func checkResourceVersionIfMatch(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M) error {
+	if ifMatchFor(ctx, collName, filter) == nil {
+		return nil
+	}
+	_, err := fetchResourceVersion(ctx, client, collName, filter)
+	return err
+}
+
+// deleteResourceVersion deletes the resource matched by filter outside a
+// transaction. When the request has an If-Match precondition on it, the
+// deletion is conditional on the version that satisfied it.
+func deleteResourceVersion(ctx context.Context, client dbadapter.DBInterface, collName string, filter bson.M) error {
+	if ifMatchFor(ctx, collName, filter) == nil {
+		return client.RestfulAPIDeleteOne(collName, filter)
+	}
+	current, err := fetchResourceVersion(ctx, client, collName, filter)
+	if err != nil {
+		return err
+	}
+	deleted, err := client.RestfulAPICompareAndDeleteWithContext(ctx, collName, versionFilter(filter, resourceVersion(current)))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return concurrentUpdateError(ctx, collName, filter)
+	}
+	return nil
+}
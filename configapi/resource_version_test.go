@@ -0,0 +1,413 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientVersioned struct {
+	MockMongoClientEmptyDB
+	document         map[string]interface{}
+	concurrentUpdate bool
+	swapFilter       bson.M
+	deleted          bool
+}
+
+func (m *MockMongoClientVersioned) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	return m.document, nil
+}
+
+func (m *MockMongoClientVersioned) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	m.swapFilter = filter
+	if m.concurrentUpdate {
+		return false, nil
+	}
+	maps.Copy(m.document, putData)
+	return true, nil
+}
+
+func (m *MockMongoClientVersioned) RestfulAPICompareAndDeleteWithContext(context context.Context, collName string, filter bson.M) (bool, error) {
+	m.swapFilter = filter
+	if m.concurrentUpdate {
+		return false, nil
+	}
+	m.deleted = true
+	return true, nil
+}
+
+func (m *MockMongoClientVersioned) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	m.deleted = true
+	return nil
+}
+
+func TestIfMatchPrecondition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	filter := bson.M{"slice-name": "slice1"}
+	current := map[string]interface{}{"slice-name": "slice1", resourceVersionField: int64(3)}
+	testCases := []struct {
+		name        string
+		header      string
+		current     map[string]interface{}
+		expectError bool
+		expectMatch bool
+	}{
+		{
+			name:        "matching entity tag",
+			header:      `"3"`,
+			current:     current,
+			expectMatch: true,
+		},
+		{
+			name:        "one of several entity tags",
+			header:      `"1", "3"`,
+			current:     current,
+			expectMatch: true,
+		},
+		{
+			name:    "stale entity tag",
+			header:  `"2"`,
+			current: current,
+		},
+		{
+			name:    "weak entity tag never matches",
+			header:  `W/"3"`,
+			current: current,
+		},
+		{
+			name:        "any version",
+			header:      "*",
+			current:     current,
+			expectMatch: true,
+		},
+		{
+			name:    "missing resource",
+			header:  "*",
+			current: nil,
+		},
+		{
+			name:        "unversioned resource",
+			header:      `"0"`,
+			current:     map[string]interface{}{"slice-name": "slice1"},
+			expectMatch: true,
+		},
+		{
+			name:        "unquoted entity tag",
+			header:      "3",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/config/v1/network-slice/slice1", nil)
+			c.Request.Header.Set("If-Match", tc.header)
+
+			err := setIfMatch(c, sliceDataColl, filter)
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("Expected an invalid If-Match header error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			precondition := ifMatchFor(c.Request.Context(), sliceDataColl, bson.M{"slice-name": "slice1"})
+			if precondition == nil {
+				t.Fatal("Expected a precondition on the network slice")
+			}
+			if ifMatchFor(c.Request.Context(), sliceDataColl, bson.M{"slice-name": "slice2"}) != nil {
+				t.Error("Expected no precondition on another network slice")
+			}
+			if match := precondition.matches(tc.current); match != tc.expectMatch {
+				t.Errorf("Expected match `%v`, got `%v`", tc.expectMatch, match)
+			}
+		})
+	}
+}
+
+func TestClaimResourceVersion(t *testing.T) {
+	filter := bson.M{"name": "gnb1"}
+	testCases := []struct {
+		name               string
+		ifMatch            string
+		document           map[string]interface{}
+		concurrentUpdate   bool
+		expectedStatus     int
+		expectedSwapFilter bson.M
+		expectedVersion    int64
+	}{
+		{
+			name:               "first update of an unversioned resource",
+			document:           map[string]interface{}{"name": "gnb1"},
+			expectedSwapFilter: bson.M{"name": "gnb1", resourceVersionField: bson.M{"$exists": false}},
+			expectedVersion:    1,
+		},
+		{
+			name:               "update with a matching If-Match",
+			ifMatch:            `"4"`,
+			document:           map[string]interface{}{"name": "gnb1", resourceVersionField: int64(4)},
+			expectedSwapFilter: bson.M{"name": "gnb1", resourceVersionField: int64(4)},
+			expectedVersion:    5,
+		},
+		{
+			name:            "update with a stale If-Match",
+			ifMatch:         `"3"`,
+			document:        map[string]interface{}{"name": "gnb1", resourceVersionField: int64(4)},
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedVersion: 4,
+		},
+		{
+			name:           "If-Match on a missing resource",
+			ifMatch:        "*",
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name: "creation without If-Match",
+		},
+		{
+			name:               "concurrent update",
+			document:           map[string]interface{}{"name": "gnb1", resourceVersionField: int64(4)},
+			concurrentUpdate:   true,
+			expectedStatus:     http.StatusConflict,
+			expectedSwapFilter: bson.M{"name": "gnb1", resourceVersionField: int64(4)},
+			expectedVersion:    4,
+		},
+		{
+			name:               "concurrent update with If-Match",
+			ifMatch:            `"4"`,
+			document:           map[string]interface{}{"name": "gnb1", resourceVersionField: int64(4)},
+			concurrentUpdate:   true,
+			expectedStatus:     http.StatusPreconditionFailed,
+			expectedSwapFilter: bson.M{"name": "gnb1", resourceVersionField: int64(4)},
+			expectedVersion:    4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/config/v1/inventory/gnb/gnb1", nil)
+			if tc.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tc.ifMatch)
+			}
+			if err := setIfMatch(c, configmodels.GnbDataColl, filter); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			dbClient := &MockMongoClientVersioned{document: tc.document, concurrentUpdate: tc.concurrentUpdate}
+
+			err := claimResourceVersion(c.Request.Context(), dbClient, configmodels.GnbDataColl, filter)
+
+			var conflictErr *versionConflictError
+			switch {
+			case tc.expectedStatus == 0 && err != nil:
+				t.Fatalf("Unexpected error: %v", err)
+			case tc.expectedStatus != 0 && !errors.As(err, &conflictErr):
+				t.Fatalf("Expected a version conflict, got `%v`", err)
+			case tc.expectedStatus != 0 && conflictErr.statusCode != tc.expectedStatus:
+				t.Errorf("Expected status `%v`, got `%v`", tc.expectedStatus, conflictErr.statusCode)
+			}
+			if !reflect.DeepEqual(dbClient.swapFilter, tc.expectedSwapFilter) {
+				t.Errorf("Expected compare-and-swap filter `%v`, got `%v`", tc.expectedSwapFilter, dbClient.swapFilter)
+			}
+			if version := resourceVersion(tc.document); version != tc.expectedVersion {
+				t.Errorf("Expected version `%v`, got `%v`", tc.expectedVersion, version)
+			}
+		})
+	}
+}
+
+func TestPutResourceVersion(t *testing.T) {
+	filter := bson.M{"slice-name": "slice1"}
+	testCases := []struct {
+		name             string
+		ifMatch          string
+		concurrentUpdate bool
+		expectedStatus   int
+		expectedDocument map[string]interface{}
+	}{
+		{
+			name:             "update",
+			expectedDocument: map[string]interface{}{"slice-name": "slice1", "sd": "010203", resourceVersionField: int64(4)},
+		},
+		{
+			name:             "update with a matching If-Match",
+			ifMatch:          `"3"`,
+			expectedDocument: map[string]interface{}{"slice-name": "slice1", "sd": "010203", resourceVersionField: int64(4)},
+		},
+		{
+			name:             "concurrent update",
+			concurrentUpdate: true,
+			expectedStatus:   http.StatusConflict,
+			expectedDocument: map[string]interface{}{"slice-name": "slice1", "sd": "000001", resourceVersionField: int64(3)},
+		},
+		{
+			name:             "concurrent update with If-Match",
+			ifMatch:          `"3"`,
+			concurrentUpdate: true,
+			expectedStatus:   http.StatusPreconditionFailed,
+			expectedDocument: map[string]interface{}{"slice-name": "slice1", "sd": "000001", resourceVersionField: int64(3)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/config/v1/network-slice/slice1", nil)
+			if tc.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tc.ifMatch)
+			}
+			if err := setIfMatch(c, sliceDataColl, filter); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			dbClient := &MockMongoClientVersioned{
+				document:         map[string]interface{}{"slice-name": "slice1", "sd": "000001", resourceVersionField: int64(3)},
+				concurrentUpdate: tc.concurrentUpdate,
+			}
+
+			err := putResourceVersion(c.Request.Context(), dbClient, sliceDataColl, filter, map[string]interface{}{"slice-name": "slice1", "sd": "010203"})
+
+			var conflictErr *versionConflictError
+			switch {
+			case tc.expectedStatus == 0 && err != nil:
+				t.Fatalf("Unexpected error: %v", err)
+			case tc.expectedStatus != 0 && !errors.As(err, &conflictErr):
+				t.Fatalf("Expected a version conflict, got `%v`", err)
+			case tc.expectedStatus != 0 && conflictErr.statusCode != tc.expectedStatus:
+				t.Errorf("Expected status `%v`, got `%v`", tc.expectedStatus, conflictErr.statusCode)
+			}
+			expectedSwapFilter := bson.M{"slice-name": "slice1", resourceVersionField: int64(3)}
+			if !reflect.DeepEqual(dbClient.swapFilter, expectedSwapFilter) {
+				t.Errorf("Expected compare-and-swap filter `%v`, got `%v`", expectedSwapFilter, dbClient.swapFilter)
+			}
+			if !reflect.DeepEqual(dbClient.document, tc.expectedDocument) {
+				t.Errorf("Expected document `%v`, got `%v`", tc.expectedDocument, dbClient.document)
+			}
+		})
+	}
+}
+
+func TestDeleteResourceVersion(t *testing.T) {
+	filter := bson.M{"slice-name": "slice1"}
+	testCases := []struct {
+		name               string
+		ifMatch            string
+		concurrentUpdate   bool
+		expectedStatus     int
+		expectedSwapFilter bson.M
+		expectedDeleted    bool
+	}{
+		{
+			name:            "deletion without If-Match",
+			expectedDeleted: true,
+		},
+		{
+			name:               "deletion with a matching If-Match",
+			ifMatch:            `"3"`,
+			expectedSwapFilter: bson.M{"slice-name": "slice1", resourceVersionField: int64(3)},
+			expectedDeleted:    true,
+		},
+		{
+			name:           "deletion with a stale If-Match",
+			ifMatch:        `"2"`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "concurrent update with If-Match",
+			ifMatch:            `"3"`,
+			concurrentUpdate:   true,
+			expectedStatus:     http.StatusPreconditionFailed,
+			expectedSwapFilter: bson.M{"slice-name": "slice1", resourceVersionField: int64(3)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodDelete, "/config/v1/network-slice/slice1", nil)
+			if tc.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tc.ifMatch)
+			}
+			if err := setIfMatch(c, sliceDataColl, filter); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			dbClient := &MockMongoClientVersioned{
+				document:         map[string]interface{}{"slice-name": "slice1", resourceVersionField: int64(3)},
+				concurrentUpdate: tc.concurrentUpdate,
+			}
+
+			err := deleteResourceVersion(c.Request.Context(), dbClient, sliceDataColl, filter)
+
+			var conflictErr *versionConflictError
+			switch {
+			case tc.expectedStatus == 0 && err != nil:
+				t.Fatalf("Unexpected error: %v", err)
+			case tc.expectedStatus != 0 && !errors.As(err, &conflictErr):
+				t.Fatalf("Expected a version conflict, got `%v`", err)
+			case tc.expectedStatus != 0 && conflictErr.statusCode != tc.expectedStatus:
+				t.Errorf("Expected status `%v`, got `%v`", tc.expectedStatus, conflictErr.statusCode)
+			}
+			if !reflect.DeepEqual(dbClient.swapFilter, tc.expectedSwapFilter) {
+				t.Errorf("Expected compare-and-delete filter `%v`, got `%v`", tc.expectedSwapFilter, dbClient.swapFilter)
+			}
+			if dbClient.deleted != tc.expectedDeleted {
+				t.Errorf("Expected deleted `%v`, got `%v`", tc.expectedDeleted, dbClient.deleted)
+			}
+		})
+	}
+}
+
+func TestNetworkSliceResourceVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	AddConfigV1Service(router)
+	origDBClient := dbadapter.CommonDBClient
+	origChannel := configChannel
+	configChannel = make(chan *configmodels.ConfigMessage, 10)
+	defer func() {
+		dbadapter.CommonDBClient = origDBClient
+		configChannel = origChannel
+	}()
+	dbClient := &MockMongoClientVersioned{
+		document: map[string]interface{}{"slice-name": "slice1", resourceVersionField: int64(3)},
+	}
+	dbadapter.CommonDBClient = dbClient
+
+	req := httptest.NewRequest(http.MethodGet, "/config/v1/network-slice/slice1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected `%v`, got `%v`", http.StatusOK, w.Code)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"3"` {
+		t.Errorf("Expected ETag `\"3\"`, got `%v`", etag)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/config/v1/network-slice/slice1", nil)
+	req.Header.Set("If-Match", `"2"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	expectedBody := `{"type":"about:blank","title":"Precondition Failed","status":412,"detail":"the resource does not match the If-Match precondition","instance":"/config/v1/network-slice/slice1","code":"precondition-failed"}`
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected `%v`, got `%v`", http.StatusPreconditionFailed, w.Code)
+	}
+	if body := withoutRequestID(w.Body.String()); body != expectedBody {
+		t.Errorf("Expected `%v`, got `%v`", expectedBody, body)
+	}
+	if dbClient.deleted {
+		t.Error("Expected the network slice not to be deleted")
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// routePermission is the permission a route requires: verb on the resource
+// type, or the verb of the HTTP method if none is given. nameParam is the path
+// parameter holding the name of the network slice or device group, for the
+// permissions restricted to some of them.
+type routePermission struct {
+	resource  string
+	verb      string
+	nameParam string
+}
+
+var routePermissions = map[string]routePermission{
+	"/config/v1/":                                 {configmodels.ResourceConfiguration, "", ""},
+	"/config/v1/device-group":                     {configmodels.ResourceDeviceGroup, "", ""},
+	"/config/v1/device-group/:group-name":         {configmodels.ResourceDeviceGroup, "", "group-name"},
+	"/config/v1/device-group/:group-name/history": {configmodels.ResourceDeviceGroup, "", "group-name"},
+	"/config/v1/device-group/:group-name/rollback/:revision": {
+		configmodels.ResourceDeviceGroup, configmodels.VerbUpdate, "group-name",
+	},
+	"/config/v1/network-slice":                     {configmodels.ResourceNetworkSlice, "", ""},
+	"/config/v1/network-slice/:slice-name":         {configmodels.ResourceNetworkSlice, "", "slice-name"},
+	"/config/v1/network-slice/:slice-name/history": {configmodels.ResourceNetworkSlice, "", "slice-name"},
+	"/config/v1/network-slice/:slice-name/rollback/:revision": {
+		configmodels.ResourceNetworkSlice, configmodels.VerbUpdate, "slice-name",
+	},
+	"/config/v1/inventory/gnb":                       {configmodels.ResourceInventory, "", ""},
+	"/config/v1/inventory/gnb/:gnb-name":             {configmodels.ResourceInventory, "", ""},
+	"/config/v1/inventory/gnb/:gnb-name/history":     {configmodels.ResourceInventory, "", ""},
+	"/config/v1/inventory/upf":                       {configmodels.ResourceInventory, "", ""},
+	"/config/v1/inventory/upf/:upf-hostname":         {configmodels.ResourceInventory, "", ""},
+	"/config/v1/inventory/upf/:upf-hostname/history": {configmodels.ResourceInventory, "", ""},
+	"/config/v1/events":                              {configmodels.ResourceConfiguration, "", ""},
+	"/api/sample":                                    {configmodels.ResourceSubscriber, "", ""},
+	"/api/subscriber":                                {configmodels.ResourceSubscriber, "", ""},
+	"/api/subscriber/:ueId":                          {configmodels.ResourceSubscriber, "", ""},
+	"/api/subscriber:action":                         {configmodels.ResourceSubscriber, "", ""},
+	"/api/registered-ue-context":                     {configmodels.ResourceSubscriber, "", ""},
+	"/api/registered-ue-context/:supi":               {configmodels.ResourceSubscriber, "", ""},
+	"/api/ue-pdu-session-info/:smContextRef":         {configmodels.ResourceSubscriber, "", ""},
+}
+
+// adminOnlyRoutes are reserved to the admin account. The import and apply
+// write every resource type at once, whatever names the roles restrict them to,
+// and the export holds the keys of the subscribers.
+var adminOnlyRoutes = map[string]bool{
+	"/config/v1/export": true,
+	"/config/v1/import": true,
+	"/config/v1/apply":  true,
+}
+
+func methodVerb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return configmodels.VerbCreate
+	case http.MethodPut, http.MethodPatch:
+		return configmodels.VerbUpdate
+	case http.MethodDelete:
+		return configmodels.VerbDelete
+	}
+	return configmodels.VerbRead
+}
+
+// RoleAuthorizationMiddleware checks that the roles of the authenticated
+// account grant the permission the route requires, see routePermissions. The
+// admin account is always allowed, and the routes without a permission and
+// adminOnlyRoutes are reserved to it. It runs after the authentication
+// middleware.
+func RoleAuthorizationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, authenticated := auth.RoleFromContext(c.Request.Context())
+		if !authenticated || role == configmodels.AdminRole {
+			c.Next()
+			return
+		}
+		requestID := getRequestID(c)
+		permission, ok := routePermissions[c.FullPath()]
+		if !ok || adminOnlyRoutes[c.FullPath()] {
+			writeProblem(c, http.StatusForbidden, configmodels.ProblemCodeForbidden, "forbidden: admin access required", requestID)
+			c.Abort()
+			return
+		}
+		verb := permission.verb
+		if verb == "" {
+			verb = methodVerb(c.Request.Method)
+		}
+		name := ""
+		if permission.nameParam != "" {
+			name = c.Param(permission.nameParam)
+		}
+		username := auth.UsernameFromContext(c.Request.Context())
+		allowed, err := accountAllows(username, permission.resource, verb, name)
+		if err != nil {
+			logger.AuthLog.Errorf("failed to check the roles of %s: %+v request ID: %s", username, err, requestID)
+			writeProblem(c, http.StatusInternalServerError, configmodels.ProblemCodeInternalError, "failed to authorize", requestID)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			writeProblem(c, http.StatusForbidden, configmodels.ProblemCodeForbidden,
+				fmt.Sprintf("forbidden: the roles of %s do not allow to %s %s", username, verb, permission.resource), requestID)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// accountAllows reports whether one of the roles of the account grants verb
+// on the resource.
+func accountAllows(username, resource, verb, name string) (bool, error) {
+	dbUserAccount, err := fetchDBUserAccount(username)
+	if err != nil {
+		return false, err
+	}
+	if dbUserAccount == nil {
+		return false, nil
+	}
+	for _, roleName := range dbUserAccount.AccountRoles() {
+		role, err := getRole(roleName)
+		if err != nil {
+			return false, err
+		}
+		// a custom role deleted after it was assigned grants nothing
+		if role != nil && role.Allows(resource, verb, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getRole returns a built-in or custom role, or nil if it does not exist.
+func getRole(name string) (*configmodels.Role, error) {
+	if role, ok := configmodels.BuiltInRoles[name]; ok {
+		return &role, nil
+	}
+	rawRole, err := dbadapter.WebuiDBClient.RestfulAPIGetOne(configmodels.RoleDataColl, bson.M{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	if len(rawRole) == 0 {
+		return nil, nil
+	}
+	var role configmodels.Role
+	if err = json.Unmarshal(configmodels.MapToByte(rawRole), &role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
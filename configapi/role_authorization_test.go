@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/auth"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientRoles struct {
+	dbadapter.DBInterface
+	accounts []map[string]interface{}
+	roles    []map[string]interface{}
+	assigned int64
+	deleted  []bson.M
+	posted   []map[string]interface{}
+}
+
+func (db *MockMongoClientRoles) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	documents, field := db.accounts, "username"
+	if collName == configmodels.RoleDataColl {
+		documents, field = db.roles, "name"
+	}
+	for _, document := range documents {
+		if document[field] == filter[field] {
+			return document, nil
+		}
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (db *MockMongoClientRoles) RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error) {
+	return db.roles, nil
+}
+
+func (db *MockMongoClientRoles) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, postData := range postDataArray {
+		db.posted = append(db.posted, postData.(bson.M))
+	}
+	return nil
+}
+
+func (db *MockMongoClientRoles) RestfulAPIPutOne(collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	db.posted = append(db.posted, putData)
+	return true, nil
+}
+
+func (db *MockMongoClientRoles) RestfulAPIPost(collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
+	db.posted = append(db.posted, postData)
+	return true, nil
+}
+
+func (db *MockMongoClientRoles) RestfulAPICount(collName string, filter bson.M) (int64, error) {
+	return db.assigned, nil
+}
+
+func (db *MockMongoClientRoles) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	db.deleted = append(db.deleted, filter)
+	return nil
+}
+
+func TestRoutePermissions(t *testing.T) {
+	for _, route := range routes {
+		if _, ok := routePermissions["/config/v1"+route.Pattern]; !ok && !adminOnlyRoutes["/config/v1"+route.Pattern] {
+			t.Errorf("route %s %s has no permission", route.Method, route.Pattern)
+		}
+	}
+	for _, route := range apiRoutes {
+		if _, ok := routePermissions["/api"+route.Pattern]; !ok {
+			t.Errorf("route %s %s has no permission", route.Method, route.Pattern)
+		}
+	}
+}
+
+func TestRoleAuthorizationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sliceOperator := map[string]interface{}{
+		"name": "slice1-operator",
+		"permissions": []interface{}{
+			map[string]interface{}{"resource": "network-slice", "verb": "read"},
+			map[string]interface{}{"resource": "network-slice", "verb": "update", "names": []interface{}{"slice1"}},
+		},
+	}
+	sliceViewer := map[string]interface{}{
+		"name": "slice1-viewer",
+		"permissions": []interface{}{
+			map[string]interface{}{"resource": "network-slice", "verb": "read", "names": []interface{}{"slice1"}},
+		},
+	}
+	accounts := []map[string]interface{}{
+		{"username": "admin", "role": configmodels.AdminRole},
+		{"username": "legacy", "role": configmodels.UserRole},
+		{"username": "viewer", "role": configmodels.UserRole, "roles": []interface{}{"viewer"}},
+		{"username": "operator", "role": configmodels.UserRole, "roles": []interface{}{"subscriber-operator"}},
+		{"username": "slice-operator", "role": configmodels.UserRole, "roles": []interface{}{"slice1-operator"}},
+		{"username": "slice-viewer", "role": configmodels.UserRole, "roles": []interface{}{"slice1-viewer"}},
+		{"username": "stale", "role": configmodels.UserRole, "roles": []interface{}{"deleted-role"}},
+	}
+
+	testCases := []struct {
+		name         string
+		username     string
+		method       string
+		path         string
+		expectedCode int
+	}{
+		{"AdminWrites", "admin", http.MethodDelete, "/config/v1/network-slice/slice1", http.StatusOK},
+		{"AccountWithoutRolesWrites", "legacy", http.MethodPost, "/config/v1/network-slice/slice1", http.StatusOK},
+		{"ViewerReads", "viewer", http.MethodGet, "/config/v1/network-slice/slice1", http.StatusOK},
+		{"ViewerReadsSubscribers", "viewer", http.MethodGet, "/api/subscriber", http.StatusOK},
+		{"ViewerWrites", "viewer", http.MethodPut, "/config/v1/network-slice/slice1", http.StatusForbidden},
+		{"ViewerApplies", "viewer", http.MethodPost, "/config/v1/apply", http.StatusForbidden},
+		{"AdminApplies", "admin", http.MethodPost, "/config/v1/apply", http.StatusOK},
+		{"AccountWithoutRolesApplies", "legacy", http.MethodPost, "/config/v1/apply", http.StatusForbidden},
+		{"AccountWithoutRolesImports", "legacy", http.MethodPost, "/config/v1/import", http.StatusForbidden},
+		{"ViewerExports", "viewer", http.MethodGet, "/config/v1/export", http.StatusForbidden},
+		{"AdminExports", "admin", http.MethodGet, "/config/v1/export", http.StatusOK},
+		{"SubscriberOperatorWritesSubscribers", "operator", http.MethodPost, "/api/subscriber/imsi-208930100007487", http.StatusOK},
+		{"SubscriberOperatorWritesDeviceGroups", "operator", http.MethodPut, "/config/v1/device-group/group1", http.StatusForbidden},
+		{"ScopedRoleUpdatesItsSlice", "slice-operator", http.MethodPut, "/config/v1/network-slice/slice1", http.StatusOK},
+		{"ScopedRoleRollsBackItsSlice", "slice-operator", http.MethodPost, "/config/v1/network-slice/slice1/rollback/2", http.StatusOK},
+		{"ScopedRoleUpdatesAnotherSlice", "slice-operator", http.MethodPut, "/config/v1/network-slice/slice2", http.StatusForbidden},
+		{"ScopedRoleCreatesSlice", "slice-operator", http.MethodPost, "/config/v1/network-slice/slice1", http.StatusForbidden},
+		{"ScopedRoleReadsSubscribers", "slice-operator", http.MethodGet, "/api/subscriber", http.StatusForbidden},
+		{"UnscopedReadListsSlices", "slice-operator", http.MethodGet, "/config/v1/network-slice", http.StatusOK},
+		{"ScopedReadReadsItsSlice", "slice-viewer", http.MethodGet, "/config/v1/network-slice/slice1", http.StatusOK},
+		{"ScopedReadReadsAnotherSlice", "slice-viewer", http.MethodGet, "/config/v1/network-slice/slice2", http.StatusForbidden},
+		{"ScopedReadListsSlices", "slice-viewer", http.MethodGet, "/config/v1/network-slice", http.StatusForbidden},
+		{"DeletedRole", "stale", http.MethodGet, "/config/v1/network-slice/slice1", http.StatusForbidden},
+		{"UnknownAccount", "unknown", http.MethodGet, "/config/v1/network-slice/slice1", http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			originalDBClient := dbadapter.WebuiDBClient
+			defer func() { dbadapter.WebuiDBClient = originalDBClient }()
+			dbadapter.WebuiDBClient = &MockMongoClientRoles{accounts: accounts, roles: []map[string]interface{}{sliceOperator, sliceViewer}}
+			role := configmodels.UserRole
+			if tc.username == "admin" {
+				role = configmodels.AdminRole
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(auth.ContextWithUser(c.Request.Context(), tc.username, role))
+			})
+			handler := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) }
+			configGroup := router.Group("/config/v1", RoleAuthorizationMiddleware())
+			configGroup.GET("/network-slice", handler)
+			configGroup.GET("/network-slice/:slice-name", handler)
+			configGroup.PUT("/network-slice/:slice-name", handler)
+			configGroup.POST("/network-slice/:slice-name", handler)
+			configGroup.DELETE("/network-slice/:slice-name", handler)
+			configGroup.POST("/network-slice/:slice-name/rollback/:revision", handler)
+			configGroup.PUT("/device-group/:group-name", handler)
+			configGroup.POST("/apply", handler)
+			configGroup.POST("/import", handler)
+			configGroup.GET("/export", handler)
+			apiGroup := router.Group("/api", RoleAuthorizationMiddleware())
+			apiGroup.GET("/subscriber", handler)
+			apiGroup.POST("/subscriber/:ueId", handler)
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.expectedCode != w.Code {
+				t.Errorf("Expected `%v`, got `%v`: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -53,6 +53,8 @@ func addRoutes(group *gin.RouterGroup, routes Routes) {
 			group.POST(route.Pattern, route.HandlerFunc)
 		case http.MethodPut:
 			group.PUT(route.Pattern, route.HandlerFunc)
+		case http.MethodPatch:
+			group.PATCH(route.Pattern, route.HandlerFunc)
 		case http.MethodDelete:
 			group.DELETE(route.Pattern, route.HandlerFunc)
 		}
@@ -147,6 +149,12 @@ var routes = Routes{
 		"/inventory/gnb",
 		GetGnbs,
 	},
+	{
+		"GetGnb",
+		http.MethodGet,
+		"/inventory/gnb/:gnb-name",
+		GetGnb,
+	},
 	{
 		"PostGnb",
 		http.MethodPost,
@@ -171,6 +179,12 @@ var routes = Routes{
 		"/inventory/upf",
 		GetUpfs,
 	},
+	{
+		"GetUpf",
+		http.MethodGet,
+		"/inventory/upf/:upf-hostname",
+		GetUpf,
+	},
 	{
 		"PostUpf",
 		http.MethodPost,
@@ -189,4 +203,64 @@ var routes = Routes{
 		"/inventory/upf/:upf-hostname",
 		DeleteUpf,
 	},
+	{
+		"ExportConfig",
+		http.MethodGet,
+		"/export",
+		ExportConfig,
+	},
+	{
+		"ImportConfig",
+		http.MethodPost,
+		"/import",
+		ImportConfig,
+	},
+	{
+		"ApplyDesiredState",
+		http.MethodPost,
+		"/apply",
+		ApplyDesiredState,
+	},
+	{
+		"GetNetworkSliceHistory",
+		http.MethodGet,
+		"/network-slice/:slice-name/history",
+		GetNetworkSliceHistory,
+	},
+	{
+		"RollbackNetworkSlice",
+		http.MethodPost,
+		"/network-slice/:slice-name/rollback/:revision",
+		RollbackNetworkSlice,
+	},
+	{
+		"GetDeviceGroupHistory",
+		http.MethodGet,
+		"/device-group/:group-name/history",
+		GetDeviceGroupHistory,
+	},
+	{
+		"RollbackDeviceGroup",
+		http.MethodPost,
+		"/device-group/:group-name/rollback/:revision",
+		RollbackDeviceGroup,
+	},
+	{
+		"GetGnbHistory",
+		http.MethodGet,
+		"/inventory/gnb/:gnb-name/history",
+		GetGnbHistory,
+	},
+	{
+		"GetUpfHistory",
+		http.MethodGet,
+		"/inventory/upf/:upf-hostname/history",
+		GetUpfHistory,
+	},
+	{
+		"GetConfigEvents",
+		http.MethodGet,
+		"/events",
+		GetConfigEvents,
+	},
 }
@@ -51,6 +51,13 @@ var apiRoutes = Routes{
 		PostSubscriberByID,
 	},
 
+	{
+		"PostSubscriberAction",
+		http.MethodPost,
+		"/subscriber:action",
+		PostSubscriberAction,
+	},
+
 	{
 		"PutSubscriberByID",
 		http.MethodPut,
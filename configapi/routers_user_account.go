@@ -10,42 +10,102 @@ import (
 	"github.com/omec-project/webconsole/backend/auth"
 )
 
-func AddUserAccountService(engine *gin.Engine, jwtSecret []byte) {
+func AddUserAccountService(engine *gin.Engine, jwtKeys *auth.JWTKeys) {
 	group := engine.Group("/config/v1")
-	addRoutes(group, getUserAccountRoutes(jwtSecret))
+	addRoutes(group, getUserAccountRoutes(jwtKeys))
 }
 
-func getUserAccountRoutes(jwtSecret []byte) Routes {
+func getUserAccountRoutes(jwtKeys *auth.JWTKeys) Routes {
 	return Routes{
 		{
 			"GetUserAccounts",
 			http.MethodGet,
 			"/account",
-			auth.AdminOnly(jwtSecret, GetUserAccounts),
+			auth.AdminOnly(jwtKeys, GetUserAccounts),
 		},
 		{
 			"GetUserAccount",
 			http.MethodGet,
 			"/account/:username",
-			auth.AdminOrMe(jwtSecret, GetUserAccount),
+			auth.AdminOrMe(jwtKeys, GetUserAccount),
 		},
 		{
 			"CreateUserAccount",
 			http.MethodPost,
 			"/account",
-			auth.AdminOrFirstUser(jwtSecret, CreateUserAccount),
+			auth.AdminOrFirstUser(jwtKeys, auditedHandler(CreateUserAccount)),
 		},
 		{
 			"DeleteUserAccount",
 			http.MethodDelete,
 			"/account/:username",
-			auth.AdminOnly(jwtSecret, DeleteUserAccount),
+			auth.AdminOnly(jwtKeys, auditedHandler(DeleteUserAccount)),
 		},
 		{
 			"ChangeUserAccountPasssword",
 			http.MethodPost,
 			"/account/:username/change_password",
-			auth.AdminOrMe(jwtSecret, ChangeUserAccountPasssword),
+			auth.AdminOrMe(jwtKeys, auditedHandler(ChangeUserAccountPasssword)),
+		},
+		{
+			"UnlockUserAccount",
+			http.MethodPost,
+			"/account/:username/unlock",
+			auth.AdminOnly(jwtKeys, auditedHandler(UnlockUserAccount)),
+		},
+		{
+			"GetApiTokens",
+			http.MethodGet,
+			"/account/:username/tokens",
+			auth.AdminOrMe(jwtKeys, GetApiTokens),
+		},
+		{
+			"CreateApiToken",
+			http.MethodPost,
+			"/account/:username/tokens",
+			auth.AdminOnly(jwtKeys, auditedHandler(CreateApiToken)),
+		},
+		{
+			"DeleteApiToken",
+			http.MethodDelete,
+			"/account/:username/tokens/:token-id",
+			auth.AdminOnly(jwtKeys, auditedHandler(DeleteApiToken)),
+		},
+		{
+			"AssignUserAccountRoles",
+			http.MethodPut,
+			"/account/:username/roles",
+			auth.AdminOnly(jwtKeys, auditedHandler(AssignUserAccountRoles)),
+		},
+		{
+			"GetRoles",
+			http.MethodGet,
+			"/role",
+			auth.AdminOnly(jwtKeys, GetRoles),
+		},
+		{
+			"GetRole",
+			http.MethodGet,
+			"/role/:role-name",
+			auth.AdminOnly(jwtKeys, GetRole),
+		},
+		{
+			"PostRole",
+			http.MethodPost,
+			"/role/:role-name",
+			auth.AdminOnly(jwtKeys, auditedHandler(PostRole)),
+		},
+		{
+			"PutRole",
+			http.MethodPut,
+			"/role/:role-name",
+			auth.AdminOnly(jwtKeys, auditedHandler(PutRole)),
+		},
+		{
+			"DeleteRole",
+			http.MethodDelete,
+			"/role/:role-name",
+			auth.AdminOnly(jwtKeys, auditedHandler(DeleteRole)),
 		},
 	}
 }
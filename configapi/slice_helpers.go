@@ -4,7 +4,9 @@
 package configapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -15,7 +17,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/omec-project/openapi/models"
-	"github.com/omec-project/webconsole/backend/factory"
 	"github.com/omec-project/webconsole/backend/logger"
 	"github.com/omec-project/webconsole/configmodels"
 	"github.com/omec-project/webconsole/dbadapter"
@@ -24,8 +25,8 @@ import (
 
 var execCommand = exec.Command
 
-func networkSliceDeleteHelper(sliceName string) error {
-	if err := handleNetworkSliceDelete(sliceName); err != nil {
+func networkSliceDeleteHelper(ctx context.Context, sliceName string) error {
+	if err := handleNetworkSliceDelete(ctx, sliceName); err != nil {
 		logger.ConfigLog.Errorf("Error deleting slice %s: %+v", sliceName, err)
 		return err
 	}
@@ -33,6 +34,7 @@ func networkSliceDeleteHelper(sliceName string) error {
 	msg.MsgMethod = configmodels.Delete_op
 	msg.MsgType = configmodels.Network_slice
 	msg.SliceName = sliceName
+	msg.RequestID = logger.RequestIDFromContext(ctx)
 	configChannel <- &msg
 	logger.ConfigLog.Infof("successfully Added Network Slice [%s] with delete_op to config channel", sliceName)
 	return nil
@@ -47,27 +49,46 @@ func networkSlicePostHelper(c *gin.Context, msgOp int, sliceName string) (int, e
 
 	logSliceMetadata(requestSlice)
 	normalizeApplicationFilteringRules(&requestSlice)
+	requestSlice.SiteInfo.NormalizeUpfs()
 	requestSlice.SliceName = sliceName
+	if err = validateSliceReferences(requestSlice); err != nil {
+		var refErr *referenceError
+		if errors.As(err, &refErr) {
+			return refErr.statusCode, err
+		}
+		return http.StatusInternalServerError, err
+	}
+	return networkSliceStoreHelper(c.Request.Context(), requestSlice, msgOp)
+}
+
+// networkSliceStoreHelper creates or updates a validated and normalized network
+// slice and sends it to the config channel. A POST only creates, it fails if
+// the network slice exists.
+func networkSliceStoreHelper(ctx context.Context, requestSlice configmodels.Slice, msgOp int) (int, error) {
+	sliceName := requestSlice.SliceName
 	prevSlice := getSliceByName(sliceName)
+	if msgOp == configmodels.Post_op && prevSlice != nil && prevSlice.SliceName != "" {
+		return http.StatusConflict, fmt.Errorf("network slice %s already exists", sliceName)
+	}
 
 	if prevSlice == nil {
 		logger.ConfigLog.Infof("Adding new slice [%s]", sliceName)
-		if statusCode, err := createNS(requestSlice); err != nil {
+		if statusCode, err := createNS(ctx, requestSlice); err != nil {
 			logger.ConfigLog.Errorf("Error creating slice %s: %+v", sliceName, err)
 			return statusCode, err
 		}
 	} else {
-		if statusCode, err := updateNS(requestSlice, *prevSlice); err != nil {
+		if statusCode, err := updateNS(ctx, requestSlice, *prevSlice); err != nil {
 			logger.ConfigLog.Errorf("Error updating slice %s: %+v", sliceName, err)
 			return statusCode, err
 		}
 	}
 	var msg configmodels.ConfigMessage
 	msg.MsgMethod = msgOp
-	requestSlice.SliceName = sliceName
 	msg.MsgType = configmodels.Network_slice
 	msg.Slice = &requestSlice
 	msg.SliceName = sliceName
+	msg.RequestID = logger.RequestIDFromContext(ctx)
 	configChannel <- &msg
 	logger.ConfigLog.Infof("successfully Added Slice [%s] to config channel", sliceName)
 	return http.StatusOK, nil
@@ -85,15 +106,19 @@ func parseAndValidateSliceRequest(c *gin.Context, sliceName string) (configmodel
 		return request, fmt.Errorf("JSON bind error: %+v", err)
 	}
 
-	for _, gnb := range request.SiteInfo.GNodeBs {
+	for i, gnb := range request.SiteInfo.GNodeBs {
 		if !isValidName(gnb.Name) {
-			return request, fmt.Errorf("invalid gNB name `%s` in Network Slice %s", gnb.Name, sliceName)
+			return request, newValidationError(fmt.Sprintf("site-info.gNodeBs[%d].name", i), "invalid gNB name `%s` in Network Slice %s", gnb.Name, sliceName)
 		}
 		if !isValidGnbTac(gnb.Tac) {
-			return request, fmt.Errorf("invalid TAC %d for gNB %s in Network Slice %s", gnb.Tac, gnb.Name, sliceName)
+			return request, newValidationError(fmt.Sprintf("site-info.gNodeBs[%d].tac", i), "invalid TAC %d for gNB %s in Network Slice %s", gnb.Tac, gnb.Name, sliceName)
 		}
 	}
 
+	if err := validateSliceUpfs(sliceName, request.SiteInfo.UpfList()); err != nil {
+		return request, err
+	}
+
 	slices.Sort(request.SiteDeviceGroup)
 	request.SiteDeviceGroup = slices.Compact(request.SiteDeviceGroup)
 
@@ -113,7 +138,9 @@ func logSliceMetadata(slice configmodels.Slice) {
 	for i, gnb := range site.GNodeBs {
 		logger.ConfigLog.Infof("gNB (%d): name=%s, tac=%d", i+1, gnb.Name, gnb.Tac)
 	}
-	logger.ConfigLog.Infof("site UPF: %s", site.Upf)
+	for i, upf := range site.UpfList() {
+		logger.ConfigLog.Infof("UPF (%d): name=%s, port=%s, priority=%d, weight=%d, dnns=%v", i+1, upf.UpfName, upf.UpfPort, upf.Priority, upf.Weight, upf.Dnns)
+	}
 }
 
 func normalizeApplicationFilteringRules(slice *configmodels.Slice) {
@@ -141,54 +168,48 @@ func convertBitrateToInt32(bitrate int64) int32 {
 	return int32(bitrate)
 }
 
-func createNS(slice configmodels.Slice) (int, error) {
-	if statusCode, err := handleNetworkSlicePost(slice, configmodels.Slice{}); err != nil {
+func createNS(ctx context.Context, slice configmodels.Slice) (int, error) {
+	if statusCode, err := handleNetworkSlicePost(ctx, slice, configmodels.Slice{}); err != nil {
 		logger.ConfigLog.Errorf("Error creating slice %s: %+v", slice.SliceName, err)
 		return statusCode, err
 	}
 	return http.StatusOK, nil
 }
 
-func updateNS(slice, prevSlice configmodels.Slice) (int, error) {
-	if statusCode, err := handleNetworkSlicePost(slice, prevSlice); err != nil {
+func updateNS(ctx context.Context, slice, prevSlice configmodels.Slice) (int, error) {
+	if statusCode, err := handleNetworkSlicePost(ctx, slice, prevSlice); err != nil {
 		logger.ConfigLog.Errorf("Error updating slice %s: %+v", slice.SliceName, err)
 		return statusCode, err
 	}
 	return http.StatusOK, nil
 }
 
-func handleNetworkSlicePost(slice configmodels.Slice, prevSlice configmodels.Slice) (int, error) {
+func handleNetworkSlicePost(ctx context.Context, slice configmodels.Slice, prevSlice configmodels.Slice) (int, error) {
 	filter := bson.M{"slice-name": slice.SliceName}
 	sliceDataBsonA := configmodels.ToBsonM(slice)
-	_, err := dbadapter.CommonDBClient.RestfulAPIPost(sliceDataColl, filter, sliceDataBsonA)
+	err := putResourceVersion(ctx, dbadapter.CommonDBClient, sliceDataColl, filter, sliceDataBsonA)
 	if err != nil {
 		logger.DbLog.Errorf("failed to post slice data for %s: %+v", slice.SliceName, err)
-		return http.StatusInternalServerError, err
+		return versionConflictStatus(err, http.StatusInternalServerError), err
 	}
 	logger.DbLog.Debugf("succeeded to post slice data for %s", slice.SliceName)
+	var prevSliceBsonA bson.M
+	if prevSlice.SliceName != "" {
+		prevSliceBsonA = configmodels.ToBsonM(prevSlice)
+	}
+	if err = recordConfigRevision(ctx, configmodels.ApplyKindNetworkSlice, slice.SliceName, prevSliceBsonA, sliceDataBsonA); err != nil {
+		logger.DbLog.Errorln(err)
+		return http.StatusInternalServerError, err
+	}
 
 	statusCode, err := syncSubscribersOnSliceCreateOrUpdate(slice, prevSlice)
 	if err != nil {
 		return statusCode, err
 	}
-	if factory.WebUIConfig.Configuration.SendPebbleNotifications {
-		err = sendPebbleNotification("aetherproject.org/webconsole/networkslice/create")
-		if err != nil {
-			logger.ConfigLog.Warnf("sending Pebble notification failed: %s. continuing silently", err.Error())
-		}
-	}
+	notifyConfigChanges(ctx, newConfigChange(configmodels.ApplyKindNetworkSlice, configChangeOperation(prevSlice.SliceName != ""), slice.SliceName))
 	return http.StatusOK, nil
 }
 
-func sendPebbleNotification(key string) error {
-	cmd := execCommand("pebble", "notify", key)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("couldn't execute a pebble notify: %+v", err)
-	}
-	logger.ConfigLog.Infoln("custom Pebble notification sent")
-	return nil
-}
-
 var syncSubscribersOnSliceDelete = func(slice *configmodels.Slice, prevSlice *configmodels.Slice) error {
 	rwLock.Lock()
 	defer rwLock.Unlock()
@@ -476,6 +497,40 @@ func getSlices() []*configmodels.Slice {
 	return slices
 }
 
+// MigrateNetworkSliceUpfs stores the UPF list of the network slices saved with
+// the single UPF shape only.
+func MigrateNetworkSliceUpfs() error {
+	filter := bson.M{
+		"site-info.upf.upf-name": bson.M{"$exists": true},
+		"site-info.upfs":         bson.M{"$exists": false},
+	}
+	rawSlices, err := dbadapter.CommonDBClient.RestfulAPIGetMany(sliceDataColl, filter)
+	if err != nil {
+		return fmt.Errorf("failed to fetch network slices: %w", err)
+	}
+	networkSlices, err := decodeDocuments[configmodels.Slice](rawSlices)
+	if err != nil {
+		return fmt.Errorf("failed to decode network slices: %w", err)
+	}
+	for _, networkSlice := range networkSlices {
+		if len(networkSlice.SiteInfo.Upfs) > 0 || len(networkSlice.SiteInfo.UpfList()) == 0 {
+			continue
+		}
+		prevSliceBsonA := configmodels.ToBsonM(networkSlice)
+		networkSlice.SiteInfo.NormalizeUpfs()
+		sliceFilter := bson.M{"slice-name": networkSlice.SliceName}
+		sliceDataBsonA := configmodels.ToBsonM(networkSlice)
+		if _, err = dbadapter.CommonDBClient.RestfulAPIPutOne(sliceDataColl, sliceFilter, sliceDataBsonA); err != nil {
+			return fmt.Errorf("failed to migrate UPFs of network slice %s: %w", networkSlice.SliceName, err)
+		}
+		if err = recordConfigRevision(context.Background(), configmodels.ApplyKindNetworkSlice, networkSlice.SliceName, prevSliceBsonA, sliceDataBsonA); err != nil {
+			return err
+		}
+		logger.DbLog.Infof("migrated UPFs of network slice %s", networkSlice.SliceName)
+	}
+	return nil
+}
+
 func getSliceByName(name string) *configmodels.Slice {
 	filter := bson.M{"slice-name": name}
 	sliceDataInterface, errGetOne := dbadapter.CommonDBClient.RestfulAPIGetOne(sliceDataColl, filter)
@@ -492,26 +547,27 @@ func getSliceByName(name string) *configmodels.Slice {
 	return &sliceData
 }
 
-func handleNetworkSliceDelete(sliceName string) error {
+func handleNetworkSliceDelete(ctx context.Context, sliceName string) error {
 	prevSlice := getSliceByName(sliceName)
 	filter := bson.M{"slice-name": sliceName}
-	err := dbadapter.CommonDBClient.RestfulAPIDeleteOne(sliceDataColl, filter)
+	err := deleteResourceVersion(ctx, dbadapter.CommonDBClient, sliceDataColl, filter)
 	if err != nil {
 		logger.DbLog.Errorf("failed to delete slice data for %+v: %+v", sliceName, err)
 		return err
 	}
+	if prevSlice != nil && prevSlice.SliceName != "" {
+		if err = recordConfigRevision(ctx, configmodels.ApplyKindNetworkSlice, sliceName, configmodels.ToBsonM(prevSlice), nil); err != nil {
+			logger.DbLog.Errorln(err)
+			return err
+		}
+	}
 	// slice is nil as it is deleted
 	if err = syncSubscribersOnSliceDelete(nil, prevSlice); err != nil {
 		logger.WebUILog.Errorf("failed to cleanup subscriber entries related to device groups %+v: %+v", sliceName, err)
 		return err
 	}
 	logger.DbLog.Debugf("succeeded to delete slice data for %s", sliceName)
-	if factory.WebUIConfig.Configuration.SendPebbleNotifications {
-		err = sendPebbleNotification("aetherproject.org/webconsole/networkslice/delete")
-		if err != nil {
-			logger.ConfigLog.Warnf("sending Pebble notification failed: %s. continuing silently", err.Error())
-		}
-	}
+	notifyConfigChanges(ctx, newConfigChange(configmodels.ApplyKindNetworkSlice, configmodels.ApplyOpDelete, sliceName))
 	return nil
 }
 
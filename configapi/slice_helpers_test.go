@@ -4,6 +4,7 @@
 package configapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -104,7 +105,7 @@ func Test_sendPebbleNotification_on_when_handleNetworkSlicePost(t *testing.T) {
 	}()
 	dbadapter.CommonDBClient = &MockMongoPost{}
 
-	statusCode, err := handleNetworkSlicePost(slice, prevSlice)
+	statusCode, err := handleNetworkSlicePost(context.Background(), slice, prevSlice)
 	if err != nil {
 		t.Errorf("Could not handle network slice post: %+v statusCode: %d", err, statusCode)
 	}
@@ -138,7 +139,7 @@ func Test_sendPebbleNotification_off_when_handleNetworkSlicePost(t *testing.T) {
 	}()
 	dbadapter.CommonDBClient = &MockMongoPost{}
 
-	statusCode, err := handleNetworkSlicePost(slice, prevSlice)
+	statusCode, err := handleNetworkSlicePost(context.Background(), slice, prevSlice)
 	if err != nil {
 		t.Errorf("handleNetworkSlicePost returned error: %+v statusCode: %d", err, statusCode)
 	}
@@ -166,10 +167,22 @@ func (m *MockMongoPost) RestfulAPIGetMany(collName string, filter bson.M) ([]map
 	return []map[string]interface{}{}, nil
 }
 
+func (m *MockMongoPost) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (m *MockMongoPost) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 func (m *MockMongoPost) Client() *mongo.Client {
 	return nil
 }
 
+func (m *MockMongoPost) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	return nil, nil
+}
+
 type MockCombinedDB struct {
 	dbadapter.DBInterface
 	testSlice configmodels.Slice
@@ -206,10 +219,22 @@ func (m *MockCombinedDB) RestfulAPIGetOne(collName string, filter bson.M) (map[s
 	return structToMap(m.testSlice)
 }
 
+func (m *MockCombinedDB) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (m *MockCombinedDB) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	return nil
+}
+
 func (m *MockCombinedDB) Client() *mongo.Client {
 	return nil
 }
 
+func (m *MockCombinedDB) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	return m.RestfulAPIPost(collName, filter, putData)
+}
+
 func Test_handleNetworkSlicePost(t *testing.T) {
 	networkSlices := []configmodels.Slice{
 		networkSlice("slice1"), networkSlice("slice2"),
@@ -232,7 +257,7 @@ func Test_handleNetworkSlicePost(t *testing.T) {
 			}()
 			dbadapter.CommonDBClient = mock
 
-			statusCode, postErr := handleNetworkSlicePost(testSlice, configmodels.Slice{})
+			statusCode, postErr := handleNetworkSlicePost(context.Background(), testSlice, configmodels.Slice{})
 
 			if postErr != nil {
 				t.Errorf("Could not handle network slice post: %+v status code: %d", postErr, statusCode)
@@ -247,7 +272,7 @@ func Test_handleNetworkSlicePost(t *testing.T) {
 				t.Errorf("Expected collection %v, got %v", expected_collection, postData[0]["coll"])
 			}
 
-			expected_filter := bson.M{"slice-name": testSlice.SliceName}
+			expected_filter := versionFilter(bson.M{"slice-name": testSlice.SliceName}, 0)
 			if !reflect.DeepEqual(postData[0]["filter"], expected_filter) {
 				t.Errorf("Expected filter %v, got %v", expected_filter, postData[0]["filter"])
 			}
@@ -308,7 +333,7 @@ func Test_handleNetworkSlicePost_alreadyExists(t *testing.T) {
 			}()
 			dbadapter.CommonDBClient = mock
 
-			statusCode, err := handleNetworkSlicePost(ts, ts)
+			statusCode, err := handleNetworkSlicePost(context.Background(), ts, ts)
 			if err != nil {
 				t.Fatalf("handleNetworkSlicePost returned error: %+v status code: %d", err, statusCode)
 			}
@@ -321,7 +346,7 @@ func Test_handleNetworkSlicePost_alreadyExists(t *testing.T) {
 				t.Errorf("Expected collection %v, got %v", sliceDataColl, postData[0]["coll"])
 			}
 
-			expectedFilter := bson.M{"slice-name": ts.SliceName}
+			expectedFilter := versionFilter(bson.M{"slice-name": ts.SliceName}, 0)
 			if !reflect.DeepEqual(postData[0]["filter"], expectedFilter) {
 				t.Errorf("Expected filter %v, got %v", expectedFilter, postData[0]["filter"])
 			}
@@ -445,7 +470,10 @@ func TestNetworkSlicePostHandler_NetworkSliceNameValidation(t *testing.T) {
 			originalDBClient := dbadapter.CommonDBClient
 			defer func() { configChannel = origChannel; dbadapter.CommonDBClient = originalDBClient }()
 			if tc.expectedCode == http.StatusOK {
-				dbadapter.CommonDBClient = &MockMongoClientEmptyDB{}
+				dbadapter.CommonDBClient = &MockMongoClientSliceInventory{
+					gnbs:         []configmodels.Gnb{{Name: "string"}},
+					deviceGroups: []configmodels.DeviceGroups{{DeviceGroupName: "string"}},
+				}
 			}
 			req, err := http.NewRequest(http.MethodPost, tc.route, strings.NewReader(NETWORK_SLICE_CONFIG))
 			if err != nil {
@@ -512,3 +540,153 @@ func TestNetworkSlicePostHandler_NetworkSliceGnbTacValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkSlicePostHandler_NetworkSliceUpfs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	AddConfigV1Service(router)
+
+	testCases := []struct {
+		name          string
+		inputData     string
+		expectedCode  int
+		expectedError string
+		expectedUpfs  []configmodels.SliceSiteInfoUpf
+		expectedUpf   map[string]interface{}
+	}{
+		{
+			name:         "single UPF shape",
+			inputData:    `{"slice-id": {"sst": "1", "sd": "010203"}, "site-info": {"site-name": "demo", "gNodeBs": [], "upf": {"upf-name": "upf1.my-domain.com", "upf-port": "8805"}}}`,
+			expectedCode: http.StatusOK,
+			expectedUpfs: []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", UpfPort: "8805"}},
+			expectedUpf:  map[string]interface{}{"upf-name": "upf1.my-domain.com", "upf-port": "8805"},
+		},
+		{
+			name: "UPF list is ordered by priority",
+			inputData: `{"slice-id": {"sst": "1", "sd": "010203"}, "site-info": {"site-name": "demo", "gNodeBs": [], "upfs": [
+				{"upf-name": "upf2.my-domain.com", "priority": 2, "weight": 10},
+				{"upf-name": "upf1.my-domain.com", "upf-port": "8805", "priority": 1, "weight": 90, "dnns": ["internet"]}]}}`,
+			expectedCode: http.StatusOK,
+			expectedUpfs: []configmodels.SliceSiteInfoUpf{
+				{UpfName: "upf1.my-domain.com", UpfPort: "8805", Priority: 1, Weight: 90, Dnns: []string{"internet"}},
+				{UpfName: "upf2.my-domain.com", Priority: 2, Weight: 10},
+			},
+			expectedUpf: map[string]interface{}{"upf-name": "upf1.my-domain.com", "upf-port": "8805"},
+		},
+		{
+			name:          "duplicate UPF",
+			inputData:     `{"slice-id": {"sst": "1"}, "site-info": {"gNodeBs": [], "upfs": [{"upf-name": "upf1.my-domain.com"}, {"upf-name": "upf1.my-domain.com"}]}}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "duplicate UPF upf1.my-domain.com in Network Slice slice-1",
+		},
+		{
+			name:          "invalid UPF priority",
+			inputData:     `{"slice-id": {"sst": "1"}, "site-info": {"gNodeBs": [], "upfs": [{"upf-name": "upf1.my-domain.com", "priority": -1}]}}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid priority -1 for UPF upf1.my-domain.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			origChannel := configChannel
+			configChannel = make(chan *configmodels.ConfigMessage, 1)
+			originalDBClient := dbadapter.CommonDBClient
+			defer func() { configChannel = origChannel; dbadapter.CommonDBClient = originalDBClient }()
+			dbadapter.CommonDBClient = &MockMongoClientSliceInventory{
+				upfs: []configmodels.Upf{{Hostname: "upf1.my-domain.com"}, {Hostname: "upf2.my-domain.com"}},
+			}
+			req, err := http.NewRequest(http.MethodPost, "/config/v1/network-slice/slice-1", strings.NewReader(tc.inputData))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+			if tc.expectedCode != w.Code {
+				t.Fatalf("Expected `%v`, got `%v` with body `%v`", tc.expectedCode, w.Code, w.Body.String())
+			}
+			if tc.expectedCode != http.StatusOK {
+				if !strings.Contains(w.Body.String(), tc.expectedError) {
+					t.Errorf("Expected body to contain error about `%v`, got `%v`", tc.expectedError, w.Body.String())
+				}
+				return
+			}
+			msg := <-configChannel
+			if !reflect.DeepEqual(tc.expectedUpfs, msg.Slice.SiteInfo.Upfs) {
+				t.Errorf("Expected UPFs %+v, got %+v", tc.expectedUpfs, msg.Slice.SiteInfo.Upfs)
+			}
+			if !reflect.DeepEqual(tc.expectedUpf, msg.Slice.SiteInfo.Upf) {
+				t.Errorf("Expected UPF %+v, got %+v", tc.expectedUpf, msg.Slice.SiteInfo.Upf)
+			}
+		})
+	}
+}
+
+type MockMongoClientSliceUpfMigration struct {
+	dbadapter.DBInterface
+	slices    []configmodels.Slice
+	putData   map[string]configmodels.Slice
+	revisions []interface{}
+}
+
+func (m *MockMongoClientSliceUpfMigration) RestfulAPIGetManyPaged(coll string, filter bson.M, sortBy bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockMongoClientSliceUpfMigration) RestfulAPIPostManyWithContext(ctx context.Context, coll string, filter bson.M, data []interface{}) error {
+	m.revisions = append(m.revisions, data...)
+	return nil
+}
+
+func (m *MockMongoClientSliceUpfMigration) RestfulAPIGetMany(coll string, filter bson.M) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	for _, slice := range m.slices {
+		results = append(results, configmodels.ToBsonM(slice))
+	}
+	return results, nil
+}
+
+func (m *MockMongoClientSliceUpfMigration) RestfulAPIPutOne(coll string, filter bson.M, data map[string]interface{}) (bool, error) {
+	var slice configmodels.Slice
+	if err := json.Unmarshal(configmodels.MapToByte(data), &slice); err != nil {
+		return false, err
+	}
+	m.putData[filter["slice-name"].(string)] = slice
+	return true, nil
+}
+
+func TestMigrateNetworkSliceUpfs(t *testing.T) {
+	legacySlice := networkSlice("legacy")
+	legacySlice.SiteInfo.Upf = map[string]interface{}{"upf-name": "upf1.my-domain.com", "upf-port": "8805"}
+	migratedSlice := networkSlice("migrated")
+	migratedSlice.SiteInfo.SetUpfs([]configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com"}, {UpfName: "upf2.my-domain.com", Priority: 1}})
+	noUpfSlice := networkSlice("no-upf")
+	noUpfSlice.SiteInfo.Upf = nil
+	mock := &MockMongoClientSliceUpfMigration{
+		slices:  []configmodels.Slice{legacySlice, migratedSlice, noUpfSlice},
+		putData: map[string]configmodels.Slice{},
+	}
+	originalDBClient := dbadapter.CommonDBClient
+	defer func() { dbadapter.CommonDBClient = originalDBClient }()
+	dbadapter.CommonDBClient = mock
+
+	if err := MigrateNetworkSliceUpfs(); err != nil {
+		t.Fatalf("failed to migrate network slices: %v", err)
+	}
+	if len(mock.putData) != 1 {
+		t.Fatalf("expected only the legacy slice to be migrated, got %+v", mock.putData)
+	}
+	expected := legacySlice
+	expected.SiteInfo.Upfs = []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", UpfPort: "8805"}}
+	if !reflect.DeepEqual(expected, mock.putData["legacy"]) {
+		t.Errorf("expected migrated slice %+v, got %+v", expected, mock.putData["legacy"])
+	}
+	if len(mock.revisions) != 1 {
+		t.Fatalf("expected the migration to be recorded as a revision, got %+v", mock.revisions)
+	}
+	if revision := mock.revisions[0].(bson.M); revision["name"] != "legacy" || documentInt(revision, "revision") != 1 {
+		t.Errorf("expected revision 1 of the legacy slice, got %+v", revision)
+	}
+}
@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/omec-project/openapi/models"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxBulkSubscribers   = 10000
+	keyHexLength         = 32
+	sequenceNumberLength = 12
+)
+
+// bulkSubscriberRow is a validated row of a bulk provisioning request.
+type bulkSubscriberRow struct {
+	index        int
+	ueId         string
+	authSubsData *models.AuthenticationSubscription
+}
+
+func newAuthenticationSubscription(opc, key, sequenceNumber string) models.AuthenticationSubscription {
+	return models.AuthenticationSubscription{
+		AuthenticationManagementField: "8000",
+		AuthenticationMethod:          "5G_AKA",
+		Milenage: &models.Milenage{
+			Op: &models.Op{
+				EncryptionAlgorithm: 0,
+				EncryptionKey:       0,
+				OpValue:             "",
+			},
+		},
+		Opc: &models.Opc{
+			EncryptionAlgorithm: 0,
+			EncryptionKey:       0,
+			OpcValue:            opc,
+		},
+		PermanentKey: &models.PermanentKey{
+			EncryptionAlgorithm: 0,
+			EncryptionKey:       0,
+			PermanentKeyValue:   key,
+		},
+		SequenceNumber: sequenceNumber,
+	}
+}
+
+// parseBulkSubscriberJSON accepts either a JSON array of subscribers
+// or a single range specification object.
+func parseBulkSubscriberJSON(body []byte) ([]configmodels.BulkSubscriber, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, errors.New("request body is empty")
+	}
+	if trimmed[0] == '[' {
+		var subscribers []configmodels.BulkSubscriber
+		if err := json.Unmarshal(trimmed, &subscribers); err != nil {
+			return nil, fmt.Errorf("invalid subscriber list: %w", err)
+		}
+		return subscribers, nil
+	}
+	var subscriberRange configmodels.BulkSubscriberRange
+	if err := json.Unmarshal(trimmed, &subscriberRange); err != nil {
+		return nil, fmt.Errorf("invalid range specification: %w", err)
+	}
+	return expandBulkSubscriberRange(subscriberRange)
+}
+
+// parseBulkSubscriberCSV reads imsi,ki,opc,sqn records. A leading header row is skipped.
+func parseBulkSubscriberCSV(body []byte) ([]configmodels.BulkSubscriber, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+	var subscribers []configmodels.BulkSubscriber
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(subscribers) == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "imsi") {
+			continue
+		}
+		subscribers = append(subscribers, configmodels.BulkSubscriber{
+			UeId: strings.TrimSpace(record[0]),
+			SubsOverrideData: configmodels.SubsOverrideData{
+				Key:            strings.TrimSpace(record[1]),
+				OPc:            strings.TrimSpace(record[2]),
+				SequenceNumber: strings.TrimSpace(record[3]),
+			},
+		})
+	}
+	return subscribers, nil
+}
+
+func expandBulkSubscriberRange(subscriberRange configmodels.BulkSubscriberRange) ([]configmodels.BulkSubscriber, error) {
+	startImsi := strings.TrimPrefix(subscriberRange.StartImsi, "imsi-")
+	if !isValidImsi(startImsi) {
+		return nil, fmt.Errorf("invalid start IMSI: %s", subscriberRange.StartImsi)
+	}
+	if subscriberRange.Count < 1 || subscriberRange.Count > maxBulkSubscribers {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxBulkSubscribers)
+	}
+	if !isValidHexValue(subscriberRange.SequenceNumber, sequenceNumberLength) {
+		return nil, fmt.Errorf("sequenceNumber must be %d hexadecimal characters", sequenceNumberLength)
+	}
+	start, err := strconv.ParseUint(startImsi, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start IMSI: %s", subscriberRange.StartImsi)
+	}
+	width := len(startImsi)
+	subscribers := make([]configmodels.BulkSubscriber, 0, subscriberRange.Count)
+	for i := 0; i < subscriberRange.Count; i++ {
+		imsi := fmt.Sprintf("%0*d", width, start+uint64(i))
+		if len(imsi) != width {
+			return nil, fmt.Errorf("IMSI range starting at %s overflows after %d entries", startImsi, i)
+		}
+		key, opc, err := deriveSubscriberKeys(subscriberRange.KeyDerivation, imsi)
+		if err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, configmodels.BulkSubscriber{
+			UeId: "imsi-" + imsi,
+			SubsOverrideData: configmodels.SubsOverrideData{
+				Key:            key,
+				OPc:            opc,
+				SequenceNumber: subscriberRange.SequenceNumber,
+			},
+		})
+	}
+	return subscribers, nil
+}
+
+func deriveSubscriberKeys(derivation configmodels.BulkKeyDerivation, imsi string) (string, string, error) {
+	var key []byte
+	switch derivation.Method {
+	case configmodels.BulkKeyDerivationStatic:
+		if !isValidHexValue(derivation.Key, keyHexLength) {
+			return "", "", fmt.Errorf("key must be %d hexadecimal characters", keyHexLength)
+		}
+		key, _ = hex.DecodeString(derivation.Key)
+	case configmodels.BulkKeyDerivationHmacSha256:
+		masterKey, err := hex.DecodeString(derivation.MasterKey)
+		if err != nil || len(masterKey) < aes.BlockSize {
+			return "", "", fmt.Errorf("masterKey must be at least %d hexadecimal characters", keyHexLength)
+		}
+		mac := hmac.New(sha256.New, masterKey)
+		mac.Write([]byte(imsi))
+		key = mac.Sum(nil)[:aes.BlockSize]
+	default:
+		return "", "", fmt.Errorf("unsupported key derivation method: %s", derivation.Method)
+	}
+
+	switch {
+	case derivation.OPc != "" && derivation.OP != "":
+		return "", "", errors.New("only one of opc and op can be provided")
+	case derivation.OPc != "":
+		if !isValidHexValue(derivation.OPc, keyHexLength) {
+			return "", "", fmt.Errorf("opc must be %d hexadecimal characters", keyHexLength)
+		}
+		return hex.EncodeToString(key), strings.ToLower(derivation.OPc), nil
+	case derivation.OP != "":
+		if !isValidHexValue(derivation.OP, keyHexLength) {
+			return "", "", fmt.Errorf("op must be %d hexadecimal characters", keyHexLength)
+		}
+		op, _ := hex.DecodeString(derivation.OP)
+		opc, err := computeOPc(key, op)
+		if err != nil {
+			return "", "", err
+		}
+		return hex.EncodeToString(key), hex.EncodeToString(opc), nil
+	default:
+		return "", "", errors.New("either opc or op must be provided")
+	}
+}
+
+// computeOPc derives OPc = AES-128(K, OP) XOR OP as specified in 3GPP TS 35.206.
+func computeOPc(key, op []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute OPc: %w", err)
+	}
+	opc := make([]byte, aes.BlockSize)
+	block.Encrypt(opc, op)
+	for i := range opc {
+		opc[i] ^= op[i]
+	}
+	return opc, nil
+}
+
+func validateBulkSubscriber(subscriber configmodels.BulkSubscriber) (string, error) {
+	imsi := strings.TrimPrefix(subscriber.UeId, "imsi-")
+	if !isValidImsi(imsi) {
+		return "", fmt.Errorf("invalid IMSI: %s", subscriber.UeId)
+	}
+	if !isValidHexValue(subscriber.Key, keyHexLength) {
+		return "", fmt.Errorf("key must be %d hexadecimal characters", keyHexLength)
+	}
+	if !isValidHexValue(subscriber.OPc, keyHexLength) {
+		return "", fmt.Errorf("opc must be %d hexadecimal characters", keyHexLength)
+	}
+	if !isValidHexValue(subscriber.SequenceNumber, sequenceNumberLength) {
+		return "", fmt.Errorf("sequenceNumber must be %d hexadecimal characters", sequenceNumberLength)
+	}
+	return "imsi-" + imsi, nil
+}
+
+// handleSubscriberBulkPost validates every requested subscriber and creates the valid
+// ones, optionally adding them to deviceGroup, in a single transaction. It returns the
+// per-row report, the rows that were written and the updated device group if any.
+func handleSubscriberBulkPost(ctx context.Context, subscribers []configmodels.BulkSubscriber, deviceGroup *configmodels.DeviceGroups) (*configmodels.BulkSubscriberReport, []*bulkSubscriberRow, *configmodels.DeviceGroups, error) {
+	report := &configmodels.BulkSubscriberReport{
+		Results: make([]configmodels.BulkSubscriberResult, len(subscribers)),
+	}
+	var rows []*bulkSubscriberRow
+	seen := make(map[string]bool, len(subscribers))
+	for i, subscriber := range subscribers {
+		result := &report.Results[i]
+		result.Row = i + 1
+		result.UeId = subscriber.UeId
+		ueId, err := validateBulkSubscriber(subscriber)
+		if err != nil {
+			result.Status = configmodels.BulkResultInvalid
+			result.Error = err.Error()
+			continue
+		}
+		result.UeId = ueId
+		if seen[ueId] {
+			result.Status = configmodels.BulkResultInvalid
+			result.Error = fmt.Sprintf("subscriber %s appears more than once in the request", ueId)
+			continue
+		}
+		seen[ueId] = true
+		authSubsData := newAuthenticationSubscription(strings.ToLower(subscriber.OPc), strings.ToLower(subscriber.Key), strings.ToLower(subscriber.SequenceNumber))
+		rows = append(rows, &bulkSubscriberRow{index: i, ueId: ueId, authSubsData: &authSubsData})
+	}
+
+	rows, err := filterExistingBulkSubscribers(rows, report)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var updatedDeviceGroup *configmodels.DeviceGroups
+	if deviceGroup != nil && len(rows) > 0 {
+		updatedDeviceGroup = addImsisToDeviceGroup(deviceGroup, rows)
+		report.DeviceGroup = deviceGroup.DeviceGroupName
+	}
+	if len(rows) > 0 {
+		if err = postBulkSubscribers(ctx, rows, deviceGroup, updatedDeviceGroup); err != nil {
+			logger.DbLog.Errorf("failed to create subscribers in bulk: %+v", err)
+			for _, row := range rows {
+				report.Results[row.index].Status = configmodels.BulkResultFailed
+				report.Results[row.index].Error = "transaction failed, no subscriber was created"
+			}
+			report.Rejected = len(subscribers)
+			return report, nil, nil, err
+		}
+	}
+	for _, row := range rows {
+		report.Results[row.index].Status = configmodels.BulkResultCreated
+	}
+	report.Created = len(rows)
+	report.Rejected = len(subscribers) - len(rows)
+	return report, rows, updatedDeviceGroup, nil
+}
+
+func filterExistingBulkSubscribers(rows []*bulkSubscriberRow, report *configmodels.BulkSubscriberReport) ([]*bulkSubscriberRow, error) {
+	if len(rows) == 0 {
+		return rows, nil
+	}
+	ueIds := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ueIds = append(ueIds, row.ueId)
+	}
+	existingSubscribers, err := dbadapter.CommonDBClient.RestfulAPIGetMany(amDataColl, bson.M{"ueId": bson.M{"$in": ueIds}})
+	if err != nil {
+		logger.DbLog.Errorf("failed to query existing subscribers: %+v", err)
+		return nil, err
+	}
+	existing := make(map[string]bool, len(existingSubscribers))
+	for _, subscriber := range existingSubscribers {
+		if ueId, ok := subscriber["ueId"].(string); ok {
+			existing[ueId] = true
+		}
+	}
+	return slices.DeleteFunc(rows, func(row *bulkSubscriberRow) bool {
+		if !existing[row.ueId] {
+			return false
+		}
+		report.Results[row.index].Status = configmodels.BulkResultConflict
+		report.Results[row.index].Error = fmt.Sprintf("subscriber %s already exists", row.ueId)
+		return true
+	}), nil
+}
+
+func addImsisToDeviceGroup(deviceGroup *configmodels.DeviceGroups, rows []*bulkSubscriberRow) *configmodels.DeviceGroups {
+	updatedDeviceGroup := *deviceGroup
+	updatedDeviceGroup.Imsis = slices.Clone(deviceGroup.Imsis)
+	for _, row := range rows {
+		imsi := strings.TrimPrefix(row.ueId, "imsi-")
+		if !slices.Contains(updatedDeviceGroup.Imsis, imsi) {
+			updatedDeviceGroup.Imsis = append(updatedDeviceGroup.Imsis, imsi)
+		}
+	}
+	return &updatedDeviceGroup
+}
+
+// postBulkSubscribers writes the authentication data and amData of all rows, and the
+// device group if provided along with its revision, in a single transaction of
+// the request. prevDeviceGroup is the device group before the update.
+func postBulkSubscribers(ctx context.Context, rows []*bulkSubscriberRow, prevDeviceGroup, deviceGroup *configmodels.DeviceGroups) error {
+	rwLock.Lock()
+	defer rwLock.Unlock()
+	authDocs := make([]interface{}, 0, len(rows))
+	amDocs := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		authDataBsonA := configmodels.ToBsonM(row.authSubsData)
+		authDataBsonA["ueId"] = row.ueId
+		authDocs = append(authDocs, authDataBsonA)
+		amDocs = append(amDocs, configmodels.ToBsonM(map[string]interface{}{"ueId": row.ueId}))
+	}
+	return runAuthAndCommonTransactionWithContext(ctx,
+		func(authSc mongo.SessionContext) error {
+			if err := dbadapter.AuthDBClient.RestfulAPIPostManyWithContext(authSc, authSubsDataColl, bson.M{}, authDocs); err != nil {
+				return fmt.Errorf("failed to insert authentication subscriptions: %w", err)
+			}
+			return nil
+		},
+		func(commonSc mongo.SessionContext) error {
+			if err := dbadapter.CommonDBClient.RestfulAPIPostManyWithContext(commonSc, amDataColl, bson.M{}, amDocs); err != nil {
+				return fmt.Errorf("failed to insert amData: %w", err)
+			}
+			if deviceGroup == nil {
+				return nil
+			}
+			filter := bson.M{"group-name": deviceGroup.DeviceGroupName}
+			if err := claimResourceVersion(commonSc, dbadapter.CommonDBClient, devGroupDataColl, filter); err != nil {
+				return fmt.Errorf("failed to update device group %s: %w", deviceGroup.DeviceGroupName, err)
+			}
+			devGroupDataBsonA := configmodels.ToBsonM(deviceGroup)
+			if _, err := dbadapter.CommonDBClient.RestfulAPIPutOneWithContext(commonSc, devGroupDataColl, filter, devGroupDataBsonA); err != nil {
+				return fmt.Errorf("failed to update device group %s: %w", deviceGroup.DeviceGroupName, err)
+			}
+			return recordConfigRevision(commonSc, configmodels.ApplyKindDeviceGroup, deviceGroup.DeviceGroupName, configmodels.ToBsonM(prevDeviceGroup), devGroupDataBsonA)
+		},
+	)
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+func TestComputeOPc(t *testing.T) {
+	// 3GPP TS 35.208 test set 1
+	key, _ := hex.DecodeString("465b5ce8b199b49faa5f0a2ee238a6bc")
+	op, _ := hex.DecodeString("cdc202d5123e20f62b6d676ac72cb318")
+	opc, err := computeOPc(key, op)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expectedOPc := "cd63cb71954a9f4e48a5994e37a02baf"
+	if hex.EncodeToString(opc) != expectedOPc {
+		t.Errorf("expected OPc %s, got %x", expectedOPc, opc)
+	}
+}
+
+func TestExpandBulkSubscriberRange(t *testing.T) {
+	testCases := []struct {
+		name            string
+		subscriberRange configmodels.BulkSubscriberRange
+		expectedUeIds   []string
+		expectedKeys    []string
+		expectedOPcs    []string
+		expectedErr     string
+	}{
+		{
+			name: "static keys",
+			subscriberRange: configmodels.BulkSubscriberRange{
+				StartImsi:      "001010000000009",
+				Count:          2,
+				SequenceNumber: "16f3b3f70fc2",
+				KeyDerivation: configmodels.BulkKeyDerivation{
+					Method: configmodels.BulkKeyDerivationStatic,
+					Key:    "465b5ce8b199b49faa5f0a2ee238a6bc",
+					OP:     "cdc202d5123e20f62b6d676ac72cb318",
+				},
+			},
+			expectedUeIds: []string{"imsi-001010000000009", "imsi-001010000000010"},
+			expectedKeys:  []string{"465b5ce8b199b49faa5f0a2ee238a6bc", "465b5ce8b199b49faa5f0a2ee238a6bc"},
+			expectedOPcs:  []string{"cd63cb71954a9f4e48a5994e37a02baf", "cd63cb71954a9f4e48a5994e37a02baf"},
+		},
+		{
+			name: "derived keys",
+			subscriberRange: configmodels.BulkSubscriberRange{
+				StartImsi:      "imsi-001010000000001",
+				Count:          2,
+				SequenceNumber: "16f3b3f70fc2",
+				KeyDerivation: configmodels.BulkKeyDerivation{
+					Method:    configmodels.BulkKeyDerivationHmacSha256,
+					MasterKey: "000102030405060708090a0b0c0d0e0f",
+					OPc:       "981d464c7c52eb6e5036234984ad0bcf",
+				},
+			},
+			expectedUeIds: []string{"imsi-001010000000001", "imsi-001010000000002"},
+			expectedOPcs:  []string{"981d464c7c52eb6e5036234984ad0bcf", "981d464c7c52eb6e5036234984ad0bcf"},
+		},
+		{
+			name: "range overflows IMSI length",
+			subscriberRange: configmodels.BulkSubscriberRange{
+				StartImsi:      "99998",
+				Count:          3,
+				SequenceNumber: "16f3b3f70fc2",
+				KeyDerivation: configmodels.BulkKeyDerivation{
+					Method: configmodels.BulkKeyDerivationStatic,
+					Key:    "465b5ce8b199b49faa5f0a2ee238a6bc",
+					OPc:    "981d464c7c52eb6e5036234984ad0bcf",
+				},
+			},
+			expectedErr: "IMSI range starting at 99998 overflows after 2 entries",
+		},
+		{
+			name: "missing OP and OPc",
+			subscriberRange: configmodels.BulkSubscriberRange{
+				StartImsi:      "001010000000001",
+				Count:          1,
+				SequenceNumber: "16f3b3f70fc2",
+				KeyDerivation: configmodels.BulkKeyDerivation{
+					Method: configmodels.BulkKeyDerivationStatic,
+					Key:    "465b5ce8b199b49faa5f0a2ee238a6bc",
+				},
+			},
+			expectedErr: "either opc or op must be provided",
+		},
+		{
+			name: "unknown derivation method",
+			subscriberRange: configmodels.BulkSubscriberRange{
+				StartImsi:      "001010000000001",
+				Count:          1,
+				SequenceNumber: "16f3b3f70fc2",
+				KeyDerivation:  configmodels.BulkKeyDerivation{Method: "milenage"},
+			},
+			expectedErr: "unsupported key derivation method: milenage",
+		},
+		{
+			name: "count too large",
+			subscriberRange: configmodels.BulkSubscriberRange{
+				StartImsi:      "001010000000001",
+				Count:          maxBulkSubscribers + 1,
+				SequenceNumber: "16f3b3f70fc2",
+			},
+			expectedErr: "count must be between 1 and 10000",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subscribers, err := expandBulkSubscriberRange(tc.subscriberRange)
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(subscribers) != len(tc.expectedUeIds) {
+				t.Fatalf("expected %d subscribers, got %d", len(tc.expectedUeIds), len(subscribers))
+			}
+			for i, subscriber := range subscribers {
+				if subscriber.UeId != tc.expectedUeIds[i] {
+					t.Errorf("expected ueId %s, got %s", tc.expectedUeIds[i], subscriber.UeId)
+				}
+				if tc.expectedKeys != nil && subscriber.Key != tc.expectedKeys[i] {
+					t.Errorf("expected key %s, got %s", tc.expectedKeys[i], subscriber.Key)
+				}
+				if subscriber.OPc != tc.expectedOPcs[i] {
+					t.Errorf("expected OPc %s, got %s", tc.expectedOPcs[i], subscriber.OPc)
+				}
+				if _, err := validateBulkSubscriber(subscriber); err != nil {
+					t.Errorf("expanded subscriber is not valid: %v", err)
+				}
+			}
+			if tc.expectedKeys == nil && subscribers[0].Key == subscribers[1].Key {
+				t.Error("expected derived keys to differ per IMSI")
+			}
+		})
+	}
+}
+
+func TestParseBulkSubscriberCSV(t *testing.T) {
+	body := strings.Join([]string{
+		"imsi,ki,opc,sqn",
+		"001010000000001, 465b5ce8b199b49faa5f0a2ee238a6bc, cd63cb71954a9f4e48a5994e37a02baf, 16f3b3f70fc2",
+		"imsi-001010000000002,465b5ce8b199b49faa5f0a2ee238a6bd,cd63cb71954a9f4e48a5994e37a02bae,16f3b3f70fc2",
+	}, "\n")
+	subscribers, err := parseBulkSubscriberCSV([]byte(body))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(subscribers) != 2 {
+		t.Fatalf("expected 2 subscribers, got %d", len(subscribers))
+	}
+	expected := configmodels.BulkSubscriber{
+		UeId: "001010000000001",
+		SubsOverrideData: configmodels.SubsOverrideData{
+			Key:            "465b5ce8b199b49faa5f0a2ee238a6bc",
+			OPc:            "cd63cb71954a9f4e48a5994e37a02baf",
+			SequenceNumber: "16f3b3f70fc2",
+		},
+	}
+	if subscribers[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, subscribers[0])
+	}
+	if subscribers[1].UeId != "imsi-001010000000002" {
+		t.Errorf("expected ueId imsi-001010000000002, got %s", subscribers[1].UeId)
+	}
+
+	if _, err = parseBulkSubscriberCSV([]byte("001010000000001,465b5ce8b199b49faa5f0a2ee238a6bc")); err == nil {
+		t.Error("expected error for record with missing fields")
+	}
+}
@@ -5,11 +5,18 @@ package configapi
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/omec-project/openapi/models"
 	"github.com/omec-project/webconsole/backend/logger"
 	"github.com/omec-project/webconsole/configmodels"
@@ -21,8 +28,56 @@ import (
 type SubscriberAuthenticationData interface {
 	SubscriberAuthenticationDataGet(imsi string) (authSubData *models.AuthenticationSubscription)
 	SubscriberAuthenticationDataCreate(imsi string, authSubData *models.AuthenticationSubscription) error
-	SubscriberAuthenticationDataUpdate(imsi string, authSubData *models.AuthenticationSubscription) error
-	SubscriberAuthenticationDataDelete(imsi string) error
+	SubscriberAuthenticationDataUpdate(ctx context.Context, imsi string, authSubData *models.AuthenticationSubscription) error
+	SubscriberAuthenticationDataDelete(ctx context.Context, imsi string) error
+}
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+
+	authSubsPatchKey = "AuthenticationSubscription"
+	amDataPatchKey   = "AccessAndMobilitySubscriptionData"
+)
+
+// subscriberPatch is a partial subscriber update split by the collection it applies to.
+// Only the merge patch or the JSON patch fields are set, depending on the request content type.
+type subscriberPatch struct {
+	authSubsMergePatch map[string]interface{}
+	amDataMergePatch   map[string]interface{}
+	authSubsJSONPatch  []byte
+	amDataJSONPatch    []byte
+}
+
+func (patch *subscriberPatch) hasAuthSubsChanges() bool {
+	return patch.authSubsMergePatch != nil || patch.authSubsJSONPatch != nil
+}
+
+func (patch *subscriberPatch) hasAmDataChanges() bool {
+	return patch.amDataMergePatch != nil || patch.amDataJSONPatch != nil
+}
+
+const (
+	defaultSubscriberPageSize = 100
+	maxSubscriberPageSize     = 1000
+)
+
+var subscriberListQueryParams = []string{"limit", "page_token", "imsi_prefix", "imsi_from", "imsi_to", "plmn_id", "device_group"}
+
+var (
+	imsiPrefixPattern = regexp.MustCompile(`^[0-9]{1,15}$`)
+	plmnIdPattern     = regexp.MustCompile(`^[0-9]{5,6}$`)
+)
+
+// subscriberListQuery holds the paging and filter parameters of a subscriber list request.
+type subscriberListQuery struct {
+	limit       int64
+	after       string
+	imsiPrefix  string
+	imsiFrom    string
+	imsiTo      string
+	plmnId      string
+	deviceGroup string
 }
 
 type DatabaseSubscriberAuthenticationData struct {
@@ -95,10 +150,18 @@ func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthent
 	return nil
 }
 
-func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthenticationDataUpdate(imsi string, authSubData *models.AuthenticationSubscription) error {
+// SubscriberAuthenticationDataUpdate writes the authentication data of the
+// subscriber. The amData write increments the version of the subscriber only
+// if it is still the one read before the AuthDB write, which is rolled back
+// otherwise.
+func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthenticationDataUpdate(ctx context.Context, imsi string, authSubData *models.AuthenticationSubscription) error {
 	filter := bson.M{"ueId": imsi}
 	authDataBsonA := configmodels.ToBsonM(authSubData)
 	authDataBsonA["ueId"] = imsi
+	amData, err := fetchResourceVersion(ctx, dbadapter.CommonDBClient, amDataColl, filter)
+	if err != nil {
+		return err
+	}
 	// get backup
 	backup, err := dbadapter.AuthDBClient.RestfulAPIGetOne(authSubsDataColl, filter)
 	if err != nil {
@@ -113,13 +176,12 @@ func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthent
 	// write to CommonDB
 	basicAmData := map[string]interface{}{"ueId": imsi}
 	basicDataBson := configmodels.ToBsonM(basicAmData)
-	if _, err = dbadapter.CommonDBClient.RestfulAPIPutOne(amDataColl, filter, basicDataBson); err != nil {
+	if err = writeResourceVersion(ctx, dbadapter.CommonDBClient, amDataColl, filter, amData, basicDataBson); err != nil {
 		logger.DbLog.Errorf("failed to update amData error: %+v", err)
 		// restore old auth data if any
 		if backup != nil {
-			_, err = dbadapter.AuthDBClient.RestfulAPIPutOne(authSubsDataColl, filter, backup)
-			if err != nil {
-				logger.DbLog.Errorf("failed to restore backup data for authentication subscription error: %+v", err)
+			if _, restoreErr := dbadapter.AuthDBClient.RestfulAPIPutOne(authSubsDataColl, filter, backup); restoreErr != nil {
+				logger.DbLog.Errorf("failed to restore backup data for authentication subscription error: %+v", restoreErr)
 			}
 		}
 		return fmt.Errorf("authData update failed, rolled back AuthDB change: %w", err)
@@ -128,9 +190,155 @@ func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthent
 	return nil
 }
 
-func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthenticationDataDelete(imsi string) error {
+// SubscriberAuthenticationDataPatch applies the patch to the stored subscriber
+// and returns its patched authentication data. Both documents are patched and
+// validated before anything is written, then written whole so that the fields
+// removed by the patch are removed. The amData write increments the version of
+// the subscriber only if it is still the one the patch was applied to.
+func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthenticationDataPatch(ctx context.Context, imsi string, patch *subscriberPatch) (*models.AuthenticationSubscription, error) {
+	filter := bson.M{"ueId": imsi}
+	amData, err := fetchResourceVersion(ctx, dbadapter.CommonDBClient, amDataColl, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(amData) == 0 {
+		return nil, &subscriberPatchError{statusCode: http.StatusNotFound, code: configmodels.ProblemCodeNotFound, message: fmt.Sprintf("subscriber %s does not exist", imsi)}
+	}
+	authSubs, err := dbadapter.AuthDBClient.RestfulAPIGetOne(authSubsDataColl, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authentication subscription: %w", err)
+	}
+	patchedAuthSubs, patchedAmData := authSubs, amData
+	if patch.hasAuthSubsChanges() {
+		if len(authSubs) == 0 {
+			return nil, &subscriberPatchError{statusCode: http.StatusNotFound, code: configmodels.ProblemCodeNotFound, message: fmt.Sprintf("authentication data of subscriber %s does not exist", imsi)}
+		}
+		if patchedAuthSubs, err = patchSubscriberDocument(authSubsPatchKey, authSubs, patch.authSubsMergePatch, patch.authSubsJSONPatch); err != nil {
+			return nil, err
+		}
+	}
+	if patch.hasAmDataChanges() {
+		if patchedAmData, err = patchSubscriberDocument(amDataPatchKey, amData, patch.amDataMergePatch, patch.amDataJSONPatch); err != nil {
+			return nil, err
+		}
+	}
+	authSubsData, err := validatePatchedSubscriber(patch, patchedAuthSubs, patchedAmData)
+	if err != nil {
+		return nil, err
+	}
+	// write to AuthDB
+	if patch.hasAuthSubsChanges() {
+		if _, err = dbadapter.AuthDBClient.RestfulAPIReplaceOneWithContext(ctx, authSubsDataColl, filter, patchedAuthSubs); err != nil {
+			logger.DbLog.Errorf("failed to patch authentication subscription error: %+v", err)
+			return nil, err
+		}
+		logger.WebUILog.Debugf("patched authentication subscription in authenticationSubscription collection: %s", imsi)
+	}
+	// write to CommonDB, even if only the authentication data changed, as
+	// amData holds the version of the subscriber
+	if err = replaceResourceVersion(ctx, dbadapter.CommonDBClient, amDataColl, filter, amData, patchedAmData); err != nil {
+		logger.DbLog.Errorf("failed to patch amData error: %+v", err)
+		// restore old auth data
+		if patch.hasAuthSubsChanges() {
+			if _, restoreErr := dbadapter.AuthDBClient.RestfulAPIReplaceOneWithContext(ctx, authSubsDataColl, filter, authSubs); restoreErr != nil {
+				logger.DbLog.Errorf("failed to restore backup data for authentication subscription error: %+v", restoreErr)
+			}
+		}
+		return nil, fmt.Errorf("amData patch failed, rolled back AuthDB change: %w", err)
+	}
+	logger.WebUILog.Debugf("successfully patched access and mobility data in amData collection: %s", imsi)
+	return authSubsData, nil
+}
+
+// subscriberPatchError reports a patch that does not apply to the stored
+// subscriber, such as a failed JSON patch test operation.
+type subscriberPatchError struct {
+	statusCode int
+	code       string
+	message    string
+}
+
+func (e *subscriberPatchError) Error() string {
+	return e.message
+}
+
+// patchSubscriberDocument applies the merge patch, or else the JSON patch, of
+// a section of the subscriber to its stored document.
+func patchSubscriberDocument(section string, document map[string]interface{}, mergePatch map[string]interface{}, jsonPatch []byte) (map[string]interface{}, error) {
+	original, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var patched []byte
+	if mergePatch != nil {
+		var patchData []byte
+		if patchData, err = json.Marshal(mergePatch); err != nil {
+			return nil, err
+		}
+		patched, err = jsonpatch.MergePatch(original, patchData)
+	} else {
+		var operations jsonpatch.Patch
+		if operations, err = jsonpatch.DecodePatch(jsonPatch); err != nil {
+			return nil, err
+		}
+		patched, err = operations.Apply(original)
+	}
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return nil, &subscriberPatchError{statusCode: http.StatusConflict, code: configmodels.ProblemCodePatchTestFailed, message: fmt.Sprintf("%s: %s", section, err)}
+	case err != nil:
+		return nil, &subscriberPatchError{statusCode: http.StatusBadRequest, code: configmodels.ProblemCodeInvalidRequest, message: fmt.Sprintf("patch does not apply to %s: %s", section, err)}
+	}
+	var patchedDocument map[string]interface{}
+	if err = json.Unmarshal(patched, &patchedDocument); err != nil {
+		return nil, err
+	}
+	return patchedDocument, nil
+}
+
+// validatePatchedSubscriber checks that the patched sections of the subscriber
+// are still valid, and returns its authentication data.
+func validatePatchedSubscriber(patch *subscriberPatch, authSubs map[string]interface{}, amData map[string]interface{}) (*models.AuthenticationSubscription, error) {
+	var authSubsData *models.AuthenticationSubscription
+	if err := json.Unmarshal(configmodels.MapToByte(authSubs), &authSubsData); err != nil {
+		return nil, patchedValueError(authSubsPatchKey, err)
+	}
+	if patch.hasAuthSubsChanges() {
+		switch {
+		case authSubsData.Opc == nil || authSubsData.Opc.OpcValue == "":
+			return nil, newValidationError(authSubsPatchKey+".opc.opcValue", "OPc must be provided")
+		case authSubsData.PermanentKey == nil || authSubsData.PermanentKey.PermanentKeyValue == "":
+			return nil, newValidationError(authSubsPatchKey+".permanentKey.permanentKeyValue", "Key must be provided")
+		case authSubsData.SequenceNumber == "":
+			return nil, newValidationError(authSubsPatchKey+".sequenceNumber", "Sequence number must be provided")
+		}
+	}
+	if patch.hasAmDataChanges() {
+		var amDataModel models.AccessAndMobilitySubscriptionData
+		if err := json.Unmarshal(configmodels.MapToByte(amData), &amDataModel); err != nil {
+			return nil, patchedValueError(amDataPatchKey, err)
+		}
+	}
+	return authSubsData, nil
+}
+
+func patchedValueError(section string, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return newValidationError(section+"."+typeErr.Field, "invalid value for %s.%s: must be %s", section, typeErr.Field, typeErr.Type)
+	}
+	return newValidationError(section, "invalid value for %s: %s", section, err)
+}
+
+// SubscriberAuthenticationDataDelete deletes the subscriber. With an If-Match
+// precondition on it, the amData deletion is conditional on the version that
+// satisfied it, and the AuthDB deletion is rolled back if it fails.
+func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthenticationDataDelete(ctx context.Context, imsi string) error {
 	logger.WebUILog.Debugf("delete authentication subscription from authenticationSubscription collection: %s", imsi)
 	filter := bson.M{"ueId": imsi}
+	if err := checkResourceVersionIfMatch(ctx, dbadapter.CommonDBClient, amDataColl, filter); err != nil {
+		return err
+	}
 
 	origAuthData, getErr := dbadapter.AuthDBClient.RestfulAPIGetOne(authSubsDataColl, filter)
 	if getErr != nil {
@@ -146,7 +354,7 @@ func (subscriberAuthData DatabaseSubscriberAuthenticationData) SubscriberAuthent
 	}
 	logger.WebUILog.Debugf("successfully deleted authentication subscription from authenticationSubscription collection: %v", imsi)
 
-	err = dbadapter.CommonDBClient.RestfulAPIDeleteOne(amDataColl, filter)
+	err = deleteResourceVersion(ctx, dbadapter.CommonDBClient, amDataColl, filter)
 	if err != nil {
 		logger.DbLog.Errorln(err)
 		// rollback AuthDB operation
@@ -188,9 +396,9 @@ func (subscriberAuthData MemorySubscriberAuthenticationData) SubscriberAuthentic
 	return nil
 }
 
-func (subscriberAuthData MemorySubscriberAuthenticationData) SubscriberAuthenticationDataDelete(imsi string) error {
+func (subscriberAuthData MemorySubscriberAuthenticationData) SubscriberAuthenticationDataDelete(ctx context.Context, imsi string) error {
 	filter := bson.M{"ueId": imsi}
-	if err := dbadapter.CommonDBClient.RestfulAPIDeleteOne(amDataColl, filter); err != nil {
+	if err := deleteResourceVersion(ctx, dbadapter.CommonDBClient, amDataColl, filter); err != nil {
 		return fmt.Errorf("failed to delete from amData collection: %w", err)
 	}
 	logger.WebUILog.Debugf("successfully deleted authentication subscription from amData collection: %s", imsi)
@@ -199,6 +407,122 @@ func (subscriberAuthData MemorySubscriberAuthenticationData) SubscriberAuthentic
 	return nil
 }
 
+func isSubscriberListQuery(values url.Values) bool {
+	for _, param := range subscriberListQueryParams {
+		if values.Has(param) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSubscriberListQuery(values url.Values) (subscriberListQuery, error) {
+	query := subscriberListQuery{limit: defaultSubscriberPageSize}
+	if limit := values.Get("limit"); limit != "" {
+		parsedLimit, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxSubscriberPageSize {
+			return query, fmt.Errorf("limit must be an integer between 1 and %d", maxSubscriberPageSize)
+		}
+		query.limit = parsedLimit
+	}
+	if pageToken := values.Get("page_token"); pageToken != "" {
+		after, err := decodePageToken(pageToken)
+		if err != nil {
+			return query, err
+		}
+		query.after = after
+	}
+	if imsiPrefix := strings.TrimPrefix(values.Get("imsi_prefix"), "imsi-"); imsiPrefix != "" {
+		if !imsiPrefixPattern.MatchString(imsiPrefix) {
+			return query, fmt.Errorf("invalid imsi_prefix: %s", imsiPrefix)
+		}
+		query.imsiPrefix = imsiPrefix
+	}
+	if imsiFrom := strings.TrimPrefix(values.Get("imsi_from"), "imsi-"); imsiFrom != "" {
+		if !isValidImsi(imsiFrom) {
+			return query, fmt.Errorf("invalid imsi_from: %s", imsiFrom)
+		}
+		query.imsiFrom = imsiFrom
+	}
+	if imsiTo := strings.TrimPrefix(values.Get("imsi_to"), "imsi-"); imsiTo != "" {
+		if !isValidImsi(imsiTo) {
+			return query, fmt.Errorf("invalid imsi_to: %s", imsiTo)
+		}
+		query.imsiTo = imsiTo
+	}
+	if plmnId := values.Get("plmn_id"); plmnId != "" {
+		if !plmnIdPattern.MatchString(plmnId) {
+			return query, fmt.Errorf("invalid plmn_id: %s", plmnId)
+		}
+		query.plmnId = plmnId
+	}
+	query.deviceGroup = values.Get("device_group")
+	return query, nil
+}
+
+// subscriberListFilter builds the amData filter matching query. IMSI ranges are
+// compared as strings, so both bounds should have the same number of digits.
+func subscriberListFilter(query subscriberListQuery) (bson.M, int, error) {
+	filter := bson.M{}
+	ueIdFilter := bson.M{}
+	if query.imsiPrefix != "" {
+		ueIdFilter["$regex"] = "^imsi-" + query.imsiPrefix
+	}
+	if query.imsiFrom != "" {
+		ueIdFilter["$gte"] = "imsi-" + query.imsiFrom
+	}
+	if query.imsiTo != "" {
+		ueIdFilter["$lte"] = "imsi-" + query.imsiTo
+	}
+	if query.deviceGroup != "" {
+		rawDeviceGroup, err := dbadapter.CommonDBClient.RestfulAPIGetOne(devGroupDataColl, bson.M{"group-name": query.deviceGroup})
+		if err != nil {
+			logger.DbLog.Errorf("failed to fetch device group %s: %+v", query.deviceGroup, err)
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to fetch device group %s", query.deviceGroup)
+		}
+		if len(rawDeviceGroup) == 0 {
+			return nil, http.StatusNotFound, fmt.Errorf("device group %s not found", query.deviceGroup)
+		}
+		var deviceGroup configmodels.DeviceGroups
+		if err = json.Unmarshal(configmodels.MapToByte(rawDeviceGroup), &deviceGroup); err != nil {
+			logger.DbLog.Errorf("could not unmarshal device group %s: %+v", query.deviceGroup, err)
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to fetch device group %s", query.deviceGroup)
+		}
+		ueIds := make([]string, 0, len(deviceGroup.Imsis))
+		for _, imsi := range deviceGroup.Imsis {
+			ueIds = append(ueIds, "imsi-"+imsi)
+		}
+		ueIdFilter["$in"] = ueIds
+	}
+	if len(ueIdFilter) > 0 {
+		filter["ueId"] = ueIdFilter
+	}
+	if query.plmnId != "" {
+		filter["servingPlmnId"] = query.plmnId
+	}
+	return filter, http.StatusOK, nil
+}
+
+// withPageCursor restricts filter to the subscribers ordered after the ueId of the previous page.
+func withPageCursor(filter bson.M, after string) bson.M {
+	if after == "" {
+		return filter
+	}
+	return bson.M{"$and": []bson.M{filter, {"ueId": bson.M{"$gt": after}}}}
+}
+
+func encodePageToken(ueId string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(ueId))
+}
+
+func decodePageToken(pageToken string) (string, error) {
+	ueId, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil || !strings.HasPrefix(string(ueId), "imsi-") {
+		return "", fmt.Errorf("invalid page_token")
+	}
+	return string(ueId), nil
+}
+
 func getDeletedImsisList(group, prevGroup *configmodels.DeviceGroups) (dimsis []string) {
 	if prevGroup == nil {
 		return
@@ -268,11 +592,25 @@ func removeSubscriberEntriesRelatedToDeviceGroups(mcc, mnc, imsi string) error {
 	return nil
 }
 
-func handleSubscriberDelete(imsi string) error {
+// runAuthAndCommonTransactionWithContext runs authFn in an AuthDB transaction and
+// commonFn in a CommonDB transaction nested in it, so a failure on either side
+// aborts both.
+func runAuthAndCommonTransactionWithContext(ctx context.Context, authFn, commonFn func(sc mongo.SessionContext) error) error {
+	authSessionRunner := dbadapter.GetSessionRunner(dbadapter.AuthDBClient)
+	return authSessionRunner(ctx, func(authSc mongo.SessionContext) error {
+		if err := authFn(authSc); err != nil {
+			return err
+		}
+		commonSessionRunner := dbadapter.GetSessionRunner(dbadapter.CommonDBClient)
+		return commonSessionRunner(authSc, commonFn)
+	})
+}
+
+func handleSubscriberDelete(ctx context.Context, imsi string) error {
 	rwLock.Lock()
 	defer rwLock.Unlock()
 	subscriberAuthData := DatabaseSubscriberAuthenticationData{}
-	err := subscriberAuthData.SubscriberAuthenticationDataDelete(imsi)
+	err := subscriberAuthData.SubscriberAuthenticationDataDelete(ctx, imsi)
 	if err != nil {
 		logger.DbLog.Errorln("SubscriberAuthDataDelete error:", err)
 		return err
@@ -281,11 +619,11 @@ func handleSubscriberDelete(imsi string) error {
 	return nil
 }
 
-func handleSubscriberPut(imsi string, authSubData *models.AuthenticationSubscription) error {
+func handleSubscriberPut(ctx context.Context, imsi string, authSubData *models.AuthenticationSubscription) error {
 	rwLock.Lock()
 	defer rwLock.Unlock()
 	subscriberAuthData := DatabaseSubscriberAuthenticationData{}
-	err := subscriberAuthData.SubscriberAuthenticationDataUpdate(imsi, authSubData)
+	err := subscriberAuthData.SubscriberAuthenticationDataUpdate(ctx, imsi, authSubData)
 	if err != nil {
 		logger.DbLog.Errorln("Subscriber Authentication Data Update Error:", err)
 		return err
@@ -294,6 +632,118 @@ func handleSubscriberPut(imsi string, authSubData *models.AuthenticationSubscrip
 	return nil
 }
 
+func handleSubscriberPatch(ctx context.Context, imsi string, patch *subscriberPatch) (*models.AuthenticationSubscription, error) {
+	rwLock.Lock()
+	defer rwLock.Unlock()
+	subscriberAuthData := DatabaseSubscriberAuthenticationData{}
+	authSubData, err := subscriberAuthData.SubscriberAuthenticationDataPatch(ctx, imsi, patch)
+	if err != nil {
+		logger.DbLog.Errorln("Subscriber Authentication Data Patch Error:", err)
+		return nil, err
+	}
+	logger.DbLog.Debugf("successfully processed subscriber patch for IMSI: %s", imsi)
+	return authSubData, nil
+}
+
+// parseSubscriberMergePatch splits an RFC 7396 merge patch document
+// shaped like configmodels.SubsData into per-collection patches.
+func parseSubscriberMergePatch(body []byte) (*subscriberPatch, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid merge patch document: %w", err)
+	}
+	if len(document) == 0 {
+		return nil, fmt.Errorf("merge patch document is empty")
+	}
+	patch := &subscriberPatch{}
+	for key, rawValue := range document {
+		var value map[string]interface{}
+		if err := json.Unmarshal(rawValue, &value); err != nil || value == nil {
+			return nil, fmt.Errorf("%s must be a JSON object", key)
+		}
+		if _, ok := value["ueId"]; ok {
+			return nil, fmt.Errorf("ueId cannot be patched")
+		}
+		switch key {
+		case authSubsPatchKey:
+			patch.authSubsMergePatch = value
+		case amDataPatchKey:
+			patch.amDataMergePatch = value
+		default:
+			return nil, fmt.Errorf("unsupported patch field: %s", key)
+		}
+	}
+	return patch, nil
+}
+
+// parseSubscriberJSONPatch splits an RFC 6902 JSON patch whose paths are rooted
+// at configmodels.SubsData into per-collection patches.
+func parseSubscriberJSONPatch(body []byte) (*subscriberPatch, error) {
+	var operations []dbadapter.PatchOperation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch document: %w", err)
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("JSON patch document is empty")
+	}
+	var authSubsOperations, amDataOperations []dbadapter.PatchOperation
+	for _, operation := range operations {
+		switch operation.Op {
+		case "add", "remove", "replace", "move", "copy", "test":
+		default:
+			return nil, fmt.Errorf("unsupported patch operation: %s", operation.Op)
+		}
+		target, path, err := splitSubscriberPatchPath(operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		operation.Path = path
+		if operation.From != "" {
+			fromTarget, from, err := splitSubscriberPatchPath(operation.From)
+			if err != nil {
+				return nil, err
+			}
+			if fromTarget != target {
+				return nil, fmt.Errorf("cannot %s values between %s and %s", operation.Op, fromTarget, target)
+			}
+			operation.From = from
+		}
+		if target == authSubsPatchKey {
+			authSubsOperations = append(authSubsOperations, operation)
+		} else {
+			amDataOperations = append(amDataOperations, operation)
+		}
+	}
+	patch := &subscriberPatch{}
+	var err error
+	if len(authSubsOperations) > 0 {
+		if patch.authSubsJSONPatch, err = json.Marshal(authSubsOperations); err != nil {
+			return nil, err
+		}
+	}
+	if len(amDataOperations) > 0 {
+		if patch.amDataJSONPatch, err = json.Marshal(amDataOperations); err != nil {
+			return nil, err
+		}
+	}
+	return patch, nil
+}
+
+func splitSubscriberPatchPath(path string) (string, string, error) {
+	for _, key := range []string{authSubsPatchKey, amDataPatchKey} {
+		prefix := "/" + key + "/"
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		subPath := path[len(prefix)-1:]
+		if subPath == "/ueId" || strings.HasPrefix(subPath, "/ueId/") {
+			return "", "", fmt.Errorf("ueId cannot be patched")
+		}
+		return key, subPath, nil
+	}
+	return "", "", fmt.Errorf("unsupported patch path: %s", path)
+}
+
 func handleSubscriberPost(imsi string, authSubData *models.AuthenticationSubscription) error {
 	rwLock.Lock()
 	defer rwLock.Unlock()
@@ -307,7 +757,7 @@ func handleSubscriberPost(imsi string, authSubData *models.AuthenticationSubscri
 	return nil
 }
 
-func updateSubscriberInDeviceGroups(imsi string) (int, error) {
+func updateSubscriberInDeviceGroups(ctx context.Context, imsi string) (int, error) {
 	filterByImsi := bson.M{
 		"imsis": imsi,
 	}
@@ -331,7 +781,7 @@ func updateSubscriberInDeviceGroups(imsi string) (int, error) {
 		}
 		deviceGroup.Imsis = filteredImsis
 		prevDevGroup := getDeviceGroupByName(deviceGroup.DeviceGroupName)
-		if statusCode, err := handleDeviceGroupPost(&deviceGroup, prevDevGroup); err != nil {
+		if statusCode, err := handleDeviceGroupPost(ctx, &deviceGroup, prevDevGroup); err != nil {
 			logger.ConfigLog.Errorf("error posting device group %+v: %+v", deviceGroup, err)
 			return statusCode, err
 		}
@@ -344,6 +794,7 @@ func updateSubscriberInDeviceGroups(imsi string) (int, error) {
 		deviceGroupUpdateMessages = append(deviceGroupUpdateMessages, deviceGroupUpdateMessage)
 	}
 	for _, msg := range deviceGroupUpdateMessages {
+		msg.RequestID = logger.RequestIDFromContext(ctx)
 		configChannel <- &msg
 		logger.WebUILog.Infof("device group [%s] update sent to config channel", msg.DevGroupName)
 	}
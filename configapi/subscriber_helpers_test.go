@@ -4,8 +4,11 @@
 package configapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 	"testing"
@@ -17,9 +20,12 @@ import (
 )
 
 type mockDB struct {
-	getOneFunc    func(collName string, filter bson.M) (map[string]interface{}, error)
-	deleteOneFunc func(collName string, filter bson.M) error
-	postFunc      func(collName string, filter bson.M, postData map[string]interface{}) (bool, error)
+	getOneFunc     func(collName string, filter bson.M) (map[string]interface{}, error)
+	deleteOneFunc  func(collName string, filter bson.M) error
+	postFunc       func(collName string, filter bson.M, postData map[string]interface{}) (bool, error)
+	replaceOneFunc func(collName string, filter bson.M, replacement map[string]interface{}) (bool, error)
+	putOneFunc     func(collName string, filter bson.M, putData map[string]interface{}) (bool, error)
+	swapFunc       func(collName string, filter bson.M, putData map[string]interface{}) (bool, error)
 	dbadapter.DBInterface
 }
 
@@ -35,6 +41,21 @@ func (m *mockDB) RestfulAPIDeleteOne(collName string, filter bson.M) error {
 	return m.deleteOneFunc(collName, filter)
 }
 
+func (m *mockDB) RestfulAPIReplaceOneWithContext(context context.Context, collName string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+	return m.replaceOneFunc(collName, filter, replacement)
+}
+
+func (m *mockDB) RestfulAPIPutOne(collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	return m.putOneFunc(collName, filter, putData)
+}
+
+func (m *mockDB) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	if m.swapFunc != nil {
+		return m.swapFunc(collName, filter, putData)
+	}
+	return true, nil
+}
+
 func TestSubscriberAuthenticationDataCreate_Success(t *testing.T) {
 	authCalled, commonCalled := false, false
 
@@ -160,6 +181,324 @@ func TestSubscriberAuthenticationDataCreate_CommonDBFails_RollsBack(t *testing.T
 	}
 }
 
+func TestSubscriberAuthenticationDataPatch_Success(t *testing.T) {
+	var authReplacement, commonReplacement map[string]interface{}
+	var commonFilter bson.M
+	authDB := &mockDB{
+		getOneFunc: func(coll string, filter bson.M) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"ueId":           "imsi-1",
+				"opc":            map[string]interface{}{"opcValue": "981d464c7c52eb6e5036234984ad0bcf"},
+				"permanentKey":   map[string]interface{}{"permanentKeyValue": "5122250214c33e723a5dd523fc145fc0"},
+				"sequenceNumber": "16f3b3f70fc2",
+			}, nil
+		},
+		replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+			if authReplacement != nil {
+				t.Error("rollback should not be called on success")
+			}
+			authReplacement = replacement
+			return true, nil
+		},
+	}
+	commonDB := &mockDB{
+		getOneFunc: func(coll string, filter bson.M) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"ueId":               "imsi-1",
+				"nssai":              map[string]interface{}{"defaultSingleNssais": []interface{}{}},
+				"subscribedUeAmbr":   map[string]interface{}{"uplink": "1 Mbps", "downlink": "1 Mbps"},
+				resourceVersionField: int64(2),
+			}, nil
+		},
+		replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+			commonFilter, commonReplacement = filter, replacement
+			return true, nil
+		},
+	}
+	origAuthDB := dbadapter.AuthDBClient
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.CommonDBClient = origCommonDB
+	}()
+	dbadapter.AuthDBClient = authDB
+	dbadapter.CommonDBClient = commonDB
+
+	patch, err := parseSubscriberJSONPatch([]byte(`[
+		{"op": "test", "path": "/AuthenticationSubscription/sequenceNumber", "value": "16f3b3f70fc2"},
+		{"op": "replace", "path": "/AuthenticationSubscription/sequenceNumber", "value": "16f3b3f70fc3"},
+		{"op": "replace", "path": "/AccessAndMobilitySubscriptionData/subscribedUeAmbr/uplink", "value": "2 Mbps"},
+		{"op": "remove", "path": "/AccessAndMobilitySubscriptionData/nssai"}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to parse patch: %v", err)
+	}
+	sub := DatabaseSubscriberAuthenticationData{}
+	authSubData, err := sub.SubscriberAuthenticationDataPatch(context.Background(), "imsi-1", patch)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if authSubData == nil || authSubData.SequenceNumber != "16f3b3f70fc3" {
+		t.Errorf("expected the patched authentication data, got %+v", authSubData)
+	}
+	if authReplacement["sequenceNumber"] != "16f3b3f70fc3" {
+		t.Errorf("expected the patched authentication subscription to be written, got %v", authReplacement)
+	}
+	expectedFilter := bson.M{"ueId": "imsi-1", resourceVersionField: int64(2)}
+	if !reflect.DeepEqual(commonFilter, expectedFilter) {
+		t.Errorf("expected amData to be written at version 2 with filter %v, got %v", expectedFilter, commonFilter)
+	}
+	expectedAmData := map[string]interface{}{
+		"ueId":               "imsi-1",
+		"subscribedUeAmbr":   map[string]interface{}{"uplink": "2 Mbps", "downlink": "1 Mbps"},
+		resourceVersionField: int64(3),
+	}
+	if !reflect.DeepEqual(commonReplacement, expectedAmData) {
+		t.Errorf("expected amData %v, got %v", expectedAmData, commonReplacement)
+	}
+}
+
+func TestSubscriberAuthenticationDataPatch_Errors(t *testing.T) {
+	authSubs := map[string]interface{}{
+		"ueId":           "imsi-1",
+		"opc":            map[string]interface{}{"opcValue": "981d464c7c52eb6e5036234984ad0bcf"},
+		"permanentKey":   map[string]interface{}{"permanentKeyValue": "5122250214c33e723a5dd523fc145fc0"},
+		"sequenceNumber": "16f3b3f70fc2",
+	}
+	testCases := []struct {
+		name           string
+		jsonPatch      bool
+		body           string
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:           "failed test operation",
+			jsonPatch:      true,
+			body:           `[{"op": "test", "path": "/AuthenticationSubscription/sequenceNumber", "value": "000000000000"}]`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "missing path",
+			jsonPatch:      true,
+			body:           `[{"op": "replace", "path": "/AuthenticationSubscription/milenage/op/opValue", "value": "abc"}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "value of the wrong type",
+			body:           `{"AuthenticationSubscription": {"sequenceNumber": 12}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedField:  "AuthenticationSubscription.sequenceNumber",
+		},
+		{
+			name:           "removed key",
+			body:           `{"AuthenticationSubscription": {"permanentKey": null}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedField:  "AuthenticationSubscription.permanentKey.permanentKeyValue",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := &mockDB{
+				getOneFunc: func(coll string, filter bson.M) (map[string]interface{}, error) {
+					if coll == authSubsDataColl {
+						return authSubs, nil
+					}
+					return map[string]interface{}{"ueId": "imsi-1"}, nil
+				},
+				replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+					t.Errorf("expected nothing to be written, got %v", replacement)
+					return true, nil
+				},
+			}
+			origAuthDB := dbadapter.AuthDBClient
+			origCommonDB := dbadapter.CommonDBClient
+			defer func() {
+				dbadapter.AuthDBClient = origAuthDB
+				dbadapter.CommonDBClient = origCommonDB
+			}()
+			dbadapter.AuthDBClient = db
+			dbadapter.CommonDBClient = db
+
+			var patch *subscriberPatch
+			var err error
+			if tc.jsonPatch {
+				patch, err = parseSubscriberJSONPatch([]byte(tc.body))
+			} else {
+				patch, err = parseSubscriberMergePatch([]byte(tc.body))
+			}
+			if err != nil {
+				t.Fatalf("failed to parse patch: %v", err)
+			}
+			sub := DatabaseSubscriberAuthenticationData{}
+			_, err = sub.SubscriberAuthenticationDataPatch(context.Background(), "imsi-1", patch)
+			var patchErr *subscriberPatchError
+			var validationErr *validationError
+			switch {
+			case errors.As(err, &patchErr):
+				if patchErr.statusCode != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d: %v", tc.expectedStatus, patchErr.statusCode, err)
+				}
+			case errors.As(err, &validationErr):
+				if tc.expectedStatus != http.StatusUnprocessableEntity || validationErr.field != tc.expectedField {
+					t.Errorf("expected status %d on %s, got a validation error on %s: %v", tc.expectedStatus, tc.expectedField, validationErr.field, err)
+				}
+			default:
+				t.Errorf("expected a patch or validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSubscriberAuthenticationDataPatch_CommonDBFails_RollsBack(t *testing.T) {
+	backup := map[string]interface{}{
+		"ueId":           "imsi-1",
+		"opc":            map[string]interface{}{"opcValue": "981d464c7c52eb6e5036234984ad0bcf"},
+		"permanentKey":   map[string]interface{}{"permanentKeyValue": "5122250214c33e723a5dd523fc145fc0"},
+		"sequenceNumber": "16f3b3f70fc2",
+	}
+	var restored map[string]interface{}
+	authDB := &mockDB{
+		getOneFunc: func(coll string, filter bson.M) (map[string]interface{}, error) {
+			return backup, nil
+		},
+		replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+			restored = replacement
+			return true, nil
+		},
+	}
+	commonDB := &mockDB{
+		getOneFunc: func(coll string, filter bson.M) (map[string]interface{}, error) {
+			return map[string]interface{}{"ueId": "imsi-1"}, nil
+		},
+		replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+			return false, fmt.Errorf("common db failure")
+		},
+	}
+	origAuthDB := dbadapter.AuthDBClient
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.CommonDBClient = origCommonDB
+	}()
+	dbadapter.AuthDBClient = authDB
+	dbadapter.CommonDBClient = commonDB
+
+	patch, err := parseSubscriberMergePatch([]byte(`{
+		"AuthenticationSubscription": {"sequenceNumber": "16f3b3f70fc3"},
+		"AccessAndMobilitySubscriptionData": {"subscribedUeAmbr": {"uplink": "2 Mbps"}}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse patch: %v", err)
+	}
+	sub := DatabaseSubscriberAuthenticationData{}
+	if _, err = sub.SubscriberAuthenticationDataPatch(context.Background(), "imsi-1", patch); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if !reflect.DeepEqual(restored, backup) {
+		t.Errorf("expected AuthDB to be restored to %v, got %v", backup, restored)
+	}
+}
+
+func TestSubscriberAuthenticationDataPatch_ConcurrentUpdate(t *testing.T) {
+	db := &mockDB{
+		getOneFunc: func(coll string, filter bson.M) (map[string]interface{}, error) {
+			return map[string]interface{}{"ueId": "imsi-1", resourceVersionField: int64(4)}, nil
+		},
+		replaceOneFunc: func(coll string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+			return false, nil
+		},
+	}
+	origAuthDB := dbadapter.AuthDBClient
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.CommonDBClient = origCommonDB
+	}()
+	dbadapter.AuthDBClient = db
+	dbadapter.CommonDBClient = db
+
+	patch, err := parseSubscriberMergePatch([]byte(`{"AccessAndMobilitySubscriptionData": {"subscribedUeAmbr": {"uplink": "2 Mbps"}}}`))
+	if err != nil {
+		t.Fatalf("failed to parse patch: %v", err)
+	}
+	sub := DatabaseSubscriberAuthenticationData{}
+	_, err = sub.SubscriberAuthenticationDataPatch(context.Background(), "imsi-1", patch)
+	if status := versionConflictStatus(err, http.StatusInternalServerError); status != http.StatusConflict {
+		t.Errorf("expected a concurrent update conflict, got %d: %v", status, err)
+	}
+}
+
+func TestParseSubscriberPatch_InvalidDocuments(t *testing.T) {
+	testCases := []struct {
+		name      string
+		jsonPatch bool
+		body      string
+		wantErr   string
+	}{
+		{
+			name:    "merge patch unknown field",
+			body:    `{"SmPolicyData": {}}`,
+			wantErr: "unsupported patch field: SmPolicyData",
+		},
+		{
+			name:    "merge patch ueId",
+			body:    `{"AuthenticationSubscription": {"ueId": "imsi-2"}}`,
+			wantErr: "ueId cannot be patched",
+		},
+		{
+			name:    "merge patch section not an object",
+			body:    `{"AuthenticationSubscription": "abc"}`,
+			wantErr: "AuthenticationSubscription must be a JSON object",
+		},
+		{
+			name:    "merge patch empty",
+			body:    `{}`,
+			wantErr: "merge patch document is empty",
+		},
+		{
+			name:      "JSON patch unknown path",
+			jsonPatch: true,
+			body:      `[{"op": "remove", "path": "/SmPolicyData/smPolicySnssaiData"}]`,
+			wantErr:   "unsupported patch path: /SmPolicyData/smPolicySnssaiData",
+		},
+		{
+			name:      "JSON patch unknown operation",
+			jsonPatch: true,
+			body:      `[{"op": "increment", "path": "/AuthenticationSubscription/sequenceNumber"}]`,
+			wantErr:   "unsupported patch operation: increment",
+		},
+		{
+			name:      "JSON patch move across sections",
+			jsonPatch: true,
+			body:      `[{"op": "move", "from": "/AuthenticationSubscription/opc", "path": "/AccessAndMobilitySubscriptionData/opc"}]`,
+			wantErr:   "cannot move values between AuthenticationSubscription and AccessAndMobilitySubscriptionData",
+		},
+		{
+			name:      "JSON patch ueId",
+			jsonPatch: true,
+			body:      `[{"op": "replace", "path": "/AccessAndMobilitySubscriptionData/ueId", "value": "imsi-2"}]`,
+			wantErr:   "ueId cannot be patched",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			if tc.jsonPatch {
+				_, err = parseSubscriberJSONPatch([]byte(tc.body))
+			} else {
+				_, err = parseSubscriberMergePatch([]byte(tc.body))
+			}
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tc.wantErr {
+				t.Errorf("expected error %q, got %q", tc.wantErr, err.Error())
+			}
+		})
+	}
+}
+
 func TestSubscriberAuthenticationDataDelete_Success(t *testing.T) {
 	origAuth := map[string]interface{}{"ueId": "imsi-12345"}
 	authDB := &mockDB{
@@ -180,7 +519,7 @@ func TestSubscriberAuthenticationDataDelete_Success(t *testing.T) {
 	dbadapter.CommonDBClient = commonDB
 
 	s := DatabaseSubscriberAuthenticationData{}
-	err := s.SubscriberAuthenticationDataDelete("imsi-12345")
+	err := s.SubscriberAuthenticationDataDelete(context.Background(), "imsi-12345")
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -197,7 +536,7 @@ func TestSubscriberAuthenticationDataDelete_AuthDBDeleteFails_Exits(t *testing.T
 	dbadapter.AuthDBClient = authDB
 
 	s := DatabaseSubscriberAuthenticationData{}
-	err := s.SubscriberAuthenticationDataDelete("imsi-12345")
+	err := s.SubscriberAuthenticationDataDelete(context.Background(), "imsi-12345")
 	if err == nil || !strings.Contains(err.Error(), "fail on authdb delete") {
 		t.Errorf("expected error about authdb delete, got %v", err)
 	}
@@ -223,7 +562,7 @@ func TestSubscriberAuthenticationDataDelete_CommonDBDeleteFails_RollbackSucceeds
 	dbadapter.CommonDBClient = commonDB
 
 	s := DatabaseSubscriberAuthenticationData{}
-	err := s.SubscriberAuthenticationDataDelete("imsi-12345")
+	err := s.SubscriberAuthenticationDataDelete(context.Background(), "imsi-12345")
 	if err == nil || !strings.Contains(err.Error(), "amData delete failed, rolled back AuthDB change") {
 		t.Errorf("expected error with rollback message, got %v", err)
 	}
@@ -251,7 +590,7 @@ func TestSubscriberAuthenticationDataDelete_CommonDBDeleteFails_RollbackFails(t
 	dbadapter.CommonDBClient = commonDB
 
 	s := DatabaseSubscriberAuthenticationData{}
-	err := s.SubscriberAuthenticationDataDelete("imsi-12345")
+	err := s.SubscriberAuthenticationDataDelete(context.Background(), "imsi-12345")
 	if err == nil || !strings.Contains(err.Error(), "amData delete failed:") || !strings.Contains(err.Error(), "rollback failed") {
 		t.Errorf("expected error with rollback fail message, got %v", err)
 	}
@@ -278,12 +617,58 @@ func TestSubscriberAuthenticationDataDelete_NoDataInAuthDB_Exits(t *testing.T) {
 	dbadapter.CommonDBClient = commonDB
 
 	s := DatabaseSubscriberAuthenticationData{}
-	err := s.SubscriberAuthenticationDataDelete("imsi-12345")
+	err := s.SubscriberAuthenticationDataDelete(context.Background(), "imsi-12345")
 	if err == nil || !strings.Contains(err.Error(), "not found") {
 		t.Errorf("expected data not found in AuthDB, got %v", err)
 	}
 }
 
+func TestSubscriberAuthenticationDataUpdate_ConcurrentUpdate_RollsBack(t *testing.T) {
+	backup := map[string]interface{}{"ueId": "imsi-12345", "sequenceNumber": "000000000001"}
+	var authWrites []map[string]interface{}
+	authDB := &mockDB{
+		getOneFunc: func(c string, f bson.M) (map[string]interface{}, error) { return backup, nil },
+		putOneFunc: func(c string, f bson.M, data map[string]interface{}) (bool, error) {
+			authWrites = append(authWrites, data)
+			return true, nil
+		},
+	}
+	var swapFilter bson.M
+	commonDB := &mockDB{
+		getOneFunc: func(c string, f bson.M) (map[string]interface{}, error) {
+			return map[string]interface{}{"ueId": "imsi-12345", resourceVersionField: int64(2)}, nil
+		},
+		swapFunc: func(c string, f bson.M, data map[string]interface{}) (bool, error) {
+			swapFilter = f
+			return false, nil
+		},
+	}
+	origAuthDB := dbadapter.AuthDBClient
+	origCommonDB := dbadapter.CommonDBClient
+	defer func() {
+		dbadapter.AuthDBClient = origAuthDB
+		dbadapter.CommonDBClient = origCommonDB
+	}()
+	dbadapter.AuthDBClient = authDB
+	dbadapter.CommonDBClient = commonDB
+
+	s := DatabaseSubscriberAuthenticationData{}
+	authSubsData := newAuthenticationSubscription("8e27b6af0e692e750f32667a3b14605d", "8baf473f2f8fd09487cccbd7097c6862", "000000000002")
+	err := s.SubscriberAuthenticationDataUpdate(context.Background(), "imsi-12345", &authSubsData)
+
+	var conflictErr *versionConflictError
+	if !errors.As(err, &conflictErr) || conflictErr.statusCode != http.StatusConflict {
+		t.Fatalf("expected a concurrent update conflict, got %v", err)
+	}
+	expectedSwapFilter := bson.M{"ueId": "imsi-12345", resourceVersionField: int64(2)}
+	if !reflect.DeepEqual(swapFilter, expectedSwapFilter) {
+		t.Errorf("expected compare-and-swap filter %v, got %v", expectedSwapFilter, swapFilter)
+	}
+	if len(authWrites) != 2 || !reflect.DeepEqual(authWrites[1], backup) {
+		t.Errorf("expected the authentication data to be restored to %v, got writes %v", backup, authWrites)
+	}
+}
+
 func Test_handleSubscriberPost5G(t *testing.T) {
 	origImsiData := ImsiData
 	origAuthDBClient := dbadapter.AuthDBClient
@@ -376,7 +761,7 @@ func Test_handleSubscriberDelete5G(t *testing.T) {
 	deleteData = make([]map[string]interface{}, 0)
 	dbadapter.AuthDBClient = &MockMongoDeleteOne{}
 	dbadapter.CommonDBClient = &MockMongoDeleteOne{}
-	delErr := handleSubscriberDelete(ueId)
+	delErr := handleSubscriberDelete(context.Background(), ueId)
 	if delErr != nil {
 		t.Errorf("Could not handle subscriber delete: %v", delErr)
 	}
@@ -4,13 +4,29 @@
 package configapi
 
 import (
+	"encoding/hex"
+	"fmt"
+	"net"
 	"regexp"
 	"strconv"
+
+	"github.com/omec-project/webconsole/configmodels"
 )
 
 const (
 	NAME_PATTERN = "^[a-zA-Z][a-zA-Z0-9-_]{1,255}$"
 	FQDN_PATTERN = "^([a-zA-Z0-9][a-zA-Z0-9-]+\\.){2,}([a-zA-Z]{2,6})$"
+	IMSI_PATTERN = "^[0-9]{5,15}$"
+	MCC_PATTERN  = "^[0-9]{3}$"
+	MNC_PATTERN  = "^[0-9]{2,3}$"
+	DNN_PATTERN  = "^[a-zA-Z0-9]([a-zA-Z0-9.-]{0,98}[a-zA-Z0-9])?$"
+)
+
+const (
+	maxGnbId       = 1<<32 - 1
+	maxUpfCapacity = 65535
+	maxUpfPriority = 65535
+	maxUpfWeight   = 65535
 )
 
 func isValidName(name string) bool {
@@ -40,3 +56,124 @@ func isValidUpfPort(port string) bool {
 func isValidGnbTac(tac int32) bool {
 	return tac >= 1 && tac <= 16777215
 }
+
+func isValidImsi(imsi string) bool {
+	imsiMatch, err := regexp.MatchString(IMSI_PATTERN, imsi)
+	if err != nil {
+		return false
+	}
+	return imsiMatch
+}
+
+func isValidHexValue(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+func isValidIPAddress(address string) bool {
+	return net.ParseIP(address) != nil
+}
+
+func isValidPlmn(plmn configmodels.SliceSiteInfoPlmn) bool {
+	mccMatch, err := regexp.MatchString(MCC_PATTERN, plmn.Mcc)
+	if err != nil || !mccMatch {
+		return false
+	}
+	mncMatch, err := regexp.MatchString(MNC_PATTERN, plmn.Mnc)
+	return err == nil && mncMatch
+}
+
+func isValidSnssai(snssai configmodels.SliceSliceId) bool {
+	sst, err := strconv.Atoi(snssai.Sst)
+	if err != nil || sst < 0 || sst > 255 {
+		return false
+	}
+	return snssai.Sd == "" || isValidHexValue(snssai.Sd, 6)
+}
+
+func isValidDnn(dnn string) bool {
+	dnnMatch, err := regexp.MatchString(DNN_PATTERN, dnn)
+	if err != nil {
+		return false
+	}
+	return dnnMatch
+}
+
+// validateUpfInventory checks the optional inventory metadata of a UPF.
+func validateUpfInventory(inventory configmodels.UpfInventory) error {
+	if inventory.N3Address != "" && !isValidIPAddress(inventory.N3Address) {
+		return newValidationError("n3-address", "invalid UPF N3 address '%s'. N3 address must be an IPv4 or IPv6 address", inventory.N3Address)
+	}
+	if inventory.N4Address != "" && !isValidIPAddress(inventory.N4Address) {
+		return newValidationError("n4-address", "invalid UPF N4 address '%s'. N4 address must be an IPv4 or IPv6 address", inventory.N4Address)
+	}
+	for i, dnn := range inventory.Dnns {
+		if !isValidDnn(dnn) {
+			return newValidationError(fmt.Sprintf("dnns[%d]", i), "invalid UPF DNN '%s'. DNN needs to match the following regular expression: %s", dnn, DNN_PATTERN)
+		}
+	}
+	for i, snssai := range inventory.Snssais {
+		if !isValidSnssai(snssai) {
+			return newValidationError(fmt.Sprintf("snssais[%d]", i), "invalid UPF S-NSSAI '%+v'. SST must be an integer within the range [0, 255] and SD, if given, 6 hexadecimal digits", snssai)
+		}
+	}
+	if inventory.Capacity < 0 || inventory.Capacity > maxUpfCapacity {
+		return newValidationError("capacity", "invalid UPF capacity '%d'. Capacity must be an integer within the range [0, %d]", inventory.Capacity, maxUpfCapacity)
+	}
+	return nil
+}
+
+// validateGnbInventory checks the optional inventory metadata of a gNB.
+func validateGnbInventory(inventory configmodels.GnbInventory) error {
+	if inventory.GnbId != nil && (*inventory.GnbId < 0 || *inventory.GnbId > maxGnbId) {
+		return newValidationError("gnb-id", "invalid gNB ID '%d'. gNB ID must be an integer within the range [0, %d]", *inventory.GnbId, int64(maxGnbId))
+	}
+	if inventory.Plmn != nil && !isValidPlmn(*inventory.Plmn) {
+		return newValidationError("plmn", "invalid gNB PLMN '%+v'. MCC must be 3 digits and MNC 2 or 3 digits", *inventory.Plmn)
+	}
+	if inventory.N2Address != "" && !isValidIPAddress(inventory.N2Address) {
+		return newValidationError("n2-address", "invalid gNB N2 address '%s'. N2 address must be an IPv4 or IPv6 address", inventory.N2Address)
+	}
+	if inventory.SiteName != "" && !isValidName(inventory.SiteName) {
+		return newValidationError("site-name", "invalid gNB site name '%s'. Name needs to match the following regular expression: %s", inventory.SiteName, NAME_PATTERN)
+	}
+	if location := inventory.Location; location != nil {
+		if location.Latitude < -90 || location.Latitude > 90 || location.Longitude < -180 || location.Longitude > 180 {
+			return newValidationError("location", "invalid gNB location '%+v'. Latitude must be within the range [-90, 90] and longitude within [-180, 180]", *location)
+		}
+	}
+	return nil
+}
+
+// validateSliceUpfs checks the UPFs a network slice refers to.
+func validateSliceUpfs(sliceName string, upfs []configmodels.SliceSiteInfoUpf) error {
+	upfNames := map[string]bool{}
+	for i, upf := range upfs {
+		field := fmt.Sprintf("site-info.upfs[%d]", i)
+		if upf.UpfName == "" {
+			return newValidationError(field+".upf-name", "missing UPF name in Network Slice %s", sliceName)
+		}
+		if upfNames[upf.UpfName] {
+			return newValidationError(field+".upf-name", "duplicate UPF %s in Network Slice %s", upf.UpfName, sliceName)
+		}
+		upfNames[upf.UpfName] = true
+		if upf.UpfPort != "" && !isValidUpfPort(upf.UpfPort) {
+			return newValidationError(field+".upf-port", "invalid port '%s' for UPF %s in Network Slice %s. Port must be a numeric string within the range [0, 65535]", upf.UpfPort, upf.UpfName, sliceName)
+		}
+		if upf.Priority < 0 || upf.Priority > maxUpfPriority {
+			return newValidationError(field+".priority", "invalid priority %d for UPF %s in Network Slice %s. Priority must be an integer within the range [0, %d]", upf.Priority, upf.UpfName, sliceName, maxUpfPriority)
+		}
+		if upf.Weight < 0 || upf.Weight > maxUpfWeight {
+			return newValidationError(field+".weight", "invalid weight %d for UPF %s in Network Slice %s. Weight must be an integer within the range [0, %d]", upf.Weight, upf.UpfName, sliceName, maxUpfWeight)
+		}
+		for j, dnn := range upf.Dnns {
+			if !isValidDnn(dnn) {
+				return newValidationError(fmt.Sprintf("%s.dnns[%d]", field, j), "invalid DNN '%s' for UPF %s in Network Slice %s. DNN needs to match the following regular expression: %s", dnn, upf.UpfName, sliceName, DNN_PATTERN)
+			}
+		}
+	}
+	return nil
+}
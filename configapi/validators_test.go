@@ -6,6 +6,8 @@ package configapi
 import (
 	"strings"
 	"testing"
+
+	"github.com/omec-project/webconsole/configmodels"
 )
 
 func TestValidateName(t *testing.T) {
@@ -106,6 +108,82 @@ func TestValidateGnbTac(t *testing.T) {
 	}
 }
 
+func TestValidateUpfInventory(t *testing.T) {
+	testCases := []struct {
+		name      string
+		inventory configmodels.UpfInventory
+		expected  bool
+	}{
+		{"empty", configmodels.UpfInventory{}, true},
+		{"all fields", configmodels.UpfInventory{N3Address: "10.0.0.1", N4Address: "fd00::1", Dnns: []string{"internet", "ims.mnc001.mcc001.gprs"}, Snssais: []configmodels.SliceSliceId{{Sst: "1", Sd: "010203"}, {Sst: "2"}}, Capacity: 100}, true},
+		{"invalid N3 address", configmodels.UpfInventory{N3Address: "10.0.0"}, false},
+		{"invalid N4 address", configmodels.UpfInventory{N4Address: "upf.local"}, false},
+		{"invalid DNN", configmodels.UpfInventory{Dnns: []string{"-internet"}}, false},
+		{"invalid SST", configmodels.UpfInventory{Snssais: []configmodels.SliceSliceId{{Sst: "a"}}}, false},
+		{"invalid SD", configmodels.UpfInventory{Snssais: []configmodels.SliceSliceId{{Sst: "1", Sd: "0102"}}}, false},
+		{"negative capacity", configmodels.UpfInventory{Capacity: -1}, false},
+		{"capacity too high", configmodels.UpfInventory{Capacity: 65536}, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateUpfInventory(tc.inventory)
+		if (err == nil) != tc.expected {
+			t.Errorf("%s: expected valid=%v, got error %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestValidateGnbInventory(t *testing.T) {
+	gnbId := int64(4096)
+	invalidGnbId := int64(1 << 32)
+	testCases := []struct {
+		name      string
+		inventory configmodels.GnbInventory
+		expected  bool
+	}{
+		{"empty", configmodels.GnbInventory{}, true},
+		{"all fields", configmodels.GnbInventory{GnbId: &gnbId, Plmn: &configmodels.SliceSiteInfoPlmn{Mcc: "001", Mnc: "001"}, N2Address: "192.168.0.10", SiteName: "site-1", Location: &configmodels.GnbLocation{Latitude: -33.9, Longitude: 151.2}}, true},
+		{"gNB ID too high", configmodels.GnbInventory{GnbId: &invalidGnbId}, false},
+		{"invalid MCC", configmodels.GnbInventory{Plmn: &configmodels.SliceSiteInfoPlmn{Mcc: "01", Mnc: "01"}}, false},
+		{"invalid MNC", configmodels.GnbInventory{Plmn: &configmodels.SliceSiteInfoPlmn{Mcc: "001", Mnc: "1"}}, false},
+		{"invalid N2 address", configmodels.GnbInventory{N2Address: "256.0.0.1"}, false},
+		{"invalid site name", configmodels.GnbInventory{SiteName: "site 1"}, false},
+		{"invalid latitude", configmodels.GnbInventory{Location: &configmodels.GnbLocation{Latitude: 91}}, false},
+		{"invalid longitude", configmodels.GnbInventory{Location: &configmodels.GnbLocation{Longitude: -181}}, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateGnbInventory(tc.inventory)
+		if (err == nil) != tc.expected {
+			t.Errorf("%s: expected valid=%v, got error %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestValidateSliceUpfs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		upfs     []configmodels.SliceSiteInfoUpf
+		expected bool
+	}{
+		{"no UPF", nil, true},
+		{"all fields", []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", UpfPort: "8805", Priority: 1, Weight: 50, Dnns: []string{"internet"}}, {UpfName: "upf2.my-domain.com"}}, true},
+		{"missing name", []configmodels.SliceSiteInfoUpf{{UpfPort: "8805"}}, false},
+		{"duplicate UPF", []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com"}, {UpfName: "upf1.my-domain.com", Priority: 1}}, false},
+		{"invalid port", []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", UpfPort: "a"}}, false},
+		{"negative priority", []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", Priority: -1}}, false},
+		{"weight too high", []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", Weight: 65536}}, false},
+		{"invalid DNN", []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", Dnns: []string{"internet-"}}}, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateSliceUpfs("slice-1", tc.upfs)
+		if (err == nil) != tc.expected {
+			t.Errorf("%s: expected valid=%v, got error %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
 func genLongString(length int) string {
 	return strings.Repeat("a", length)
 }
@@ -0,0 +1,343 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/backend/logger"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	webhookSignatureHeader   = "X-Webconsole-Signature"
+	webhookDeliveryHeader    = "X-Webconsole-Delivery"
+	webhookEventHeader       = "X-Webconsole-Event"
+	webhookDispatchInterval  = time.Second
+	webhookDispatchBatchSize = 50
+	webhookMaxAttempts       = 10
+	webhookInitialBackoff    = 2 * time.Second
+	webhookMaxBackoff        = time.Hour
+	maxWebhookErrorLength    = 512
+)
+
+var webhookHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+var webhookKinds = []string{
+	configmodels.ApplyKindNetworkSlice,
+	configmodels.ApplyKindDeviceGroup,
+	configmodels.ConfigEventTypeSubscriber,
+	configmodels.ApplyKindGnb,
+	configmodels.ApplyKindUpf,
+}
+
+func validateWebhook(webhook configmodels.Webhook) error {
+	if !isValidName(webhook.Name) {
+		return newValidationError("name", "invalid webhook name '%s'. Name needs to match the following regular expression: %s", webhook.Name, NAME_PATTERN)
+	}
+	webhookUrl, err := url.Parse(webhook.Url)
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Host == "" {
+		return newValidationError("url", "invalid webhook URL '%s'. URL must be an absolute http or https URL", webhook.Url)
+	}
+	if webhook.Secret == "" {
+		return newValidationError("secret", "webhook secret must be set")
+	}
+	for i, kind := range webhook.Kinds {
+		if !slices.Contains(webhookKinds, kind) {
+			return newValidationError(fmt.Sprintf("kinds[%d]", i), "invalid webhook kind '%s'. Kind must be one of %v", kind, webhookKinds)
+		}
+	}
+	return nil
+}
+
+// configuredWebhooks returns the webhooks of the configuration file.
+func configuredWebhooks() []configmodels.Webhook {
+	var webhooks []configmodels.Webhook
+	for _, webhook := range factory.WebUIConfig.Configuration.Webhooks {
+		webhooks = append(webhooks, configmodels.Webhook{
+			Name:     webhook.Name,
+			Url:      webhook.Url,
+			Secret:   webhook.Secret,
+			Kinds:    webhook.Kinds,
+			ReadOnly: true,
+		})
+	}
+	return webhooks
+}
+
+// getWebhooks returns the webhooks of the configuration file followed by the
+// ones registered through the API.
+func getWebhooks() ([]configmodels.Webhook, error) {
+	rawWebhooks, err := dbadapter.CommonDBClient.RestfulAPIGetMany(configmodels.WebhookDataColl, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve webhooks: %w", err)
+	}
+	storedWebhooks, err := decodeDocuments[configmodels.Webhook](rawWebhooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode webhooks: %w", err)
+	}
+	return append(configuredWebhooks(), storedWebhooks...), nil
+}
+
+// getWebhook returns the webhook with the given name, or nil if it does not
+// exist.
+func getWebhook(name string) (*configmodels.Webhook, error) {
+	for _, webhook := range configuredWebhooks() {
+		if webhook.Name == name {
+			return &webhook, nil
+		}
+	}
+	rawWebhook, err := dbadapter.CommonDBClient.RestfulAPIGetOne(configmodels.WebhookDataColl, bson.M{"name": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve webhook %s: %w", name, err)
+	}
+	if len(rawWebhook) == 0 {
+		return nil, nil
+	}
+	webhooks, err := decodeDocuments[configmodels.Webhook]([]map[string]interface{}{rawWebhook})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode webhook %s: %w", name, err)
+	}
+	return &webhooks[0], nil
+}
+
+func webhookAcceptsKind(webhook configmodels.Webhook, kind string) bool {
+	return len(webhook.Kinds) == 0 || slices.Contains(webhook.Kinds, kind)
+}
+
+// webhookDeliveryDocument returns the stored form of a delivery. Times are
+// stored as dates so that the pending deliveries can be selected by their next
+// attempt.
+func webhookDeliveryDocument(delivery configmodels.WebhookDelivery) bson.M {
+	document := bson.M{
+		"id":      delivery.Id,
+		"webhook": delivery.Webhook,
+		"change": bson.M{
+			"kind":      delivery.Change.Kind,
+			"operation": delivery.Change.Operation,
+			"name":      delivery.Change.Name,
+			"timestamp": delivery.Change.Timestamp,
+		},
+		"status":       delivery.Status,
+		"attempts":     delivery.Attempts,
+		"next-attempt": delivery.NextAttempt,
+		"created-at":   delivery.CreatedAt,
+		"updated-at":   delivery.UpdatedAt,
+	}
+	if delivery.ResponseStatus != 0 {
+		document["response-status"] = delivery.ResponseStatus
+	}
+	if delivery.LastError != "" {
+		document["last-error"] = delivery.LastError
+	}
+	return document
+}
+
+// webhookNotifier queues a delivery of every change to each webhook accepting
+// its kind. The deliveries are stored with the context of the write, so they
+// are only queued if a surrounding transaction commits.
+type webhookNotifier struct{}
+
+func (webhookNotifier) notify(ctx context.Context, changes []configmodels.ConfigChange) error {
+	webhooks, err := getWebhooks()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	var deliveries []interface{}
+	for _, webhook := range webhooks {
+		for _, change := range changes {
+			if !webhookAcceptsKind(webhook, change.Kind) {
+				continue
+			}
+			deliveries = append(deliveries, webhookDeliveryDocument(configmodels.WebhookDelivery{
+				Id:          uuid.New().String(),
+				Webhook:     webhook.Name,
+				Change:      change,
+				Status:      configmodels.WebhookDeliveryPending,
+				NextAttempt: now,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}))
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err = dbadapter.CommonDBClient.RestfulAPIPostManyWithContext(ctx, configmodels.WebhookDeliveryDataColl, nil, deliveries); err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// webhookBackoff returns the delay before the next attempt of a delivery that
+// failed attempts times.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookInitialBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+// signWebhookPayload returns the HMAC-SHA256 signature of a payload, in the
+// format of the signature header.
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhook posts a delivery to a webhook and returns the response status.
+func postWebhook(ctx context.Context, webhook configmodels.Webhook, delivery configmodels.WebhookDelivery) (int, error) {
+	payload, err := json.Marshal(configmodels.WebhookPayload{DeliveryId: delivery.Id, ConfigChange: delivery.Change})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookDeliveryHeader, delivery.Id)
+	req.Header.Set(webhookEventHeader, delivery.Change.Kind+"."+delivery.Change.Operation)
+	req.Header.Set(webhookSignatureHeader, signWebhookPayload(webhook.Secret, payload))
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logger.WebUILog.Warnf("failed to close webhook response body: %+v", closeErr)
+		}
+	}()
+	if _, err = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024)); err != nil {
+		logger.WebUILog.Debugf("failed to read webhook response body: %+v", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// attemptWebhookDelivery posts a delivery and updates its status: delivered,
+// pending with a later next attempt, or failed once the attempts are exhausted
+// or the webhook no longer exists.
+func attemptWebhookDelivery(ctx context.Context, webhooks map[string]configmodels.Webhook, delivery configmodels.WebhookDelivery, now time.Time) configmodels.WebhookDelivery {
+	delivery.UpdatedAt = now
+	webhook, ok := webhooks[delivery.Webhook]
+	if !ok {
+		delivery.Status = configmodels.WebhookDeliveryFailed
+		delivery.LastError = "webhook no longer exists"
+		return delivery
+	}
+	delivery.Attempts++
+	statusCode, err := postWebhook(ctx, webhook, delivery)
+	delivery.ResponseStatus = statusCode
+	if err == nil {
+		delivery.Status = configmodels.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		return delivery
+	}
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > maxWebhookErrorLength {
+		delivery.LastError = delivery.LastError[:maxWebhookErrorLength]
+	}
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = configmodels.WebhookDeliveryFailed
+		return delivery
+	}
+	delivery.NextAttempt = now.Add(webhookBackoff(delivery.Attempts))
+	return delivery
+}
+
+// dispatchWebhookDeliveries attempts the pending deliveries that are due.
+func dispatchWebhookDeliveries(ctx context.Context, now time.Time) error {
+	filter := bson.M{
+		"status":       configmodels.WebhookDeliveryPending,
+		"next-attempt": bson.M{"$lte": now},
+	}
+	rawDeliveries, err := dbadapter.CommonDBClient.RestfulAPIGetManyPaged(configmodels.WebhookDeliveryDataColl, filter,
+		bson.D{{Key: "next-attempt", Value: 1}}, 0, webhookDispatchBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve pending webhook deliveries: %w", err)
+	}
+	if len(rawDeliveries) == 0 {
+		return nil
+	}
+	deliveries, err := decodeDocuments[configmodels.WebhookDelivery](rawDeliveries)
+	if err != nil {
+		return fmt.Errorf("failed to decode pending webhook deliveries: %w", err)
+	}
+	webhookList, err := getWebhooks()
+	if err != nil {
+		return err
+	}
+	webhooks := map[string]configmodels.Webhook{}
+	for _, webhook := range webhookList {
+		webhooks[webhook.Name] = webhook
+	}
+	for _, delivery := range deliveries {
+		delivery = attemptWebhookDelivery(ctx, webhooks, delivery, now)
+		if delivery.Status != configmodels.WebhookDeliveryDelivered {
+			logger.WebUILog.Warnf("webhook %s delivery %s attempt %d failed: %s", delivery.Webhook, delivery.Id, delivery.Attempts, delivery.LastError)
+		}
+		_, err = dbadapter.CommonDBClient.RestfulAPIPutOne(configmodels.WebhookDeliveryDataColl, bson.M{"id": delivery.Id}, webhookDeliveryDocument(delivery))
+		if err != nil {
+			logger.DbLog.Errorf("failed to update webhook delivery %s: %+v", delivery.Id, err)
+		}
+	}
+	return nil
+}
+
+// StartWebhookDispatcher registers the webhook notifier and delivers the queued
+// webhook notifications until ctx is done. Deliveries left pending by a previous
+// run are resumed.
+func StartWebhookDispatcher(ctx context.Context) {
+	configNotifiers = append(configNotifiers, webhookNotifier{})
+	go func() {
+		ticker := time.NewTicker(webhookDispatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := dispatchWebhookDeliveries(ctx, time.Now().UTC()); err != nil {
+					logger.WebUILog.Errorln(err)
+				}
+			}
+		}
+	}()
+}
+
+// getWebhookDeliveries returns the deliveries of a webhook, newest first,
+// optionally only those with the given status.
+func getWebhookDeliveries(name, status string, offset, limit int64) ([]configmodels.WebhookDelivery, error) {
+	filter := bson.M{"webhook": name}
+	if status != "" {
+		filter["status"] = status
+	}
+	rawDeliveries, err := dbadapter.CommonDBClient.RestfulAPIGetManyPaged(configmodels.WebhookDeliveryDataColl, filter,
+		bson.D{{Key: "created-at", Value: -1}}, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve webhook deliveries: %w", err)
+	}
+	return decodeDocuments[configmodels.WebhookDelivery](rawDeliveries)
+}
@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/webconsole/backend/factory"
+	"github.com/omec-project/webconsole/configmodels"
+	"github.com/omec-project/webconsole/dbadapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MockMongoClientWebhooks struct {
+	dbadapter.DBInterface
+	webhooks   []map[string]interface{}
+	deliveries []bson.M
+}
+
+func (m *MockMongoClientWebhooks) RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error) {
+	return m.webhooks, nil
+}
+
+func (m *MockMongoClientWebhooks) RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error) {
+	for _, webhook := range m.webhooks {
+		if webhook["name"] == filter["name"] {
+			return webhook, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockMongoClientWebhooks) RestfulAPIPost(collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
+	m.webhooks = append(m.webhooks, postData)
+	return false, nil
+}
+
+func (m *MockMongoClientWebhooks) RestfulAPIDeleteOne(collName string, filter bson.M) error {
+	for i, webhook := range m.webhooks {
+		if webhook["name"] == filter["name"] {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *MockMongoClientWebhooks) RestfulAPIPostManyWithContext(ctx context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
+	for _, data := range postDataArray {
+		m.deliveries = append(m.deliveries, data.(bson.M))
+	}
+	return nil
+}
+
+func (m *MockMongoClientWebhooks) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	for _, delivery := range m.deliveries {
+		if status, ok := filter["status"]; ok && delivery["status"] != status {
+			continue
+		}
+		if webhook, ok := filter["webhook"]; ok && delivery["webhook"] != webhook {
+			continue
+		}
+		if nextAttempt, ok := filter["next-attempt"].(bson.M); ok && delivery["next-attempt"].(time.Time).After(nextAttempt["$lte"].(time.Time)) {
+			continue
+		}
+		results = append(results, delivery)
+	}
+	return results, nil
+}
+
+func (m *MockMongoClientWebhooks) RestfulAPIPutOne(collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	for i, delivery := range m.deliveries {
+		if delivery["id"] == filter["id"] {
+			m.deliveries[i] = putData
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func webhookDocument(webhook configmodels.Webhook) map[string]interface{} {
+	return configmodels.ToBsonM(webhook)
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{5, 32 * time.Second},
+		{20, time.Hour},
+	}
+	for _, tc := range testCases {
+		if backoff := webhookBackoff(tc.attempts); backoff != tc.expected {
+			t.Errorf("expected backoff %v after %d attempts, got %v", tc.expected, tc.attempts, backoff)
+		}
+	}
+}
+
+func TestWebhookNotifierQueuesMatchingDeliveries(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	origConfig := factory.WebUIConfig
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		factory.WebUIConfig = origConfig
+	}()
+	factory.WebUIConfig = &factory.Config{Configuration: &factory.Configuration{
+		Webhooks: []*factory.Webhook{{Name: "all", Url: "http://example.com/all", Secret: "s1"}},
+	}}
+	dbClient := &MockMongoClientWebhooks{webhooks: []map[string]interface{}{
+		webhookDocument(configmodels.Webhook{Name: "slices", Url: "http://example.com/slices", Secret: "s2", Kinds: []string{configmodels.ApplyKindNetworkSlice}}),
+	}}
+	dbadapter.CommonDBClient = dbClient
+
+	changes := []configmodels.ConfigChange{
+		newConfigChange(configmodels.ApplyKindNetworkSlice, configmodels.ApplyOpCreate, "slice1"),
+		newConfigChange(configmodels.ConfigEventTypeSubscriber, configmodels.ApplyOpDelete, "imsi-001010000000001"),
+	}
+	if err := (webhookNotifier{}).notify(context.Background(), changes); err != nil {
+		t.Fatalf("failed to notify changes: %v", err)
+	}
+
+	var queued []string
+	for _, delivery := range dbClient.deliveries {
+		change := delivery["change"].(bson.M)
+		queued = append(queued, delivery["webhook"].(string)+":"+change["name"].(string))
+		if delivery["status"] != configmodels.WebhookDeliveryPending {
+			t.Errorf("expected a pending delivery, got %v", delivery["status"])
+		}
+	}
+	expected := []string{"all:slice1", "all:imsi-001010000000001", "slices:slice1"}
+	if !reflect.DeepEqual(queued, expected) {
+		t.Errorf("expected deliveries %v, got %v", expected, queued)
+	}
+}
+
+func TestDispatchWebhookDeliveries(t *testing.T) {
+	origCommonDB := dbadapter.CommonDBClient
+	origConfig := factory.WebUIConfig
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		factory.WebUIConfig = origConfig
+	}()
+	factory.WebUIConfig = &factory.Config{Configuration: &factory.Configuration{}}
+
+	var requests []*http.Request
+	var bodies [][]byte
+	responses := []int{http.StatusInternalServerError, http.StatusNoContent}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(responses[len(requests)-1])
+	}))
+	defer server.Close()
+
+	dbClient := &MockMongoClientWebhooks{webhooks: []map[string]interface{}{
+		webhookDocument(configmodels.Webhook{Name: "hook", Url: server.URL, Secret: "secret"}),
+	}}
+	dbadapter.CommonDBClient = dbClient
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	change := configmodels.ConfigChange{Kind: configmodels.ApplyKindGnb, Operation: configmodels.ApplyOpUpdate, Name: "gnb1", Timestamp: now}
+	for _, delivery := range []configmodels.WebhookDelivery{
+		{Id: "1", Webhook: "hook", Change: change, Status: configmodels.WebhookDeliveryPending, NextAttempt: now, CreatedAt: now},
+		{Id: "2", Webhook: "removed", Change: change, Status: configmodels.WebhookDeliveryPending, NextAttempt: now, CreatedAt: now},
+	} {
+		dbClient.deliveries = append(dbClient.deliveries, webhookDeliveryDocument(delivery))
+	}
+
+	steps := []struct {
+		now              time.Time
+		expectedRequests int
+		expectedStatus   []string
+	}{
+		{now, 1, []string{configmodels.WebhookDeliveryPending, configmodels.WebhookDeliveryFailed}},
+		{now.Add(time.Second), 1, []string{configmodels.WebhookDeliveryPending, configmodels.WebhookDeliveryFailed}},
+		{now.Add(webhookInitialBackoff), 2, []string{configmodels.WebhookDeliveryDelivered, configmodels.WebhookDeliveryFailed}},
+	}
+	for i, step := range steps {
+		if err := dispatchWebhookDeliveries(context.Background(), step.now); err != nil {
+			t.Fatalf("step %d: failed to dispatch deliveries: %v", i, err)
+		}
+		if len(requests) != step.expectedRequests {
+			t.Fatalf("step %d: expected %d requests, got %d", i, step.expectedRequests, len(requests))
+		}
+		var statuses []string
+		for _, delivery := range dbClient.deliveries {
+			statuses = append(statuses, delivery["status"].(string))
+		}
+		if !reflect.DeepEqual(statuses, step.expectedStatus) {
+			t.Errorf("step %d: expected statuses %v, got %v", i, step.expectedStatus, statuses)
+		}
+	}
+
+	if attempts := dbClient.deliveries[0]["attempts"]; attempts != 2 {
+		t.Errorf("expected 2 attempts, got %v", attempts)
+	}
+	for i, req := range requests {
+		if signature := req.Header.Get(webhookSignatureHeader); signature != signWebhookPayload("secret", bodies[i]) {
+			t.Errorf("request %d has an invalid signature %s", i, signature)
+		}
+		if event := req.Header.Get(webhookEventHeader); event != "gnb.update" {
+			t.Errorf("request %d has event %s", i, event)
+		}
+		var payload configmodels.WebhookPayload
+		if err := json.Unmarshal(bodies[i], &payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if payload.DeliveryId != "1" || payload.Name != "gnb1" || !payload.Timestamp.Equal(now) {
+			t.Errorf("unexpected payload %+v", payload)
+		}
+	}
+}
+
+func TestWebhookAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	AddWebhookService(router, nil)
+	origCommonDB := dbadapter.CommonDBClient
+	origWebuiDB := dbadapter.WebuiDBClient
+	origConfig := factory.WebUIConfig
+	defer func() {
+		dbadapter.CommonDBClient = origCommonDB
+		dbadapter.WebuiDBClient = origWebuiDB
+		factory.WebUIConfig = origConfig
+	}()
+	// the webhook writes are audited
+	resetAuditHashKey(t)
+	auditLog := &MockMongoClientAudit{}
+	dbadapter.WebuiDBClient = auditLog
+	factory.WebUIConfig = &factory.Config{Configuration: &factory.Configuration{
+		Webhooks: []*factory.Webhook{{Name: "configured", Url: "http://example.com", Secret: "s1"}},
+	}}
+	dbadapter.CommonDBClient = &MockMongoClientWebhooks{}
+
+	steps := []struct {
+		name         string
+		method       string
+		route        string
+		body         string
+		expectedCode int
+	}{
+		{"create", http.MethodPost, "/config/v1/webhooks", `{"name": "hook", "url": "https://example.com/hook", "secret": "s2", "kinds": ["upf"]}`, http.StatusCreated},
+		{"create duplicate", http.MethodPost, "/config/v1/webhooks", `{"name": "hook", "url": "https://example.com/hook", "secret": "s2"}`, http.StatusConflict},
+		{"create without secret", http.MethodPost, "/config/v1/webhooks", `{"name": "other", "url": "https://example.com/hook"}`, http.StatusBadRequest},
+		{"create with invalid URL", http.MethodPost, "/config/v1/webhooks", `{"name": "other", "url": "example.com", "secret": "s"}`, http.StatusBadRequest},
+		{"create with invalid kind", http.MethodPost, "/config/v1/webhooks", `{"name": "other", "url": "https://example.com", "secret": "s", "kinds": ["pcf"]}`, http.StatusBadRequest},
+		{"list", http.MethodGet, "/config/v1/webhooks", "", http.StatusOK},
+		{"get", http.MethodGet, "/config/v1/webhooks/hook", "", http.StatusOK},
+		{"delete configured", http.MethodDelete, "/config/v1/webhooks/configured", "", http.StatusConflict},
+		{"deliveries", http.MethodGet, "/config/v1/webhooks/hook/deliveries?status=failed", "", http.StatusOK},
+		{"deliveries with invalid status", http.MethodGet, "/config/v1/webhooks/hook/deliveries?status=lost", "", http.StatusBadRequest},
+		{"delete", http.MethodDelete, "/config/v1/webhooks/hook", "", http.StatusOK},
+		{"get deleted", http.MethodGet, "/config/v1/webhooks/hook", "", http.StatusNotFound},
+	}
+	for _, step := range steps {
+		req, err := http.NewRequest(step.method, step.route, strings.NewReader(step.body))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != step.expectedCode {
+			t.Fatalf("%s: expected %d, got %d: %s", step.name, step.expectedCode, w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "s1") || strings.Contains(w.Body.String(), "s2") {
+			t.Errorf("%s: response leaks a webhook secret: %s", step.name, w.Body.String())
+		}
+		if step.name == "list" {
+			var webhooks []configmodels.Webhook
+			if err = json.Unmarshal(w.Body.Bytes(), &webhooks); err != nil {
+				t.Fatalf("failed to decode webhooks: %v", err)
+			}
+			expected := []configmodels.Webhook{
+				{Name: "configured", Url: "http://example.com", ReadOnly: true},
+				{Name: "hook", Url: "https://example.com/hook", Kinds: []string{configmodels.ApplyKindUpf}},
+			}
+			if !reflect.DeepEqual(webhooks, expected) {
+				t.Errorf("expected webhooks %+v, got %+v", expected, webhooks)
+			}
+		}
+	}
+	if len(auditLog.entries) != 7 {
+		t.Errorf("expected an audit log entry per write, got %d", len(auditLog.entries))
+	}
+}
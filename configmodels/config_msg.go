@@ -19,6 +19,9 @@ const (
 	Device_group = iota
 	Network_slice
 	Sub_data
+	// Config_sync announces many device groups and network slices at once, such
+	// as the ones of a configuration import
+	Config_sync
 )
 
 type ConfigMessage struct {
@@ -28,8 +31,19 @@ type ConfigMessage struct {
 	DevGroupName string
 	SliceName    string
 	Imsi         string
-	MsgType      int
-	MsgMethod    int
+	// Imsis are the subscribers of a Sub_data message about several of them,
+	// such as the ones created in bulk, in place of Imsi
+	Imsis []string
+	// DevGroups, Slices, DeletedDevGroupNames and DeletedSliceNames are the
+	// changes of a Config_sync message
+	DevGroups            []*DeviceGroups
+	Slices               []*Slice
+	DeletedDevGroupNames []string
+	DeletedSliceNames    []string
+	MsgType              int
+	MsgMethod            int
+	// RequestID is the ID of the API request that caused the change, if any
+	RequestID string
 }
 
 // Slice + attached device group
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const ApiTokenDataColl = "webconsoleData.snapshots.apiTokenData"
+
+// API token scopes. A token without scopes has the full access of its account.
+// A scoped token can read everything and only write the resources its scopes
+// cover; read-only gives no write access at all.
+const (
+	ApiTokenScopeReadOnly          = "read-only"
+	ApiTokenScopeSubscribersWrite  = "subscribers:write"
+	ApiTokenScopeSlicesWrite       = "slices:write"
+	ApiTokenScopeDeviceGroupsWrite = "device-groups:write"
+	ApiTokenScopeInventoryWrite    = "inventory:write"
+)
+
+var ApiTokenScopes = []string{
+	ApiTokenScopeReadOnly,
+	ApiTokenScopeSubscribersWrite,
+	ApiTokenScopeSlicesWrite,
+	ApiTokenScopeDeviceGroupsWrite,
+	ApiTokenScopeInventoryWrite,
+}
+
+// DBApiToken is a long-lived token authenticating as the account Username. Only
+// the SHA-256 hash of the token is stored.
+type DBApiToken struct {
+	Id        string     `json:"id"`
+	Username  string     `json:"username"`
+	Name      string     `json:"name"`
+	TokenHash string     `json:"token-hash"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	CreatedAt time.Time  `json:"created-at"`
+	ExpiresAt *time.Time `json:"expires-at,omitempty"`
+}
+
+type CreateApiTokenParams struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires-at,omitempty"`
+}
+
+type ApiTokenResponse struct {
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	CreatedAt time.Time  `json:"created-at"`
+	ExpiresAt *time.Time `json:"expires-at,omitempty"`
+}
+
+// CreateApiTokenResponse returns the token itself, which cannot be retrieved
+// again after its creation.
+type CreateApiTokenResponse struct {
+	ApiTokenResponse
+	Token string `json:"token"`
+}
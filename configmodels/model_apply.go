@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+const (
+	ApplyKindGnb          = "gnb"
+	ApplyKindUpf          = "upf"
+	ApplyKindDeviceGroup  = "device-group"
+	ApplyKindNetworkSlice = "network-slice"
+)
+
+const (
+	ApplyOpCreate = "create"
+	ApplyOpUpdate = "update"
+	ApplyOpDelete = "delete"
+)
+
+// DesiredState describes the complete network configuration. Objects that
+// exist in the database but not in the desired state are deleted.
+type DesiredState struct {
+	Gnbs          []Gnb          `json:"gnbs"`
+	Upfs          []Upf          `json:"upfs"`
+	DeviceGroups  []DeviceGroups `json:"device-groups"`
+	NetworkSlices []Slice        `json:"network-slices"`
+}
+
+type ApplyChange struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Operation string   `json:"operation"`
+	Fields    []string `json:"fields,omitempty"`
+}
+
+type ApplyResult struct {
+	DryRun  bool          `json:"dry-run"`
+	Applied bool          `json:"applied"`
+	Changes []ApplyChange `json:"changes"`
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const (
+	AuditLogDataColl = "webconsoleData.snapshots.auditLogData"
+	AuditKeyDataColl = "webconsoleData.snapshots.auditKeyData"
+)
+
+// AuditLogEntry records a mutating API call. The state of the resource before
+// and after the call is only kept as an HMAC-SHA-256 hash, keyed with a secret
+// of the server, so that secrets such as subscriber keys and password hashes
+// never end up in the audit log. Username and Role are empty when
+// authentication is disabled.
+type AuditLogEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Username   string    `json:"username,omitempty"`
+	Role       *int      `json:"role,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	ResourceID string    `json:"resource-id,omitempty"`
+	Status     int       `json:"status"`
+	BeforeHash string    `json:"before-hash,omitempty"`
+	AfterHash  string    `json:"after-hash,omitempty"`
+	RequestID  string    `json:"request-id,omitempty"`
+}
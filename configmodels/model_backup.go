@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	BackupFormat  = "webconsole-backup"
+	BackupVersion = 1
+)
+
+const (
+	ImportModeMerge   = "merge"
+	ImportModeReplace = "replace"
+	ImportModeDryRun  = "dry-run"
+)
+
+// BackupHeader is the first line of a JSON lines backup and the manifest of a tar.gz backup.
+type BackupHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupRecord is a document of a JSON lines backup. Document is MongoDB relaxed extended JSON.
+type BackupRecord struct {
+	Collection string          `json:"collection"`
+	Document   json.RawMessage `json:"document"`
+}
+
+type ImportCollectionReport struct {
+	Documents int `json:"documents"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+}
+
+type ImportReport struct {
+	Mode        string                            `json:"mode"`
+	Applied     bool                              `json:"applied"`
+	Collections map[string]ImportCollectionReport `json:"collections"`
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+const (
+	BulkKeyDerivationStatic     = "static"
+	BulkKeyDerivationHmacSha256 = "hmac-sha256"
+)
+
+const (
+	BulkResultCreated  = "created"
+	BulkResultInvalid  = "invalid"
+	BulkResultConflict = "conflict"
+	BulkResultFailed   = "failed"
+)
+
+type BulkSubscriber struct {
+	UeId string `json:"ueId"`
+	SubsOverrideData
+}
+
+// BulkSubscriberRange describes a block of consecutive IMSIs provisioned
+// with keys derived according to KeyDerivation.
+type BulkSubscriberRange struct {
+	StartImsi      string            `json:"startImsi"`
+	Count          int               `json:"count"`
+	SequenceNumber string            `json:"sequenceNumber"`
+	KeyDerivation  BulkKeyDerivation `json:"keyDerivation"`
+}
+
+// BulkKeyDerivation selects how the Ki and OPc of every IMSI in a range are computed.
+// With the "static" method every IMSI gets Key. With "hmac-sha256" the Ki is the first
+// 16 bytes of HMAC-SHA256(MasterKey, IMSI). The OPc is either the static OPc value or
+// computed per Ki from the operator OP.
+type BulkKeyDerivation struct {
+	Method    string `json:"method"`
+	Key       string `json:"key,omitempty"`
+	MasterKey string `json:"masterKey,omitempty"`
+	OPc       string `json:"opc,omitempty"`
+	OP        string `json:"op,omitempty"`
+}
+
+type BulkSubscriberResult struct {
+	Row    int    `json:"row"`
+	UeId   string `json:"ueId,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkSubscriberReport struct {
+	Created     int                    `json:"created"`
+	Rejected    int                    `json:"rejected"`
+	DeviceGroup string                 `json:"deviceGroup,omitempty"`
+	Results     []BulkSubscriberResult `json:"results"`
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const (
+	ConfigEventDataColl    = "webconsoleData.snapshots.configEventData"
+	ConfigEventCounterColl = "webconsoleData.snapshots.configEventCounter"
+)
+
+const ConfigEventTypeSubscriber = "subscriber"
+
+// ConfigEvent notifies a change of the configuration. Type is
+// ApplyKindDeviceGroup, ApplyKindNetworkSlice or ConfigEventTypeSubscriber and
+// Operation takes the ApplyOp* values. Revision increases with every event, so
+// that a consumer can resume from the last event it received.
+type ConfigEvent struct {
+	Revision  int64     `json:"revision"`
+	Type      string    `json:"type"`
+	Operation string    `json:"operation"`
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+}
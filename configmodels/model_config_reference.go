@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+// ConfigReference describes a reference between configuration objects that
+// prevents a change. Kind and Name identify the object at the other end of the
+// reference.
+type ConfigReference struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
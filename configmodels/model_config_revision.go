@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const ConfigRevisionDataColl = "webconsoleData.snapshots.configRevisionData"
+
+// ConfigRevision records a write to a network slice, device group, gNB or UPF.
+// Kind and Operation take the ApplyKind* and ApplyOp* values. Document is the
+// object as stored after the write and is empty for a delete.
+type ConfigRevision struct {
+	Kind      string                 `json:"kind"`
+	Name      string                 `json:"name"`
+	Revision  int64                  `json:"revision"`
+	Operation string                 `json:"operation"`
+	Author    string                 `json:"author"`
+	Timestamp time.Time              `json:"timestamp"`
+	Diff      []ConfigFieldDiff      `json:"diff,omitempty"`
+	Document  map[string]interface{} `json:"document,omitempty"`
+}
+
+type ConfigFieldDiff struct {
+	Field    string      `json:"field"`
+	Previous interface{} `json:"previous,omitempty"`
+	Current  interface{} `json:"current,omitempty"`
+}
@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+// IdempotencyKeyDataColl holds the requests sent with an Idempotency-Key
+// header and their responses, until the configured window expires.
+const IdempotencyKeyDataColl = "webconsoleData.snapshots.idempotencyKeyData"
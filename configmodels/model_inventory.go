@@ -11,27 +11,58 @@ const (
 type Gnb struct {
 	Name string `json:"name"`
 	Tac  *int32 `json:"tac,omitempty"`
+	GnbInventory
+}
+
+// GnbInventory holds the optional inventory metadata of a gNB.
+type GnbInventory struct {
+	GnbId     *int64             `json:"gnb-id,omitempty"`
+	Plmn      *SliceSiteInfoPlmn `json:"plmn,omitempty"`
+	N2Address string             `json:"n2-address,omitempty"`
+	SiteName  string             `json:"site-name,omitempty"`
+	Location  *GnbLocation       `json:"location,omitempty"`
+}
+
+type GnbLocation struct {
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Description string  `json:"description,omitempty"`
 }
 
 type PostGnbRequest struct {
 	Name string `json:"name"`
 	Tac  *int32 `json:"tac"`
+	GnbInventory
 }
 
 type PutGnbRequest struct {
 	Tac int32 `json:"tac"`
+	GnbInventory
 }
 
 type Upf struct {
 	Hostname string `json:"hostname"`
 	Port     string `json:"port"`
+	UpfInventory
+}
+
+// UpfInventory holds the optional inventory metadata of a UPF. Capacity is
+// the relative weight of the UPF for the UPF selection, 0 if unknown.
+type UpfInventory struct {
+	N3Address string         `json:"n3-address,omitempty"`
+	N4Address string         `json:"n4-address,omitempty"`
+	Dnns      []string       `json:"dnns,omitempty"`
+	Snssais   []SliceSliceId `json:"snssais,omitempty"`
+	Capacity  int32          `json:"capacity,omitempty"`
 }
 
 type PostUpfRequest struct {
 	Hostname string `json:"hostname"`
 	Port     string `json:"port"`
+	UpfInventory
 }
 
 type PutUpfRequest struct {
 	Port string `json:"port"`
+	UpfInventory
 }
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const JwtKeyDataColl = "webconsoleData.snapshots.jwtKeyData"
+
+// DBJwtKey is a key signing the authentication tokens, shared by the webconsole
+// replicas. Key is the base64 encoded secret of an HS256 key, or the PKCS #8
+// PEM private key of an RS256 or EdDSA key. A key replaced by a newer one gets
+// an expiry date, after which the tokens it signed have all expired.
+type DBJwtKey struct {
+	Kid       string     `json:"kid"`
+	Algorithm string     `json:"algorithm"`
+	Key       string     `json:"key"`
+	CreatedAt time.Time  `json:"created-at"`
+	ExpiresAt *time.Time `json:"expires-at,omitempty"`
+}
@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const LoginFailureDataColl = "webconsoleData.snapshots.loginFailureData"
+
+// DBLoginFailures counts the failed logins of an account or of a client IP
+// address, Key being "account:<username>" or "ip:<address>". The count is
+// dropped at ExpiresAt, which is the end of the lockout once Failures reached
+// the limit.
+type DBLoginFailures struct {
+	Key       string    `json:"key"`
+	Failures  int       `json:"failures"`
+	ExpiresAt time.Time `json:"expires-at"`
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const OIDCLoginDataColl = "webconsoleData.snapshots.oidcLoginData"
+
+// DBOIDCLogin is a login in progress with the OpenID Connect provider, from the
+// redirection to the provider until its callback with the matching State.
+type DBOIDCLogin struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code-verifier"`
+	ExpiresAt    time.Time `json:"expires-at"`
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+// Stable error codes of the problem details returned by the configuration API.
+const (
+	ProblemCodeInvalidRequest       = "invalid-request"
+	ProblemCodeValidationFailed     = "validation-failed"
+	ProblemCodeUnsupportedMediaType = "unsupported-media-type"
+	ProblemCodePayloadTooLarge      = "payload-too-large"
+	ProblemCodeUnauthorized         = "unauthorized"
+	ProblemCodeForbidden            = "forbidden"
+	ProblemCodeNotFound             = "not-found"
+	ProblemCodeAlreadyExists        = "already-exists"
+	ProblemCodeStillReferenced      = "still-referenced"
+	ProblemCodeReadOnly             = "read-only"
+	ProblemCodeInvalidReferences    = "invalid-references"
+	ProblemCodePreconditionFailed   = "precondition-failed"
+	ProblemCodeConcurrentUpdate     = "concurrent-update"
+	ProblemCodePatchTestFailed      = "patch-test-failed"
+	ProblemCodeIdempotencyKeyInUse  = "idempotency-key-in-use"
+	ProblemCodeIdempotencyKeyReused = "idempotency-key-reused"
+	ProblemCodeInternalError        = "internal-error"
+)
+
+// ProblemDetails - RFC 7807 problem details, served as application/problem+json.
+// Code is stable and meant for programs, Title and Detail for humans. Changes
+// lists the changes of a desired state applied before a failure.
+type ProblemDetails struct {
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	Status     int               `json:"status"`
+	Detail     string            `json:"detail,omitempty"`
+	Instance   string            `json:"instance,omitempty"`
+	Code       string            `json:"code"`
+	RequestID  string            `json:"request_id,omitempty"`
+	Errors     []FieldError      `json:"errors,omitempty"`
+	References []ConfigReference `json:"references,omitempty"`
+	Changes    []ApplyChange     `json:"changes,omitempty"`
+}
+
+// FieldError - invalid field of a request body. Field is the JSON path of the
+// field, like site-info.gNodeBs[0].tac.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "slices"
+
+const RoleDataColl = "webconsoleData.snapshots.roleData"
+
+// Resource types permissions are granted on.
+const (
+	ResourceNetworkSlice  = "network-slice"
+	ResourceDeviceGroup   = "device-group"
+	ResourceSubscriber    = "subscriber"
+	ResourceInventory     = "inventory"
+	ResourceConfiguration = "configuration"
+)
+
+// Verbs permissions are granted for. They follow the HTTP method of the
+// request: GET reads, POST creates, PUT and PATCH update and DELETE deletes.
+const (
+	VerbRead   = "read"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+)
+
+// Built-in roles. The admin role is held by the admin account only, the user
+// role is the role of accounts without assigned roles.
+const (
+	RoleAdmin              = "admin"
+	RoleUser               = "user"
+	RoleNetworkEngineer    = "network-engineer"
+	RoleSubscriberOperator = "subscriber-operator"
+	RoleViewer             = "viewer"
+)
+
+var (
+	Resources = []string{ResourceNetworkSlice, ResourceDeviceGroup, ResourceSubscriber, ResourceInventory, ResourceConfiguration}
+	Verbs     = []string{VerbRead, VerbCreate, VerbUpdate, VerbDelete}
+)
+
+// Permission allows a verb on a resource type. Names restricts it to the named
+// network slices or device groups; a permission without names applies to all
+// of them. A restricted permission does not allow the requests that are not
+// about one of them, such as listing them.
+type Permission struct {
+	Resource string   `json:"resource"`
+	Verb     string   `json:"verb"`
+	Names    []string `json:"names,omitempty"`
+}
+
+// Role is a named set of permissions, either built in or defined by an admin.
+type Role struct {
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+	BuiltIn     bool         `json:"built-in,omitempty"`
+}
+
+type AssignRolesParams struct {
+	Roles []string `json:"roles"`
+}
+
+// Allows reports whether the role grants verb on the resource. name is the
+// network slice or device group the request is about, if any; without one,
+// only the permissions without names apply.
+func (r Role) Allows(resource, verb, name string) bool {
+	for _, permission := range r.Permissions {
+		if permission.Resource != resource || permission.Verb != verb {
+			continue
+		}
+		if len(permission.Names) == 0 || (name != "" && slices.Contains(permission.Names, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func allPermissions(resources []string, verbs []string) []Permission {
+	permissions := make([]Permission, 0, len(resources)*len(verbs))
+	for _, resource := range resources {
+		for _, verb := range verbs {
+			permissions = append(permissions, Permission{Resource: resource, Verb: verb})
+		}
+	}
+	return permissions
+}
+
+var BuiltInRoles = map[string]Role{
+	RoleAdmin: {
+		Name:        RoleAdmin,
+		Permissions: allPermissions(Resources, Verbs),
+		BuiltIn:     true,
+	},
+	RoleUser: {
+		Name:        RoleUser,
+		Permissions: allPermissions(Resources, Verbs),
+		BuiltIn:     true,
+	},
+	RoleNetworkEngineer: {
+		Name: RoleNetworkEngineer,
+		Permissions: append(
+			allPermissions([]string{ResourceNetworkSlice, ResourceDeviceGroup, ResourceInventory, ResourceConfiguration}, Verbs),
+			Permission{Resource: ResourceSubscriber, Verb: VerbRead},
+		),
+		BuiltIn: true,
+	},
+	RoleSubscriberOperator: {
+		Name: RoleSubscriberOperator,
+		Permissions: append(
+			allPermissions([]string{ResourceNetworkSlice, ResourceDeviceGroup, ResourceInventory, ResourceConfiguration}, []string{VerbRead}),
+			allPermissions([]string{ResourceSubscriber}, Verbs)...,
+		),
+		BuiltIn: true,
+	},
+	RoleViewer: {
+		Name:        RoleViewer,
+		Permissions: allPermissions(Resources, []string{VerbRead}),
+		BuiltIn:     true,
+	},
+}
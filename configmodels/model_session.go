@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const SessionDataColl = "webconsoleData.snapshots.sessionData"
+
+// DBSession is a login session of the account Username. The access tokens of
+// the session are only valid while it exists, so deleting it revokes them.
+// Only the SHA-256 hashes of the refresh tokens are stored: the current one and
+// the ones already used, to spot a refresh token being replayed.
+type DBSession struct {
+	Id                     string    `json:"id"`
+	Username               string    `json:"username"`
+	RefreshTokenHash       string    `json:"refresh-token-hash"`
+	UsedRefreshTokenHashes []string  `json:"used-refresh-token-hashes,omitempty"`
+	CreatedAt              time.Time `json:"created-at"`
+	ExpiresAt              time.Time `json:"expires-at"`
+}
@@ -14,6 +14,12 @@
 
 package configmodels
 
+import (
+	"cmp"
+	"slices"
+	"strconv"
+)
+
 // SliceSiteInfo - give details of the site where this device group is activated
 type SliceSiteInfo struct {
 	// Unique name per Site.
@@ -23,6 +29,82 @@ type SliceSiteInfo struct {
 
 	GNodeBs []SliceSiteInfoGNodeBs `json:"gNodeBs"`
 
-	// UPF which belong to this slice
+	// UPF which belong to this slice. Kept for the clients using the single
+	// UPF shape, it holds the preferred UPF of Upfs.
 	Upf map[string]interface{} `json:"upf,omitempty"`
+
+	// UPFs which belong to this slice, by order of preference
+	Upfs []SliceSiteInfoUpf `json:"upfs,omitempty"`
+}
+
+// SliceSiteInfoUpf - inventory UPF serving the slice. The UPFs with the lowest
+// priority are preferred, and share the load according to their weight. A UPF
+// without DNNs serves all the DNNs of the slice.
+type SliceSiteInfoUpf struct {
+	UpfName string `json:"upf-name"`
+
+	UpfPort string `json:"upf-port,omitempty"`
+
+	Priority int32 `json:"priority,omitempty"`
+
+	Weight int32 `json:"weight,omitempty"`
+
+	Dnns []string `json:"dnns,omitempty"`
+}
+
+// UpfList returns the UPFs of the site, converting the single UPF shape when
+// the list is empty.
+func (s SliceSiteInfo) UpfList() []SliceSiteInfoUpf {
+	if len(s.Upfs) > 0 {
+		return s.Upfs
+	}
+	name, _ := s.Upf["upf-name"].(string)
+	if name == "" {
+		return nil
+	}
+	upf := SliceSiteInfoUpf{UpfName: name}
+	switch port := s.Upf["upf-port"].(type) {
+	case string:
+		upf.UpfPort = port
+	case float64:
+		upf.UpfPort = strconv.FormatFloat(port, 'f', -1, 64)
+	case int32:
+		upf.UpfPort = strconv.FormatInt(int64(port), 10)
+	case int64:
+		upf.UpfPort = strconv.FormatInt(port, 10)
+	}
+	return []SliceSiteInfoUpf{upf}
+}
+
+// PrimaryUpf returns the preferred UPF of the site.
+func (s SliceSiteInfo) PrimaryUpf() (SliceSiteInfoUpf, bool) {
+	upfs := s.UpfList()
+	if len(upfs) == 0 {
+		return SliceSiteInfoUpf{}, false
+	}
+	return upfs[0], true
+}
+
+// SetUpfs replaces the UPFs of the site, ordering them by priority and keeping
+// the single UPF shape in sync with the preferred one.
+func (s *SliceSiteInfo) SetUpfs(upfs []SliceSiteInfoUpf) {
+	if len(upfs) == 0 {
+		s.Upfs = nil
+		s.Upf = nil
+		return
+	}
+	upfs = slices.Clone(upfs)
+	slices.SortStableFunc(upfs, func(a, b SliceSiteInfoUpf) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	s.Upfs = upfs
+	s.Upf = map[string]interface{}{"upf-name": upfs[0].UpfName}
+	if upfs[0].UpfPort != "" {
+		s.Upf["upf-port"] = upfs[0].UpfPort
+	}
+}
+
+// NormalizeUpfs stores the UPFs of the site in both shapes.
+func (s *SliceSiteInfo) NormalizeUpfs() {
+	s.SetUpfs(s.UpfList())
 }
@@ -9,3 +9,9 @@ type SubsListIE struct {
 	PlmnID string `json:"plmnID"`
 	UeId   string `json:"ueId"`
 }
+
+type SubsListPage struct {
+	Subscribers   []SubsListIE `json:"subscribers"`
+	Total         int64        `json:"total"`
+	NextPageToken string       `json:"next_page_token,omitempty"`
+}
@@ -14,15 +14,27 @@ const (
 
 const UserAccountDataColl = "webconsoleData.snapshots.userAccountData"
 
+// DBUserAccount is a user account. Service accounts have no password, they
+// can only authenticate with API tokens. Neither have the accounts created by
+// an identity provider: the single sign-on, which logs them in as the OpenID
+// Connect subject OIDCSubject, or the LDAP directory, where they are the entry
+// LDAPDN. Roles are the names of the roles assigned to the account, see
+// AccountRoles.
 type DBUserAccount struct {
-	Username       string `json:"username"`
-	HashedPassword string `json:"password,omitempty"`
-	Role           int    `json:"role"`
+	Username       string   `json:"username"`
+	HashedPassword string   `json:"password,omitempty"`
+	Role           int      `json:"role"`
+	ServiceAccount bool     `json:"service-account,omitempty"`
+	OIDCSubject    string   `json:"oidc-subject,omitempty"`
+	LDAPDN         string   `json:"ldap-dn,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
 }
 
 type CreateUserAccountParams struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username       string   `json:"username"`
+	Password       string   `json:"password"`
+	ServiceAccount bool     `json:"service-account,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
 }
 
 type ChangePasswordParams struct {
@@ -30,8 +42,23 @@ type ChangePasswordParams struct {
 }
 
 type GetUserAccountResponse struct {
-	Username string `json:"username"`
-	Role     int    `json:"role"`
+	Username       string   `json:"username"`
+	Role           int      `json:"role"`
+	ServiceAccount bool     `json:"service-account,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+}
+
+// AccountRoles returns the roles the permissions of an account come from: the
+// admin role for the admin account, the assigned roles for the others, or the
+// user role if none is assigned.
+func (a *DBUserAccount) AccountRoles() []string {
+	if a.Role == AdminRole {
+		return []string{RoleAdmin}
+	}
+	if len(a.Roles) == 0 {
+		return []string{RoleUser}
+	}
+	return a.Roles
 }
 
 func CreateNewDBUserAccount(username string, password string, role int) (*DBUserAccount, error) {
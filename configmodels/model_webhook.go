@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 Canonical Ltd.
+
+package configmodels
+
+import "time"
+
+const (
+	WebhookDataColl         = "webconsoleData.snapshots.webhookData"
+	WebhookDeliveryDataColl = "webconsoleData.snapshots.webhookDeliveryData"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// ConfigChange notifies a write to a network slice, device group, subscriber,
+// gNB or UPF. Kind takes the ApplyKind* values or ConfigEventTypeSubscriber and
+// Operation the ApplyOp* values.
+type ConfigChange struct {
+	Kind      string    `json:"kind"`
+	Operation string    `json:"operation"`
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Webhook receives the configuration changes of the given kinds, or of all
+// kinds if none is given. The payloads are signed with Secret. Webhooks defined
+// in the configuration file are ReadOnly.
+type Webhook struct {
+	Name     string   `json:"name"`
+	Url      string   `json:"url"`
+	Secret   string   `json:"secret,omitempty"`
+	Kinds    []string `json:"kinds,omitempty"`
+	ReadOnly bool     `json:"read-only,omitempty"`
+}
+
+// WebhookDelivery is the delivery of a configuration change to a webhook.
+type WebhookDelivery struct {
+	Id             string       `json:"id"`
+	Webhook        string       `json:"webhook"`
+	Change         ConfigChange `json:"change"`
+	Status         string       `json:"status"`
+	Attempts       int          `json:"attempts"`
+	NextAttempt    time.Time    `json:"next-attempt"`
+	ResponseStatus int          `json:"response-status,omitempty"`
+	LastError      string       `json:"last-error,omitempty"`
+	CreatedAt      time.Time    `json:"created-at"`
+	UpdatedAt      time.Time    `json:"updated-at"`
+}
+
+// WebhookPayload is the body posted to a webhook.
+type WebhookPayload struct {
+	DeliveryId string `json:"delivery-id"`
+	ConfigChange
+}
@@ -7,6 +7,7 @@ package dbadapter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,11 +18,14 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type DBInterface interface {
 	RestfulAPIGetOne(collName string, filter bson.M) (map[string]interface{}, error)
 	RestfulAPIGetMany(collName string, filter bson.M) ([]map[string]interface{}, error)
+	RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error)
+	RestfulAPIForEachWithContext(context context.Context, collName string, filter bson.M, fn func(map[string]interface{}) error) error
 	RestfulAPIPutOneTimeout(collName string, filter bson.M, putData map[string]interface{}, timeout int32, timeField string) bool
 	RestfulAPIPutOne(collName string, filter bson.M, putData map[string]interface{}) (bool, error)
 	RestfulAPIPutOneWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error)
@@ -30,6 +34,7 @@ type DBInterface interface {
 	RestfulAPIDeleteOne(collName string, filter bson.M) error
 	RestfulAPIDeleteOneWithContext(context context.Context, collName string, filter bson.M) error
 	RestfulAPIDeleteMany(collName string, filter bson.M) error
+	RestfulAPIDeleteManyWithContext(context context.Context, collName string, filter bson.M) error
 	RestfulAPIMergePatch(collName string, filter bson.M, patchData map[string]interface{}) error
 	RestfulAPIJSONPatch(collName string, filter bson.M, patchJSON []byte) error
 	RestfulAPIJSONPatchWithContext(context context.Context, collName string, filter bson.M, patchJSON []byte) error
@@ -41,7 +46,14 @@ type DBInterface interface {
 	RestfulAPICount(collName string, filter bson.M) (int64, error)
 	RestfulAPIPullOne(collName string, filter bson.M, putData map[string]interface{}) error
 	RestfulAPIPullOneWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) error
+	RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error)
+	RestfulAPIReplaceOneWithContext(context context.Context, collName string, filter bson.M, replacement map[string]interface{}) (bool, error)
+	RestfulAPICompareAndDeleteWithContext(context context.Context, collName string, filter bson.M) (bool, error)
+	RestfulAPIFindOneAndUpdateWithContext(context context.Context, collName string, filter bson.M, update bson.M) (map[string]interface{}, error)
 	CreateIndex(collName string, keyField string) (bool, error)
+	CreateCompoundIndex(collName string, keyFields []string) (bool, error)
+	CreateNonUniqueIndex(collName string, keyField string) (bool, error)
+	RestfulAPICreateTTLIndex(collName string, timeout int32, timeField string) bool
 	StartSession() (mongo.Session, error)
 	SupportsTransactions() (bool, error)
 }
@@ -54,6 +66,7 @@ var (
 
 type MongoDBClient struct {
 	mongoapi.MongoClient
+	dbName string
 }
 type SessionRunner func(ctx context.Context, fn func(sc mongo.SessionContext) error) error
 
@@ -82,6 +95,7 @@ type PatchOperation struct {
 	Value interface{} `json:"value,omitempty"`
 	Op    string      `json:"op"`
 	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
 }
 
 func setDBClient(url, dbname string) (DBInterface, error) {
@@ -89,7 +103,7 @@ func setDBClient(url, dbname string) (DBInterface, error) {
 	if errConnect != nil {
 		return nil, errConnect
 	}
-	return &MongoDBClient{*mClient}, nil
+	return &MongoDBClient{MongoClient: *mClient, dbName: dbname}, nil
 }
 
 func ConnectMongo(url string, dbname string, client *DBInterface) {
@@ -175,6 +189,48 @@ func InitMongoDB() error {
 			logger.InitLog.Errorf("error creating gNB index in commonDB %v", err)
 			return err
 		}
+		if resp, err := CommonDBClient.CreateIndex(configmodels.ConfigEventDataColl, "revision"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating config event index in commonDB %v", err)
+			return err
+		}
+		if resp, err := CommonDBClient.CreateIndex(configmodels.ConfigEventCounterColl, "name"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating config event counter index in commonDB %v", err)
+			return err
+		}
+		if resp, err := CommonDBClient.CreateCompoundIndex(configmodels.ConfigRevisionDataColl, []string{"kind", "name", "revision"}); !resp || err != nil {
+			logger.InitLog.Errorf("error creating config revision index in commonDB %v", err)
+			return err
+		}
+		if resp, err := CommonDBClient.CreateIndex(configmodels.WebhookDataColl, "name"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating webhook index in commonDB %v", err)
+			return err
+		}
+		if resp, err := CommonDBClient.CreateIndex(configmodels.WebhookDeliveryDataColl, "id"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating webhook delivery index in commonDB %v", err)
+			return err
+		}
+		if resp, err := CommonDBClient.CreateIndex(configmodels.IdempotencyKeyDataColl, "key"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating idempotency key index in commonDB %v", err)
+			return err
+		}
+		// expired idempotency keys are removed by MongoDB at their expires-at date
+		if !CommonDBClient.RestfulAPICreateTTLIndex(configmodels.IdempotencyKeyDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.IdempotencyKeyDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
+		if resp, err := CommonDBClient.CreateIndex(configmodels.AuditKeyDataColl, "name"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating audit key index in commonDB %v", err)
+			return err
+		}
+		// the audit log is kept in the common database when authentication is
+		// disabled, its entries are removed by MongoDB at their expires-at date
+		if !factory.WebUIConfig.Configuration.EnableAuthentication &&
+			!CommonDBClient.RestfulAPICreateTTLIndex(configmodels.AuditLogDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.AuditLogDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
 	}
 	if factory.WebUIConfig.Configuration.EnableAuthentication {
 		ConnectMongo(mongodb.WebuiDBUrl, mongodb.WebuiDBName, &WebuiDBClient)
@@ -182,6 +238,80 @@ func InitMongoDB() error {
 			logger.InitLog.Errorf("error initializing webuiDB %v", err)
 			return err
 		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.ApiTokenDataColl, "token-hash"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating API token index in webuiDB %v", err)
+			return err
+		}
+		// expired API tokens are removed by MongoDB at their expires-at date,
+		// tokens without one are kept until revoked
+		if !WebuiDBClient.RestfulAPICreateTTLIndex(configmodels.ApiTokenDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.ApiTokenDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.RoleDataColl, "name"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating role index in webuiDB %v", err)
+			return err
+		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.SessionDataColl, "id"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating session index in webuiDB %v", err)
+			return err
+		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.SessionDataColl, "refresh-token-hash"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating refresh token index in webuiDB %v", err)
+			return err
+		}
+		// looks up the session of a replaced refresh token presented again
+		if resp, err := WebuiDBClient.CreateNonUniqueIndex(configmodels.SessionDataColl, "used-refresh-token-hashes"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating used refresh token index in webuiDB %v", err)
+			return err
+		}
+		// sessions are removed by MongoDB once their refresh token expired
+		if !WebuiDBClient.RestfulAPICreateTTLIndex(configmodels.SessionDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.SessionDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.OIDCLoginDataColl, "state"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating OIDC login index in webuiDB %v", err)
+			return err
+		}
+		// abandoned OIDC logins are removed by MongoDB once expired
+		if !WebuiDBClient.RestfulAPICreateTTLIndex(configmodels.OIDCLoginDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.OIDCLoginDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.JwtKeyDataColl, "kid"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating JWT key index in webuiDB %v", err)
+			return err
+		}
+		// replaced JWT keys are removed by MongoDB once the tokens they signed expired
+		if !WebuiDBClient.RestfulAPICreateTTLIndex(configmodels.JwtKeyDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.JwtKeyDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.LoginFailureDataColl, "key"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating login failure index in webuiDB %v", err)
+			return err
+		}
+		// failed logins are forgotten by MongoDB once their window or lockout ended
+		if !WebuiDBClient.RestfulAPICreateTTLIndex(configmodels.LoginFailureDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.LoginFailureDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
+		if resp, err := WebuiDBClient.CreateIndex(configmodels.AuditKeyDataColl, "name"); !resp || err != nil {
+			logger.InitLog.Errorf("error creating audit key index in webuiDB %v", err)
+			return err
+		}
+		// the audit log entries are removed by MongoDB at their expires-at date
+		if !WebuiDBClient.RestfulAPICreateTTLIndex(configmodels.AuditLogDataColl, 0, "expires-at") {
+			err := fmt.Errorf("failed to create the TTL index of %s", configmodels.AuditLogDataColl)
+			logger.InitLog.Errorln(err)
+			return err
+		}
 	}
 
 	logger.InitLog.Info("MongoDB initialization completed successfully")
@@ -196,6 +326,71 @@ func (db *MongoDBClient) RestfulAPIGetMany(collName string, filter bson.M) ([]ma
 	return db.MongoClient.RestfulAPIGetMany(collName, filter)
 }
 
+// RestfulAPIGetManyPaged returns at most limit documents matching filter, ordered by sort,
+// after skipping the first skip documents. A limit of 0 means no limit.
+func (db *MongoDBClient) RestfulAPIGetManyPaged(collName string, filter bson.M, sort bson.D, skip int64, limit int64) ([]map[string]interface{}, error) {
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	findOptions := options.Find().SetSort(sort).SetSkip(skip).SetLimit(limit)
+	cur, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("RestfulAPIGetManyPaged err: %+v", err)
+	}
+	defer func() {
+		if err := cur.Close(ctx); err != nil {
+			logger.DbLog.Warnf("failed to close cursor: %+v", err)
+		}
+	}()
+
+	var resultArray []map[string]interface{}
+	for cur.Next(ctx) {
+		var result map[string]interface{}
+		if err := cur.Decode(&result); err != nil {
+			return nil, fmt.Errorf("RestfulAPIGetManyPaged err: %+v", err)
+		}
+		// Delete "_id" entry which is auto-inserted by MongoDB
+		delete(result, "_id")
+		resultArray = append(resultArray, result)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("RestfulAPIGetManyPaged err: %+v", err)
+	}
+	return resultArray, nil
+}
+
+// RestfulAPIForEachWithContext calls fn with every document matching filter as
+// the cursor reads them, so that a whole collection is never held in memory.
+// It stops at the first error fn returns.
+func (db *MongoDBClient) RestfulAPIForEachWithContext(context context.Context, collName string, filter bson.M, fn func(map[string]interface{}) error) error {
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	cur, err := collection.Find(context, filter)
+	if err != nil {
+		return fmt.Errorf("RestfulAPIForEachWithContext err: %+v", err)
+	}
+	defer func() {
+		if err := cur.Close(context); err != nil {
+			logger.DbLog.Warnf("failed to close cursor: %+v", err)
+		}
+	}()
+	for cur.Next(context) {
+		var result map[string]interface{}
+		if err := cur.Decode(&result); err != nil {
+			return fmt.Errorf("RestfulAPIForEachWithContext err: %+v", err)
+		}
+		// Delete "_id" entry which is auto-inserted by MongoDB
+		delete(result, "_id")
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("RestfulAPIForEachWithContext err: %+v", err)
+	}
+	return nil
+}
+
 func (db *MongoDBClient) RestfulAPIPutOneTimeout(collName string, filter bson.M, putData map[string]interface{}, timeout int32, timeField string) bool {
 	return db.MongoClient.RestfulAPIPutOneTimeout(collName, filter, putData, timeout, timeField)
 }
@@ -205,6 +400,7 @@ func (db *MongoDBClient) RestfulAPIPutOne(collName string, filter bson.M, putDat
 }
 
 func (db *MongoDBClient) RestfulAPIPutOneWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	db.logWrite(context, "RestfulAPIPutOneWithContext", collName, filter)
 	return db.MongoClient.RestfulAPIPutOneWithContext(context, collName, filter, putData)
 }
 
@@ -221,6 +417,7 @@ func (db *MongoDBClient) RestfulAPIDeleteOne(collName string, filter bson.M) err
 }
 
 func (db *MongoDBClient) RestfulAPIDeleteOneWithContext(context context.Context, collName string, filter bson.M) error {
+	db.logWrite(context, "RestfulAPIDeleteOneWithContext", collName, filter)
 	return db.MongoClient.RestfulAPIDeleteOneWithContext(context, collName, filter)
 }
 
@@ -228,6 +425,15 @@ func (db *MongoDBClient) RestfulAPIDeleteMany(collName string, filter bson.M) er
 	return db.MongoClient.RestfulAPIDeleteMany(collName, filter)
 }
 
+func (db *MongoDBClient) RestfulAPIDeleteManyWithContext(context context.Context, collName string, filter bson.M) error {
+	db.logWrite(context, "RestfulAPIDeleteManyWithContext", collName, filter)
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	if _, err := collection.DeleteMany(context, filter); err != nil {
+		return fmt.Errorf("RestfulAPIDeleteManyWithContext err: %+v", err)
+	}
+	return nil
+}
+
 func (db *MongoDBClient) RestfulAPIMergePatch(collName string, filter bson.M, patchData map[string]interface{}) error {
 	return db.MongoClient.RestfulAPIMergePatch(collName, filter, patchData)
 }
@@ -237,6 +443,7 @@ func (db *MongoDBClient) RestfulAPIJSONPatch(collName string, filter bson.M, pat
 }
 
 func (db *MongoDBClient) RestfulAPIJSONPatchWithContext(context context.Context, collName string, filter bson.M, patchJSON []byte) error {
+	db.logWrite(context, "RestfulAPIJSONPatchWithContext", collName, filter)
 	return db.MongoClient.RestfulAPIJSONPatchWithContext(context, collName, filter, patchJSON)
 }
 
@@ -249,15 +456,29 @@ func (db *MongoDBClient) RestfulAPIPost(collName string, filter bson.M, postData
 }
 
 func (db *MongoDBClient) RestfulAPIPostWithContext(context context.Context, collName string, filter bson.M, postData map[string]interface{}) (bool, error) {
+	db.logWrite(context, "RestfulAPIPostWithContext", collName, filter)
 	return db.MongoClient.RestfulAPIPostWithContext(context, collName, filter, postData)
 }
 
 func (db *MongoDBClient) RestfulAPIPostMany(collName string, filter bson.M, postDataArray []interface{}) error {
-	return db.MongoClient.RestfulAPIPostMany(collName, filter, postDataArray)
+	return db.insertMany(context.TODO(), collName, postDataArray)
 }
 
 func (db *MongoDBClient) RestfulAPIPostManyWithContext(context context.Context, collName string, filter bson.M, postDataArray []interface{}) error {
-	return db.MongoClient.RestfulAPIPostManyWithContext(context, collName, filter, postDataArray)
+	db.logWrite(context, "RestfulAPIPostManyWithContext", collName, filter)
+	return db.insertMany(context, collName, postDataArray)
+}
+
+// insertMany inserts the documents in collName. The mongoapi client formats
+// the driver errors with %+v, which drops their type, so the documents are
+// inserted here and the error is wrapped for IsDuplicateKeyError and
+// IsWriteConflictError.
+func (db *MongoDBClient) insertMany(ctx context.Context, collName string, documents []interface{}) error {
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	if _, err := collection.InsertMany(ctx, documents); err != nil {
+		return fmt.Errorf("RestfulAPIPostMany err: %w", err)
+	}
+	return nil
 }
 
 func (db *MongoDBClient) RestfulAPICount(collName string, filter bson.M) (int64, error) {
@@ -269,13 +490,107 @@ func (db *MongoDBClient) RestfulAPIPullOne(collName string, filter bson.M, putDa
 }
 
 func (db *MongoDBClient) RestfulAPIPullOneWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) error {
+	db.logWrite(context, "RestfulAPIPullOneWithContext", collName, filter)
 	return db.MongoClient.RestfulAPIPullOneWithContext(context, collName, filter, putData)
 }
 
+// RestfulAPICompareAndSwapWithContext sets putData on the document matching
+// filter, which usually includes the version the caller expects. Unlike the put
+// operations it never inserts a document, and it reports whether one matched.
+func (db *MongoDBClient) RestfulAPICompareAndSwapWithContext(context context.Context, collName string, filter bson.M, putData map[string]interface{}) (bool, error) {
+	db.logWrite(context, "RestfulAPICompareAndSwapWithContext", collName, filter)
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	result, err := collection.UpdateOne(context, filter, bson.M{"$set": putData})
+	if err != nil {
+		return false, fmt.Errorf("RestfulAPICompareAndSwapWithContext err: %+v", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// RestfulAPIReplaceOneWithContext replaces the whole document matching filter,
+// so that the fields missing from replacement are removed. It never inserts a
+// document, and it reports whether one matched.
+func (db *MongoDBClient) RestfulAPIReplaceOneWithContext(context context.Context, collName string, filter bson.M, replacement map[string]interface{}) (bool, error) {
+	db.logWrite(context, "RestfulAPIReplaceOneWithContext", collName, filter)
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	result, err := collection.ReplaceOne(context, filter, replacement)
+	if err != nil {
+		return false, fmt.Errorf("RestfulAPIReplaceOneWithContext err: %+v", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// RestfulAPICompareAndDeleteWithContext deletes the document matching filter,
+// which usually includes the version the caller expects, and reports whether
+// one was deleted.
+func (db *MongoDBClient) RestfulAPICompareAndDeleteWithContext(context context.Context, collName string, filter bson.M) (bool, error) {
+	db.logWrite(context, "RestfulAPICompareAndDeleteWithContext", collName, filter)
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	result, err := collection.DeleteOne(context, filter)
+	if err != nil {
+		return false, fmt.Errorf("RestfulAPICompareAndDeleteWithContext err: %+v", err)
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// RestfulAPIFindOneAndUpdateWithContext atomically applies the update operators
+// to the document matching filter, inserting it if there is none, and returns
+// the updated document.
+func (db *MongoDBClient) RestfulAPIFindOneAndUpdateWithContext(context context.Context, collName string, filter bson.M, update bson.M) (map[string]interface{}, error) {
+	db.logWrite(context, "RestfulAPIFindOneAndUpdateWithContext", collName, filter)
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var result map[string]interface{}
+	if err := collection.FindOneAndUpdate(context, filter, update, opts).Decode(&result); err != nil {
+		return nil, fmt.Errorf("RestfulAPIFindOneAndUpdateWithContext err: %+v", err)
+	}
+	return result, nil
+}
+
+// logWrite records a write done in a context at debug level, together with the
+// ID of the API request that caused it.
+func (db *MongoDBClient) logWrite(ctx context.Context, operation, collName string, filter bson.M) {
+	logger.WithRequestID(logger.DbLog, logger.RequestIDFromContext(ctx)).Debugf("%s on %s.%s with filter %v", operation, db.dbName, collName, filter)
+}
+
 func (db *MongoDBClient) CreateIndex(collName string, keyField string) (bool, error) {
 	return db.MongoClient.CreateIndex(collName, keyField)
 }
 
+// CreateCompoundIndex creates a unique index on the combination of keyFields.
+func (db *MongoDBClient) CreateCompoundIndex(collName string, keyFields []string) (bool, error) {
+	keys := bson.D{}
+	for _, keyField := range keyFields {
+		keys = append(keys, bson.E{Key: keyField, Value: 1})
+	}
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateNonUniqueIndex creates an index on keyField that several documents, or
+// none, can share a value of, such as an array field.
+func (db *MongoDBClient) CreateNonUniqueIndex(collName string, keyField string) (bool, error) {
+	collection := db.MongoClient.Client.Database(db.dbName).Collection(collName)
+	_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: keyField, Value: 1}},
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (db *MongoDBClient) RestfulAPICreateTTLIndex(collName string, timeout int32, timeField string) bool {
+	return db.MongoClient.RestfulAPICreateTTLIndex(collName, timeout, timeField)
+}
+
 func (db *MongoDBClient) StartSession() (mongo.Session, error) {
 	return db.MongoClient.StartSession()
 }
@@ -283,3 +598,15 @@ func (db *MongoDBClient) StartSession() (mongo.Session, error) {
 func (db *MongoDBClient) SupportsTransactions() (bool, error) {
 	return db.MongoClient.SupportsTransactions()
 }
+
+// IsDuplicateKeyError reports whether err is a MongoDB duplicate key error.
+func IsDuplicateKeyError(err error) bool {
+	return mongo.IsDuplicateKeyError(err)
+}
+
+// IsWriteConflictError reports whether err is a MongoDB write conflict, which
+// a transaction gets when a concurrent one wrote the same documents.
+func IsWriteConflictError(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(112)
+}
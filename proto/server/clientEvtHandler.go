@@ -45,6 +45,7 @@ type clientReqMsg struct {
 	devGroup           *configmodels.DeviceGroups
 	slice              *configmodels.Slice
 	newClient          bool
+	requestID          string
 }
 
 // message format to send response from client go routine to grpc server
@@ -243,10 +244,20 @@ func fillSite(siteInfoConf *configmodels.SliceSiteInfo, siteInfoProto *protos.Si
 	pl.Mnc = siteInfoConf.Plmn.Mnc
 	siteInfoProto.Plmn = pl
 
+	// SiteInfo carries a single UPF, the preferred one of the slice
+	upfConf, ok := siteInfoConf.PrimaryUpf()
+	if !ok {
+		return
+	}
 	upf := &protos.UpfInfo{}
-	upf.UpfName = siteInfoConf.Upf["upf-name"].(string)
-	// TODO panic
-	// upf.UpfPort = siteInfoConf.Upf["upf-port"].(uint32)
+	upf.UpfName = upfConf.UpfName
+	if upfConf.UpfPort != "" {
+		if port, err := strconv.ParseUint(upfConf.UpfPort, 10, 16); err == nil {
+			upf.UpfPort = uint32(port)
+		} else {
+			logger.GrpcLog.Warnf("invalid port %s for UPF %s: %v", upfConf.UpfPort, upfConf.UpfName, err)
+		}
+	}
 	siteInfoProto.Upf = upf
 }
 
@@ -476,30 +487,36 @@ func clientEventMachine(client *clientNF) {
 			}
 
 		case configMsg := <-client.outStandingPushConfig:
+			if configMsg.MsgType == configmodels.Config_sync {
+				syncClientConfig(client, configMsg)
+				continue
+			}
+			// the request ID correlates the push with the API call that changed the config
+			pushLog := logger.WithRequestID(client.clientLog, configMsg.RequestID)
 			var lastDevGroup *configmodels.DeviceGroups
 			var lastSlice *configmodels.Slice
 
 			// update config snapshot
 			if configMsg.DevGroup != nil {
 				lastDevGroup = client.devgroupsConfigClient[configMsg.DevGroupName]
-				client.clientLog.Debugf("Received configuration for device Group  %v ", configMsg.DevGroupName)
+				pushLog.Debugf("Received configuration for device Group  %v ", configMsg.DevGroupName)
 				client.devgroupsConfigClient[configMsg.DevGroupName] = configMsg.DevGroup
 			} else if configMsg.DevGroupName != "" && configMsg.MsgMethod == configmodels.Delete_op {
 				lastDevGroup = client.devgroupsConfigClient[configMsg.DevGroupName]
-				client.clientLog.Debugf("Received delete configuration for  Device Group: %v ", configMsg.DevGroupName)
+				pushLog.Debugf("Received delete configuration for  Device Group: %v ", configMsg.DevGroupName)
 				delete(client.devgroupsConfigClient, configMsg.DevGroupName)
 			}
 
 			if configMsg.Slice != nil {
 				lastSlice = client.slicesConfigClient[configMsg.SliceName]
-				client.clientLog.Infof("Received new configuration for slice %v ", configMsg.SliceName)
+				pushLog.Infof("Received new configuration for slice %v ", configMsg.SliceName)
 				client.slicesConfigClient[configMsg.SliceName] = configMsg.Slice
 			} else if configMsg.SliceName != "" && configMsg.MsgMethod == configmodels.Delete_op {
 				lastSlice = client.slicesConfigClient[configMsg.SliceName]
-				client.clientLog.Infof("Received delete configuration for Slice: %v ", configMsg.SliceName)
+				pushLog.Infof("Received delete configuration for Slice: %v ", configMsg.SliceName)
 				// checking whether the slice is exist or not
 				if lastSlice == nil {
-					client.clientLog.Warnf("Received non-exist slice: [%v] from Roc/Simapp", configMsg.SliceName)
+					pushLog.Warnf("Received non-exist slice: [%v] from Roc/Simapp", configMsg.SliceName)
 					continue
 				}
 				delete(client.slicesConfigClient, configMsg.SliceName)
@@ -509,7 +526,7 @@ func clientEventMachine(client *clientNF) {
 			/*If client is attached through stream, then
 			  send update to client */
 			if client.resStream != nil {
-				client.clientLog.Infoln("resStream available")
+				pushLog.Infoln("resStream available")
 				var reqMsg clientReqMsg
 				var nReq protos.NetworkSliceRequest
 				nReq.MetadataRequested = client.metadataReqtd
@@ -520,8 +537,9 @@ func clientEventMachine(client *clientNF) {
 				reqMsg.lastSlice = lastSlice
 				reqMsg.devGroup = configMsg.DevGroup
 				reqMsg.slice = configMsg.Slice
+				reqMsg.requestID = configMsg.RequestID
 				client.tempGrpcReq <- &reqMsg
-				client.clientLog.Infoln("sent data to client from push config ")
+				pushLog.Infoln("sent data to client from push config ")
 			}
 			if !factory.WebUIConfig.Configuration.Mode5G {
 				// push config to 4G network functions
@@ -723,12 +741,73 @@ func clientEventMachine(client *clientNF) {
 					}
 				}
 			}
-			client.clientLog.Infoln("send slice success")
+			logger.WithRequestID(client.clientLog, cReqMsg.requestID).Infoln("send slice success")
 			client.configChanged = false // TODO RACE CONDITION
 		}
 	}
 }
 
+// syncClientConfig applies the device groups and network slices of a
+// Config_sync message to the snapshot of the client, then pushes the resulting
+// configuration once, as to a new client, rather than change by change.
+func syncClientConfig(client *clientNF, configMsg *configmodels.ConfigMessage) {
+	pushLog := logger.WithRequestID(client.clientLog, configMsg.RequestID)
+	pushLog.Infof("Received configuration of %d device groups and %d slices, deleting %d device groups and %d slices",
+		len(configMsg.DevGroups), len(configMsg.Slices), len(configMsg.DeletedDevGroupNames), len(configMsg.DeletedSliceNames))
+	var removedImsis []string
+	for _, name := range configMsg.DeletedDevGroupNames {
+		removedImsis = append(removedImsis, deletedImsis(client.devgroupsConfigClient[name], nil)...)
+		delete(client.devgroupsConfigClient, name)
+	}
+	for _, name := range configMsg.DeletedSliceNames {
+		delete(client.slicesConfigClient, name)
+	}
+	for _, devGroup := range configMsg.DevGroups {
+		removedImsis = append(removedImsis, deletedImsis(client.devgroupsConfigClient[devGroup.DeviceGroupName], devGroup)...)
+		client.devgroupsConfigClient[devGroup.DeviceGroupName] = devGroup
+	}
+	for _, slice := range configMsg.Slices {
+		client.slicesConfigClient[slice.SliceName] = slice
+	}
+
+	client.configChanged = true
+	if client.resStream != nil {
+		var reqMsg clientReqMsg
+		reqMsg.networkSliceReqMsg = &protos.NetworkSliceRequest{MetadataRequested: client.metadataReqtd}
+		reqMsg.grpcRspMsg = make(chan *clientRspMsg)
+		reqMsg.newClient = true
+		reqMsg.requestID = configMsg.RequestID
+		client.tempGrpcReq <- &reqMsg
+		pushLog.Infoln("sent complete snapshot to client from push config")
+	}
+	if factory.WebUIConfig.Configuration.Mode5G {
+		return
+	}
+	switch client.id {
+	case "hss":
+		remaining := map[string]bool{}
+		for _, devGroup := range client.devgroupsConfigClient {
+			for _, imsi := range devGroup.Imsis {
+				remaining[imsi] = true
+			}
+		}
+		for _, imsi := range removedImsis {
+			if !remaining[imsi] {
+				deleteConfigHss(client, imsi)
+			}
+		}
+		rwLock.RLock()
+		postConfigHss(client, nil, nil)
+		rwLock.RUnlock()
+	case "mme-app", "mme-s1ap":
+		postConfigMme(client)
+	case "pcrf":
+		postConfigPcrf(client)
+	case "spgw":
+		postConfigSpgw(client)
+	}
+}
+
 func postConfigMme(client *clientNF) {
 	if len(client.slicesConfigClient) == 0 {
 		client.clientLog.Infoln("Not posting config to MME since number of slices: 0")
@@ -1226,7 +1305,9 @@ func postConfigSpgw(client *clientNF) {
 			// user plane profile
 			var upProf userPlaneProfile
 			userProfName := sliceName + "_up"
-			upProf.UserPlane = siteInfo.Upf["upf-name"].(string)
+			if upf, ok := siteInfo.PrimaryUpf(); ok {
+				upProf.UserPlane = upf.UpfName
+			}
 			upProf.GlobalAddress = true
 			config.UserPlaneProfiles[userProfName] = &upProf
 			rule.SelectedUserPlaneProfile = userProfName
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2025 Canonical Ltd
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"testing"
+
+	protos "github.com/omec-project/config5g/proto/sdcoreConfig"
+	"github.com/omec-project/webconsole/configmodels"
+)
+
+func Test_fillSite_upf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		siteInfo configmodels.SliceSiteInfo
+		expected *protos.UpfInfo
+	}{
+		{
+			name:     "single UPF shape",
+			siteInfo: configmodels.SliceSiteInfo{Upf: map[string]interface{}{"upf-name": "upf1.my-domain.com", "upf-port": "8805"}},
+			expected: &protos.UpfInfo{UpfName: "upf1.my-domain.com", UpfPort: 8805},
+		},
+		{
+			name: "UPF list sends the preferred UPF",
+			siteInfo: configmodels.SliceSiteInfo{Upfs: []configmodels.SliceSiteInfoUpf{
+				{UpfName: "upf1.my-domain.com", UpfPort: "8805", Priority: 1},
+				{UpfName: "upf2.my-domain.com", UpfPort: "8806", Priority: 2},
+			}},
+			expected: &protos.UpfInfo{UpfName: "upf1.my-domain.com", UpfPort: 8805},
+		},
+		{
+			name:     "invalid port",
+			siteInfo: configmodels.SliceSiteInfo{Upfs: []configmodels.SliceSiteInfoUpf{{UpfName: "upf1.my-domain.com", UpfPort: "a"}}},
+			expected: &protos.UpfInfo{UpfName: "upf1.my-domain.com"},
+		},
+		{
+			name:     "no UPF",
+			siteInfo: configmodels.SliceSiteInfo{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			siteInfoProto := &protos.SiteInfo{}
+			fillSite(&tc.siteInfo, siteInfoProto)
+			if tc.expected == nil {
+				if siteInfoProto.Upf != nil {
+					t.Errorf("expected no UPF, got %+v", siteInfoProto.Upf)
+				}
+				return
+			}
+			if siteInfoProto.Upf.GetUpfName() != tc.expected.UpfName || siteInfoProto.Upf.GetUpfPort() != tc.expected.UpfPort {
+				t.Errorf("expected UPF %s:%d, got %s:%d", tc.expected.UpfName, tc.expected.UpfPort,
+					siteInfoProto.Upf.GetUpfName(), siteInfoProto.Upf.GetUpfPort())
+			}
+		})
+	}
+}
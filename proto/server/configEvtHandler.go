@@ -38,9 +38,11 @@ func configHandler(configMsgChan chan *configmodels.ConfigMessage, configReceive
 	for {
 		logger.ConfigLog.Infoln("waiting for configuration event")
 		configMsg := <-configMsgChan
+		configapi.QueueConfigEvent(configMsg)
 
 		if configMsg.MsgMethod == configmodels.Post_op || configMsg.MsgMethod == configmodels.Put_op {
-			if !firstConfigRcvd && (configMsg.MsgType == configmodels.Device_group || configMsg.MsgType == configmodels.Network_slice) {
+			if !firstConfigRcvd && (configMsg.MsgType == configmodels.Device_group || configMsg.MsgType == configmodels.Network_slice ||
+				(configMsg.MsgType == configmodels.Config_sync && (len(configMsg.DevGroups) > 0 || len(configMsg.Slices) > 0))) {
 				logger.ConfigLog.Debugln("first config received from ROC")
 				firstConfigRcvd = true
 				configReceived <- true
@@ -50,7 +52,7 @@ func configHandler(configMsgChan chan *configmodels.ConfigMessage, configReceive
 			logger.ConfigLog.Infoln("no client available. No need to send config")
 		}
 		for _, client := range clientNFPool {
-			logger.ConfigLog.Infoln("push config for client:", client.id)
+			logger.WithRequestID(logger.ConfigLog, configMsg.RequestID).Infoln("push config for client:", client.id)
 			client.outStandingPushConfig <- configMsg
 		}
 	}
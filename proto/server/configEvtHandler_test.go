@@ -5,6 +5,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -203,12 +204,12 @@ func (m *InMemoryAuthDataStore) SubscriberAuthenticationDataCreate(imsi string,
 	return nil
 }
 
-func (m *InMemoryAuthDataStore) SubscriberAuthenticationDataUpdate(imsi string, authSubData *models.AuthenticationSubscription) error {
+func (m *InMemoryAuthDataStore) SubscriberAuthenticationDataUpdate(ctx context.Context, imsi string, authSubData *models.AuthenticationSubscription) error {
 	m.store[imsi] = authSubData
 	return nil
 }
 
-func (m *InMemoryAuthDataStore) SubscriberAuthenticationDataDelete(imsi string) error {
+func (m *InMemoryAuthDataStore) SubscriberAuthenticationDataDelete(ctx context.Context, imsi string) error {
 	delete(m.store, imsi)
 	return nil
 }
@@ -22,7 +22,7 @@ type mockWebUI struct {
 	startedChan chan struct{}
 }
 
-func (m *mockWebUI) Start(ctx context.Context, syncChan chan<- struct{}) {
+func (m *mockWebUI) Start(ctx context.Context, syncChan chan<- string) {
 	select {
 	case <-ctx.Done():
 		return
@@ -36,20 +36,20 @@ func (m *mockWebUI) Start(ctx context.Context, syncChan chan<- struct{}) {
 
 type mockNFConfigSuccess struct{}
 
-func (m *mockNFConfigSuccess) Start(ctx context.Context, syncChan <-chan struct{}) error {
+func (m *mockNFConfigSuccess) Start(ctx context.Context, syncChan <-chan string) error {
 	time.Sleep(50 * time.Millisecond)
 	return nil
 }
 
 type mockNFConfigFail struct{}
 
-func (m *mockNFConfigFail) Start(ctx context.Context, syncChan <-chan struct{}) error {
+func (m *mockNFConfigFail) Start(ctx context.Context, syncChan <-chan string) error {
 	return errors.New("NFConfig start failed")
 }
 
 type MockNFConfig struct{}
 
-func (m *MockNFConfig) Start(ctx context.Context, syncChan <-chan struct{}) error {
+func (m *MockNFConfig) Start(ctx context.Context, syncChan <-chan string) error {
 	return nil
 }
 